@@ -121,6 +121,9 @@ goreleaser release --snapshot
 
 # Run a complete release:
 goreleaser release
+
+# Verify the checksums and signatures of a release:
+goreleaser verify
 		`,
 		PersistentPreRun: func(*cobra.Command, []string) {
 			if root.verbose {
@@ -143,6 +146,7 @@ goreleaser release
 		newInitCmd().cmd,
 		newManCmd().cmd,
 		newSchemaCmd().cmd,
+		newVerifyCmd().cmd,
 	)
 	root.cmd = cmd
 	return root
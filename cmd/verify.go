@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/verify"
+	"github.com/spf13/cobra"
+)
+
+type verifyCmd struct {
+	cmd          *cobra.Command
+	keys         []string
+	roots        []string
+	identities   []string
+	issuers      []string
+	sshNamespace string
+	quiet        bool
+}
+
+func newVerifyCmd() *verifyCmd {
+	root := &verifyCmd{}
+	cmd := &cobra.Command{
+		Use:   "verify [dir]",
+		Short: "Verifies the checksums and signatures of a release",
+		Long: `Verifies the checksums, signatures, and certificates of a release.
+
+The given directory (dist by default) must have the artifacts.json file created during the release.
+It can be either the dist directory itself, or a directory with the downloaded release files.
+
+Signatures are verified against the given public keys, which can be OpenPGP, minisign, SSH, or PEM public keys.
+Certificates are validated against the given root certificates, and must have one of the given identities or issuers.`,
+		Example: `
+# Verify the dist directory:
+goreleaser verify --key cosign.pub
+
+# Verify a downloaded release:
+goreleaser verify ./downloads --key minisign.pub --key ~/.ssh/id_ed25519.pub
+
+# Verify keyless signatures:
+goreleaser verify --ca fulcio.pem \
+  --certificate-identity https://github.com/user/repo/.github/workflows/release.yml@refs/tags/v1.0.0 \
+  --certificate-issuer https://token.actions.githubusercontent.com
+		`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if root.quiet {
+				log.Log = log.New(io.Discard)
+			}
+
+			dir := "dist"
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			log.WithField("dir", dir).Info(boldStyle.Render("verifying release..."))
+			checks, err := verify.Run(verify.Options{
+				Dir:          dir,
+				Keys:         root.keys,
+				Roots:        root.roots,
+				Identities:   root.identities,
+				Issuers:      root.issuers,
+				SSHNamespace: root.sshNamespace,
+			})
+			if err != nil {
+				return err
+			}
+
+			log.IncreasePadding()
+			defer log.ResetPadding()
+
+			if len(checks) == 0 {
+				log.Warn("no checksums or signatures found")
+			}
+
+			var failed bool
+			for _, c := range checks {
+				if err := check(c.Name, c.Err); err != nil {
+					failed = true
+				}
+			}
+			if failed {
+				return errors.New("one or more checks failed")
+			}
+
+			log.Infof(boldStyle.Render("done!"))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&root.keys, "key", "k", nil, "Public key to verify signatures with (can be used multiple times)")
+	cmd.Flags().StringArrayVar(&root.roots, "ca", nil, "Root certificates to validate certificate chains with, in PEM format (can be used multiple times)")
+	_ = cmd.MarkFlagFilename("ca", "pem", "crt")
+	cmd.Flags().StringArrayVar(&root.identities, "certificate-identity", nil, "Expected certificate identity, e.g. an email or URI in its subject alternative names (can be used multiple times)")
+	cmd.Flags().StringArrayVar(&root.issuers, "certificate-issuer", nil, "Expected certificate OIDC issuer, or issuer common name (can be used multiple times)")
+	cmd.Flags().StringVar(&root.sshNamespace, "ssh-namespace", "file", "Expected namespace of SSH signatures")
+	cmd.Flags().BoolVarP(&root.quiet, "quiet", "q", false, "Quiet mode: no output")
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/stretchr/testify/require"
+)
+
+func setupVerify(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "foo.tar.gz"), []byte("foo"), 0o644))
+	sum := sha256.Sum256([]byte("foo"))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "checksums.txt"), fmt.Appendf(nil, "%x  foo.tar.gz\n", sum), 0o644))
+	bts, err := json.Marshal([]artifact.Artifact{
+		{Name: "foo.tar.gz", Path: "dist/foo.tar.gz", Type: artifact.UploadableArchive},
+		{Name: "checksums.txt", Path: "dist/checksums.txt", Type: artifact.Checksum},
+	})
+	require.NoError(tb, err)
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "artifacts.json"), bts, 0o644))
+	return dir
+}
+
+func TestVerify(t *testing.T) {
+	dir := setupVerify(t)
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{dir})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestVerifyQuiet(t *testing.T) {
+	dir := setupVerify(t)
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{dir, "--quiet"})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestVerifyFailed(t *testing.T) {
+	dir := setupVerify(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.tar.gz"), []byte("bar"), 0o644))
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{dir})
+	require.EqualError(t, cmd.cmd.Execute(), "one or more checks failed")
+}
+
+func TestVerifyNoArtifacts(t *testing.T) {
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{t.TempDir()})
+	require.ErrorContains(t, cmd.cmd.Execute(), "could not read artifacts")
+}
+
+func TestVerifyInvalidKey(t *testing.T) {
+	dir := setupVerify(t)
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{dir, "--key", filepath.Join(dir, "foo.tar.gz")})
+	require.ErrorContains(t, cmd.cmd.Execute(), "unsupported public key")
+}
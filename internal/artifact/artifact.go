@@ -118,6 +118,20 @@ const (
 	SourceRPM
 	// MSIX is a Windows MSIX package generated by nfpm.
 	MSIX
+	// Provenance is a SLSA provenance attestation, in a DSSE envelope.
+	Provenance
+	// MacOSPkg is a macOS flat installer package (.pkg).
+	MacOSPkg
+	// DMG is a macOS disk image.
+	DMG
+	// MSI is a Windows installer package.
+	MSI
+	// OCIArtifact is a published OCI artifact manifest.
+	OCIArtifact
+	// HelmChart is a packaged Helm chart.
+	HelmChart
+	// HelmChartProvenance is the provenance file of a packaged Helm chart.
+	HelmChartProvenance
 
 	// XXX: if it is an uploadable kind of artifact, add it to UploadableTypes
 	// below.
@@ -132,7 +146,7 @@ const (
 // When adding a new artifact type that should be part of a release, add it
 // here and all pipes that use this func will automatically include it.
 //
-// GoReleaser Pro has more formats: MSI, DMG, etc.
+// GoReleaser Pro has more formats: NSIS, etc.
 func ReleaseUploadableTypes() []Type {
 	return []Type{
 		UploadableArchive,
@@ -142,12 +156,16 @@ func ReleaseUploadableTypes() []Type {
 		Makeself,
 		LinuxPackage,
 		MSIX,
+		MSI,
+		MacOSPkg,
+		DMG,
 		Flatpak,
 		SourceRPM,
 		SBOM,
 		PyWheel,
 		PySdist,
 		Checksum,
+		Provenance,
 		Signature,
 		Certificate,
 	}
@@ -179,6 +197,12 @@ func (t Type) String() string {
 		return "Linux Package"
 	case MSIX:
 		return "MSIX"
+	case MacOSPkg:
+		return "MacOS Package"
+	case DMG:
+		return "DMG"
+	case MSI:
+		return "MSI"
 	case PublishableDockerImage, DockerImageV2:
 		return "Docker Image"
 	case DockerImage:
@@ -233,6 +257,14 @@ func (t Type) String() string {
 		return "Flatpak"
 	case SourceRPM:
 		return "Source RPM"
+	case Provenance:
+		return "Provenance"
+	case OCIArtifact:
+		return "OCI Artifact"
+	case HelmChart:
+		return "Helm Chart"
+	case HelmChartProvenance:
+		return "Helm Chart Provenance"
 	default:
 		return "unknown"
 	}
@@ -242,21 +274,23 @@ func (t Type) String() string {
 // If you add or change these, please update the documentation at
 // www/content/customization/general/artifacts.md as well.
 const (
-	ExtraID         = "ID"
-	ExtraBinary     = "Binary"
-	ExtraExt        = "Ext" // should always have the preceding '.'
-	ExtraFormat     = "Format"
-	ExtraWrappedIn  = "WrappedIn"
-	ExtraBinaries   = "Binaries"
-	ExtraFiles      = "Files"
-	ExtraRefresh    = "Refresh"
-	ExtraReplaces   = "Replaces"
-	ExtraDigest     = "Digest"
-	ExtraSize       = "Size"
-	ExtraChecksum   = "Checksum"
-	ExtraChecksumOf = "ChecksumOf"
-	ExtraBuilder    = "Builder"
-	ExtranDynLink   = "DynamicallyLinked"
+	ExtraID          = "ID"
+	ExtraBinary      = "Binary"
+	ExtraExt         = "Ext" // should always have the preceding '.'
+	ExtraFormat      = "Format"
+	ExtraWrappedIn   = "WrappedIn"
+	ExtraBinaries    = "Binaries"
+	ExtraFiles       = "Files"
+	ExtraRefresh     = "Refresh"
+	ExtraReplaces    = "Replaces"
+	ExtraDigest      = "Digest"
+	ExtraSize        = "Size"
+	ExtraChecksum    = "Checksum"
+	ExtraChecksumOf  = "ChecksumOf"
+	ExtraSBOMOf      = "SBOMOf"
+	ExtraSignatureOf = "SignatureOf"
+	ExtraBuilder     = "Builder"
+	ExtranDynLink    = "DynamicallyLinked"
 )
 
 // Extras represents the extra fields in an artifact.
@@ -424,9 +458,9 @@ var noRefresh = func() error { return nil }
 
 // Refresh executes a Refresh extra function on artifacts, if it exists.
 func (a Artifact) Refresh() error {
-	// for now lets only do it for checksums, as we know for a fact that
-	// they are the only ones that support this right now.
-	if a.Type != Checksum {
+	// for now lets only do it for checksums and provenance, as we know for a
+	// fact that they are the only ones that support this right now.
+	if a.Type != Checksum && a.Type != Provenance {
 		return nil
 	}
 	if err := ExtraOr(a, ExtraRefresh, noRefresh)(); err != nil {
@@ -546,7 +580,7 @@ func relPath(a *Artifact) (string, error) {
 
 func shouldRelPath(a *Artifact) bool {
 	switch a.Type {
-	case DockerImage, DockerManifest, PublishableDockerImage, DockerImageV2:
+	case DockerImage, DockerManifest, PublishableDockerImage, DockerImageV2, OCIArtifact:
 		return false
 	default:
 		return filepath.IsAbs(a.Path)
@@ -667,6 +701,12 @@ func ByGoamd64s(s ...string) Filter {
 	return autoOr(s, ByGoamd64)
 }
 
+// ByGoamd64OrOtherArch filters amd64 artifacts by the given goamd64, while
+// keeping the artifacts of all the other architectures.
+func ByGoamd64OrOtherArch(s string) Filter {
+	return Or(Not(ByGoarch("amd64")), ByGoamd64(s))
+}
+
 // ByType is a predefined filter that filters by the given type.
 func ByType(t Type) Filter {
 	return func(a *Artifact) bool {
@@ -148,6 +148,7 @@ func TestFilter(t *testing.T) {
 	require.Len(t, artifacts.Filter(ByGoamd64("v2")).items, 1)
 	require.Len(t, artifacts.Filter(ByGoamd64("v3")).items, 1)
 	require.Len(t, artifacts.Filter(ByGoamd64("v4")).items, 1)
+	require.Len(t, artifacts.Filter(ByGoamd64OrOtherArch("v2")).items, len(data)-4)
 
 	require.Len(t, artifacts.Filter(And(ByGoarch("arm"), ByGoarm("6"))).items, 3)
 	require.Empty(t, artifacts.Filter(ByGoarm("7")).items)
@@ -1115,12 +1116,16 @@ func TestReleaseUploadableTypes(t *testing.T) {
 		Makeself,
 		LinuxPackage,
 		MSIX,
+		MSI,
+		MacOSPkg,
+		DMG,
 		Flatpak,
 		SourceRPM,
 		SBOM,
 		PyWheel,
 		PySdist,
 		Checksum,
+		Provenance,
 		Signature,
 		Certificate,
 	}
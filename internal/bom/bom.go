@@ -0,0 +1,359 @@
+// Package bom writes macOS bill of materials files (the "Bom" file inside
+// installer packages).
+//
+// The format is undocumented, this follows what bomutils' mkbom does.
+//
+// See: https://github.com/hogliux/bomutils
+package bom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	headerSize = 512
+
+	typeFile      = 1
+	typeDirectory = 2
+	typeLink      = 3
+
+	// maxLeafPaths is the maximum number of paths in a single tree leaf.
+	maxLeafPaths = 256
+)
+
+// Entry is a file, directory, or symbolic link in the bill of materials.
+type Entry struct {
+	// Path is the slash separated path, relative to the install location.
+	Path    string
+	Mode    fs.FileMode
+	UID     uint32
+	GID     uint32
+	ModTime time.Time
+	Size    uint32
+	// Checksum is the POSIX cksum of the file contents, see [Checksum].
+	Checksum uint32
+	LinkName string
+}
+
+// Write writes a bill of materials with the given entries to w.
+//
+// The root directory is added automatically, every other directory must be
+// in the entries.
+func Write(w io.Writer, root Entry, entries []Entry) error {
+	nodes, err := sortEntries(root, entries)
+	if err != nil {
+		return err
+	}
+
+	var s storage
+	s.blocks = [][]byte{nil} // the first block is always null.
+
+	info := &bytes.Buffer{}
+	write(info, uint32(1), uint32(len(nodes)+1), uint32(1), make([]byte, 16))
+	infoID := s.add(info.Bytes())
+
+	type key struct{ pathInfo1, file uint32 }
+	keys := make([]key, 0, len(nodes))
+	for _, n := range nodes {
+		info2 := &bytes.Buffer{}
+		write(
+			info2,
+			n.kind,
+			uint8(1),
+			uint16(3),
+			n.mode,
+			n.entry.UID,
+			n.entry.GID,
+			uint32(n.entry.ModTime.Unix()),
+			n.entry.Size,
+			uint8(1),
+			n.entry.Checksum,
+		)
+		if n.kind == typeLink {
+			write(info2, uint32(len(n.entry.LinkName)+1))
+			info2.WriteString(n.entry.LinkName)
+			info2.WriteByte(0)
+		} else {
+			write(info2, uint32(0))
+		}
+		info2ID := s.add(info2.Bytes())
+
+		info1 := &bytes.Buffer{}
+		write(info1, n.id, info2ID)
+		file := &bytes.Buffer{}
+		write(file, n.parent)
+		file.WriteString(n.name)
+		file.WriteByte(0)
+		keys = append(keys, key{s.add(info1.Bytes()), s.add(file.Bytes())})
+	}
+
+	// paths are stored in a tree, with up to [maxLeafPaths] per leaf.
+	type leaf struct {
+		id   uint32
+		last key
+	}
+	var leaves []leaf
+	for chunk := range slices.Chunk(keys, maxLeafPaths) {
+		leaves = append(leaves, leaf{id: s.add(nil), last: chunk[len(chunk)-1]})
+	}
+	i := 0
+	for chunk := range slices.Chunk(keys, maxLeafPaths) {
+		b := &bytes.Buffer{}
+		var forward, backward uint32
+		if i+1 < len(leaves) {
+			forward = leaves[i+1].id
+		}
+		if i > 0 {
+			backward = leaves[i-1].id
+		}
+		write(b, uint16(1), uint16(len(chunk)), forward, backward)
+		for _, k := range chunk {
+			write(b, k.pathInfo1, k.file)
+		}
+		s.blocks[leaves[i].id] = b.Bytes()
+		i++
+	}
+	pathsID := leaves[0].id
+	if len(leaves) > 1 {
+		b := &bytes.Buffer{}
+		write(b, uint16(0), uint16(len(leaves)), uint32(0), uint32(0))
+		for _, l := range leaves {
+			write(b, l.id, l.last.file)
+		}
+		pathsID = s.add(b.Bytes())
+	}
+
+	treeID := s.add(treeBlock(pathsID, 4096, uint32(len(nodes))))
+	hlIndexID := s.add(treeBlock(s.add(emptyPaths()), 4096, 0))
+	vTreeID := s.add(treeBlock(s.add(emptyPaths()), 128, 0))
+	vIndex := &bytes.Buffer{}
+	write(vIndex, uint32(1), vTreeID, uint32(0), uint8(0))
+	vIndexID := s.add(vIndex.Bytes())
+	size64ID := s.add(treeBlock(s.add(emptyPaths()), 128, 0))
+
+	return s.write(w, []variable{
+		{"BomInfo", infoID},
+		{"Paths", treeID},
+		{"HLIndex", hlIndexID},
+		{"VIndex", vIndexID},
+		{"Size64", size64ID},
+	})
+}
+
+// Checksum returns the POSIX cksum of the given data, as used in bill of
+// materials entries.
+func Checksum(data []byte) uint32 {
+	var c Cksum
+	_, _ = c.Write(data)
+	return c.Sum32()
+}
+
+// Cksum computes the POSIX cksum of everything written to it, so large files
+// can be streamed into it, see [Checksum].
+type Cksum struct {
+	crc  uint32
+	size int64
+}
+
+// Write adds the given data to the checksum, it never fails.
+func (c *Cksum) Write(p []byte) (int, error) {
+	for _, b := range p {
+		c.crc = cksumUpdate(c.crc, b)
+	}
+	c.size += int64(len(p))
+	return len(p), nil
+}
+
+// Size returns the amount of bytes written so far.
+func (c *Cksum) Size() int64 { return c.size }
+
+// Sum32 returns the checksum of the data written so far.
+func (c *Cksum) Sum32() uint32 {
+	crc := c.crc
+	for n := c.size; n > 0; n >>= 8 {
+		crc = cksumUpdate(crc, byte(n))
+	}
+	return ^crc
+}
+
+func cksumUpdate(crc uint32, b byte) uint32 {
+	crc ^= uint32(b) << 24
+	for range 8 {
+		if crc&0x80000000 != 0 {
+			crc = crc<<1 ^ 0x04c11db7
+		} else {
+			crc <<= 1
+		}
+	}
+	return crc
+}
+
+type node struct {
+	entry  Entry
+	id     uint32
+	parent uint32
+	name   string
+	kind   uint8
+	mode   uint16
+}
+
+// sortEntries assigns ids to the entries in breadth-first order, so they end
+// up sorted by parent id and name, which is the order of the paths tree.
+func sortEntries(root Entry, entries []Entry) ([]node, error) {
+	children := map[string][]Entry{}
+	dirs := map[string]bool{".": true}
+	for _, e := range entries {
+		p := path.Clean(strings.TrimPrefix(e.Path, "./"))
+		if p == "." || strings.HasPrefix(p, "../") || path.IsAbs(p) {
+			return nil, fmt.Errorf("bom: invalid path %q", e.Path)
+		}
+		e.Path = p
+		if e.Mode.IsDir() {
+			if dirs[p] {
+				return nil, fmt.Errorf("bom: %s: duplicated path", p)
+			}
+			dirs[p] = true
+		}
+		parent := path.Dir(p)
+		if slices.ContainsFunc(children[parent], func(c Entry) bool { return c.Path == p }) {
+			return nil, fmt.Errorf("bom: %s: duplicated path", p)
+		}
+		children[parent] = append(children[parent], e)
+	}
+	for parent := range children {
+		if !dirs[parent] {
+			return nil, fmt.Errorf("bom: %s: parent directory is missing", parent)
+		}
+	}
+
+	root.Path = "."
+	root.Mode |= fs.ModeDir
+	nodes := []node{{entry: root, id: 1, parent: 0, name: "."}}
+	queue := []int{0}
+	for len(queue) > 0 {
+		n := nodes[queue[0]]
+		queue = queue[1:]
+		kids := children[n.entry.Path]
+		slices.SortFunc(kids, func(a, b Entry) int {
+			return strings.Compare(path.Base(a.Path), path.Base(b.Path))
+		})
+		for _, e := range kids {
+			nodes = append(nodes, node{
+				entry:  e,
+				id:     uint32(len(nodes) + 1),
+				parent: n.id,
+				name:   path.Base(e.Path),
+			})
+			if e.Mode.IsDir() {
+				queue = append(queue, len(nodes)-1)
+			}
+		}
+	}
+	for i := range nodes {
+		n := &nodes[i]
+		perm := uint16(n.entry.Mode.Perm())
+		switch {
+		case n.entry.Mode.IsDir():
+			n.kind, n.mode = typeDirectory, 0o040000|perm
+			n.entry.Size, n.entry.Checksum = 0, 0
+		case n.entry.Mode&fs.ModeSymlink != 0:
+			n.kind, n.mode = typeLink, 0o120000|perm
+		default:
+			n.kind, n.mode = typeFile, 0o100000|perm
+		}
+	}
+	return nodes, nil
+}
+
+func treeBlock(child, blockSize, count uint32) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("tree")
+	write(b, uint32(1), child, blockSize, count, uint8(0))
+	return b.Bytes()
+}
+
+func emptyPaths() []byte {
+	b := &bytes.Buffer{}
+	write(b, uint16(1), uint16(0), uint32(0), uint32(0))
+	return b.Bytes()
+}
+
+func write(w *bytes.Buffer, values ...any) {
+	for _, v := range values {
+		// writes to a bytes.Buffer never fail.
+		_ = binary.Write(w, binary.BigEndian, v)
+	}
+}
+
+type variable struct {
+	name  string
+	block uint32
+}
+
+type storage struct {
+	blocks [][]byte
+}
+
+func (s *storage) add(b []byte) uint32 {
+	s.blocks = append(s.blocks, b)
+	return uint32(len(s.blocks) - 1)
+}
+
+func (s *storage) write(w io.Writer, vars []variable) error {
+	data := &bytes.Buffer{}
+	type pointer struct{ address, length uint32 }
+	pointers := make([]pointer, len(s.blocks))
+	for i, b := range s.blocks {
+		if i == 0 {
+			continue
+		}
+		pointers[i] = pointer{uint32(headerSize + data.Len()), uint32(len(b))}
+		data.Write(b)
+	}
+
+	varsOffset := headerSize + data.Len()
+	write(data, uint32(len(vars)))
+	for _, v := range vars {
+		write(data, v.block, uint8(len(v.name)))
+		data.WriteString(v.name)
+	}
+	varsLength := headerSize + data.Len() - varsOffset
+
+	indexOffset := headerSize + data.Len()
+	write(data, uint32(len(pointers)))
+	for _, p := range pointers {
+		write(data, p.address, p.length)
+	}
+	// free list, with 2 empty entries.
+	write(data, uint32(2), make([]byte, 16))
+	indexLength := headerSize + data.Len() - indexOffset
+
+	header := &bytes.Buffer{}
+	header.WriteString("BOMStore")
+	write(
+		header,
+		uint32(1),
+		uint32(len(s.blocks)-1),
+		uint32(indexOffset),
+		uint32(indexLength),
+		uint32(varsOffset),
+		uint32(varsLength),
+	)
+	header.Write(make([]byte, headerSize-header.Len()))
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return fmt.Errorf("bom: %w", err)
+	}
+	if _, err := w.Write(data.Bytes()); err != nil {
+		return fmt.Errorf("bom: %w", err)
+	}
+	return nil
+}
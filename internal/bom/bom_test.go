@@ -0,0 +1,189 @@
+package bom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestChecksum(t *testing.T) {
+	// values from `printf ... | cksum`.
+	require.Equal(t, uint32(4294967295), Checksum(nil))
+	require.Equal(t, uint32(3733384285), Checksum([]byte("hello world\n")))
+
+	t.Run("streaming", func(t *testing.T) {
+		var c Cksum
+		_, _ = c.Write([]byte("hello "))
+		_, _ = c.Write([]byte("world\n"))
+		require.Equal(t, uint32(3733384285), c.Sum32())
+		require.Equal(t, int64(12), c.Size())
+	})
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Entry{Mode: 0o755, ModTime: now}, []Entry{
+		{Path: "bin", Mode: fs.ModeDir | 0o755, ModTime: now},
+		{Path: "bin/foo", Mode: 0o755, ModTime: now, Size: 3, Checksum: 42},
+		{Path: "./README.md", Mode: 0o644, ModTime: now, Size: 5, Checksum: 10},
+		{Path: "bin/link", Mode: fs.ModeSymlink | 0o755, ModTime: now, LinkName: "foo"},
+	}))
+
+	b := readBOM(t, buf.Bytes())
+	require.Equal(t, []string{"BomInfo", "Paths", "HLIndex", "VIndex", "Size64"}, b.vars)
+	require.Equal(t, []bomPath{
+		{id: 1, parent: 0, name: ".", kind: typeDirectory, mode: 0o040755},
+		{id: 2, parent: 1, name: "README.md", kind: typeFile, mode: 0o100644, size: 5, checksum: 10},
+		{id: 3, parent: 1, name: "bin", kind: typeDirectory, mode: 0o040755},
+		{id: 4, parent: 3, name: "foo", kind: typeFile, mode: 0o100755, size: 3, checksum: 42},
+		{id: 5, parent: 3, name: "link", kind: typeLink, mode: 0o120755, link: "foo"},
+	}, b.paths)
+	require.Equal(t, 1, b.leaves)
+}
+
+func TestWriteManyPaths(t *testing.T) {
+	entries := make([]Entry, 0, 600)
+	for i := range 600 {
+		entries = append(entries, Entry{Path: fmt.Sprintf("file%03d", i), Mode: 0o644, ModTime: now})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Entry{Mode: 0o755}, entries))
+
+	b := readBOM(t, buf.Bytes())
+	require.Len(t, b.paths, 601)
+	require.Equal(t, 3, b.leaves)
+	for i, p := range b.paths {
+		require.Equal(t, uint32(i+1), p.id)
+	}
+	require.Equal(t, "file599", b.paths[600].name)
+}
+
+func TestWriteErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		entries []Entry
+		err     string
+	}{
+		"root": {
+			entries: []Entry{{Path: "."}},
+			err:     `bom: invalid path "."`,
+		},
+		"absolute": {
+			entries: []Entry{{Path: "/foo"}},
+			err:     `bom: invalid path "/foo"`,
+		},
+		"outside": {
+			entries: []Entry{{Path: "../foo"}},
+			err:     `bom: invalid path "../foo"`,
+		},
+		"duplicated": {
+			entries: []Entry{{Path: "foo"}, {Path: "./foo"}},
+			err:     "bom: foo: duplicated path",
+		},
+		"duplicated dir": {
+			entries: []Entry{{Path: "foo", Mode: fs.ModeDir}, {Path: "foo", Mode: fs.ModeDir}},
+			err:     "bom: foo: duplicated path",
+		},
+		"missing parent": {
+			entries: []Entry{{Path: "foo/bar"}},
+			err:     "bom: foo: parent directory is missing",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.EqualError(t, Write(&bytes.Buffer{}, Entry{}, tc.entries), tc.err)
+		})
+	}
+}
+
+type bomPath struct {
+	id, parent     uint32
+	name, link     string
+	kind           uint8
+	mode           uint16
+	size, checksum uint32
+}
+
+type parsed struct {
+	vars   []string
+	paths  []bomPath
+	leaves int
+}
+
+// readBOM is a minimal reader, following what lsbom does.
+func readBOM(tb testing.TB, bts []byte) parsed {
+	tb.Helper()
+	be := binary.BigEndian
+	require.Equal(tb, "BOMStore", string(bts[:8]))
+	indexOffset := be.Uint32(bts[16:])
+	varsOffset := be.Uint32(bts[24:])
+
+	count := be.Uint32(bts[indexOffset:])
+	require.Equal(tb, count-1, be.Uint32(bts[12:]))
+	block := func(id uint32) []byte {
+		require.Less(tb, id, count)
+		p := bts[indexOffset+4+id*8:]
+		addr, length := be.Uint32(p), be.Uint32(p[4:])
+		return bts[addr : addr+length]
+	}
+
+	var result parsed
+	vars := map[string]uint32{}
+	p := bts[varsOffset:]
+	n := be.Uint32(p)
+	p = p[4:]
+	for range n {
+		id, length := be.Uint32(p), int(p[4])
+		name := string(p[5 : 5+length])
+		vars[name] = id
+		result.vars = append(result.vars, name)
+		p = p[5+length:]
+	}
+
+	info := block(vars["BomInfo"])
+	require.Equal(tb, uint32(1), be.Uint32(info))
+
+	tree := block(vars["Paths"])
+	require.Equal(tb, "tree", string(tree[:4]))
+	pathCount := be.Uint32(tree[16:])
+	node := block(be.Uint32(tree[8:]))
+	for be.Uint16(node) == 0 {
+		// not a leaf, go to the first child.
+		node = block(be.Uint32(node[12:]))
+	}
+	for {
+		result.leaves++
+		count := int(be.Uint16(node[2:]))
+		for i := range count {
+			idx := node[12+i*8:]
+			info1 := block(be.Uint32(idx))
+			file := block(be.Uint32(idx[4:]))
+			info2 := block(be.Uint32(info1[4:]))
+			entry := bomPath{
+				id:       be.Uint32(info1),
+				parent:   be.Uint32(file),
+				name:     string(bytes.TrimRight(file[4:], "\x00")),
+				kind:     info2[0],
+				mode:     be.Uint16(info2[4:]),
+				size:     be.Uint32(info2[18:]),
+				checksum: be.Uint32(info2[23:]),
+			}
+			if l := be.Uint32(info2[27:]); l > 0 {
+				entry.link = string(info2[31 : 31+l-1])
+			}
+			result.paths = append(result.paths, entry)
+		}
+		forward := be.Uint32(node[4:])
+		if forward == 0 {
+			break
+		}
+		node = block(forward)
+	}
+	require.Len(tb, result.paths, int(pathCount))
+	return result
+}
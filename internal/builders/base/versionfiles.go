@@ -0,0 +1,420 @@
+package base
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/cargo"
+	"github.com/goreleaser/goreleaser/v2/internal/packagejson"
+	"github.com/goreleaser/goreleaser/v2/internal/pyproject"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// lockfiles are the lockfiles that the build tools may update once the
+// version of the given manifest changes.
+var lockfiles = map[string][]string{
+	"Cargo.toml":     {"Cargo.lock"},
+	"pyproject.toml": {"uv.lock", "poetry.lock"},
+	"package.json": {
+		"package-lock.json",
+		"npm-shrinkwrap.json",
+		"yarn.lock",
+		"pnpm-lock.yaml",
+		"bun.lock",
+		"bun.lockb",
+	},
+	"deno.json": {"deno.lock"},
+}
+
+// RewriteVersionFiles sets the version in all the version files of the given
+// build, without changing the checkout.
+//
+// The files are rewritten in an overlay of the current directory, in which
+// everything else links to the original files, and the returned build runs
+// from it.
+// The version files and their lockfiles, next to them or in a parent
+// workspace, are copies, so the build tools may update them freely.
+//
+// It also returns a function that removes the overlay, which should be
+// called once the build is done.
+func RewriteVersionFiles(ctx *context.Context, build config.Build) (config.Build, func() error, error) {
+	if len(build.VersionFiles) == 0 {
+		return build, func() error { return nil }, nil
+	}
+
+	o, err := newOverlay(ctx, build)
+	if err != nil {
+		return build, nil, err
+	}
+	cleanup := func() error {
+		log.WithField("path", o.dir).Debug("removing version files overlay")
+		if err := os.RemoveAll(o.dir); err != nil {
+			return err
+		}
+		// fails if other builds still have their overlays in there.
+		_ = os.Remove(filepath.Dir(o.dir))
+		return nil
+	}
+
+	dir, err := o.path(cmp.Or(build.Dir, "."))
+	if err != nil {
+		return build, nil, errors.Join(err, cleanup())
+	}
+
+	tpl := tmpl.New(ctx)
+	for _, vf := range build.VersionFiles {
+		version, err := tpl.Apply(vf.Version)
+		if err != nil {
+			return build, nil, errors.Join(err, cleanup())
+		}
+		path := filepath.Join(build.Dir, vf.Path)
+		if err := o.copyLockfiles(path); err != nil {
+			return build, nil, errors.Join(err, cleanup())
+		}
+		if err := o.copy(path); err != nil {
+			return build, nil, errors.Join(
+				fmt.Errorf("could not set version in %s: %w", path, err),
+				cleanup(),
+			)
+		}
+		overlaid, err := o.path(path)
+		if err != nil {
+			return build, nil, errors.Join(err, cleanup())
+		}
+		if err := rewriteVersionFile(overlaid, version); err != nil {
+			return build, nil, errors.Join(
+				fmt.Errorf("could not set version in %s: %w", path, err),
+				cleanup(),
+			)
+		}
+	}
+
+	build.Dir = dir
+	return build, cleanup, nil
+}
+
+// overlay mirrors a directory tree using symbolic links, only copying the
+// files that need to be changed, and creating real directories leading to
+// them.
+type overlay struct {
+	// root is the absolute path of the mirrored directory.
+	root string
+	// dir is the absolute path of the overlay.
+	dir string
+	// skip is an absolute path that should not be mirrored, i.e. the dist
+	// directory, in which the overlay lives.
+	skip string
+}
+
+func newOverlay(ctx *context.Context, build config.Build) (overlay, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return overlay{}, err
+	}
+	if dir, err := filepath.Abs(build.Dir); err == nil && !isWithin(root, dir) {
+		// the build is not in the current directory, mirror its directory
+		// instead.
+		root = dir
+	}
+	dist, err := filepath.Abs(ctx.Config.Dist)
+	if err != nil {
+		return overlay{}, err
+	}
+	dir := filepath.Join(dist, "versionfiles", build.ID)
+	if err := os.RemoveAll(dir); err != nil {
+		return overlay{}, err
+	}
+	o := overlay{root: root, dir: dir, skip: dist}
+	if err := o.mirror(root, dir); err != nil {
+		return overlay{}, errors.Join(err, os.RemoveAll(dir))
+	}
+	return o, nil
+}
+
+// path returns the path of the given path in the overlay.
+func (o overlay) path(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if !isWithin(o.root, abs) {
+		return "", fmt.Errorf("%s is outside of %s", path, o.root)
+	}
+	rel, err := filepath.Rel(o.root, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(o.dir, rel), nil
+}
+
+// mirror creates the given directory, linking it to all the entries of the
+// given original directory.
+func (o overlay) mirror(orig, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(orig)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		src := filepath.Join(orig, entry.Name())
+		if src == o.skip {
+			continue
+		}
+		if err := os.Symlink(src, filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// realDir makes sure the given directory, and its parents, are real
+// directories in the overlay, and not links to the original ones.
+func (o overlay) realDir(path string) error {
+	if path == o.dir {
+		return nil
+	}
+	if err := o.realDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+	orig, err := os.Readlink(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return o.mirror(orig, path)
+}
+
+// copy replaces the link to the given file in the overlay with a copy of
+// it.
+func (o overlay) copy(path string) error {
+	dst, err := o.path(path)
+	if err != nil {
+		return err
+	}
+	if err := o.realDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	info, err := os.Lstat(dst)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		// already copied.
+		return nil
+	}
+	orig, err := os.Readlink(dst)
+	if err != nil {
+		return err
+	}
+	bts, err := os.ReadFile(orig)
+	if err != nil {
+		return err
+	}
+	info, err = os.Stat(orig)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, bts, info.Mode())
+}
+
+// copyLockfiles copies the lockfiles of the given manifest into the overlay,
+// looking in its directory and its parents up to the overlay root, as
+// workspaces usually have a single lockfile at their root.
+//
+// The directories are also made real, so lockfiles created by the build end
+// up in the overlay too.
+func (o overlay) copyLockfiles(manifest string) error {
+	names := lockfiles[filepath.Base(manifest)]
+	if len(names) == 0 {
+		return nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(manifest))
+	if err != nil {
+		return err
+	}
+	for isWithin(o.root, dir) {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err := o.copy(path); err != nil {
+				return err
+			}
+		}
+		overlaid, err := o.path(dir)
+		if err != nil {
+			return err
+		}
+		if err := o.realDir(overlaid); err != nil {
+			return err
+		}
+		if dir == o.root {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	return nil
+}
+
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func rewriteVersionFile(path, version string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var rewritten []byte
+	var check func() (string, error)
+	switch filepath.Base(path) {
+	case "Cargo.toml":
+		rewritten, err = setTOMLVersion(bts, "package", version)
+		check = func() (string, error) {
+			c, err := cargo.Open(path)
+			return c.Package.Version, err
+		}
+	case "pyproject.toml":
+		rewritten, err = setTOMLVersion(bts, "project", version)
+		check = func() (string, error) {
+			p, err := pyproject.Open(path)
+			return p.Project.Version, err
+		}
+	case "package.json", "deno.json":
+		rewritten, err = setJSONVersion(bts, version)
+		check = func() (string, error) {
+			p, err := packagejson.Open(path)
+			return p.Version, err
+		}
+	case "build.zig.zon":
+		rewritten, err = setZONVersion(bts, version)
+		check = func() (string, error) { return version, nil }
+	default:
+		return errors.New("unsupported version file, must be one of Cargo.toml, pyproject.toml, package.json, deno.json, or build.zig.zon")
+	}
+	if err != nil {
+		return err
+	}
+
+	log.WithField("path", path).
+		WithField("version", version).
+		Info("setting version")
+	if err := os.WriteFile(path, rewritten, info.Mode()); err != nil {
+		return err
+	}
+
+	got, err := check()
+	if err != nil {
+		return err
+	}
+	if got != version {
+		return fmt.Errorf("expected version to be %q, got %q", version, got)
+	}
+	return nil
+}
+
+// tomlVersionRe matches the version key set to a basic or a literal string,
+// including multi-line ones written in a single line.
+var tomlVersionRe = regexp.MustCompile(`^(\s*version\s*=\s*)(?:"""[^"]*"""|'''[^']*'''|"[^"]*"|'[^']*')`)
+
+// setTOMLVersion sets the version key of the given table, keeping everything
+// else as is.
+func setTOMLVersion(bts []byte, table, version string) ([]byte, error) {
+	lines := strings.Split(string(bts), "\n")
+	inTable := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			header, _, _ := strings.Cut(trimmed, "#")
+			inTable = strings.TrimSpace(header) == "["+table+"]"
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		if loc := tomlVersionRe.FindStringSubmatchIndex(line); loc != nil {
+			lines[i] = line[:loc[3]] + strconv.Quote(version) + line[loc[1]:]
+			return []byte(strings.Join(lines, "\n")), nil
+		}
+	}
+	return nil, fmt.Errorf("could not find %s.version", table)
+}
+
+// setJSONVersion sets the top-level version key, keeping everything else as
+// is.
+func setJSONVersion(bts []byte, version string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(bts))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("invalid json object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		start := dec.InputOffset()
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		end := dec.InputOffset()
+		if tok != "version" {
+			continue
+		}
+		if !bytes.HasPrefix(value, []byte(`"`)) {
+			return nil, errors.New("version is not a string")
+		}
+		start += int64(bytes.IndexByte(bts[start:end], '"'))
+		quoted, err := json.Marshal(version)
+		if err != nil {
+			return nil, err
+		}
+		return slices.Concat(bts[:start], quoted, bts[end:]), nil
+	}
+	return nil, errors.New("could not find version")
+}
+
+var zonVersionRe = regexp.MustCompile(`(?m)^(\s*\.version\s*=\s*)"[^"]*"`)
+
+// setZONVersion sets the .version field of a build.zig.zon file, keeping
+// everything else as is.
+func setZONVersion(bts []byte, version string) ([]byte, error) {
+	loc := zonVersionRe.FindSubmatchIndex(bts)
+	if loc == nil {
+		return nil, errors.New("could not find .version")
+	}
+	return slices.Concat(bts[:loc[3]], []byte(strconv.Quote(version)), bts[loc[1]:]), nil
+}
@@ -0,0 +1,315 @@
+package base
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCargo = `[package]
+name = "foo"
+version = "0.1.0" # bumped by goreleaser
+edition = "2021"
+
+[dependencies]
+bar = { version = "1.0.0" }
+
+[workspace.package]
+version = "0.0.1"
+`
+	testPyProject = `[build-system]
+requires = ["hatchling"]
+
+[project]
+name = "foo"
+version = "0.1.0"
+`
+	testPackageJSON = `{
+  "name": "foo",
+  "dependencies": {
+    "version": "1.0.0"
+  },
+  "version": "0.1.0",
+  "private": true
+}
+`
+	testZon = `.{
+    .name = .foo,
+    .version = "0.1.0",
+    .dependencies = .{},
+}
+`
+)
+
+func TestRewriteVersionFiles(t *testing.T) {
+	for name, tt := range map[string]struct {
+		file     string
+		content  string
+		expected string
+	}{
+		"cargo": {
+			file:    "Cargo.toml",
+			content: testCargo,
+			expected: `[package]
+name = "foo"
+version = "1.2.3" # bumped by goreleaser
+edition = "2021"
+
+[dependencies]
+bar = { version = "1.0.0" }
+
+[workspace.package]
+version = "0.0.1"
+`,
+		},
+		"pyproject": {
+			file:    "pyproject.toml",
+			content: testPyProject,
+			expected: `[build-system]
+requires = ["hatchling"]
+
+[project]
+name = "foo"
+version = "1.2.3"
+`,
+		},
+		"cargo literal string": {
+			file:     "Cargo.toml",
+			content:  "[package]\nname = 'foo'\nversion = '0.1.0'\n",
+			expected: "[package]\nname = 'foo'\nversion = \"1.2.3\"\n",
+		},
+		"pyproject multi-line literal string": {
+			file:     "pyproject.toml",
+			content:  "[project]\nname = \"foo\"\nversion = '''0.1.0'''\n",
+			expected: "[project]\nname = \"foo\"\nversion = \"1.2.3\"\n",
+		},
+		"pyproject multi-line basic string": {
+			file:     "pyproject.toml",
+			content:  "[project]\nname = \"foo\"\nversion = \"\"\"0.1.0\"\"\"\n",
+			expected: "[project]\nname = \"foo\"\nversion = \"1.2.3\"\n",
+		},
+		"package.json": {
+			file:    "package.json",
+			content: testPackageJSON,
+			expected: `{
+  "name": "foo",
+  "dependencies": {
+    "version": "1.0.0"
+  },
+  "version": "1.2.3",
+  "private": true
+}
+`,
+		},
+		"zon": {
+			file:    "build.zig.zon",
+			content: testZon,
+			expected: `.{
+    .name = .foo,
+    .version = "1.2.3",
+    .dependencies = .{},
+}
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testlib.Mktmp(t)
+			require.NoError(t, os.Mkdir("app", 0o755))
+			path := filepath.Join("app", tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{Dist: "dist"}, testctx.WithVersion("1.2.3"))
+			build, cleanup, err := RewriteVersionFiles(ctx, config.Build{
+				ID:  "foo",
+				Dir: "app",
+				VersionFiles: []config.VersionFile{
+					{Path: tt.file, Version: "{{ .Version }}"},
+				},
+			})
+			require.NoError(t, err)
+			overlaid := filepath.Join(build.Dir, tt.file)
+			bts, err := os.ReadFile(overlaid)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(bts))
+
+			info, err := os.Stat(overlaid)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+			// the checkout is never changed.
+			bts, err = os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.content, string(bts))
+
+			require.NoError(t, cleanup())
+			require.NoDirExists(t, filepath.Join("dist", "versionfiles"))
+		})
+	}
+}
+
+func TestRewriteVersionFilesNone(t *testing.T) {
+	ctx := testctx.Wrap(t.Context())
+	build, cleanup, err := RewriteVersionFiles(ctx, config.Build{Dir: "app"})
+	require.NoError(t, err)
+	require.Equal(t, "app", build.Dir)
+	require.NoError(t, cleanup())
+}
+
+func TestRewriteVersionFilesOverlay(t *testing.T) {
+	testlib.Mktmp(t)
+	require.NoError(t, os.MkdirAll(filepath.Join("crates", "foo", "src"), 0o755))
+	require.NoError(t, os.Mkdir("dist", 0o755))
+	require.NoError(t, os.WriteFile("Cargo.toml", []byte("[workspace]\nmembers = [\"crates/foo\"]\n"), 0o644))
+	require.NoError(t, os.WriteFile("Cargo.lock", []byte("version = 4\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join("crates", "foo", "Cargo.toml"), []byte(testCargo), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join("crates", "foo", "src", "main.rs"), []byte("fn main() {}\n"), 0o644))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{Dist: "dist"}, testctx.WithVersion("1.2.3"))
+	build, cleanup, err := RewriteVersionFiles(ctx, config.Build{
+		ID:  "foo",
+		Dir: filepath.Join("crates", "foo"),
+		VersionFiles: []config.VersionFile{
+			{Path: "Cargo.toml", Version: "{{ .Version }}"},
+		},
+	})
+	require.NoError(t, err)
+	root := filepath.Dir(filepath.Dir(build.Dir))
+
+	// other files are links to the checkout.
+	info, err := os.Lstat(filepath.Join(build.Dir, "src"))
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink)
+	info, err = os.Lstat(filepath.Join(root, "Cargo.toml"))
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink)
+	require.NoFileExists(t, filepath.Join(root, "dist"))
+
+	// lockfiles are copies, so simulate the build updating and creating
+	// them.
+	info, err = os.Lstat(filepath.Join(root, "Cargo.lock"))
+	require.NoError(t, err)
+	require.Zero(t, info.Mode()&os.ModeSymlink)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Cargo.lock"), []byte("version = 5\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(build.Dir, "Cargo.lock"), []byte("version = 5\n"), 0o644))
+
+	require.NoError(t, cleanup())
+	require.NoDirExists(t, root)
+	bts, err := os.ReadFile("Cargo.lock")
+	require.NoError(t, err)
+	require.Equal(t, "version = 4\n", string(bts))
+	bts, err = os.ReadFile(filepath.Join("crates", "foo", "Cargo.toml"))
+	require.NoError(t, err)
+	require.Equal(t, testCargo, string(bts))
+	require.NoFileExists(t, filepath.Join("crates", "foo", "Cargo.lock"))
+	require.FileExists(t, filepath.Join("crates", "foo", "src", "main.rs"))
+}
+
+func TestRewriteVersionFilesErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		file    string
+		content string
+		version string
+		err     string
+	}{
+		"unsupported": {
+			file:    "go.mod",
+			content: "module foo\n",
+			err:     "unsupported version file",
+		},
+		"no version in cargo": {
+			file:    "Cargo.toml",
+			content: "[package]\nname = \"foo\"\nversion.workspace = true\n",
+			err:     "could not find package.version",
+		},
+		"no version in package.json": {
+			file:    "package.json",
+			content: `{"name": "foo"}`,
+			err:     "could not find version",
+		},
+		"non string version in package.json": {
+			file:    "package.json",
+			content: `{"version": 1}`,
+			err:     "version is not a string",
+		},
+		"invalid package.json": {
+			file:    "package.json",
+			content: `[]`,
+			err:     "invalid json object",
+		},
+		"no version in zon": {
+			file:    "build.zig.zon",
+			content: ".{ .name = .foo }",
+			err:     "could not find .version",
+		},
+		"missing file": {
+			file: "Cargo.toml",
+			err:  "no such file or directory",
+		},
+		"outside of the current directory": {
+			file: "../Cargo.toml",
+			err:  "is outside of",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testlib.Mktmp(t)
+			if tt.content != "" {
+				require.NoError(t, os.WriteFile(tt.file, []byte(tt.content), 0o644))
+			}
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{Dist: "dist"}, testctx.WithVersion("1.2.3"))
+			_, _, err := RewriteVersionFiles(ctx, config.Build{
+				ID: "foo",
+				VersionFiles: []config.VersionFile{
+					{Path: tt.file, Version: "{{ .Version }}"},
+				},
+			})
+			require.ErrorContains(t, err, tt.err)
+			require.NoDirExists(t, filepath.Join("dist", "versionfiles", "foo"))
+			if tt.content != "" {
+				bts, err := os.ReadFile(tt.file)
+				require.NoError(t, err)
+				require.Equal(t, tt.content, string(bts))
+			}
+		})
+	}
+}
+
+func TestRewriteVersionFilesCleansUpOnError(t *testing.T) {
+	testlib.Mktmp(t)
+	require.NoError(t, os.WriteFile("Cargo.toml", []byte(testCargo), 0o644))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{Dist: "dist"}, testctx.WithVersion("1.2.3"))
+	_, _, err := RewriteVersionFiles(ctx, config.Build{
+		ID: "foo",
+		VersionFiles: []config.VersionFile{
+			{Path: "Cargo.toml", Version: "{{ .Version }}"},
+			{Path: "package.json", Version: "{{ .Version }}"},
+		},
+	})
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.NoDirExists(t, filepath.Join("dist", "versionfiles", "foo"))
+
+	bts, err := os.ReadFile("Cargo.toml")
+	require.NoError(t, err)
+	require.Equal(t, testCargo, string(bts))
+}
+
+func TestRewriteVersionFilesInvalidTemplate(t *testing.T) {
+	testlib.Mktmp(t)
+	require.NoError(t, os.WriteFile("Cargo.toml", []byte(testCargo), 0o644))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{Dist: "dist"})
+	_, _, err := RewriteVersionFiles(ctx, config.Build{
+		ID: "foo",
+		VersionFiles: []config.VersionFile{
+			{Path: "Cargo.toml", Version: "{{ .Nope }}"},
+		},
+	})
+	testlib.RequireTemplateError(t, err)
+	require.NoDirExists(t, filepath.Join("dist", "versionfiles", "foo"))
+}
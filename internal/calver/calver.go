@@ -0,0 +1,224 @@
+// Package calver handles calendar versioning parsing.
+//
+// See https://calver.org for the scheme conventions.
+package calver
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultScheme is the scheme used when none is given.
+const DefaultScheme = "YYYY.0M.MICRO"
+
+type token string
+
+const (
+	fullYear   token = "YYYY"
+	shortYear  token = "YY"
+	padYear    token = "0Y"
+	shortMonth token = "MM"
+	padMonth   token = "0M"
+	shortWeek  token = "WW"
+	padWeek    token = "0W"
+	shortDay   token = "DD"
+	padDay     token = "0D"
+	major      token = "MAJOR"
+	minor      token = "MINOR"
+	micro      token = "MICRO"
+)
+
+// Scheme is a parsed calver scheme, e.g. 'YYYY.0M.MICRO'.
+type Scheme struct {
+	raw    string
+	tokens []token
+}
+
+// Version is a parsed calendar version.
+type Version struct {
+	Year     uint64
+	Month    uint64
+	Week     uint64
+	Day      uint64
+	Major    uint64
+	Minor    uint64
+	Micro    uint64
+	Modifier string
+
+	// segments holds the numeric segments in the scheme order, used to
+	// compare versions.
+	segments []uint64
+}
+
+// ParseScheme parses the given scheme.
+func ParseScheme(s string) (Scheme, error) {
+	if s == "" {
+		s = DefaultScheme
+	}
+	scheme := Scheme{raw: s}
+	seen := map[token]bool{}
+	for part := range strings.SplitSeq(s, ".") {
+		t := token(part)
+		switch t {
+		case fullYear, shortYear, padYear,
+			shortMonth, padMonth,
+			shortWeek, padWeek,
+			shortDay, padDay,
+			major, minor, micro:
+		default:
+			return Scheme{}, fmt.Errorf("invalid calver scheme %q: unknown segment %q", s, part)
+		}
+		if seen[kind(t)] {
+			return Scheme{}, fmt.Errorf("invalid calver scheme %q: duplicated segment %q", s, part)
+		}
+		seen[kind(t)] = true
+		scheme.tokens = append(scheme.tokens, t)
+	}
+	if !seen[fullYear] {
+		return Scheme{}, fmt.Errorf("invalid calver scheme %q: missing year segment", s)
+	}
+	return scheme, nil
+}
+
+// String returns the scheme as given.
+func (s Scheme) String() string {
+	return s.raw
+}
+
+// Parse parses the given version using the scheme.
+//
+// A leading 'v' is ignored, and anything after the first '-' is set as the
+// version modifier.
+func (s Scheme) Parse(version string) (Version, error) {
+	base, modifier, _ := strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	parts := strings.Split(base, ".")
+	if len(parts) != len(s.tokens) {
+		return Version{}, fmt.Errorf("version %q does not match calver scheme %q", version, s.raw)
+	}
+
+	v := Version{Modifier: modifier}
+	for i, t := range s.tokens {
+		n, err := parseSegment(t, parts[i])
+		if err != nil {
+			return Version{}, fmt.Errorf("version %q does not match calver scheme %q: %w", version, s.raw, err)
+		}
+		switch kind(t) {
+		case fullYear:
+			v.Year = n
+		case shortMonth:
+			v.Month = n
+		case shortWeek:
+			v.Week = n
+		case shortDay:
+			v.Day = n
+		case major:
+			v.Major = n
+		case minor:
+			v.Minor = n
+		case micro:
+			v.Micro = n
+		}
+		v.segments = append(v.segments, n)
+	}
+	return v, nil
+}
+
+// Segments returns the numeric segments of the version, in the scheme order.
+func (v Version) Segments() []uint64 {
+	return v.segments
+}
+
+// Compare compares two versions parsed with the same scheme.
+//
+// Versions with a modifier are lower than the same version without one.
+func Compare(a, b Version) int {
+	for i := range min(len(a.segments), len(b.segments)) {
+		if c := cmp.Compare(a.segments[i], b.segments[i]); c != 0 {
+			return c
+		}
+	}
+	if c := cmp.Compare(len(a.segments), len(b.segments)); c != 0 {
+		return c
+	}
+	switch {
+	case a.Modifier == b.Modifier:
+		return 0
+	case a.Modifier == "":
+		return 1
+	case b.Modifier == "":
+		return -1
+	default:
+		return strings.Compare(a.Modifier, b.Modifier)
+	}
+}
+
+// kind returns the canonical token for the given token, so that, e.g., both
+// 'MM' and '0M' are months.
+func kind(t token) token {
+	switch t {
+	case fullYear, shortYear, padYear:
+		return fullYear
+	case shortMonth, padMonth:
+		return shortMonth
+	case shortWeek, padWeek:
+		return shortWeek
+	case shortDay, padDay:
+		return shortDay
+	default:
+		return t
+	}
+}
+
+func parseSegment(t token, s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty %s segment", t)
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid %s segment %q", t, s)
+		}
+	}
+
+	switch t {
+	case fullYear:
+		if len(s) != 4 {
+			return 0, fmt.Errorf("invalid %s segment %q: must have 4 digits", t, s)
+		}
+	case padYear, padMonth, padWeek, padDay:
+		if len(s) != 2 {
+			return 0, fmt.Errorf("invalid %s segment %q: must have 2 digits", t, s)
+		}
+	default:
+		if len(s) > 1 && s[0] == '0' {
+			return 0, fmt.Errorf("invalid %s segment %q: must not be zero-padded", t, s)
+		}
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s segment %q: %w", t, s, err)
+	}
+
+	switch t {
+	case shortYear, padYear:
+		n += 2000
+	}
+
+	switch kind(t) {
+	case shortMonth:
+		if n < 1 || n > 12 {
+			return 0, fmt.Errorf("invalid %s segment %q: out of range", t, s)
+		}
+	case shortWeek:
+		if n < 1 || n > 53 {
+			return 0, fmt.Errorf("invalid %s segment %q: out of range", t, s)
+		}
+	case shortDay:
+		if n < 1 || n > 31 {
+			return 0, fmt.Errorf("invalid %s segment %q: out of range", t, s)
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,118 @@
+package calver
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheme(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"YYYY.0M.MICRO",
+		"YY.MM.DD",
+		"0Y.0W",
+		"YYYY.MINOR.MICRO",
+		"YYYY.0M.0D.MICRO",
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseScheme(s)
+			require.NoError(t, err)
+		})
+	}
+
+	for s, expect := range map[string]string{
+		"YYYY.FOO":    `invalid calver scheme "YYYY.FOO": unknown segment "FOO"`,
+		"YYYY.MM.0M":  `invalid calver scheme "YYYY.MM.0M": duplicated segment "0M"`,
+		"MAJOR.MINOR": `invalid calver scheme "MAJOR.MINOR": missing year segment`,
+		"YYYY..MM":    `invalid calver scheme "YYYY..MM": unknown segment ""`,
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseScheme(s)
+			require.EqualError(t, err, expect)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		scheme  string
+		version string
+		expect  Version
+	}{
+		{"YYYY.0M.MICRO", "v2024.01.3", Version{Year: 2024, Month: 1, Micro: 3}},
+		{"YYYY.0M.MICRO", "2024.12.0-rc1", Version{Year: 2024, Month: 12, Modifier: "rc1"}},
+		{"YY.MM.DD", "24.1.15", Version{Year: 2024, Month: 1, Day: 15}},
+		{"0Y.0W", "06.52", Version{Year: 2006, Week: 52}},
+		{"YYYY.MINOR.MICRO", "2024.2.10", Version{Year: 2024, Minor: 2, Micro: 10}},
+		{"YYYY.MAJOR.MINOR.MICRO", "2024.1.2.3", Version{Year: 2024, Major: 1, Minor: 2, Micro: 3}},
+	} {
+		t.Run(tt.scheme+"/"+tt.version, func(t *testing.T) {
+			scheme, err := ParseScheme(tt.scheme)
+			require.NoError(t, err)
+			v, err := scheme.Parse(tt.version)
+			require.NoError(t, err)
+			v.segments = nil
+			require.Equal(t, tt.expect, v)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range []struct {
+		scheme  string
+		version string
+		expect  string
+	}{
+		{"YYYY.0M.MICRO", "1.2.3", `version "1.2.3" does not match calver scheme "YYYY.0M.MICRO": invalid YYYY segment "1": must have 4 digits`},
+		{"YYYY.0M.MICRO", "2024.1.3", `version "2024.1.3" does not match calver scheme "YYYY.0M.MICRO": invalid 0M segment "1": must have 2 digits`},
+		{"YYYY.MM.MICRO", "2024.01.3", `version "2024.01.3" does not match calver scheme "YYYY.MM.MICRO": invalid MM segment "01": must not be zero-padded`},
+		{"YYYY.0M.MICRO", "2024.13.3", `version "2024.13.3" does not match calver scheme "YYYY.0M.MICRO": invalid 0M segment "13": out of range`},
+		{"YY.MM.DD", "24.1.32", `version "24.1.32" does not match calver scheme "YY.MM.DD": invalid DD segment "32": out of range`},
+		{"YYYY.0W", "2024.54", `version "2024.54" does not match calver scheme "YYYY.0W": invalid 0W segment "54": out of range`},
+		{"YYYY.0M.MICRO", "2024.01", `version "2024.01" does not match calver scheme "YYYY.0M.MICRO"`},
+		{"YYYY.0M.MICRO", "2024.01.a", `version "2024.01.a" does not match calver scheme "YYYY.0M.MICRO": invalid MICRO segment "a"`},
+		{"YYYY.0M.MICRO", "2024.01.", `version "2024.01." does not match calver scheme "YYYY.0M.MICRO": empty MICRO segment`},
+	} {
+		t.Run(tt.version, func(t *testing.T) {
+			scheme, err := ParseScheme(tt.scheme)
+			require.NoError(t, err)
+			_, err = scheme.Parse(tt.version)
+			require.EqualError(t, err, tt.expect)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	scheme, err := ParseScheme("YYYY.0M.MICRO")
+	require.NoError(t, err)
+
+	versions := []string{
+		"2023.12.10",
+		"2024.01.0",
+		"2024.01.1-rc2",
+		"2024.01.1-rc1",
+		"2024.01.1",
+		"2023.12.9",
+		"2024.02.0",
+	}
+	parsed := map[string]Version{}
+	for _, s := range versions {
+		v, err := scheme.Parse(s)
+		require.NoError(t, err)
+		parsed[s] = v
+	}
+	slices.SortFunc(versions, func(a, b string) int {
+		return Compare(parsed[a], parsed[b])
+	})
+	require.Equal(t, []string{
+		"2023.12.9",
+		"2023.12.10",
+		"2024.01.0",
+		"2024.01.1-rc1",
+		"2024.01.1-rc2",
+		"2024.01.1",
+		"2024.02.0",
+	}, versions)
+}
@@ -10,7 +10,8 @@ import (
 // Cargo a parsed Cargo.toml.
 type Cargo struct {
 	Package struct {
-		Name string
+		Name    string
+		Version string
 	}
 	Workspace struct {
 		Members []string
@@ -27,3 +28,28 @@ func Open(name string) (Cargo, error) {
 	err = toml.Unmarshal(bts, &cargo)
 	return cargo, err
 }
+
+// Lock a parsed Cargo.lock.
+type Lock struct {
+	Packages []LockPackage `toml:"package"`
+}
+
+// LockPackage is a package locked in a Cargo.lock.
+type LockPackage struct {
+	Name         string
+	Version      string
+	Source       string
+	Checksum     string
+	Dependencies []string
+}
+
+// OpenLock opens and parses the given Cargo.lock file name.
+func OpenLock(name string) (Lock, error) {
+	var lock Lock
+	bts, err := os.ReadFile(name)
+	if err != nil {
+		return lock, err
+	}
+	err = toml.Unmarshal(bts, &lock)
+	return lock, err
+}
@@ -17,3 +17,21 @@ func TestParseCargo_workspaces(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, cargo.Workspace.Members, 2)
 }
+
+func TestParseCargoLock(t *testing.T) {
+	lock, err := OpenLock("./testdata/Cargo.lock")
+	require.NoError(t, err)
+	require.Equal(t, []LockPackage{
+		{
+			Name:     "libc",
+			Version:  "0.2.172",
+			Source:   "registry+https://github.com/rust-lang/crates.io-index",
+			Checksum: "d750af042f7ef4f724306de029d18836c26c1765a54a6a3f094cbd23a7267ffa",
+		},
+		{
+			Name:         "some-name",
+			Version:      "0.1.0",
+			Dependencies: []string{"libc"},
+		},
+	}, lock.Packages)
+}
@@ -29,6 +29,19 @@ type Author struct {
 	Username string
 }
 
+// Contributor is an author along with how many commits they authored in the
+// current release.
+type Contributor struct {
+	Author
+
+	// Commits is the number of commits in the release the author is credited
+	// in, either as author or co-author.
+	Commits int
+
+	// New is true if the author has no commits before the previous tag.
+	New bool
+}
+
 var coauthorRe = regexp.MustCompile(`(?i)^co-authored-by:\s*([^<]+[^<\s])\s*<([^>]+)>`)
 
 // ExtractCoAuthors extracts co-authors from a commit message.
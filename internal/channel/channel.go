@@ -0,0 +1,50 @@
+// Package channel handles release channels.
+//
+// A release channel is derived from the prerelease identifier of the current
+// version: no prerelease means 'stable', otherwise the first identifier is
+// used, without any trailing numbers, e.g. 'beta' for '1.0.0-beta.1' and 'rc'
+// for '1.0.0-rc2'.
+package channel
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	// Stable is the channel of releases without a prerelease identifier.
+	Stable = "stable"
+
+	// Prerelease is the channel of prereleases whose identifier has no name,
+	// e.g. '1.0.0-1'.
+	Prerelease = "prerelease"
+)
+
+// Of returns the release channel of the current version.
+func Of(ctx *context.Context) string {
+	return FromPrerelease(ctx.Semver.Prerelease)
+}
+
+// FromPrerelease returns the release channel of the given prerelease
+// identifier.
+func FromPrerelease(prerelease string) string {
+	if prerelease == "" {
+		return Stable
+	}
+	id, _, _ := strings.Cut(prerelease, ".")
+	id, _, _ = strings.Cut(id, "-")
+	id = strings.TrimRight(strings.ToLower(id), "0123456789")
+	if id == "" {
+		return Prerelease
+	}
+	return id
+}
+
+// Includes returns true if the current release channel is one of the given
+// channels.
+// An empty list includes all channels.
+func Includes(ctx *context.Context, channels []string) bool {
+	return len(channels) == 0 || slices.Contains(channels, Of(ctx))
+}
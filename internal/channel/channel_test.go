@@ -0,0 +1,41 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromPrerelease(t *testing.T) {
+	for prerelease, expect := range map[string]string{
+		"":           "stable",
+		"beta":       "beta",
+		"beta.1":     "beta",
+		"RC2":        "rc",
+		"alpha-1":    "alpha",
+		"nightly.20": "nightly",
+		"1":          "prerelease",
+		"0.3.7":      "prerelease",
+	} {
+		t.Run(prerelease, func(t *testing.T) {
+			require.Equal(t, expect, FromPrerelease(prerelease))
+		})
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	stable := testctx.Wrap(t.Context(), testctx.WithSemver(1, 0, 0, ""))
+	beta := testctx.Wrap(t.Context(), testctx.WithSemver(1, 0, 0, "beta.1"))
+
+	require.Equal(t, "stable", Of(stable))
+	require.Equal(t, "beta", Of(beta))
+
+	require.True(t, Includes(stable, nil))
+	require.True(t, Includes(beta, nil))
+	require.True(t, Includes(stable, []string{"stable", "beta"}))
+	require.True(t, Includes(beta, []string{"stable", "beta"}))
+	require.True(t, Includes(stable, []string{"stable"}))
+	require.False(t, Includes(beta, []string{"stable"}))
+	require.False(t, Includes(stable, []string{"beta", "rc"}))
+}
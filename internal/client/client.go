@@ -92,6 +92,14 @@ type FilesCreator interface {
 	CreateFiles(ctx *context.Context, commitAuthor config.CommitAuthor, repo Repo, message string, files []RepoFile) (err error)
 }
 
+// FileGetter can read a file from some code repository.
+// It reads from the repository branch if it exists, and from the default
+// branch otherwise.
+// If the file does not exist, the returned error wraps [os.ErrNotExist].
+type FileGetter interface {
+	GetFile(ctx *context.Context, repo Repo, path string) ([]byte, error)
+}
+
 // ReleaseNotesGenerator can generate release notes.
 type ReleaseNotesGenerator interface {
 	GenerateReleaseNotes(ctx *context.Context, repo Repo, prev, current string) (string, error)
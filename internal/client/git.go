@@ -21,7 +21,13 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
-var gil sync.Mutex
+var (
+	gil sync.Mutex
+
+	// configured holds the clones that already had their local git config
+	// set, guarded by gil.
+	configured = map[string]bool{}
+)
 
 // DefaultGitSSHCommand used for git over SSH.
 const DefaultGitSSHCommand = `ssh -i "{{ .KeyPath }}" -o StrictHostKeyChecking=accept-new -F /dev/null`
@@ -30,8 +36,14 @@ type gitClient struct {
 	branch string
 }
 
+// GitUploadClient can read and create files in a git repository.
+type GitUploadClient interface {
+	FilesCreator
+	FileGetter
+}
+
 // NewGitUploadClient creates a new git client.
-func NewGitUploadClient(branch string) FilesCreator {
+func NewGitUploadClient(branch string) GitUploadClient {
 	return &gitClient{
 		branch: branch,
 	}
@@ -48,32 +60,90 @@ func (g *gitClient) CreateFiles(
 	gil.Lock()
 	defer gil.Unlock()
 
+	cwd, url, env, err := g.checkout(ctx, &repo)
+	if err != nil {
+		return err
+	}
+
+	if !configured[cwd] {
+		if err := configureRepo(ctx, cwd, env, commitAuthor); err != nil {
+			return err
+		}
+		configured[cwd] = true
+	}
+
+	for _, file := range files {
+		location := filepath.Join(cwd, file.Path)
+		log.WithField("path", location).Info("writing")
+		if err := os.MkdirAll(filepath.Dir(location), 0o755); err != nil {
+			return fmt.Errorf("failed to create parent dirs for %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(location, file.Content, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		log.
+			WithField("repository", url).
+			WithField("name", repo.Name).
+			WithField("file", file.Path).
+			Info("pushing")
+	}
+
+	if err := runGitCmds(ctx, cwd, env, [][]string{
+		{"add", "-A", "."},
+		{"commit", "-m", message},
+	}); err != nil {
+		return fmt.Errorf("git: failed to commit %q (%q): %w", repo.Name, url, err)
+	}
+	if err := pushRepo(ctx, cwd, env); err != nil {
+		return fmt.Errorf("git: failed to push %q (%q): %w", repo.Name, url, err)
+	}
+
+	return nil
+}
+
+// GetFile implements FileGetter.
+func (g *gitClient) GetFile(ctx *context.Context, repo Repo, path string) ([]byte, error) {
+	gil.Lock()
+	defer gil.Unlock()
+
+	cwd, _, _, err := g.checkout(ctx, &repo)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(cwd, path))
+}
+
+// checkout clones the repository into the dist directory, if it wasn't
+// cloned yet, and checks out the branch.
+// It returns the path of the clone, its URL, and the environment to run git
+// commands with.
+func (g *gitClient) checkout(ctx *context.Context, repo *Repo) (string, string, []string, error) {
 	url, err := tmpl.New(ctx).Apply(repo.GitURL)
 	if err != nil {
-		return fmt.Errorf("git: failed to template git url: %w", err)
+		return "", "", nil, fmt.Errorf("git: failed to template git url: %w", err)
 	}
 
 	if url == "" {
-		return pipe.Skip("url is empty")
+		return "", "", nil, pipe.Skip("url is empty")
 	}
 
 	repo.Name = cmp.Or(repo.Name, nameFromURL(url))
 
 	key, err := tmpl.New(ctx).Apply(repo.PrivateKey)
 	if err != nil {
-		return fmt.Errorf("git: failed to template private key: %w", err)
+		return "", "", nil, fmt.Errorf("git: failed to template private key: %w", err)
 	}
 
 	key, err = keyPath(key)
 	if err != nil {
-		return err
+		return "", "", nil, err
 	}
 
 	sshcmd, err := tmpl.New(ctx).WithExtraFields(tmpl.Fields{
 		"KeyPath": key,
 	}).Apply(cmp.Or(repo.GitSSHCommand, DefaultGitSSHCommand))
 	if err != nil {
-		return fmt.Errorf("git: failed to template ssh command: %w", err)
+		return "", "", nil, fmt.Errorf("git: failed to template ssh command: %w", err)
 	}
 
 	parent := filepath.Join(ctx.Config.Dist, "git")
@@ -81,82 +151,67 @@ func (g *gitClient) CreateFiles(
 	cwd := filepath.Join(parent, name)
 	env := []string{fmt.Sprintf("GIT_SSH_COMMAND=%s", sshcmd)}
 
-	if _, err := os.Stat(cwd); errors.Is(err, os.ErrNotExist) {
-		if err := os.MkdirAll(parent, 0o755); err != nil {
-			return fmt.Errorf("git: failed to create parent: %w", err)
-		}
-
-		if err := cloneRepo(ctx, parent, url, name, env); err != nil {
-			return err
-		}
-
-		gitCmds := [][]string{
-			{"config", "--local", "user.name", commitAuthor.Name},
-			{"config", "--local", "user.email", commitAuthor.Email},
-			{"config", "--local", "init.defaultBranch", cmp.Or(g.branch, "master")},
-		}
-
-		// append git flags for signing to overall comand if configured
-		if commitAuthor.Signing.Enabled {
-			gitCmds = append(gitCmds, []string{"config", "--local", "commit.gpgSign", "true"})
-
-			if commitAuthor.Signing.Key != "" {
-				gitCmds = append(gitCmds, []string{"config", "--local", "user.signingKey", commitAuthor.Signing.Key})
-			}
+	if _, err := os.Stat(cwd); !errors.Is(err, os.ErrNotExist) {
+		return cwd, url, env, nil
+	}
 
-			if commitAuthor.Signing.Program != "" {
-				gitCmds = append(gitCmds, []string{"config", "--local", "gpg.program", commitAuthor.Signing.Program})
-			}
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return "", "", nil, fmt.Errorf("git: failed to create parent: %w", err)
+	}
 
-			if commitAuthor.Signing.Format != "" && commitAuthor.Signing.Format != "openpgp" {
-				gitCmds = append(gitCmds, []string{"config", "--local", "gpg.format", commitAuthor.Signing.Format})
-			}
-		} else {
-			gitCmds = append(gitCmds, []string{"config", "--local", "commit.gpgSign", "false"})
-		}
+	if err := cloneRepo(ctx, parent, url, name, env); err != nil {
+		return "", "", nil, err
+	}
+	delete(configured, cwd)
 
-		if err := runGitCmds(ctx, cwd, env, gitCmds); err != nil {
-			return fmt.Errorf("git: failed to setup local repository: %w", err)
-		}
-		if g.branch != "" {
+	if err := runGitCmds(ctx, cwd, env, [][]string{
+		{"config", "--local", "init.defaultBranch", cmp.Or(g.branch, "master")},
+	}); err != nil {
+		return "", "", nil, fmt.Errorf("git: failed to setup local repository: %w", err)
+	}
+	if g.branch != "" {
+		if err := runGitCmds(ctx, cwd, env, [][]string{
+			{"checkout", g.branch},
+		}); err != nil {
 			if err := runGitCmds(ctx, cwd, env, [][]string{
-				{"checkout", g.branch},
+				{"checkout", "-b", g.branch},
 			}); err != nil {
-				if err := runGitCmds(ctx, cwd, env, [][]string{
-					{"checkout", "-b", g.branch},
-				}); err != nil {
-					return fmt.Errorf("git: could not checkout branch %s: %w", g.branch, err)
-				}
+				return "", "", nil, fmt.Errorf("git: could not checkout branch %s: %w", g.branch, err)
 			}
 		}
 	}
+	return cwd, url, env, nil
+}
 
-	for _, file := range files {
-		location := filepath.Join(cwd, file.Path)
-		log.WithField("path", location).Info("writing")
-		if err := os.MkdirAll(filepath.Dir(location), 0o755); err != nil {
-			return fmt.Errorf("failed to create parent dirs for %s: %w", file.Path, err)
+// configureRepo sets the commit author and signing options of the clone.
+func configureRepo(ctx *context.Context, cwd string, env []string, commitAuthor config.CommitAuthor) error {
+	gitCmds := [][]string{
+		{"config", "--local", "user.name", commitAuthor.Name},
+		{"config", "--local", "user.email", commitAuthor.Email},
+	}
+
+	// append git flags for signing to overall comand if configured
+	if commitAuthor.Signing.Enabled {
+		gitCmds = append(gitCmds, []string{"config", "--local", "commit.gpgSign", "true"})
+
+		if commitAuthor.Signing.Key != "" {
+			gitCmds = append(gitCmds, []string{"config", "--local", "user.signingKey", commitAuthor.Signing.Key})
 		}
-		if err := os.WriteFile(location, file.Content, 0o644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+
+		if commitAuthor.Signing.Program != "" {
+			gitCmds = append(gitCmds, []string{"config", "--local", "gpg.program", commitAuthor.Signing.Program})
 		}
-		log.
-			WithField("repository", url).
-			WithField("name", repo.Name).
-			WithField("file", file.Path).
-			Info("pushing")
-	}
 
-	if err := runGitCmds(ctx, cwd, env, [][]string{
-		{"add", "-A", "."},
-		{"commit", "-m", message},
-	}); err != nil {
-		return fmt.Errorf("git: failed to commit %q (%q): %w", repo.Name, url, err)
-	}
-	if err := pushRepo(ctx, cwd, env); err != nil {
-		return fmt.Errorf("git: failed to push %q (%q): %w", repo.Name, url, err)
+		if commitAuthor.Signing.Format != "" && commitAuthor.Signing.Format != "openpgp" {
+			gitCmds = append(gitCmds, []string{"config", "--local", "gpg.format", commitAuthor.Signing.Format})
+		}
+	} else {
+		gitCmds = append(gitCmds, []string{"config", "--local", "commit.gpgSign", "false"})
 	}
 
+	if err := runGitCmds(ctx, cwd, env, gitCmds); err != nil {
+		return fmt.Errorf("git: failed to setup local repository: %w", err)
+	}
 	return nil
 }
 
@@ -2,6 +2,7 @@ package client
 
 import (
 	"os"
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -57,6 +58,57 @@ func TestGitClient(t *testing.T) {
 		require.Equal(t, "fake2 content", string(testlib.CatFileFromBareRepository(t, url, "fake2.txt")))
 	})
 
+	t.Run("get file", func(t *testing.T) {
+		url := testlib.GitMakeBareRepository(t)
+		repo := Repo{
+			GitURL:     url,
+			PrivateKey: sshKey,
+			Name:       "test1",
+		}
+		require.NoError(t, NewGitUploadClient(repo.Branch).CreateFile(
+			testctx.WrapWithCfg(t.Context(), config.Project{Dist: t.TempDir()}),
+			author,
+			repo,
+			[]byte("fake content"),
+			"dir/fake.txt",
+			"hey test",
+		))
+
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: t.TempDir(),
+		})
+		cli := NewGitUploadClient(repo.Branch)
+		content, err := cli.GetFile(ctx, repo, "dir/fake.txt")
+		require.NoError(t, err)
+		require.Equal(t, "fake content", string(content))
+		_, err = cli.GetFile(ctx, repo, "nope.txt")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("get file then create files", func(t *testing.T) {
+		url := testlib.GitMakeBareRepository(t)
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: t.TempDir(),
+		})
+		repo := Repo{
+			GitURL:     url,
+			PrivateKey: sshKey,
+			Name:       "test1",
+		}
+		cli := NewGitUploadClient(repo.Branch)
+		_, err := cli.GetFile(ctx, repo, "fake.txt")
+		require.ErrorIs(t, err, os.ErrNotExist)
+		require.NoError(t, cli.CreateFile(ctx, author, repo, []byte("fake content"), "fake.txt", "hey test"))
+
+		// the clone is only configured once, so the first author is kept.
+		other := config.CommitAuthor{Name: "Bar", Email: "bar@foo.com"}
+		require.NoError(t, cli.CreateFile(ctx, other, repo, []byte("fake2 content"), "fake2.txt", "hey test 2"))
+
+		out, err := exec.CommandContext(t.Context(), "git", "-C", url, "log", "--format=%an <%ae>").CombinedOutput()
+		require.NoError(t, err)
+		require.Equal(t, "Foo <foo@bar.com>\nFoo <foo@bar.com>\n", string(out))
+	})
+
 	t.Run("with new branch", func(t *testing.T) {
 		url := testlib.GitMakeBareRepository(t)
 		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
@@ -231,6 +231,34 @@ func (c *giteaClient) CreateFile(
 	return err
 }
 
+// GetFile implements FileGetter.
+func (c *giteaClient) GetFile(ctx *context.Context, repo Repo, path string) ([]byte, error) {
+	ref := repo.Branch
+	if ref != "" {
+		_, res, err := giteaDo(ctx, func() (*gitea.Branch, *gitea.Response, error) {
+			return c.client.GetRepoBranch(repo.Owner, repo.Name, ref)
+		})
+		if err != nil && (res == nil || res.StatusCode != http.StatusNotFound) {
+			return nil, fmt.Errorf("could not get branch %q: %w", ref, err)
+		}
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			// the branch will be created from the default one.
+			ref = ""
+		}
+	}
+
+	content, res, err := giteaDo(ctx, func() ([]byte, *gitea.Response, error) {
+		return c.client.GetFile(repo.Owner, repo.Name, ref, path)
+	})
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get %q: %w", path, err)
+	}
+	return content, nil
+}
+
 func (c *giteaClient) createRelease(ctx *context.Context, title, body string) (*gitea.Release, error) {
 	releaseConfig := ctx.Config.Release
 	owner := releaseConfig.Gitea.Owner
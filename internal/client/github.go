@@ -515,6 +515,49 @@ func (c *githubClient) CreateFile(
 	return nil
 }
 
+// GetFile implements FileGetter.
+func (c *githubClient) GetFile(ctx *context.Context, repo Repo, path string) ([]byte, error) {
+	c.checkRateLimit(ctx)
+	ref := repo.Branch
+	if ref != "" {
+		_, res, err := githubDo(ctx, func() (*github.Branch, *github.Response, error) {
+			return c.client.Repositories.GetBranch(ctx, repo.Owner, repo.Name, ref, 100)
+		})
+		if err != nil && (res == nil || res.StatusCode != http.StatusNotFound) {
+			return nil, fmt.Errorf("could not get branch %q: %w", ref, err)
+		}
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			// the branch will be created from the default one.
+			ref = ""
+		}
+	}
+
+	file, res, err := githubDo(ctx, func() (*github.RepositoryContent, *github.Response, error) {
+		content, _, r, err := c.client.Repositories.GetContents(
+			ctx,
+			repo.Owner,
+			repo.Name,
+			path,
+			&github.RepositoryContentGetOptions{Ref: ref},
+		)
+		return content, r, err
+	})
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get %q: %w", path, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s is not a file", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %q: %w", path, err)
+	}
+	return []byte(content), nil
+}
+
 func (c *githubClient) CreateRelease(ctx *context.Context, body string) (string, error) {
 	tpl := tmpl.New(ctx)
 	title, err := tpl.Apply(ctx.Config.Release.NameTemplate)
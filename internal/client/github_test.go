@@ -2746,3 +2746,53 @@ func githubTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
 	t.Cleanup(srv.Close)
 	return srv
 }
+
+func TestGitHubGetFile(t *testing.T) {
+	t.Parallel()
+	srv := githubTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		switch r.URL.Path {
+		case "/api/v3/repos/someone/something/branches/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/api/v3/repos/someone/something/branches/feature":
+			fmt.Fprint(w, `{"name": "feature"}`)
+		case "/api/v3/repos/someone/something/contents/file.txt":
+			fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`,
+				base64.StdEncoding.EncodeToString([]byte("content@"+r.URL.Query().Get("ref"))))
+		case "/api/v3/repos/someone/something/contents/nope.txt":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Error("unhandled request: " + r.URL.Path)
+		}
+	})
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := newGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	repo := Repo{
+		Owner: "someone",
+		Name:  "something",
+	}
+
+	content, err := client.GetFile(ctx, repo, "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, "content@", string(content))
+
+	repo.Branch = "feature"
+	content, err = client.GetFile(ctx, repo, "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, "content@feature", string(content))
+
+	repo.Branch = "missing"
+	content, err = client.GetFile(ctx, repo, "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, "content@", string(content))
+
+	_, err = client.GetFile(ctx, repo, "nope.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
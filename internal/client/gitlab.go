@@ -417,6 +417,42 @@ func (c *gitlabClient) CreateFile(
 	return nil
 }
 
+// GetFile implements FileGetter.
+func (c *gitlabClient) GetFile(ctx *context.Context, repo Repo, fileName string) ([]byte, error) {
+	if err := c.checkIsPrivateToken(); err != nil {
+		return nil, fmt.Errorf("get file: %w", err)
+	}
+
+	projectID := repo.Name
+	if repo.Owner != "" {
+		projectID = repo.Owner + "/" + projectID
+	}
+
+	opts := &gitlab.GetRawFileOptions{}
+	if repo.Branch != "" {
+		exists, err := c.checkBranchExists(ctx, repo, repo.Branch)
+		if err != nil {
+			return nil, err
+		}
+		// if the branch does not exist, it will be created from the default
+		// one, which is the one used if no ref is given.
+		if exists {
+			opts.Ref = &repo.Branch
+		}
+	}
+
+	content, res, err := gitlabDo(ctx, func() ([]byte, *gitlab.Response, error) {
+		return c.client.RepositoryFiles.GetRawFile(projectID, fileName, opts)
+	})
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", fileName, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get %q: %w", fileName, err)
+	}
+	return content, nil
+}
+
 // CreateRelease creates a new release or updates it by keeping
 // the release notes if it exists.
 func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (releaseID string, err error) {
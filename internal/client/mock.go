@@ -17,6 +17,7 @@ var (
 	_ PullRequestOpener     = &Mock{}
 	_ ForkSyncer            = &Mock{}
 	_ ReleaseChecker        = &Mock{}
+	_ FileGetter            = &Mock{}
 )
 
 func NewMock() *Mock {
@@ -45,6 +46,7 @@ type Mock struct {
 	ReleaseNotesParams   []string
 	OpenedPullRequest    bool
 	SyncedFork           bool
+	Files                map[string]string
 }
 
 func (c *Mock) SyncFork(_ *context.Context, _ Repo, _ Repo) error {
@@ -99,6 +101,15 @@ func (c *Mock) ReleaseURLTemplate(_ *context.Context) (string, error) {
 	return "https://dummyhost/download/{{ urlPathEscape .Tag }}/{{ .ArtifactName }}", nil
 }
 
+// GetFile implements FileGetter, returning the content from Files.
+func (c *Mock) GetFile(_ *context.Context, _ Repo, path string) ([]byte, error) {
+	content, ok := c.Files[path]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	return []byte(content), nil
+}
+
 func (c *Mock) CreateFile(_ *context.Context, _ config.CommitAuthor, _ Repo, content []byte, path, msg string) error {
 	c.CreatedFile = true
 	c.Content = string(content)
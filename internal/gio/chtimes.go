@@ -22,3 +22,16 @@ func Chtimes(path, ts string) error {
 	}
 	return nil
 }
+
+// ModTime parses the given unix timestamp, as used by the mod_timestamp
+// options, returning fallback if it is empty.
+func ModTime(ts string, fallback time.Time) (time.Time, error) {
+	if ts == "" {
+		return fallback, nil
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid mod_timestamp: %w", err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
@@ -48,3 +48,19 @@ func TestChtimesEmpty(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, modTime.Equal(stat.ModTime()))
 }
+
+func TestModTime(t *testing.T) {
+	fallback := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got, err := ModTime("1700000000", fallback)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), got)
+
+	got, err = ModTime("", fallback)
+	require.NoError(t, err)
+	require.Equal(t, fallback, got)
+
+	_, err = ModTime("fake", fallback)
+	require.ErrorIs(t, err, strconv.ErrSyntax)
+	require.ErrorContains(t, err, "invalid mod_timestamp")
+}
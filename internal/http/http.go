@@ -4,6 +4,7 @@ package http
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	h "net/http"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/caarlos0/log"
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/channel"
 	"github.com/goreleaser/goreleaser/v2/internal/extrafiles"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
 	"github.com/goreleaser/goreleaser/v2/internal/redact"
@@ -106,6 +108,10 @@ func CheckConfig(ctx *context.Context, upload *config.Upload, kind string) error
 		return misconfigured(kind, upload, fmt.Sprintf("either 'password' or environment variable '%s' are required when 'username' is set", passwordEnv))
 	}
 
+	if upload.ChecksumDeploy && kind != "artifactory" {
+		return misconfigured(kind, upload, "'checksum_deploy' is only supported by artifactory")
+	}
+
 	if upload.TrustedCerts != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(upload.TrustedCerts)) {
 		return misconfigured(kind, upload, "no certificate could be added from the specified trusted_certificates configuration")
 	}
@@ -162,10 +168,15 @@ type ResponseChecker func(*h.Response) error
 
 // Upload does the actual uploading work.
 func Upload(ctx *context.Context, uploads []config.Upload, kind string, check ResponseChecker) error {
+	journal, err := openJournal(ctx.Config.Dist, kind)
+	if err != nil {
+		return err
+	}
+
 	skips := &pipe.SkipMemento{}
 	// Handle every configured upload
 	for _, upload := range uploads {
-		err := uploadOne(ctx, upload, kind, check)
+		err := uploadOne(ctx, upload, kind, check, journal)
 		if pipe.IsSkip(err) {
 			skips.Remember(err)
 			continue
@@ -178,7 +189,7 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 	return skips.Evaluate()
 }
 
-func uploadOne(ctx *context.Context, upload config.Upload, kind string, check ResponseChecker) error {
+func uploadOne(ctx *context.Context, upload config.Upload, kind string, check ResponseChecker, journal *journal) error {
 	if err := CheckConfig(ctx, &upload, kind); err != nil {
 		return err
 	}
@@ -190,6 +201,9 @@ func uploadOne(ctx *context.Context, upload config.Upload, kind string, check Re
 	if skip {
 		return pipe.Skip("skip evaluates to true")
 	}
+	if !channel.Includes(ctx, upload.Channels) {
+		return pipe.Skipf("release channel %q is not in %v", channel.Of(ctx), upload.Channels)
+	}
 
 	types := []artifact.Type{}
 	if upload.Checksum {
@@ -213,6 +227,9 @@ func uploadOne(ctx *context.Context, upload config.Upload, kind string, check Re
 			artifact.Makeself,
 			artifact.LinuxPackage,
 			artifact.Flatpak,
+			artifact.MacOSPkg,
+			artifact.DMG,
+			artifact.MSI,
 			artifact.PySdist,
 			artifact.PyWheel,
 		)
@@ -230,13 +247,13 @@ func uploadOne(ctx *context.Context, upload config.Upload, kind string, check Re
 			artifact.ByFormats(upload.Exts...),
 		),
 	)
-	if err := uploadWithFilter(ctx, &upload, filter, kind, check); err != nil {
+	if err := uploadWithFilter(ctx, &upload, filter, kind, check, journal); err != nil {
 		return err
 	}
 	return nil
 }
 
-func uploadWithFilter(ctx *context.Context, upload *config.Upload, filter artifact.Filter, kind string, check ResponseChecker) error {
+func uploadWithFilter(ctx *context.Context, upload *config.Upload, filter artifact.Filter, kind string, check ResponseChecker, journal *journal) error {
 	var artifacts []*artifact.Artifact
 	extraFiles, err := extrafiles.Find(ctx, upload.ExtraFiles)
 	if err != nil {
@@ -259,17 +276,21 @@ func uploadWithFilter(ctx *context.Context, upload *config.Upload, filter artifa
 		log.Info("no artifacts found")
 	}
 	log.Debugf("will upload %d artifacts", len(artifacts))
-	g := semerrgroup.New(ctx.Parallelism)
+	parallelism := upload.Parallelism
+	if parallelism <= 0 {
+		parallelism = ctx.Parallelism
+	}
+	g := semerrgroup.New(parallelism)
 	for _, artifact := range artifacts {
 		g.Go(func() error {
-			return uploadAsset(ctx, upload, artifact, kind, check)
+			return uploadAsset(ctx, upload, artifact, kind, check, journal)
 		})
 	}
 	return g.Wait()
 }
 
 // uploadAsset uploads file to target and logs all actions.
-func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact.Artifact, kind string, check ResponseChecker) error {
+func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact.Artifact, kind string, check ResponseChecker, journal *journal) error {
 	// username and secret are optional since the server may not support/need
 	// basic authentication always
 	username, err := getUsername(ctx, upload, kind)
@@ -300,7 +321,19 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 		}
 		targetURL += artifact.Name
 	}
-	log.Debugf("generated target url: %s", redact.String(targetURL, ctx.Env.Strings()))
+	redactedURL := redact.String(targetURL, ctx.Env.Strings())
+	log.Debugf("generated target url: %s", redactedURL)
+
+	sum, err := artifact.Checksum("sha256")
+	if err != nil {
+		return err
+	}
+	if journal.uploaded(redactedURL, sum) {
+		log.WithField("instance", upload.Name).
+			WithField("file", artifact.Name).
+			Info("already uploaded, skipping")
+		return nil
+	}
 
 	headers := make(map[string]string, len(upload.CustomHeaders))
 	for name, value := range upload.CustomHeaders {
@@ -311,13 +344,16 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 		headers[name] = resolvedValue
 	}
 	if upload.ChecksumHeader != "" {
-		sum, err := artifact.Checksum("sha256")
-		if err != nil {
-			return err
-		}
 		headers[upload.ChecksumHeader] = sum
 	}
 
+	if upload.ChecksumDeploy && checksumDeploy(ctx, upload, targetURL, username, secret, headers, artifact, sum) {
+		log.WithField("instance", upload.Name).
+			WithField("file", artifact.Name).
+			Info("deployed by checksum")
+		return journal.add(redactedURL, sum)
+	}
+
 	log.WithField("instance", upload.Name).
 		WithField("mode", upload.Mode).
 		WithField("file", artifact.Name).
@@ -331,7 +367,66 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 		log.WithError(err).Warn("failed to close response body")
 	}
 
-	return nil
+	return journal.add(redactedURL, sum)
+}
+
+// checksumDeploy tries to deploy the artifact without sending its contents,
+// which Artifactory supports if it already has a file with the same
+// checksums.
+//
+// It returns false if the server could not deploy it, or if the checksums it
+// reports don't match, in which case the artifact should be uploaded normally.
+func checksumDeploy(ctx *context.Context, upload *config.Upload, target, username, secret string, headers map[string]string, artifact *artifact.Artifact, sha256sum string) bool {
+	sha1sum, err := artifact.Checksum("sha1")
+	if err != nil {
+		log.WithError(err).Debug("could not checksum artifact")
+		return false
+	}
+	req, err := h.NewRequestWithContext(ctx, upload.Method, target, h.NoBody)
+	if err != nil {
+		log.WithError(err).Debug("could not create checksum deploy request")
+		return false
+	}
+	if username != "" && secret != "" {
+		req.SetBasicAuth(username, secret)
+	}
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
+	req.Header.Set("X-Checksum-Deploy", "true")
+	req.Header.Set("X-Checksum-Sha1", sha1sum)
+	req.Header.Set("X-Checksum-Sha256", sha256sum)
+
+	client, err := getHTTPClient(upload)
+	if err != nil {
+		log.WithError(err).Debug("could not create http client")
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.WithError(err).Debug("checksum deploy failed")
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		log.WithField("status", resp.StatusCode).Debug("checksum deploy failed")
+		return false
+	}
+	var deployed struct {
+		Checksums struct {
+			SHA1   string `json:"sha1"`
+			SHA256 string `json:"sha256"`
+		} `json:"checksums"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&deployed); err != nil {
+		log.WithError(err).Debug("could not parse checksum deploy response")
+		return false
+	}
+	if deployed.Checksums.SHA1 != sha1sum || deployed.Checksums.SHA256 != sha256sum {
+		log.Debug("checksum deploy response doesn't match the artifact checksums")
+		return false
+	}
+	return true
 }
 
 // uploadAssetToServer uploads the asset file to target.
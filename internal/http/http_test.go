@@ -15,6 +15,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
@@ -750,3 +751,228 @@ func TestManyUploads(t *testing.T) {
 	require.True(t, pipe.IsSkip(err), err)
 	require.True(t, uploaded.Load(), "should have uploaded")
 }
+
+func TestUploadChannels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("should not have uploaded")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpFile := filepath.Join(t.TempDir(), "checksums.txt")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("a"), 0o644))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "blah",
+		Uploads: []config.Upload{
+			{
+				Name:     "stable",
+				Mode:     "archive",
+				Checksum: true,
+				Target:   srv.URL,
+				Channels: []string{"stable"},
+			},
+		},
+	}, testctx.WithVersion("2.1.0-beta.1"), testctx.WithSemver(2, 1, 0, "beta.1"))
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "checksums.txt",
+		Path: tmpFile,
+		Type: artifact.Checksum,
+	})
+	err := Upload(ctx, ctx.Config.Uploads, "test", func(*http.Response) error { return nil })
+	require.True(t, pipe.IsSkip(err), err)
+	require.ErrorContains(t, err, `release channel "beta" is not in [stable]`)
+}
+
+func TestUploadJournal(t *testing.T) {
+	var failing atomic.Bool
+	var m sync.Mutex
+	var uploaded []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() && strings.HasSuffix(r.URL.Path, "/b.tar.gz") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		m.Lock()
+		uploaded = append(uploaded, r.URL.Path)
+		m.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "blah",
+		Dist:        dist,
+		Uploads: []config.Upload{{
+			Name:   "production",
+			Mode:   ModeArchive,
+			Target: srv.URL + "/{{ .ProjectName }}/{{ .Version }}/",
+		}},
+	}, testctx.WithVersion("2.1.0"))
+	for _, name := range []string{"a.tar.gz", "b.tar.gz", "c.tar.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: name,
+			Path: path,
+			Type: artifact.UploadableArchive,
+		})
+	}
+	publish := func(tb testing.TB) ([]string, error) {
+		tb.Helper()
+		uploaded = nil
+		err := Upload(ctx, ctx.Config.Uploads, "test", func(r *http.Response) error {
+			if r.StatusCode/100 == 2 {
+				return nil
+			}
+			return fmt.Errorf("unexpected http status code: %v", r.StatusCode)
+		})
+		return uploaded, err
+	}
+
+	failing.Store(true)
+	got, err := publish(t)
+	require.ErrorContains(t, err, "unexpected http status code: 500")
+	require.ElementsMatch(t, []string{"/blah/2.1.0/a.tar.gz", "/blah/2.1.0/c.tar.gz"}, got)
+	require.FileExists(t, filepath.Join(dist, "test-journal.json"))
+
+	failing.Store(false)
+	got, err = publish(t)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/blah/2.1.0/b.tar.gz"}, got)
+
+	got, err = publish(t)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	t.Run("changed", func(t *testing.T) {
+		a := ctx.Artifacts.List()[0]
+		require.NoError(t, os.WriteFile(a.Path, []byte("changed"), 0o644))
+		got, err := publish(t)
+		require.NoError(t, err)
+		require.Equal(t, []string{"/blah/2.1.0/" + a.Name}, got)
+	})
+
+	t.Run("invalid journal", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dist, "test-journal.json"), []byte("{"), 0o644))
+		_, err := publish(t)
+		require.ErrorContains(t, err, "failed to parse upload journal")
+	})
+}
+
+func TestUploadChecksumDeploy(t *testing.T) {
+	// a.tar.gz is known by the server, b.tar.gz isn't, and c.tar.gz is
+	// accepted by a server that doesn't really support checksum deploy.
+	var m sync.Mutex
+	var deployed, uploaded []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		name := filepath.Base(r.URL.Path)
+		if r.Header.Get("X-Checksum-Deploy") == "true" {
+			sha1sum := r.Header.Get("X-Checksum-Sha1")
+			sha256sum := r.Header.Get("X-Checksum-Sha256")
+			require.Len(t, sha1sum, 40)
+			require.Len(t, sha256sum, 64)
+			require.Zero(t, r.ContentLength)
+			switch name {
+			case "a.tar.gz":
+				deployed = append(deployed, name)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = fmt.Fprintf(w, `{"checksums":{"sha1":%q,"sha256":%q}}`, sha1sum, sha256sum)
+			case "c.tar.gz":
+				w.WriteHeader(http.StatusCreated)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		}
+		bts, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, name, string(bts))
+		uploaded = append(uploaded, name)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "blah",
+		Artifactories: []config.Upload{{
+			Name:           "production",
+			Mode:           ModeArchive,
+			Target:         srv.URL,
+			ChecksumDeploy: true,
+		}},
+	}, testctx.WithVersion("2.1.0"))
+	for _, name := range []string{"a.tar.gz", "b.tar.gz", "c.tar.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: name,
+			Path: path,
+			Type: artifact.UploadableArchive,
+		})
+	}
+	require.NoError(t, Upload(ctx, ctx.Config.Artifactories, "artifactory", func(*http.Response) error { return nil }))
+	require.Equal(t, []string{"a.tar.gz"}, deployed)
+	require.ElementsMatch(t, []string{"b.tar.gz", "c.tar.gz"}, uploaded)
+}
+
+func TestUploadChecksumDeployNotArtifactory(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "blah",
+		Uploads: []config.Upload{{
+			Name:           "production",
+			Mode:           ModeArchive,
+			Target:         "http://example.com",
+			ChecksumDeploy: true,
+		}},
+	})
+	require.EqualError(
+		t,
+		Upload(ctx, ctx.Config.Uploads, "upload", func(*http.Response) error { return nil }),
+		"upload section 'production' is not configured properly ('checksum_deploy' is only supported by artifactory)",
+	)
+}
+
+func TestUploadParallelism(t *testing.T) {
+	var current, highest atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			h := highest.Load()
+			if n <= h || highest.CompareAndSwap(h, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "blah",
+		Uploads: []config.Upload{{
+			Name:        "production",
+			Mode:        ModeArchive,
+			Target:      srv.URL,
+			Parallelism: 2,
+		}},
+	}, testctx.WithVersion("2.1.0"))
+	ctx.Parallelism = 10
+	for i := range 10 {
+		path := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(path, []byte("a"), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: fmt.Sprintf("%d.tar.gz", i),
+			Path: path,
+			Type: artifact.UploadableArchive,
+		})
+	}
+	require.NoError(t, Upload(ctx, ctx.Config.Uploads, "test", func(*http.Response) error { return nil }))
+	require.LessOrEqual(t, highest.Load(), int32(2))
+	require.Positive(t, highest.Load())
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// journal keeps track of the files already uploaded, so publishing again
+// from the same dist only uploads the missing ones.
+//
+// A nil journal does not track anything.
+type journal struct {
+	path string
+	lock sync.Mutex
+	// Uploads maps the target URLs to the sha256 of the uploaded files.
+	Uploads map[string]string `json:"uploads"`
+}
+
+// openJournal loads the journal of the given kind from the dist directory,
+// if any.
+func openJournal(dist, kind string) (*journal, error) {
+	if dist == "" {
+		return nil, nil
+	}
+	j := &journal{
+		path:    filepath.Join(dist, kind+"-journal.json"),
+		Uploads: map[string]string{},
+	}
+	bts, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload journal: %w", err)
+	}
+	if err := json.Unmarshal(bts, j); err != nil {
+		return nil, fmt.Errorf("failed to parse upload journal %s: %w", j.path, err)
+	}
+	return j, nil
+}
+
+// uploaded checks whether the file with the given sha256 was already
+// uploaded to the given target.
+func (j *journal) uploaded(target, sum string) bool {
+	if j == nil {
+		return false
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.Uploads[target] == sum
+}
+
+// add records the given upload, and persists the journal.
+func (j *journal) add(target, sum string) error {
+	if j == nil {
+		return nil
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.Uploads[target] = sum
+	bts, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so an interrupted write doesn't
+	// corrupt the journal.
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, bts, 0o644); err != nil {
+		return fmt.Errorf("failed to write upload journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("failed to write upload journal: %w", err)
+	}
+	return nil
+}
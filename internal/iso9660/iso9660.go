@@ -0,0 +1,538 @@
+// Package iso9660 writes ISO 9660 filesystem images with Rock Ridge
+// extensions, so file names, and permissions are preserved.
+//
+// It is used as the filesystem inside DMG images, as macOS can mount it
+// natively.
+package iso9660
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SectorSize is the ISO 9660 logical sector size.
+const SectorSize = 2048
+
+const (
+	systemAreaSectors = 16
+	maxNameLen        = 150
+
+	rripID     = "RRIP_1991A"
+	rripDesc   = "THE ROCK RIDGE INTERCHANGE PROTOCOL PROVIDES SUPPORT FOR POSIX FILE SYSTEM SEMANTICS"
+	rripSource = "PLEASE CONTACT DISC PUBLISHER FOR SPECIFICATION SOURCE.  SEE PUBLISHER IDENTIFIER IN PRIMARY VOLUME DESCRIPTOR FOR CONTACT INFORMATION."
+)
+
+// File is a file or directory in the image.
+type File struct {
+	// Name is the slash separated path of the file inside the image.
+	Name    string
+	Mode    fs.FileMode
+	ModTime time.Time
+	// Data is the file contents, ignored for directories.
+	Data []byte
+	// Path is the file to copy the contents from when Data is not set, so
+	// they are streamed into the image instead.
+	Path string
+}
+
+// size returns the size of the file contents.
+func (f File) size() (int64, error) {
+	if f.Path == "" {
+		return int64(len(f.Data)), nil
+	}
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return 0, fmt.Errorf("iso9660: %w", err)
+	}
+	return info.Size(), nil
+}
+
+type node struct {
+	file     File
+	isoName  string
+	children []*node
+	parent   *node
+
+	// set when laying out the image.
+	number int // directory number in the path table.
+	lba    uint32
+	size   uint32
+}
+
+func (n *node) isDir() bool { return n.file.Mode.IsDir() }
+
+// Write writes an ISO 9660 image with the given volume name and files to w.
+//
+// Parent directories are created as needed.
+func Write(w io.Writer, volume string, files []File, created time.Time) error {
+	root, err := tree(files, created)
+	if err != nil {
+		return err
+	}
+
+	// directories in path table order.
+	dirs := []*node{root}
+	for i := 0; i < len(dirs); i++ {
+		for _, c := range dirs[i].children {
+			if c.isDir() {
+				dirs = append(dirs, c)
+			}
+		}
+	}
+	for i, d := range dirs {
+		d.number = i + 1
+	}
+
+	// layout: system area, primary volume descriptor, terminator, path
+	// tables, directories, rock ridge continuation area, and file data.
+	//
+	// the continuation area must come after the root directory, as some
+	// readers only read forward.
+	ptSize := uint32(0)
+	for _, d := range dirs {
+		ptSize += uint32(8 + len(pathTableName(d)) + len(pathTableName(d))%2)
+	}
+	lPathLBA := uint32(systemAreaSectors + 2)
+	mPathLBA := lPathLBA + sectors(ptSize)
+	next := mPathLBA + sectors(ptSize)
+
+	for _, d := range dirs {
+		records, err := dirRecords(d, 0)
+		if err != nil {
+			return err
+		}
+		d.lba = next
+		d.size = uint32(len(records))
+		next += sectors(d.size)
+	}
+	ceLBA := next
+	next++
+	for _, d := range dirs {
+		for _, c := range d.children {
+			if c.isDir() {
+				continue
+			}
+			size, err := c.file.size()
+			if err != nil {
+				return err
+			}
+			if size == 0 {
+				continue
+			}
+			if uint64(size) > uint64(^uint32(0)) {
+				return fmt.Errorf("iso9660: %s: file too big", c.file.Name)
+			}
+			c.lba = next
+			c.size = uint32(size)
+			next += sectors(c.size)
+		}
+	}
+	total := next
+
+	out := &sectorWriter{w: w}
+	out.write(make([]byte, systemAreaSectors*SectorSize))
+	out.write(primaryVolumeDescriptor(volume, root, total, ptSize, lPathLBA, mPathLBA, created))
+	out.write(terminator())
+	out.write(pathTable(dirs, binary.LittleEndian))
+	out.pad()
+	out.write(pathTable(dirs, binary.BigEndian))
+	out.pad()
+	for _, d := range dirs {
+		records, err := dirRecords(d, ceLBA)
+		if err != nil {
+			return err
+		}
+		out.write(records)
+		out.pad()
+	}
+	out.write(extensionReference())
+	out.pad()
+	for _, d := range dirs {
+		for _, c := range d.children {
+			if c.isDir() || c.size == 0 {
+				continue
+			}
+			out.copy(c.file, c.size)
+			out.pad()
+		}
+	}
+	if out.err != nil {
+		return fmt.Errorf("iso9660: %w", out.err)
+	}
+	if out.n != int64(total)*SectorSize {
+		return errors.New("iso9660: unexpected image size")
+	}
+	return nil
+}
+
+type sectorWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (s *sectorWriter) write(b []byte) {
+	if s.err != nil {
+		return
+	}
+	n, err := s.w.Write(b)
+	s.n += int64(n)
+	s.err = err
+}
+
+// copy writes the given file contents, which must have the given size.
+func (s *sectorWriter) copy(f File, size uint32) {
+	if s.err != nil {
+		return
+	}
+	if f.Path == "" {
+		s.write(f.Data)
+		return
+	}
+	in, err := os.Open(f.Path)
+	if err != nil {
+		s.err = err
+		return
+	}
+	defer in.Close()
+	n, err := io.Copy(s.w, in)
+	s.n += n
+	s.err = err
+	if err == nil && n != int64(size) {
+		s.err = fmt.Errorf("%s: file changed while writing the image", f.Path)
+	}
+}
+
+func (s *sectorWriter) pad() {
+	if rem := s.n % SectorSize; rem != 0 {
+		s.write(make([]byte, SectorSize-rem))
+	}
+}
+
+func sectors(size uint32) uint32 {
+	return (size + SectorSize - 1) / SectorSize
+}
+
+func tree(files []File, created time.Time) (*node, error) {
+	root := &node{file: File{Mode: fs.ModeDir | 0o755, ModTime: created}}
+	dirs := map[string]*node{"": root}
+	var mkdir func(name string) *node
+	mkdir = func(name string) *node {
+		if n, ok := dirs[name]; ok {
+			return n
+		}
+		parent := mkdir(parentOf(name))
+		n := &node{
+			file:   File{Name: name, Mode: fs.ModeDir | 0o755, ModTime: created},
+			parent: parent,
+		}
+		parent.children = append(parent.children, n)
+		dirs[name] = n
+		return n
+	}
+
+	for _, f := range files {
+		name := strings.Trim(path.Clean(f.Name), "/")
+		if name == "" || name == "." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("iso9660: invalid file name %q", f.Name)
+		}
+		if len(path.Base(name)) > maxNameLen {
+			return nil, fmt.Errorf("iso9660: %s: name too long", name)
+		}
+		f.Name = name
+		if f.Mode.IsDir() {
+			mkdir(name).file = f
+			continue
+		}
+		if _, ok := dirs[name]; ok {
+			return nil, fmt.Errorf("iso9660: %s: is a directory", name)
+		}
+		parent := mkdir(parentOf(name))
+		if slices.ContainsFunc(parent.children, func(n *node) bool { return n.file.Name == name }) {
+			return nil, fmt.Errorf("iso9660: %s: duplicated file", name)
+		}
+		parent.children = append(parent.children, &node{file: f, parent: parent})
+	}
+
+	for _, d := range dirs {
+		assignNames(d.children)
+	}
+	return root, nil
+}
+
+func parentOf(name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// assignNames sets unique ISO 9660 identifiers for the given siblings, and
+// sorts them by it, as required by the spec.
+//
+// The real names are kept in the Rock Ridge NM entries.
+func assignNames(nodes []*node) {
+	seen := map[string]bool{}
+	for _, n := range nodes {
+		base := isoName(path.Base(n.file.Name), n.isDir())
+		name := base
+		for i := 1; seen[name]; i++ {
+			suffix := "_" + strconv.Itoa(i)
+			if n.isDir() {
+				name = truncate(base, 31-len(suffix)) + suffix
+				continue
+			}
+			stem, ext, _ := strings.Cut(strings.TrimSuffix(base, ";1"), ".")
+			name = truncate(stem, 26-len(ext)-len(suffix)) + suffix + "." + ext + ";1"
+		}
+		seen[name] = true
+		n.isoName = name
+	}
+	slices.SortFunc(nodes, func(a, b *node) int {
+		return strings.Compare(a.isoName, b.isoName)
+	})
+}
+
+// isoName returns a valid ISO 9660 identifier for the given name.
+func isoName(name string, dir bool) string {
+	clean := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+				return r
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			default:
+				return '_'
+			}
+		}, s)
+	}
+	if dir {
+		return truncate(clean(name), 31)
+	}
+	stem, ext := name, ""
+	if i := strings.LastIndex(name, "."); i > 0 {
+		stem, ext = name[:i], name[i+1:]
+	}
+	ext = truncate(clean(ext), 3)
+	return truncate(clean(stem), 26-len(ext)) + "." + ext + ";1"
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func pathTableName(d *node) string {
+	if d.parent == nil {
+		return "\x00"
+	}
+	return d.isoName
+}
+
+func pathTable(dirs []*node, order binary.ByteOrder) []byte {
+	var b bytes.Buffer
+	for _, d := range dirs {
+		name := pathTableName(d)
+		parent := 1
+		if d.parent != nil {
+			parent = d.parent.number
+		}
+		rec := make([]byte, 8)
+		rec[0] = byte(len(name))
+		order.PutUint32(rec[2:], d.lba)
+		order.PutUint16(rec[6:], uint16(parent))
+		b.Write(rec)
+		b.WriteString(name)
+		if len(name)%2 == 1 {
+			b.WriteByte(0)
+		}
+	}
+	return b.Bytes()
+}
+
+// dirRecords returns the contents of the given directory extent.
+//
+// Its size doesn't depend on the layout, so it can be used to calculate it.
+func dirRecords(d *node, ceLBA uint32) ([]byte, error) {
+	parent := d
+	if d.parent != nil {
+		parent = d.parent
+	}
+
+	self := px(d)
+	if d.parent == nil {
+		self = append(sp(), self...)
+		self = append(self, ce(ceLBA, uint32(len(extensionReference())))...)
+	}
+	records := [][]byte{
+		record("\x00", self, d.lba, d.size, d.file.ModTime, true),
+		record("\x01", px(parent), parent.lba, parent.size, parent.file.ModTime, true),
+	}
+	for _, c := range d.children {
+		rec := record(c.isoName, append(px(c), nm(path.Base(c.file.Name))...), c.lba, c.size, c.file.ModTime, c.isDir())
+		if len(rec) > 255 {
+			return nil, fmt.Errorf("iso9660: %s: name too long", c.file.Name)
+		}
+		records = append(records, rec)
+	}
+
+	var b bytes.Buffer
+	for _, rec := range records {
+		if used := b.Len() % SectorSize; used+len(rec) > SectorSize {
+			b.Write(make([]byte, SectorSize-used))
+		}
+		b.Write(rec)
+	}
+	if rem := b.Len() % SectorSize; rem != 0 {
+		b.Write(make([]byte, SectorSize-rem))
+	}
+	return b.Bytes(), nil
+}
+
+func record(name string, su []byte, lba, size uint32, mtime time.Time, dir bool) []byte {
+	length := 33 + len(name)
+	if len(name)%2 == 0 {
+		length++
+	}
+	rec := make([]byte, length, length+len(su))
+	bothUint32(rec[2:], lba)
+	bothUint32(rec[10:], size)
+	copy(rec[18:], recordDate(mtime))
+	if dir {
+		rec[25] = 2
+	}
+	bothUint16(rec[28:], 1)
+	rec[32] = byte(len(name))
+	copy(rec[33:], name)
+	rec = append(rec, su...)
+	rec[0] = byte(len(rec))
+	return rec
+}
+
+// sp is the SUSP entry that marks the use of the System Use Sharing Protocol.
+func sp() []byte {
+	return []byte{'S', 'P', 7, 1, 0xbe, 0xef, 0}
+}
+
+// ce points to the continuation area, where the ER entry is.
+func ce(lba, length uint32) []byte {
+	b := make([]byte, 28)
+	copy(b, "CE")
+	b[2], b[3] = 28, 1
+	bothUint32(b[4:], lba)
+	bothUint32(b[12:], 0)
+	bothUint32(b[20:], length)
+	return b
+}
+
+// extensionReference is the ER entry announcing the Rock Ridge extensions.
+func extensionReference() []byte {
+	b := []byte{'E', 'R', byte(8 + len(rripID) + len(rripDesc) + len(rripSource)), 1, byte(len(rripID)), byte(len(rripDesc)), byte(len(rripSource)), 1}
+	b = append(b, rripID...)
+	b = append(b, rripDesc...)
+	return append(b, rripSource...)
+}
+
+// px is the Rock Ridge entry with the POSIX file attributes.
+func px(n *node) []byte {
+	b := make([]byte, 36)
+	copy(b, "PX")
+	b[2], b[3] = 36, 1
+	mode, links := uint32(0o100000), uint32(1)
+	if n.isDir() {
+		mode, links = 0o040000, 2
+	}
+	bothUint32(b[4:], mode|uint32(n.file.Mode.Perm()))
+	bothUint32(b[12:], links)
+	return b
+}
+
+// nm is the Rock Ridge entry with the alternate (real) name.
+func nm(name string) []byte {
+	b := []byte{'N', 'M', byte(5 + len(name)), 1, 0}
+	return append(b, name...)
+}
+
+func primaryVolumeDescriptor(volume string, root *node, total, ptSize, lPathLBA, mPathLBA uint32, created time.Time) []byte {
+	b := make([]byte, SectorSize)
+	b[0] = 1
+	copy(b[1:], "CD001")
+	b[6] = 1
+	copy(b[8:], padded("", 32))
+	copy(b[40:], padded(volume, 32))
+	bothUint32(b[80:], total)
+	bothUint16(b[120:], 1)
+	bothUint16(b[124:], 1)
+	bothUint16(b[128:], SectorSize)
+	bothUint32(b[132:], ptSize)
+	binary.LittleEndian.PutUint32(b[140:], lPathLBA)
+	binary.BigEndian.PutUint32(b[148:], mPathLBA)
+	copy(b[156:], record("\x00", nil, root.lba, root.size, root.file.ModTime, true))
+	copy(b[190:], padded("", 128))
+	copy(b[318:], padded("", 128))
+	copy(b[446:], padded("", 128))
+	copy(b[574:], padded("GORELEASER", 128))
+	copy(b[702:], padded("", 37*3))
+	copy(b[813:], volumeDate(created))
+	copy(b[830:], volumeDate(created))
+	copy(b[847:], volumeDate(time.Time{}))
+	copy(b[864:], volumeDate(time.Time{}))
+	b[881] = 1
+	return b
+}
+
+func terminator() []byte {
+	b := make([]byte, SectorSize)
+	b[0] = 255
+	copy(b[1:], "CD001")
+	b[6] = 1
+	return b
+}
+
+func padded(s string, n int) []byte {
+	return []byte(truncate(s, n) + strings.Repeat(" ", max(0, n-len(s))))
+}
+
+func recordDate(t time.Time) []byte {
+	t = t.UTC()
+	return []byte{
+		byte(t.Year() - 1900),
+		byte(t.Month()),
+		byte(t.Day()),
+		byte(t.Hour()),
+		byte(t.Minute()),
+		byte(t.Second()),
+		0,
+	}
+}
+
+func volumeDate(t time.Time) []byte {
+	if t.IsZero() {
+		return append([]byte(strings.Repeat("0", 16)), 0)
+	}
+	return append([]byte(t.UTC().Format("20060102150405")+"00"), 0)
+}
+
+func bothUint16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b, v)
+	binary.BigEndian.PutUint16(b[2:], v)
+}
+
+func bothUint32(b []byte, v uint32) {
+	binary.LittleEndian.PutUint32(b, v)
+	binary.BigEndian.PutUint32(b[4:], v)
+}
@@ -0,0 +1,203 @@
+package iso9660
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, "foo_1.0", []File{
+		{Name: "foo", Mode: 0o755, ModTime: now, Data: []byte("binary")},
+		{Name: "README.md", Mode: 0o644, ModTime: now, Data: []byte("# foo")},
+		{Name: "docs/LICENSE", Mode: 0o600, ModTime: now, Data: bytes.Repeat([]byte("a"), SectorSize+1)},
+		{Name: "docs/empty", Mode: 0o644, ModTime: now},
+		{Name: "empty-dir", Mode: fs.ModeDir | 0o700, ModTime: now},
+	}, now))
+	require.Zero(t, buf.Len()%SectorSize)
+
+	img := buf.Bytes()
+	pvd := img[16*SectorSize:]
+	require.Equal(t, []byte{1, 'C', 'D', '0', '0', '1', 1}, pvd[:7])
+	require.Equal(t, "foo_1.0", strings.TrimSpace(string(pvd[40:72])))
+	require.Equal(t, uint32(len(img)/SectorSize), binary.LittleEndian.Uint32(pvd[80:]))
+	require.Equal(t, byte(255), img[17*SectorSize])
+
+	require.Equal(t, map[string]entry{
+		"README.md":    {mode: 0o100644, data: "# foo"},
+		"docs":         {mode: 0o040755},
+		"docs/LICENSE": {mode: 0o100600, data: strings.Repeat("a", SectorSize+1)},
+		"docs/empty":   {mode: 0o100644},
+		"empty-dir":    {mode: 0o040700},
+		"foo":          {mode: 0o100755, data: "binary"},
+	}, readImage(t, img))
+
+	t.Run("reproducible", func(t *testing.T) {
+		var again bytes.Buffer
+		require.NoError(t, Write(&again, "foo_1.0", []File{
+			{Name: "empty-dir", Mode: fs.ModeDir | 0o700, ModTime: now},
+			{Name: "docs/empty", Mode: 0o644, ModTime: now},
+			{Name: "docs/LICENSE", Mode: 0o600, ModTime: now, Data: bytes.Repeat([]byte("a"), SectorSize+1)},
+			{Name: "README.md", Mode: 0o644, ModTime: now, Data: []byte("# foo")},
+			{Name: "foo", Mode: 0o755, ModTime: now, Data: []byte("binary")},
+		}, now))
+		require.Equal(t, img, again.Bytes())
+	})
+}
+
+func TestWriteFromPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo")
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("b"), SectorSize*2+1), 0o755))
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, "foo_1.0", []File{
+		{Name: "foo", Mode: 0o755, ModTime: now, Path: path},
+		{Name: "README.md", Mode: 0o644, ModTime: now, Data: []byte("# foo")},
+	}, now))
+	require.Equal(t, map[string]entry{
+		"README.md": {mode: 0o100644, data: "# foo"},
+		"foo":       {mode: 0o100755, data: strings.Repeat("b", SectorSize*2+1)},
+	}, readImage(t, buf.Bytes()))
+
+	t.Run("missing", func(t *testing.T) {
+		require.ErrorIs(t, Write(io.Discard, "foo_1.0", []File{
+			{Name: "foo", Mode: 0o755, ModTime: now, Path: filepath.Join(t.TempDir(), "nope")},
+		}, now), os.ErrNotExist)
+	})
+}
+
+func TestIsoNames(t *testing.T) {
+	var buf bytes.Buffer
+	files := []File{
+		{Name: "foo.tar.gz", Mode: 0o644},
+		{Name: "foo.tar-gz", Mode: 0o644},
+		{Name: "a-very-long-file-name-that-does-not-fit.txt", Mode: 0o644},
+		{Name: "a-very-long-file-name-that-does-not-fit-either.txt", Mode: 0o644},
+		{Name: "a-very-long-directory-name-that-does-not-fit/foo", Mode: 0o644},
+		{Name: "a-very-long-directory-name-that-does-not-fit-either/foo", Mode: 0o644},
+	}
+	require.NoError(t, Write(&buf, "foo", files, now))
+
+	got := readImage(t, buf.Bytes())
+	for _, f := range files {
+		require.Contains(t, got, f.Name)
+	}
+
+	root, err := tree(files, now)
+	require.NoError(t, err)
+	var names []string
+	for _, c := range root.children {
+		names = append(names, c.isoName)
+	}
+	require.Equal(t, []string{
+		"A_VERY_LONG_DIRECTORY_NAME_THAT",
+		"A_VERY_LONG_DIRECTORY_NAME_TH_1",
+		"A_VERY_LONG_FILE_NAME_1.TXT;1",
+		"A_VERY_LONG_FILE_NAME_T.TXT;1",
+		"FOO.TAR;1",
+		"FOO_TAR.GZ;1",
+	}, names)
+}
+
+func TestWriteErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		files []File
+		err   string
+	}{
+		"empty name": {
+			files: []File{{Name: "/"}},
+			err:   `iso9660: invalid file name "/"`,
+		},
+		"outside": {
+			files: []File{{Name: "../foo"}},
+			err:   `iso9660: invalid file name "../foo"`,
+		},
+		"too long": {
+			files: []File{{Name: strings.Repeat("a", 151)}},
+			err:   "iso9660: " + strings.Repeat("a", 151) + ": name too long",
+		},
+		"duplicated": {
+			files: []File{{Name: "foo"}, {Name: "foo"}},
+			err:   "iso9660: foo: duplicated file",
+		},
+		"file over directory": {
+			files: []File{{Name: "foo/bar"}, {Name: "foo"}},
+			err:   "iso9660: foo: is a directory",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.EqualError(t, Write(&bytes.Buffer{}, "foo", tc.files, now), tc.err)
+		})
+	}
+}
+
+type entry struct {
+	mode uint32
+	data string
+}
+
+// readImage walks the image using the Rock Ridge names and modes.
+func readImage(tb testing.TB, img []byte) map[string]entry {
+	tb.Helper()
+	le := binary.LittleEndian
+	result := map[string]entry{}
+
+	var walk func(prefix string, lba, size uint32)
+	walk = func(prefix string, lba, size uint32) {
+		extent := img[lba*SectorSize : lba*SectorSize+size]
+		for off := 0; off < len(extent); {
+			length := int(extent[off])
+			if length == 0 {
+				// records don't cross sectors.
+				off = (off/SectorSize + 1) * SectorSize
+				continue
+			}
+			rec := extent[off : off+length]
+			off += length
+
+			nameLen := int(rec[32])
+			if nameLen == 1 && rec[33] <= 1 {
+				continue // . and ..
+			}
+			su := rec[33+nameLen+(nameLen+1)%2:]
+			var name string
+			var mode uint32
+			for len(su) >= 4 && su[2] > 0 {
+				switch string(su[:2]) {
+				case "NM":
+					name = string(su[5:su[2]])
+				case "PX":
+					mode = le.Uint32(su[4:])
+				}
+				su = su[su[2]:]
+			}
+			require.NotEmpty(tb, name)
+
+			extLBA, extSize := le.Uint32(rec[2:]), le.Uint32(rec[10:])
+			if rec[25]&2 != 0 {
+				result[prefix+name] = entry{mode: mode}
+				walk(prefix+name+"/", extLBA, extSize)
+				continue
+			}
+			result[prefix+name] = entry{
+				mode: mode,
+				data: string(img[extLBA*SectorSize : extLBA*SectorSize+extSize]),
+			}
+		}
+	}
+
+	root := img[16*SectorSize+156:]
+	walk("", le.Uint32(root[2:]), le.Uint32(root[10:]))
+	return result
+}
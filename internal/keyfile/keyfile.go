@@ -0,0 +1,75 @@
+// Package keyfile loads the keys used to sign and verify artifacts.
+package keyfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// Read returns the contents of the given key, which can be either a path to
+// a file or the key contents, which allows to pass it directly from an
+// environment variable.
+func Read(key string) ([]byte, error) {
+	trimmed := strings.TrimSpace(key)
+	if strings.HasPrefix(trimmed, "-----BEGIN ") ||
+		strings.HasPrefix(trimmed, "untrusted comment:") ||
+		isAuthorizedKey(trimmed) {
+		return []byte(trimmed), nil
+	}
+	return os.ReadFile(key)
+}
+
+// isAuthorizedKey returns true if the given key looks like an
+// authorized_keys formatted public key, e.g. 'ssh-ed25519 AAAA...'.
+func isAuthorizedKey(key string) bool {
+	return (strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-")) &&
+		strings.Contains(key, " ")
+}
+
+// OpenPGP returns the first entity with a private key of the given armored
+// or binary OpenPGP keyring, decrypted with the given password.
+func OpenPGP(key []byte, password string) (*openpgp.Entity, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(key))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read key: %w", err)
+	}
+	for _, entity := range keyring {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if err := entity.DecryptPrivateKeys([]byte(password)); err != nil {
+			return nil, fmt.Errorf("could not decrypt key: %w", err)
+		}
+		return entity, nil
+	}
+	return nil, errors.New("no private key found")
+}
+
+// OpenPGPSigningKey reads the given OpenPGP key, see [Read] and [OpenPGP],
+// and returns its private key to sign with at the given date, which might
+// be a subkey.
+func OpenPGPSigningKey(key, password string, date time.Time) (*packet.PrivateKey, error) {
+	bts, err := Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not read key: %w", err)
+	}
+	entity, err := OpenPGP(bts, password)
+	if err != nil {
+		return nil, err
+	}
+	signing, ok := entity.SigningKey(date)
+	if !ok {
+		return entity.PrivateKey, nil
+	}
+	return signing.PrivateKey, nil
+}
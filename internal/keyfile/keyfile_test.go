@@ -0,0 +1,80 @@
+package keyfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("from file"), 0o600))
+
+	for key, expected := range map[string]string{
+		path: "from file",
+		"  -----BEGIN PUBLIC KEY-----\nfoo\n-----END PUBLIC KEY-----\n": "-----BEGIN PUBLIC KEY-----\nfoo\n-----END PUBLIC KEY-----",
+		"untrusted comment: minisign public key\nRWQ=":                  "untrusted comment: minisign public key\nRWQ=",
+		"ssh-ed25519 AAAA foo@bar":                                      "ssh-ed25519 AAAA foo@bar",
+		"ecdsa-sha2-nistp256 AAAA":                                      "ecdsa-sha2-nistp256 AAAA",
+	} {
+		bts, err := Read(key)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(bts))
+	}
+
+	_, err := Read(filepath.Join(t.TempDir(), "ssh-key"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestOpenPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.PrivateKey.Encrypt([]byte("secret")))
+	for _, sub := range entity.Subkeys {
+		require.NoError(t, sub.PrivateKey.Encrypt([]byte("secret")))
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(t, w.Close())
+	key := buf.String()
+
+	t.Run("valid", func(t *testing.T) {
+		got, err := OpenPGP([]byte(key), "secret")
+		require.NoError(t, err)
+		require.Equal(t, entity.PrimaryKey.Fingerprint, got.PrimaryKey.Fingerprint)
+
+		signing, err := OpenPGPSigningKey(key, "secret", time.Now())
+		require.NoError(t, err)
+		require.False(t, signing.Encrypted)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := OpenPGPSigningKey(key, "nope", time.Now())
+		require.ErrorContains(t, err, "could not decrypt key")
+	})
+
+	t.Run("public key only", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, entity.Serialize(&buf))
+		_, err := OpenPGP(buf.Bytes(), "")
+		require.EqualError(t, err, "no private key found")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := OpenPGP([]byte("nope"), "")
+		require.ErrorContains(t, err, "could not read key")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := OpenPGPSigningKey(filepath.Join(t.TempDir(), "nope"), "", time.Now())
+		require.ErrorContains(t, err, "could not read key")
+	})
+}
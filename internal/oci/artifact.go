@@ -0,0 +1,183 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// MediaTypeEmpty is the media type of the empty config of OCI artifacts.
+const MediaTypeEmpty types.MediaType = "application/vnd.oci.empty.v1+json"
+
+// AnnotationTitle is the annotation holding the file name of a layer.
+const AnnotationTitle = "org.opencontainers.image.title"
+
+// Artifact is an OCI artifact manifest to be pushed.
+type Artifact struct {
+	ArtifactType string
+	// Config defaults to the empty config.
+	Config      v1.Layer
+	Layers      []Layer
+	Annotations map[string]string
+	// Subject is the manifest this artifact refers to, if any.
+	Subject *v1.Descriptor
+}
+
+// Layer is a layer of an artifact, and its annotations.
+type Layer struct {
+	v1.Layer
+	Annotations map[string]string
+}
+
+// Push pushes the given artifact, with its layers, to the given reference.
+//
+// If the artifact has a subject, it is also added to the referrers of the
+// subject, using the fallback tag if the registry doesn't support the
+// referrers API.
+func Push(ctx context.Context, ref name.Reference, artifact Artifact, opts ...remote.Option) (v1.Descriptor, error) {
+	opts = append([]remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(Keychain),
+	}, opts...)
+
+	config := artifact.config()
+	if err := remote.WriteLayer(ref.Context(), config, opts...); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("could not push config: %w", err)
+	}
+	for _, layer := range artifact.Layers {
+		if err := remote.WriteLayer(ref.Context(), layer, opts...); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("could not push %s: %w", layer.Annotations[AnnotationTitle], err)
+		}
+	}
+
+	raw, err := artifact.Manifest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if err := remote.Put(ref, rawManifest(raw), opts...); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("could not push manifest: %w", err)
+	}
+	return artifact.descriptor(raw)
+}
+
+// Descriptor returns the descriptor of the artifact manifest.
+func (a Artifact) Descriptor() (v1.Descriptor, error) {
+	raw, err := a.Manifest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return a.descriptor(raw)
+}
+
+func (a Artifact) descriptor(raw []byte) (v1.Descriptor, error) {
+	digest, size, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{
+		MediaType:    types.OCIManifestSchema1,
+		Size:         size,
+		Digest:       digest,
+		ArtifactType: a.ArtifactType,
+	}, nil
+}
+
+// Manifest returns the raw artifact manifest.
+func (a Artifact) Manifest() ([]byte, error) {
+	configDesc, err := describe(a.config(), nil)
+	if err != nil {
+		return nil, err
+	}
+	manifest := v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  a.ArtifactType,
+		Config:        configDesc,
+		Layers:        []v1.Descriptor{},
+		Annotations:   a.Annotations,
+		Subject:       a.Subject,
+	}
+	for _, layer := range a.Layers {
+		desc, err := describe(layer, layer.Annotations)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+	return json.Marshal(manifest)
+}
+
+func (a Artifact) config() v1.Layer {
+	if a.Config == nil {
+		return static.NewLayer([]byte("{}"), MediaTypeEmpty)
+	}
+	return a.Config
+}
+
+func describe(layer v1.Layer, annotations map[string]string) (v1.Descriptor, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	size, err := layer.Size()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	mt, err := layer.MediaType()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{
+		MediaType:   mt,
+		Size:        size,
+		Digest:      digest,
+		Annotations: annotations,
+	}, nil
+}
+
+type rawManifest []byte
+
+func (r rawManifest) RawManifest() ([]byte, error)      { return r, nil }
+func (rawManifest) MediaType() (types.MediaType, error) { return types.OCIManifestSchema1, nil }
+
+// FileLayer is a layer with the contents of the given file, as is.
+func FileLayer(path string, mt types.MediaType) (v1.Layer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	digest, size, err := v1.SHA256(f)
+	if err != nil {
+		return nil, err
+	}
+	return fileLayer{
+		path:   path,
+		digest: digest,
+		size:   size,
+		mt:     mt,
+	}, nil
+}
+
+type fileLayer struct {
+	path   string
+	digest v1.Hash
+	size   int64
+	mt     types.MediaType
+}
+
+func (l fileLayer) Digest() (v1.Hash, error)             { return l.digest, nil }
+func (l fileLayer) DiffID() (v1.Hash, error)             { return l.digest, nil }
+func (l fileLayer) Compressed() (io.ReadCloser, error)   { return os.Open(l.path) }
+func (l fileLayer) Uncompressed() (io.ReadCloser, error) { return os.Open(l.path) }
+func (l fileLayer) Size() (int64, error)                 { return l.size, nil }
+func (l fileLayer) MediaType() (types.MediaType, error)  { return l.mt, nil }
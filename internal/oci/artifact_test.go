@@ -0,0 +1,68 @@
+package oci
+
+import (
+	"io"
+	stdlog "log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPush(t *testing.T) {
+	srv := httptest.NewServer(registry.New(registry.Logger(stdlog.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o644))
+	layer, err := FileLayer(path, "text/plain")
+	require.NoError(t, err)
+
+	artifact := Artifact{
+		ArtifactType: "application/vnd.test",
+		Config:       static.NewLayer([]byte(`{"foo":"bar"}`), "application/vnd.test.config+json"),
+		Layers: []Layer{{
+			Layer:       layer,
+			Annotations: map[string]string{AnnotationTitle: "file.txt"},
+		}},
+	}
+	ref, err := name.ParseReference(host + "/test:v1")
+	require.NoError(t, err)
+	desc, err := Push(t.Context(), ref, artifact)
+	require.NoError(t, err)
+
+	expected, err := artifact.Descriptor()
+	require.NoError(t, err)
+	require.Equal(t, expected, desc)
+
+	got, err := remote.Get(ref)
+	require.NoError(t, err)
+	require.Equal(t, desc.Digest, got.Digest)
+	raw, err := artifact.Manifest()
+	require.NoError(t, err)
+	require.JSONEq(t, string(raw), string(got.Manifest))
+
+	digest, err := layer.Digest()
+	require.NoError(t, err)
+	pushed, err := remote.Layer(ref.Context().Digest(digest.String()))
+	require.NoError(t, err)
+	rc, err := pushed.Compressed()
+	require.NoError(t, err)
+	defer rc.Close()
+	bts, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(bts))
+}
+
+func TestFileLayerMissing(t *testing.T) {
+	_, err := FileLayer(filepath.Join(t.TempDir(), "nope"), "text/plain")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,22 @@
+// Package oci has helpers to push things to OCI registries.
+package oci
+
+import (
+	"io"
+
+	"github.com/awslabs/amazon-ecr-credential-helper/ecr-login"
+	"github.com/chrismellard/docker-credential-acr-env/pkg/credhelper"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/github"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+)
+
+// Keychain resolves the registry credentials from the Docker config file and
+// the usual cloud credential helpers.
+var Keychain = authn.NewMultiKeychain(
+	authn.NewKeychainFromHelper(ecr.NewECRHelper(ecr.WithLogger(io.Discard))),
+	authn.DefaultKeychain,
+	google.Keychain,
+	github.Keychain,
+	authn.NewKeychainFromHelper(credhelper.NewACRCredentialsHelper()),
+)
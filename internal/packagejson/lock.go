@@ -0,0 +1,213 @@
+package packagejson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Lock is a parsed package-lock.json or bun.lock.
+type Lock struct {
+	Packages []LockPackage
+}
+
+// LockPackage is a package locked in a lock file.
+type LockPackage struct {
+	Name    string
+	Version string
+	// Integrity is the Subresource Integrity of the package, e.g.
+	// 'sha512-...'.
+	Integrity string
+	// Dev is true if the package is only a development dependency.
+	Dev bool
+}
+
+// OpenLock opens and parses the given lock file name.
+//
+// Both npm's package-lock.json and bun's text based bun.lock are supported.
+func OpenLock(name string) (Lock, error) {
+	bts, err := os.ReadFile(name)
+	if err != nil {
+		return Lock{}, err
+	}
+	if filepath.Base(name) == "bun.lock" {
+		return parseBunLock(bts)
+	}
+	return parseNPMLock(bts)
+}
+
+type npmLock struct {
+	LockfileVersion int                      `json:"lockfileVersion"`
+	Packages        map[string]npmPackage    `json:"packages"`
+	Dependencies    map[string]npmDependency `json:"dependencies"`
+}
+
+type npmPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Integrity string `json:"integrity"`
+	Dev       bool   `json:"dev"`
+	Link      bool   `json:"link"`
+}
+
+type npmDependency struct {
+	Version      string                   `json:"version"`
+	Integrity    string                   `json:"integrity"`
+	Dev          bool                     `json:"dev"`
+	Dependencies map[string]npmDependency `json:"dependencies"`
+}
+
+func parseNPMLock(bts []byte) (Lock, error) {
+	var lock npmLock
+	if err := json.Unmarshal(bts, &lock); err != nil {
+		return Lock{}, err
+	}
+
+	var result Lock
+	if lock.LockfileVersion < 2 {
+		addNPMDependencies(&result, lock.Dependencies)
+	}
+	for key, pkg := range lock.Packages {
+		// the root package is under the "" key, links and workspaces point
+		// to local packages.
+		idx := strings.LastIndex(key, "node_modules/")
+		if idx < 0 || pkg.Link {
+			continue
+		}
+		name := pkg.Name
+		if name == "" {
+			name = strings.TrimPrefix(key[idx:], "node_modules/")
+		}
+		result.add(LockPackage{
+			Name:      name,
+			Version:   pkg.Version,
+			Integrity: pkg.Integrity,
+			Dev:       pkg.Dev,
+		})
+	}
+	result.sort()
+	return result, nil
+}
+
+// addNPMDependencies adds the dependencies of lockfile v1, which are nested.
+func addNPMDependencies(lock *Lock, deps map[string]npmDependency) {
+	for name, pkg := range deps {
+		lock.add(LockPackage{
+			Name:      name,
+			Version:   pkg.Version,
+			Integrity: pkg.Integrity,
+			Dev:       pkg.Dev,
+		})
+		addNPMDependencies(lock, pkg.Dependencies)
+	}
+}
+
+type bunLock struct {
+	Workspaces map[string]struct {
+		DevDependencies map[string]string `json:"devDependencies"` //nolint:tagliatelle
+	} `json:"workspaces"`
+	Packages map[string][]json.RawMessage `json:"packages"`
+}
+
+func parseBunLock(bts []byte) (Lock, error) {
+	var lock bunLock
+	if err := json.Unmarshal(stripTrailingCommas(bts), &lock); err != nil {
+		return Lock{}, err
+	}
+
+	dev := map[string]bool{}
+	for _, ws := range lock.Workspaces {
+		for name := range ws.DevDependencies {
+			dev[name] = true
+		}
+	}
+
+	var result Lock
+	for key, entry := range lock.Packages {
+		if len(entry) == 0 {
+			continue
+		}
+		// the first element is 'name@version', and the last one the
+		// integrity, if any.
+		var id, integrity string
+		if err := json.Unmarshal(entry[0], &id); err != nil {
+			return Lock{}, fmt.Errorf("invalid package %q: %w", key, err)
+		}
+		_ = json.Unmarshal(entry[len(entry)-1], &integrity)
+		if !strings.HasPrefix(integrity, "sha") {
+			integrity = ""
+		}
+
+		idx := strings.LastIndex(id, "@")
+		if idx <= 0 {
+			continue
+		}
+		name, version := id[:idx], id[idx+1:]
+		// workspace, link, file, etc.
+		if strings.Contains(version, ":") {
+			continue
+		}
+		result.add(LockPackage{
+			Name:      name,
+			Version:   version,
+			Integrity: integrity,
+			Dev:       dev[name],
+		})
+	}
+	result.sort()
+	return result, nil
+}
+
+func (l *Lock) add(pkg LockPackage) {
+	if slices.ContainsFunc(l.Packages, func(p LockPackage) bool {
+		return p.Name == pkg.Name && p.Version == pkg.Version
+	}) {
+		return
+	}
+	l.Packages = append(l.Packages, pkg)
+}
+
+func (l *Lock) sort() {
+	slices.SortFunc(l.Packages, func(a, b LockPackage) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Version, b.Version)
+	})
+}
+
+// stripTrailingCommas removes the trailing commas bun.lock has, so it can be
+// parsed as regular JSON.
+func stripTrailingCommas(bts []byte) []byte {
+	var out bytes.Buffer
+	var inString, escaped bool
+	for i, c := range bts {
+		if inString {
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			rest := bytes.TrimLeft(bts[i+1:], " \t\r\n")
+			if len(rest) > 0 && (rest[0] == '}' || rest[0] == ']') {
+				continue
+			}
+		}
+		out.WriteByte(c)
+	}
+	return out.Bytes()
+}
@@ -8,6 +8,7 @@ import (
 
 type Package struct {
 	Name    string            `json:"name"`
+	Version string            `json:"version"`
 	Module  string            `json:"module"`
 	Type    string            `json:"type"`
 	Engines map[string]string `json:"engines"`
@@ -1,6 +1,7 @@
 package packagejson
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -16,3 +17,31 @@ func TestParse(t *testing.T) {
 	require.Equal(t, ">=22.20.0", pkg.Engines["node"])
 	require.Equal(t, "esbuild src/index.ts", pkg.Scripts["build"])
 }
+
+func TestParseNPMLock(t *testing.T) {
+	lock, err := OpenLock("./testdata/package-lock.json")
+	require.NoError(t, err)
+	integrity := "sha512-9/u6bgY2+JDlb7vzKD5STG+jIErimDgtYkdB0NxmODJuKCxBvl5CVNiCB3LFUYosWowMf37aGVlKfrU5RT4e1w=="
+	require.Equal(t, []LockPackage{
+		{Name: "@scope/foo", Version: "0.9.0"},
+		{Name: "@scope/foo", Version: "1.0.1", Integrity: integrity},
+		{Name: "bar", Version: "2.0.0", Integrity: integrity},
+		{Name: "esbuild", Version: "0.25.0", Dev: true},
+	}, lock.Packages)
+}
+
+func TestParseBunLock(t *testing.T) {
+	lock, err := OpenLock("./testdata/bun.lock")
+	require.NoError(t, err)
+	integrity := "sha512-9/u6bgY2+JDlb7vzKD5STG+jIErimDgtYkdB0NxmODJuKCxBvl5CVNiCB3LFUYosWowMf37aGVlKfrU5RT4e1w=="
+	require.Equal(t, []LockPackage{
+		{Name: "@scope/foo", Version: "1.0.1", Integrity: integrity},
+		{Name: "@types/bun", Version: "1.2.0", Integrity: integrity, Dev: true},
+		{Name: "weird, name", Version: "1.0.0"},
+	}, lock.Packages)
+}
+
+func TestParseLockNotFound(t *testing.T) {
+	_, err := OpenLock("./testdata/nope.lock")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -4,6 +4,7 @@ package announce
 import (
 	"fmt"
 
+	"github.com/goreleaser/goreleaser/v2/internal/channel"
 	"github.com/goreleaser/goreleaser/v2/internal/middleware/errhandler"
 	"github.com/goreleaser/goreleaser/v2/internal/middleware/logging"
 	"github.com/goreleaser/goreleaser/v2/internal/middleware/skip"
@@ -60,6 +61,9 @@ func (Pipe) Skip(ctx *context.Context) (bool, error) {
 	if skips.Any(ctx, skips.Announce) {
 		return true, nil
 	}
+	if !channel.Includes(ctx, ctx.Config.Announce.Channels) {
+		return true, nil
+	}
 	return tmpl.New(ctx).Bool(ctx.Config.Announce.Skip)
 }
 
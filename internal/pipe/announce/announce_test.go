@@ -47,6 +47,18 @@ func TestSkip(t *testing.T) {
 		require.True(t, b)
 	})
 
+	t.Run("skip on channel", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Announce: config.Announce{
+				Channels: []string{"stable"},
+			},
+		}, testctx.WithSemver(1, 0, 0, "rc.1"))
+
+		b, err := Pipe{}.Skip(ctx)
+		require.NoError(t, err)
+		require.True(t, b)
+	})
+
 	t.Run("skip on patches", func(t *testing.T) {
 		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
 			Announce: config.Announce{
@@ -3,6 +3,7 @@ package blob
 import (
 	"errors"
 
+	"github.com/goreleaser/goreleaser/v2/internal/channel"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
 	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
@@ -49,6 +50,9 @@ func (Pipe) Publish(ctx *context.Context) error {
 			if b {
 				return pipe.Skip("configuration is disabled")
 			}
+			if !channel.Includes(ctx, conf.Channels) {
+				return pipe.Skipf("release channel %q is not in %v", channel.Of(ctx), conf.Channels)
+			}
 			return doUpload(ctx, conf)
 		})
 	}
@@ -268,3 +268,17 @@ func TestSkip(t *testing.T) {
 		require.False(t, Pipe{}.Skip(ctx))
 	})
 }
+
+func TestPublishChannels(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Blobs: []config.Blob{{
+			Bucket:   "foo",
+			Provider: "s3",
+			Channels: []string{"stable", "rc"},
+		}},
+	}, testctx.WithSemver(1, 0, 0, "beta.1"))
+	require.NoError(t, Pipe{}.Default(ctx))
+	err := Pipe{}.Publish(ctx)
+	testlib.AssertSkipped(t, err)
+	require.ErrorContains(t, err, `release channel "beta" is not in [stable rc]`)
+}
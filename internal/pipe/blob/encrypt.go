@@ -0,0 +1,123 @@
+package blob
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"gocloud.dev/secrets"
+)
+
+// With kms_envelope, files are encrypted using envelope encryption, so they can
+// be streamed instead of being loaded in memory to be encrypted by the KMS.
+//
+// Each file is encrypted with a random AES-256-GCM data key, which is in turn
+// encrypted by the KMS.
+// The resulting file is laid out as follows:
+//
+//	magic | uint32 big endian key length | encrypted data key | segments...
+//
+// The data is split into segments of segmentSize bytes, each sealed
+// individually and followed by its GCM tag.
+// The nonce of each segment is its index as an 11-byte big endian number,
+// followed by 1 if it is the last segment, or 0 otherwise.
+const (
+	envelopeMagic = "goreleaser.kms.v1"
+	segmentSize   = 64 * 1024
+	dataKeySize   = 32
+)
+
+func encrypt(ctx *context.Context, keeper *secrets.Keeper, r io.Reader) (io.Reader, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	encryptedKey, err := keeper.Encrypt(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.WriteString(envelopeMagic)
+	_ = binary.Write(&header, binary.BigEndian, uint32(len(encryptedKey)))
+	header.Write(encryptedKey)
+
+	return io.MultiReader(&header, &sealReader{
+		aead:  aead,
+		src:   bufio.NewReaderSize(r, segmentSize),
+		plain: make([]byte, segmentSize),
+	}), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func segmentNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// sealReader encrypts the data read from src one segment at a time.
+type sealReader struct {
+	aead    cipher.AEAD
+	src     *bufio.Reader
+	plain   []byte
+	sealed  []byte
+	buf     []byte
+	counter uint64
+	done    bool
+}
+
+func (s *sealReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+func (s *sealReader) next() error {
+	n, err := io.ReadFull(s.src, s.plain)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	last := n < segmentSize
+	if !last {
+		// peek to know whether this is the last segment.
+		if _, err := s.src.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	s.sealed = s.aead.Seal(s.sealed[:0], segmentNonce(s.counter, last), s.plain[:n], nil)
+	s.buf = s.sealed
+	s.counter++
+	s.done = last
+	return nil
+}
@@ -0,0 +1,226 @@
+package blob
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"html/template"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/goreleaser/goreleaser/v2/internal/calver"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"gocloud.dev/blob"
+)
+
+const (
+	indexJSON = "index.json"
+	indexHTML = "index.html"
+)
+
+// index lists all the versions in the parent of the blob directory.
+type index struct {
+	Project  string         `json:"project"`
+	Versions []indexVersion `json:"versions"`
+}
+
+type indexVersion struct {
+	Name  string      `json:"name"`
+	Files []indexFile `json:"files"`
+}
+
+type indexFile struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Project }}</title>
+</head>
+<body>
+<h1>{{ .Project }}</h1>
+{{- range .Versions }}
+<h2 id="{{ .Name }}">{{ .Name }}</h2>
+<ul>
+{{- range .Files }}
+<li><a href="{{ .Path }}">{{ .Name }}</a> ({{ .Size }} bytes)</li>
+{{- end }}
+</ul>
+{{- end }}
+</body>
+</html>
+`))
+
+// writeIndex writes an index.json and an index.html to the parent of the
+// given directory, listing each of its subdirectories (usually, the versions)
+// and their files.
+func writeIndex(ctx *context.Context, up uploader, dir string) error {
+	prefix, versions, err := listVersions(ctx, up, dir)
+	if err != nil {
+		return err
+	}
+
+	idx := index{
+		Project:  ctx.Config.ProjectName,
+		Versions: []indexVersion{},
+	}
+	for _, version := range versions {
+		v := indexVersion{Name: version.name}
+		for _, obj := range version.objects {
+			name := strings.TrimPrefix(obj.Key, prefix+version.name+"/")
+			v.Files = append(v.Files, indexFile{
+				Name:     name,
+				Path:     version.name + "/" + name,
+				Size:     obj.Size,
+				Modified: obj.ModTime.UTC(),
+			})
+		}
+		idx.Versions = append(idx.Versions, v)
+	}
+
+	bts, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := up.WriteFile(ctx, prefix+indexJSON, "application/json", bts); err != nil {
+		return err
+	}
+
+	var html bytes.Buffer
+	if err := indexTemplate.Execute(&html, idx); err != nil {
+		return err
+	}
+	return up.WriteFile(ctx, prefix+indexHTML, "text/html; charset=utf-8", html.Bytes())
+}
+
+// versionObjects are the objects in a version directory.
+type versionObjects struct {
+	name    string
+	version *parsedVersion // nil if name is not a version
+	objects []*blob.ListObject
+}
+
+// parsedVersion is either a semantic or a calendar version, depending on the
+// versioning mode.
+type parsedVersion struct {
+	semver *semver.Version
+	calver calver.Version
+}
+
+// prerelease returns true if the version is a prerelease, calendar versions
+// being prereleases when they have a modifier.
+func (v *parsedVersion) prerelease() bool {
+	if v.semver != nil {
+		return v.semver.Prerelease() != ""
+	}
+	return v.calver.Modifier != ""
+}
+
+func (v *parsedVersion) compare(o *parsedVersion) int {
+	if v.semver != nil && o.semver != nil {
+		return v.semver.Compare(o.semver)
+	}
+	return calver.Compare(v.calver, o.calver)
+}
+
+// modTime returns when the version was last modified.
+func (v versionObjects) modTime() time.Time {
+	var result time.Time
+	for _, obj := range v.objects {
+		if obj.ModTime.After(result) {
+			result = obj.ModTime
+		}
+	}
+	return result
+}
+
+// listVersions lists the files in the parent of the given directory, grouped
+// by their subdirectory (usually, the versions), newest first.
+//
+// It also returns the prefix of the parent directory.
+func listVersions(ctx *context.Context, up uploader, dir string) (string, []versionObjects, error) {
+	var scheme *calver.Scheme
+	if ctx.Config.Versioning.Mode == "calver" {
+		s, err := calver.ParseScheme(ctx.Config.Versioning.Scheme)
+		if err != nil {
+			return "", nil, err
+		}
+		scheme = &s
+	}
+
+	prefix := parentPrefix(dir)
+	objects, err := up.List(ctx, prefix)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var versions []versionObjects
+	indexes := map[string]int{}
+	for _, obj := range objects {
+		name, _, ok := strings.Cut(strings.TrimPrefix(obj.Key, prefix), "/")
+		if !ok {
+			// files in the parent directory itself, e.g. the index.
+			continue
+		}
+		i, ok := indexes[name]
+		if !ok {
+			i = len(versions)
+			indexes[name] = i
+			versions = append(versions, versionObjects{name: name, version: parseVersion(scheme, name)})
+		}
+		versions[i].objects = append(versions[i].objects, obj)
+	}
+	slices.SortFunc(versions, compareVersions)
+	return prefix, versions, nil
+}
+
+// parentPrefix returns the prefix of the parent of the given directory, or an
+// empty string if it is the bucket root.
+func parentPrefix(dir string) string {
+	parent := path.Dir(dir)
+	if parent == "." || parent == "/" {
+		return ""
+	}
+	return parent + "/"
+}
+
+// parseVersion parses the given directory name as a calendar version of the
+// given scheme, or as a strict semantic version if there is no scheme,
+// optionally prefixed with a "v".
+// It returns nil if the name is not one.
+func parseVersion(scheme *calver.Scheme, name string) *parsedVersion {
+	if scheme != nil {
+		version, err := scheme.Parse(name)
+		if err != nil {
+			return nil
+		}
+		return &parsedVersion{calver: version}
+	}
+	version, err := semver.StrictNewVersion(strings.TrimPrefix(name, "v"))
+	if err != nil {
+		return nil
+	}
+	return &parsedVersion{semver: version}
+}
+
+// compareVersions sorts the newest versions first.
+func compareVersions(a, b versionObjects) int {
+	switch {
+	case a.version != nil && b.version != nil:
+		return b.version.compare(a.version)
+	case a.version != nil:
+		return -1
+	case b.version != nil:
+		return 1
+	default:
+		return cmp.Compare(b.name, a.name)
+	}
+}
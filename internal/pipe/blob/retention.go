@@ -0,0 +1,75 @@
+package blob
+
+import (
+	"errors"
+	"path"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// prune deletes the old versions in the parent of the given directory,
+// according to the retention policy.
+//
+// Only directories named after semantic versions, or calendar versions when
+// using calver, are considered, and the
+// version being released is always kept.
+// The bucket root is never pruned.
+func prune(ctx *context.Context, retention config.BlobRetention, up uploader, dir string) error {
+	if retention.KeepLast <= 0 && retention.PrereleaseMaxAgeDays <= 0 {
+		return nil
+	}
+
+	if parentPrefix(dir) == "" {
+		return errors.New("retention can't be used when the directory is at the root of the bucket")
+	}
+
+	_, versions, err := listVersions(ctx, up, dir)
+	if err != nil {
+		return err
+	}
+
+	current := path.Base(dir)
+	maxAge := time.Duration(retention.PrereleaseMaxAgeDays) * 24 * time.Hour
+	var rank int
+	for _, version := range versions {
+		if version.version == nil {
+			continue
+		}
+		rank++
+		if version.name == current || !shouldPrune(ctx, retention, version, rank, maxAge) {
+			continue
+		}
+
+		if retention.DryRun {
+			log.WithField("version", version.name).
+				WithField("files", len(version.objects)).
+				Info("would delete (dry-run)")
+			continue
+		}
+		log.WithField("version", version.name).
+			WithField("files", len(version.objects)).
+			Info("deleting")
+		for _, obj := range version.objects {
+			if err := up.Delete(ctx, obj.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// shouldPrune checks whether the given version is either older than the last
+// versions to keep, or a prerelease older than the maximum age.
+func shouldPrune(ctx *context.Context, retention config.BlobRetention, version versionObjects, rank int, maxAge time.Duration) bool {
+	stable := !version.version.prerelease()
+	if stable && retention.KeepStable {
+		return false
+	}
+	if retention.KeepLast > 0 && rank > retention.KeepLast {
+		return true
+	}
+	return !stable && maxAge > 0 && ctx.Date.Sub(version.modTime()) > maxAge
+}
@@ -0,0 +1,141 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+)
+
+func TestPrune(t *testing.T) {
+	versions := []string{
+		"v1.0.0",
+		"v1.1.0-rc1",
+		"v1.1.0",
+		"v1.2.0-beta",
+		"v1.2.0",
+		"v1.3.0-rc1",
+		"v2",
+		"nightly",
+	}
+	for name, tc := range map[string]struct {
+		retention config.BlobRetention
+		dir       string
+		date      time.Time
+		expect    []string
+	}{
+		"no retention": {
+			expect: versions,
+		},
+		"keep last": {
+			retention: config.BlobRetention{KeepLast: 2},
+			expect:    []string{"v1.2.0", "v1.3.0-rc1", "v2", "nightly"},
+		},
+		"keep last and stable": {
+			retention: config.BlobRetention{KeepLast: 2, KeepStable: true},
+			expect:    []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-rc1", "v2", "nightly"},
+		},
+		"keep current": {
+			retention: config.BlobRetention{KeepLast: 1},
+			dir:       "foo/v1.0.0",
+			expect:    []string{"v1.0.0", "v1.3.0-rc1", "v2", "nightly"},
+		},
+		"recent prereleases": {
+			retention: config.BlobRetention{PrereleaseMaxAgeDays: 30},
+			date:      time.Now().Add(24 * time.Hour),
+			expect:    versions,
+		},
+		"old prereleases": {
+			retention: config.BlobRetention{PrereleaseMaxAgeDays: 30},
+			date:      time.Now().Add(31 * 24 * time.Hour),
+			expect:    []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-rc1", "v2", "nightly"},
+		},
+		"keep last and old prereleases": {
+			retention: config.BlobRetention{KeepLast: 4, PrereleaseMaxAgeDays: 30},
+			date:      time.Now().Add(31 * 24 * time.Hour),
+			expect:    []string{"v1.1.0", "v1.2.0", "v1.3.0-rc1", "v2", "nightly"},
+		},
+		"dry run": {
+			retention: config.BlobRetention{KeepLast: 1, DryRun: true},
+			expect:    versions,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			up := &productionUploader{bucket: memblob.OpenBucket(nil)}
+			t.Cleanup(func() { _ = up.Close() })
+			ctx := testctx.Wrap(t.Context(), testctx.WithDate(tc.date))
+			for _, version := range versions {
+				for _, name := range []string{"foo.tar.gz", "checksums.txt"} {
+					require.NoError(t, up.bucket.WriteAll(ctx, "foo/"+version+"/"+name, []byte(version), nil))
+				}
+			}
+			require.NoError(t, up.bucket.WriteAll(ctx, "foo/index.json", []byte("{}"), nil))
+			require.NoError(t, up.bucket.WriteAll(ctx, "bar/v0.1.0/bar.tar.gz", []byte("bar"), nil))
+
+			dir := tc.dir
+			if dir == "" {
+				dir = "foo/v1.3.0-rc1"
+			}
+			require.NoError(t, prune(ctx, tc.retention, up, dir))
+
+			require.ElementsMatch(t, tc.expect, remainingVersions(t, ctx, up, dir))
+			require.Equal(t, []string{"v0.1.0"}, remainingVersions(t, ctx, up, "bar/v0.1.0"))
+			exists, err := up.bucket.Exists(ctx, "foo/index.json")
+			require.NoError(t, err)
+			require.True(t, exists)
+		})
+	}
+}
+
+func TestPruneCalVer(t *testing.T) {
+	up := &productionUploader{bucket: memblob.OpenBucket(nil)}
+	t.Cleanup(func() { _ = up.Close() })
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{
+			Mode:   "calver",
+			Scheme: "YYYY.0M.0D",
+		},
+	})
+	for _, version := range []string{
+		"2025.12.29",
+		"2026.01.05",
+		"2026.01.12-rc1",
+		"2026.01.12",
+		"v1.0.0",
+	} {
+		require.NoError(t, up.bucket.WriteAll(ctx, "foo/"+version+"/foo.tar.gz", []byte(version), nil))
+	}
+
+	require.NoError(t, prune(ctx, config.BlobRetention{KeepLast: 2}, up, "foo/2026.01.12"))
+	require.Equal(t, []string{"2026.01.12", "2026.01.12-rc1", "v1.0.0"}, remainingVersions(t, ctx, up, "foo/2026.01.12"))
+}
+
+func TestPruneRoot(t *testing.T) {
+	up := &productionUploader{bucket: memblob.OpenBucket(nil)}
+	t.Cleanup(func() { _ = up.Close() })
+	ctx := testctx.Wrap(t.Context())
+	require.NoError(t, up.bucket.WriteAll(ctx, "v1.0.0/foo.tar.gz", []byte("foo"), nil))
+	require.EqualError(
+		t,
+		prune(ctx, config.BlobRetention{KeepLast: 1}, up, "v1.1.0"),
+		"retention can't be used when the directory is at the root of the bucket",
+	)
+	exists, err := up.bucket.Exists(ctx, "v1.0.0/foo.tar.gz")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func remainingVersions(tb testing.TB, ctx *context.Context, up uploader, dir string) []string {
+	tb.Helper()
+	_, versions, err := listVersions(ctx, up, dir)
+	require.NoError(tb, err)
+	var result []string
+	for _, v := range versions {
+		result = append(result, v.name)
+	}
+	return result
+}
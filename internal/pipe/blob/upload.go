@@ -1,6 +1,11 @@
 package blob
 
 import (
+	"bytes"
+	stdctx "context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -20,6 +25,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/pkg/config"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
 	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
 	"gocloud.dev/secrets"
 
 	// Import the blob packages we want to be able to open.
@@ -105,9 +111,17 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 		return err
 	}
 
+	artifacts := artifactList(ctx, conf)
+	files, err := extrafiles.Find(ctx, conf.ExtraFiles)
+	if err != nil {
+		return err
+	}
+
 	up := &productionUploader{
 		cacheControl:       conf.CacheControl,
 		contentDisposition: conf.ContentDisposition,
+		partSize:           conf.PartSize,
+		concurrency:        partConcurrency(ctx.Parallelism, len(artifacts)+len(files)),
 	}
 	if provider == "s3" && conf.ACL != "" {
 		up.beforeWrite = func(asFunc func(any) bool) error {
@@ -138,7 +152,7 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 	defer up.Close()
 
 	g := semerrgroup.New(ctx.Parallelism)
-	for _, artifact := range artifactList(ctx, conf) {
+	for _, artifact := range artifacts {
 		g.Go(func() error {
 			// TODO: replace this with ?prefix=folder on the bucket url
 			dataFile := artifact.Path
@@ -148,10 +162,6 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 		})
 	}
 
-	files, err := extrafiles.Find(ctx, conf.ExtraFiles)
-	if err != nil {
-		return err
-	}
 	for name, fullpath := range files {
 		g.Go(func() error {
 			uploadFile := path.Join(dir, name)
@@ -159,7 +169,28 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 		})
 	}
 
-	return g.Wait()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if err := prune(ctx, conf.Retention, up, dir); err != nil {
+		return handleError(err, bucketURL)
+	}
+
+	if !conf.Index {
+		return nil
+	}
+	if err := writeIndex(ctx, up, dir); err != nil {
+		return handleError(err, bucketURL)
+	}
+	return nil
+}
+
+// partConcurrency returns how many parts of each file may be uploaded at the
+// same time, as up to parallelism files are uploaded at the same time too,
+// so the total amount of parts in flight stays within parallelism.
+func partConcurrency(parallelism, files int) int {
+	return max(1, parallelism/max(1, min(files, parallelism)))
 }
 
 func artifactList(ctx *context.Context, conf config.Blob) []*artifact.Artifact {
@@ -177,17 +208,70 @@ func artifactList(ctx *context.Context, conf config.Blob) []*artifact.Artifact {
 }
 
 func uploadData(ctx *context.Context, conf config.Blob, up uploader, dataFile, uploadFile, bucketURL string) error {
+	sha256sum, md5sum, err := checksums(dataFile)
+	if err != nil {
+		return err
+	}
+
+	attrs, err := up.Attributes(ctx, uploadFile)
+	if err != nil {
+		return handleError(err, bucketURL)
+	}
+	if attrs != nil {
+		if isIdentical(conf, attrs, sha256sum, md5sum) {
+			log.WithField("path", uploadFile).Info("already uploaded, skipping")
+			return nil
+		}
+		if conf.Overwrite != nil && !*conf.Overwrite {
+			return fmt.Errorf("%s already exists in %s with different contents, set overwrite: true to replace it", uploadFile, bucketURL)
+		}
+	}
+
 	data, err := getData(ctx, conf, dataFile)
 	if err != nil {
 		return err
 	}
+	defer data.Close()
 
-	if err := up.Upload(ctx, uploadFile, data); err != nil {
+	if err := up.Upload(ctx, uploadFile, data, map[string]string{
+		sha256MetadataKey: sha256sum,
+	}); err != nil {
 		return handleError(err, bucketURL)
 	}
 	return nil
 }
 
+// sha256MetadataKey is the metadata key in which the sha256 of the uploaded
+// file is stored.
+// It is the checksum of the local file, so it can be compared even if the
+// object was encrypted.
+const sha256MetadataKey = "sha256"
+
+// isIdentical checks whether the existing object has the same contents as the
+// local file.
+func isIdentical(conf config.Blob, attrs *blob.Attributes, sha256sum string, md5sum []byte) bool {
+	if sum, ok := attrs.Metadata[sha256MetadataKey]; ok {
+		return sum == sha256sum
+	}
+	// encrypted objects always have different contents.
+	return conf.KMSKey == "" && len(attrs.MD5) > 0 && bytes.Equal(attrs.MD5, md5sum)
+}
+
+// checksums returns the sha256 and md5 of the given file.
+func checksums(path string) (string, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer f.Close()
+	sha := sha256.New()
+	sum := md5.New()
+	if _, err := io.Copy(io.MultiWriter(sha, sum), f); err != nil {
+		return "", nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return hex.EncodeToString(sha.Sum(nil)), sum.Sum(nil), nil
+}
+
 // errorContains check if error contains specific string.
 func errorContains(err error, subs ...string) bool {
 	for _, sub := range subs {
@@ -219,31 +303,58 @@ func handleError(err error, url string) error {
 	}
 }
 
-func getData(ctx *context.Context, conf config.Blob, path string) ([]byte, error) {
-	data, err := os.ReadFile(path)
+// getData opens the given file for streaming, encrypting it if a KMS key is
+// set.
+//
+// By default, the whole file is encrypted with the KMS key.
+// With kms_envelope, it is encrypted on the fly with envelope encryption
+// instead.
+func getData(ctx *context.Context, conf config.Blob, path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return data, fmt.Errorf("failed to open file %s: %w", path, err)
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
 	}
 	if conf.KMSKey == "" {
-		return data, nil
+		return f, nil
 	}
 	keeper, err := secrets.OpenKeeper(ctx, conf.KMSKey)
 	if err != nil {
-		return data, fmt.Errorf("failed to open kms %s: %w", conf.KMSKey, err)
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to open kms %s: %w", conf.KMSKey, err)
 	}
 	defer keeper.Close()
-	data, err = keeper.Encrypt(ctx, data)
+	if !conf.KMSEnvelope {
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		data, err = keeper.Encrypt(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt with kms: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	r, err := encrypt(ctx, keeper, f)
 	if err != nil {
-		return data, fmt.Errorf("failed to encrypt with kms: %w", err)
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to encrypt with kms: %w", err)
 	}
-	return data, err
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
 }
 
 // uploader implements upload.
 type uploader interface {
 	io.Closer
 	Open(ctx *context.Context, url string) error
-	Upload(ctx *context.Context, path string, data []byte) error
+	Upload(ctx *context.Context, path string, data io.Reader, metadata map[string]string) error
+	Attributes(ctx *context.Context, path string) (*blob.Attributes, error)
+	List(ctx *context.Context, prefix string) ([]*blob.ListObject, error)
+	WriteFile(ctx *context.Context, path, contentType string, data []byte) error
+	Delete(ctx *context.Context, path string) error
 }
 
 // productionUploader actually do upload to.
@@ -252,6 +363,8 @@ type productionUploader struct {
 	beforeWrite        func(asFunc func(any) bool) error
 	cacheControl       []string
 	contentDisposition string
+	partSize           int
+	concurrency        int
 }
 
 func (u *productionUploader) Close() error {
@@ -272,7 +385,7 @@ func (u *productionUploader) Open(ctx *context.Context, bucket string) error {
 	return nil
 }
 
-func (u *productionUploader) Upload(ctx *context.Context, filepath string, data []byte) error {
+func (u *productionUploader) Upload(ctx *context.Context, filepath string, data io.Reader, metadata map[string]string) error {
 	log.WithField("path", filepath).Info("uploading")
 
 	disp, err := tmpl.New(ctx).WithExtraFields(tmpl.Fields{
@@ -286,14 +399,70 @@ func (u *productionUploader) Upload(ctx *context.Context, filepath string, data
 		ContentDisposition: disp,
 		BeforeWrite:        u.beforeWrite,
 		CacheControl:       strings.Join(u.cacheControl, ", "),
+		BufferSize:         u.partSize,
+		MaxConcurrency:     u.concurrency,
+		Metadata:           metadata,
 	}
-	w, err := u.bucket.NewWriter(ctx, filepath, opts)
+
+	// the writer splits the data in parts of BufferSize, uploading up to
+	// MaxConcurrency of them at the same time.
+	// canceling the context aborts the upload if anything goes wrong.
+	wctx, cancel := stdctx.WithCancel(ctx)
+	defer cancel()
+	w, err := u.bucket.NewWriter(wctx, filepath, opts)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = w.Close() }()
-	if _, err = w.Write(data); err != nil {
+	if _, err := w.ReadFrom(data); err != nil {
+		cancel()
+		_ = w.Close()
 		return err
 	}
 	return w.Close()
 }
+
+// Attributes returns the attributes of the given object, or nil if it does
+// not exist or can't be read.
+func (u *productionUploader) Attributes(ctx *context.Context, filepath string) (*blob.Attributes, error) {
+	attrs, err := u.bucket.Attributes(ctx, filepath)
+	switch gcerrors.Code(err) {
+	case gcerrors.NotFound:
+		return nil, nil
+	case gcerrors.PermissionDenied:
+		// some credentials are only allowed to write.
+		log.WithField("path", filepath).WithError(err).Warn("could not check if file was already uploaded")
+		return nil, nil
+	}
+	return attrs, err
+}
+
+func (u *productionUploader) List(ctx *context.Context, prefix string) ([]*blob.ListObject, error) {
+	var result []*blob.ListObject
+	iter := u.bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !obj.IsDir {
+			result = append(result, obj)
+		}
+	}
+}
+
+// WriteFile writes the given data, always showing it inline.
+func (u *productionUploader) WriteFile(ctx *context.Context, filepath, contentType string, data []byte) error {
+	log.WithField("path", filepath).Info("writing")
+	return u.bucket.WriteAll(ctx, filepath, data, &blob.WriterOptions{
+		ContentType: contentType,
+		BeforeWrite: u.beforeWrite,
+	})
+}
+
+func (u *productionUploader) Delete(ctx *context.Context, filepath string) error {
+	log.WithField("path", filepath).Debug("deleting")
+	return u.bucket.Delete(ctx, filepath)
+}
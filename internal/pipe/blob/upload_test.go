@@ -0,0 +1,357 @@
+package blob
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/secrets"
+	"gocloud.dev/secrets/localsecrets"
+
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestFileUpload(t *testing.T) {
+	bucket := t.TempDir()
+	data := randomFile(t, 3*segmentSize+42)
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "testupload",
+		Blobs: []config.Blob{{
+			Provider:   "file",
+			Bucket:     bucket,
+			PartSize:   segmentSize,
+			ExtraFiles: []config.ExtraFile{{Glob: "./testdata/file.golden"}},
+		}},
+	}, testctx.WithCurrentTag("v1.0.0"))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: data,
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	requireSameFile(t, data, filepath.Join(bucket, "testupload", "v1.0.0", "bin.tar.gz"))
+	requireSameFile(t, "./testdata/file.golden", filepath.Join(bucket, "testupload", "v1.0.0", "file.golden"))
+}
+
+func TestFileUploadKMS(t *testing.T) {
+	key, err := localsecrets.NewRandomKey()
+	require.NoError(t, err)
+	keeper := localsecrets.NewKeeper(key)
+	t.Cleanup(func() { _ = keeper.Close() })
+
+	bucket := t.TempDir()
+	data := randomFile(t, 42)
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "testupload",
+		Blobs: []config.Blob{{
+			Provider: "file",
+			Bucket:   bucket,
+			KMSKey:   "base64key://" + base64Key(key),
+		}},
+	}, testctx.WithCurrentTag("v1.0.0"))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: data,
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	encrypted, err := os.ReadFile(filepath.Join(bucket, "testupload", "v1.0.0", "bin.tar.gz"))
+	require.NoError(t, err)
+	expected, err := os.ReadFile(data)
+	require.NoError(t, err)
+	got, err := keeper.Decrypt(t.Context(), encrypted)
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+}
+
+func TestFileUploadKMSEnvelope(t *testing.T) {
+	key, err := localsecrets.NewRandomKey()
+	require.NoError(t, err)
+	keeper := localsecrets.NewKeeper(key)
+	t.Cleanup(func() { _ = keeper.Close() })
+
+	for name, size := range map[string]int{
+		"empty":    0,
+		"small":    42,
+		"segment":  segmentSize,
+		"segments": 2 * segmentSize,
+		"big":      3*segmentSize + 42,
+	} {
+		t.Run(name, func(t *testing.T) {
+			bucket := t.TempDir()
+			data := randomFile(t, size)
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+				ProjectName: "testupload",
+				Blobs: []config.Blob{{
+					Provider:    "file",
+					Bucket:      bucket,
+					KMSKey:      "base64key://" + base64Key(key),
+					KMSEnvelope: true,
+				}},
+			}, testctx.WithCurrentTag("v1.0.0"))
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Type: artifact.UploadableArchive,
+				Name: "bin.tar.gz",
+				Path: data,
+			})
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.NoError(t, Pipe{}.Publish(ctx))
+
+			encrypted, err := os.ReadFile(filepath.Join(bucket, "testupload", "v1.0.0", "bin.tar.gz"))
+			require.NoError(t, err)
+			expected, err := os.ReadFile(data)
+			require.NoError(t, err)
+			got, err := decrypt(t, keeper, encrypted)
+			require.NoError(t, err)
+			require.Equal(t, expected, got)
+
+			t.Run("truncated", func(t *testing.T) {
+				_, err := decrypt(t, keeper, encrypted[:len(encrypted)-1])
+				require.Error(t, err)
+			})
+			t.Run("tampered", func(t *testing.T) {
+				tampered := bytes.Clone(encrypted)
+				tampered[len(tampered)-1] ^= 1
+				_, err := decrypt(t, keeper, tampered)
+				require.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestFileUploadErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		blob config.Blob
+		path string
+		err  string
+	}{
+		"missing file": {
+			path: "./testdata/nope.txt",
+			err:  "failed to open file ./testdata/nope.txt",
+		},
+		"invalid kms": {
+			blob: config.Blob{KMSKey: "nope://foo"},
+			path: "./testdata/file.golden",
+			err:  "failed to open kms nope://foo",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.blob.Provider = "file"
+			tc.blob.Bucket = t.TempDir()
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+				ProjectName: "testupload",
+				Blobs:       []config.Blob{tc.blob},
+			}, testctx.WithCurrentTag("v1.0.0"))
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Type: artifact.UploadableArchive,
+				Name: "bin.tar.gz",
+				Path: tc.path,
+			})
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.ErrorContains(t, Pipe{}.Publish(ctx), tc.err)
+		})
+	}
+}
+
+// decrypt reverses what encrypt does.
+func decrypt(tb testing.TB, keeper *secrets.Keeper, data []byte) ([]byte, error) {
+	tb.Helper()
+	r := bytes.NewReader(data)
+	magic := make([]byte, len(envelopeMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	require.Equal(tb, envelopeMagic, string(magic))
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	encryptedKey := make([]byte, size)
+	if _, err := io.ReadFull(r, encryptedKey); err != nil {
+		return nil, err
+	}
+	key, err := keeper.Decrypt(tb.Context(), encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []byte{}
+	segment := make([]byte, segmentSize+aead.Overhead())
+	for counter := uint64(0); ; counter++ {
+		n, err := io.ReadFull(r, segment)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		last := r.Len() == 0
+		plain, err := aead.Open(nil, segmentNonce(counter, last), segment[:n], nil)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, plain...)
+		if last {
+			return out, nil
+		}
+	}
+}
+
+func base64Key(key [32]byte) string {
+	return base64.URLEncoding.EncodeToString(key[:])
+}
+
+func randomFile(tb testing.TB, size int) string {
+	tb.Helper()
+	data := make([]byte, size)
+	_, _ = rand.Read(data)
+	path := filepath.Join(tb.TempDir(), "data")
+	require.NoError(tb, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+func requireSameFile(tb testing.TB, expected, got string) {
+	tb.Helper()
+	a, err := os.ReadFile(expected)
+	require.NoError(tb, err)
+	b, err := os.ReadFile(got)
+	require.NoError(tb, err)
+	require.Equal(tb, a, b)
+}
+
+func TestFileUploadExisting(t *testing.T) {
+	bucket := t.TempDir()
+	data := randomFile(t, 42)
+	publish := func(tb testing.TB, blob config.Blob) error {
+		tb.Helper()
+		blob.Provider = "file"
+		blob.Bucket = bucket
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			ProjectName: "testupload",
+			Blobs:       []config.Blob{blob},
+		}, testctx.WithCurrentTag("v1.0.0"))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.UploadableArchive,
+			Name: "bin.tar.gz",
+			Path: data,
+		})
+		require.NoError(tb, Pipe{}.Default(ctx))
+		return Pipe{}.Publish(ctx)
+	}
+	uploaded := filepath.Join(bucket, "testupload", "v1.0.0", "bin.tar.gz")
+	modTime := func(tb testing.TB) time.Time {
+		tb.Helper()
+		info, err := os.Stat(uploaded)
+		require.NoError(tb, err)
+		return info.ModTime()
+	}
+
+	require.NoError(t, publish(t, config.Blob{}))
+	before := modTime(t)
+
+	t.Run("identical", func(t *testing.T) {
+		require.NoError(t, publish(t, config.Blob{}))
+		require.Equal(t, before, modTime(t))
+	})
+
+	t.Run("identical encrypted", func(t *testing.T) {
+		require.NoError(t, publish(t, config.Blob{KMSKey: "base64key://"}))
+		require.Equal(t, before, modTime(t))
+	})
+
+	require.NoError(t, os.WriteFile(data, []byte("changed"), 0o644))
+
+	t.Run("no overwrite", func(t *testing.T) {
+		require.ErrorContains(t, publish(t, config.Blob{Overwrite: new(false)}), "testupload/v1.0.0/bin.tar.gz already exists in file://"+bucket+" with different contents, set overwrite: true to replace it")
+		bts, err := os.ReadFile(uploaded)
+		require.NoError(t, err)
+		require.NotEqual(t, "changed", string(bts))
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		require.NoError(t, publish(t, config.Blob{}))
+		requireSameFile(t, data, uploaded)
+	})
+}
+
+func TestFileUploadIndex(t *testing.T) {
+	bucket := t.TempDir()
+	for _, tag := range []string{"v1.2.0", "v1.10.0", "nightly", "v1.9.0-rc1"} {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			ProjectName: "testupload",
+			Blobs: []config.Blob{{
+				Provider: "file",
+				Bucket:   bucket,
+				Index:    true,
+			}},
+		}, testctx.WithCurrentTag(tag))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.UploadableArchive,
+			Name: "bin.tar.gz",
+			Path: randomFile(t, 42),
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.Checksum,
+			Name: "checksums.txt",
+			Path: randomFile(t, 10),
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Publish(ctx))
+	}
+
+	bts, err := os.ReadFile(filepath.Join(bucket, "testupload", "index.json"))
+	require.NoError(t, err)
+	var idx index
+	require.NoError(t, json.Unmarshal(bts, &idx))
+	require.Equal(t, "testupload", idx.Project)
+	var versions []string
+	for _, v := range idx.Versions {
+		versions = append(versions, v.Name)
+		require.Len(t, v.Files, 2)
+		require.Equal(t, "bin.tar.gz", v.Files[0].Name)
+		require.Equal(t, v.Name+"/bin.tar.gz", v.Files[0].Path)
+		require.Equal(t, int64(42), v.Files[0].Size)
+		require.Equal(t, "checksums.txt", v.Files[1].Name)
+	}
+	require.Equal(t, []string{"v1.10.0", "v1.9.0-rc1", "v1.2.0", "nightly"}, versions)
+
+	html, err := os.ReadFile(filepath.Join(bucket, "testupload", "index.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(html), `<a href="v1.10.0/bin.tar.gz">bin.tar.gz</a> (42 bytes)`)
+	require.Contains(t, string(html), `<h2 id="nightly">nightly</h2>`)
+}
+
+func TestPartConcurrency(t *testing.T) {
+	for _, tc := range []struct {
+		parallelism, files, expected int
+	}{
+		{parallelism: 4, files: 1, expected: 4},
+		{parallelism: 4, files: 2, expected: 2},
+		{parallelism: 4, files: 3, expected: 1},
+		{parallelism: 4, files: 10, expected: 1},
+		{parallelism: 8, files: 0, expected: 8},
+		{parallelism: 1, files: 5, expected: 1},
+	} {
+		t.Run(fmt.Sprintf("%d/%d", tc.parallelism, tc.files), func(t *testing.T) {
+			require.Equal(t, tc.expected, partConcurrency(tc.parallelism, tc.files))
+		})
+	}
+}
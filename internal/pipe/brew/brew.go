@@ -16,6 +16,7 @@ import (
 
 	"github.com/caarlos0/log"
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/channel"
 	"github.com/goreleaser/goreleaser/v2/internal/client"
 	"github.com/goreleaser/goreleaser/v2/internal/commitauthor"
 	"github.com/goreleaser/goreleaser/v2/internal/deprecate"
@@ -142,6 +143,10 @@ func doPublish(ctx *context.Context, formula *artifact.Artifact, cl client.Clien
 		return pipe.Skip("prerelease detected with 'auto' upload, skipping homebrew publish")
 	}
 
+	if !channel.Includes(ctx, brew.Channels) {
+		return pipe.Skipf("release channel %q is not in %v", channel.Of(ctx), brew.Channels)
+	}
+
 	repo := client.RepoFromRef(brew.Repository)
 
 	gpath := buildFormulaPath(brew.Directory, formula.Name)
@@ -1189,6 +1189,12 @@ func TestRunPipeNoUpload(t *testing.T) {
 		ctx.Semver.Prerelease = "beta1"
 		assertNoPublish(t)
 	})
+	t.Run("release channel not included", func(t *testing.T) {
+		ctx.Config.Brews[0].SkipUpload = ""
+		ctx.Config.Brews[0].Channels = []string{"stable"}
+		ctx.Semver.Prerelease = "beta.1"
+		assertNoPublish(t)
+	})
 }
 
 func TestRunEmptyTokenType(t *testing.T) {
@@ -3,13 +3,16 @@
 package build
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/caarlos0/log"
 	"github.com/goreleaser/go-shellwords"
+	"github.com/goreleaser/goreleaser/v2/internal/builders/base"
 	"github.com/goreleaser/goreleaser/v2/internal/deprecate"
 	"github.com/goreleaser/goreleaser/v2/internal/gerrors"
 	"github.com/goreleaser/goreleaser/v2/internal/ids"
@@ -40,20 +43,43 @@ func (Pipe) String() string {
 }
 
 // Run the pipe.
-func (Pipe) Run(ctx *context.Context) error {
+func (Pipe) Run(ctx *context.Context) (err error) {
+	var cleanups []func() error
+	defer func() {
+		for _, cleanup := range slices.Backward(cleanups) {
+			err = errors.Join(err, cleanup())
+		}
+	}()
+
 	g := semerrgroup.New(ctx.Parallelism)
+	cleanups, err = queueBuilds(ctx, g)
+	if err != nil {
+		// builds already queued might still be running, and the version files
+		// overlays can only be removed once they are done.
+		return errors.Join(err, g.Wait())
+	}
+	return g.Wait()
+}
+
+func queueBuilds(ctx *context.Context, g semerrgroup.Group) ([]func() error, error) {
+	var cleanups []func() error
 	for _, build := range ctx.Config.Builds {
 		skip, err := tmpl.New(ctx).Bool(build.Skip)
 		if err != nil {
-			return err
+			return cleanups, err
 		}
 		if skip {
 			log.WithField("id", build.ID).Info("skip is set")
 			continue
 		}
 		log.WithField("build", build).Debug("building")
+		build, cleanup, err := base.RewriteVersionFiles(ctx, build)
+		if err != nil {
+			return cleanups, err
+		}
+		cleanups = append(cleanups, cleanup)
 		if err := prepare(ctx, build); err != nil {
-			return err
+			return cleanups, err
 		}
 		if allowParallelism(build) {
 			runPipeOnBuild(ctx, g, build)
@@ -65,7 +91,7 @@ func (Pipe) Run(ctx *context.Context) error {
 			return gg.Wait()
 		})
 	}
-	return g.Wait()
+	return cleanups, nil
 }
 
 func allowParallelism(build config.Build) bool {
@@ -126,6 +152,15 @@ func buildWithDefaults(ctx *context.Context, build config.Build) (config.Build,
 	for k, v := range build.Env {
 		build.Env[k] = os.ExpandEnv(v)
 	}
+	for i := range build.VersionFiles {
+		vf := &build.VersionFiles[i]
+		if vf.Path == "" {
+			return build, errors.New("version_files: path is required")
+		}
+		if vf.Version == "" {
+			vf.Version = "{{ .Version }}"
+		}
+	}
 	return builders.For(build.Builder).WithDefaults(build)
 }
 
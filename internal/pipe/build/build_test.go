@@ -46,6 +46,7 @@ func (f fakeTarget) Fields() map[string]string {
 type fakeBuilder struct {
 	fail        bool
 	failDefault bool
+	copyFile    string
 }
 
 // Parse implements build.Builder.
@@ -60,11 +61,19 @@ func (f *fakeBuilder) WithDefaults(build config.Build) (config.Build, error) {
 	return build, nil
 }
 
-func (f *fakeBuilder) Build(ctx *context.Context, _ config.Build, options api.Options) error {
+func (f *fakeBuilder) Build(ctx *context.Context, build config.Build, options api.Options) error {
 	if f.fail {
 		return errFailedBuild
 	}
-	if err := os.WriteFile(options.Path, []byte("foo"), 0o755); err != nil {
+	content := []byte("foo")
+	if f.copyFile != "" {
+		bts, err := os.ReadFile(filepath.Join(build.Dir, f.copyFile))
+		if err != nil {
+			return err
+		}
+		content = bts
+	}
+	if err := os.WriteFile(options.Path, content, 0o755); err != nil {
 		return err
 	}
 	ctx.Artifacts.Add(&artifact.Artifact{
@@ -81,6 +90,9 @@ func init() {
 	api.Register("fakeFailDefault", &fakeBuilder{
 		failDefault: true,
 	})
+	api.Register("fakeCargo", &fakeBuilder{
+		copyFile: "Cargo.toml",
+	})
 }
 
 func TestPipeDescription(t *testing.T) {
@@ -176,6 +188,52 @@ func TestRunFullPipe(t *testing.T) {
 	require.FileExists(t, filepath.Join(folder, "build1_linux_amd64", "testing"))
 }
 
+func TestRunPipeVersionFiles(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	cargo := "[package]\nname = \"foo\"\nversion = \"0.1.0\"\n"
+	require.NoError(t, os.WriteFile("Cargo.toml", []byte(cargo), 0o644))
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Dist: folder,
+		Builds: []config.Build{
+			{
+				ID:      "build1",
+				Builder: "fakeCargo",
+				Binary:  "testing",
+				Dir:     ".",
+				Targets: []string{"linux_amd64", "darwin_arm64"},
+				VersionFiles: []config.VersionFile{
+					{Path: "Cargo.toml"},
+				},
+			},
+		},
+	}, testctx.WithCurrentTag("v2.4.5"), testctx.WithVersion("2.4.5"))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "{{ .Version }}", ctx.Config.Builds[0].VersionFiles[0].Version)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	for _, target := range []string{"linux_amd64", "darwin_arm64"} {
+		bts, err := os.ReadFile(filepath.Join(folder, "build1_"+target, "testing"))
+		require.NoError(t, err)
+		require.Contains(t, string(bts), `version = "2.4.5"`)
+	}
+
+	bts, err := os.ReadFile("Cargo.toml")
+	require.NoError(t, err)
+	require.Equal(t, cargo, string(bts))
+}
+
+func TestDefaultVersionFilesNoPath(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Builds: []config.Build{
+			{
+				Builder:      "fake",
+				VersionFiles: []config.VersionFile{{Version: "1.0.0"}},
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "version_files: path is required")
+}
+
 func TestRunFullPipeFail(t *testing.T) {
 	folder := testlib.Mktmp(t)
 	pre := filepath.Join(folder, "pre")
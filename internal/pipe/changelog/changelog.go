@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
@@ -14,6 +13,7 @@ import (
 	"github.com/caarlos0/log"
 	"github.com/goreleaser/goreleaser/v2/internal/changelog"
 	"github.com/goreleaser/goreleaser/v2/internal/client"
+	"github.com/goreleaser/goreleaser/v2/internal/commitauthor"
 	"github.com/goreleaser/goreleaser/v2/internal/git"
 	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
@@ -59,6 +59,20 @@ func (Pipe) Default(ctx *context.Context) error {
 			ctx.Config.Changelog.Format = "{{ .SHA }}: {{ .Message }} ({{ with .AuthorUsername }}@{{ . }}{{ else }}{{ .AuthorName }} <{{ .AuthorEmail }}>{{ end }})"
 		}
 	}
+	if ctx.Config.Changelog.Contributors.Template == "" {
+		ctx.Config.Changelog.Contributors.Template = defaultContributorsTemplate
+	}
+	if len(ctx.Config.Changelog.Formats) == 0 {
+		ctx.Config.Changelog.Formats = []string{formatMarkdown}
+	}
+	kac := &ctx.Config.Changelog.KeepAChangelog
+	if kac.Path == "" {
+		kac.Path = "CHANGELOG.md"
+	}
+	if kac.CommitMessageTemplate == "" {
+		kac.CommitMessageTemplate = "Changelog update for {{ .ProjectName }} version {{ .Tag }}"
+	}
+	kac.CommitAuthor = commitauthor.Default(kac.CommitAuthor)
 	return nil
 }
 
@@ -71,7 +85,7 @@ func (Pipe) Run(ctx *context.Context) error {
 	ctx.ReleaseNotes = notes
 
 	if ctx.ReleaseNotesFile != "" || ctx.ReleaseNotesTmpl != "" {
-		return nil
+		return writeKeepAChangelogSection(ctx, notes, nil)
 	}
 
 	footer, err := loadContent(ctx, ctx.ReleaseFooterFile, ctx.ReleaseFooterTmpl)
@@ -88,12 +102,24 @@ func (Pipe) Run(ctx *context.Context) error {
 		return err
 	}
 
-	changes, err := buildChangelog(ctx)
+	if err := checkFormats(ctx.Config.Changelog.Formats); err != nil {
+		return err
+	}
+
+	changes, groups, err := buildChangelog(ctx)
 	if err != nil {
 		return err
 	}
 	changelogElements := []string{changes}
 
+	contributors, err := formatContributors(ctx)
+	if err != nil {
+		return err
+	}
+	if contributors != "" {
+		changelogElements = append(changelogElements, contributors)
+	}
+
 	if header != "" {
 		changelogElements = append([]string{header}, changelogElements...)
 	}
@@ -106,17 +132,21 @@ func (Pipe) Run(ctx *context.Context) error {
 		ctx.ReleaseNotes += "\n"
 	}
 
-	path := filepath.Join(ctx.Config.Dist, "CHANGELOG.md")
-	log.WithField("path", path).Debug("writing changelog")
-	return os.WriteFile(path, []byte(ctx.ReleaseNotes), 0o644) //nolint:gosec
+	return writeFiles(ctx, changes, groups)
 }
 
 type changelogGroup struct {
 	title   string
-	entries []string
+	entries []changelogEntry
 	order   int
 }
 
+// changelogEntry is a changelog item along with its formatted line.
+type changelogEntry struct {
+	item Item
+	line string
+}
+
 func title(s string, level int) string {
 	if s == "" {
 		return ""
@@ -150,25 +180,49 @@ func abbrevEntry(sha string, abbr int) string {
 }
 
 func formatChangelog(ctx *context.Context, entries []Item) (string, error) {
+	groups, err := groupEntries(ctx, entries)
+	return renderChangelog(ctx, groups), err
+}
+
+func renderChangelog(ctx *context.Context, groups []changelogGroup) string {
 	result := []string{title("Changelog", 2)}
+	grouping := len(ctx.Config.Changelog.Groups) > 0
+	for _, group := range groups {
+		if grouping && len(group.entries) == 0 {
+			continue
+		}
+		if grouping {
+			result = append(result, title(group.title, 3))
+		}
+		for _, entry := range group.entries {
+			result = append(result, entry.line)
+		}
+	}
+	return strings.Join(result, newLineFor(ctx))
+}
+
+// groupEntries formats the given entries, and groups them according to the
+// configuration.
+// If there are no groups configured, a single untitled group is returned.
+func groupEntries(ctx *context.Context, entries []Item) ([]changelogGroup, error) {
 	if len(ctx.Config.Changelog.Groups) == 0 {
 		log.Debug("not grouping entries")
 		lines, err := formatEntries(ctx, entries)
-		return strings.Join(append(result, lines...), newLineFor(ctx)), err
+		return []changelogGroup{{entries: lines}}, err
 	}
 
 	log.Debug("grouping entries")
 	var groups []changelogGroup
 	for _, group := range ctx.Config.Changelog.Groups {
 		item := changelogGroup{
-			title: title(group.Title, 3),
+			title: group.Title,
 			order: group.Order,
 		}
 		if group.Regexp == "" {
 			// If no regexp is provided, we purge all strikethrough entries and add remaining entries to the list
 			lines, err := formatEntries(ctx, entries)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 			item.entries = lines
 			// clear array
@@ -176,7 +230,7 @@ func formatChangelog(ctx *context.Context, entries []Item) (string, error) {
 		} else {
 			re, err := regexp.Compile(group.Regexp)
 			if err != nil {
-				return "", fmt.Errorf("failed to group into %q: %w", group.Title, err)
+				return nil, fmt.Errorf("failed to group into %q: %w", group.Title, err)
 			}
 
 			log.Debugf("group: %#v", group)
@@ -187,9 +241,12 @@ func formatChangelog(ctx *context.Context, entries []Item) (string, error) {
 				if match {
 					line, err := formatEntry(ctx, entry)
 					if err != nil {
-						return "", err
+						return nil, err
 					}
-					item.entries = append(item.entries, line)
+					item.entries = append(item.entries, changelogEntry{
+						item: entry,
+						line: line,
+					})
 				} else {
 					// Keep unmatched entry.
 					entries[i] = entry
@@ -206,13 +263,7 @@ func formatChangelog(ctx *context.Context, entries []Item) (string, error) {
 	}
 
 	slices.SortFunc(groups, groupSort)
-	for _, group := range groups {
-		if len(group.entries) > 0 {
-			result = append(result, group.title)
-			result = append(result, group.entries...)
-		}
-	}
-	return strings.Join(result, newLineFor(ctx)), nil
+	return groups, nil
 }
 
 func groupSort(i, j changelogGroup) int {
@@ -241,19 +292,27 @@ func checkSortDirection(mode string) error {
 	}
 }
 
-func buildChangelog(ctx *context.Context) (string, error) {
-	var cl staticChangeloger
-	var err error
-	switch ctx.Config.Changelog.Use {
-	case "github-native":
-		cl, err = newGithubChangeloger(ctx)
-	default:
-		cl, err = newCustomizedChangelog(ctx)
+// buildChangelog builds the changelog, returning it rendered as well as
+// grouped.
+// Groups are not available when using 'github-native'.
+func buildChangelog(ctx *context.Context) (string, []changelogGroup, error) {
+	if ctx.Config.Changelog.Use == useGitHubNative {
+		cl, err := newGithubChangeloger(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		changes, err := cl.Log(ctx)
+		return changes, nil, err
 	}
+	cl, err := newCustomizedChangelog(ctx)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+	groups, err := cl.Groups(ctx)
+	if err != nil {
+		return "", nil, err
 	}
-	return cl.Log(ctx)
+	return renderChangelog(ctx, groups), groups, nil
 }
 
 func formatEntry(ctx *context.Context, entry Item) (string, error) {
@@ -295,14 +354,17 @@ func logins(authors []Author) []string {
 	return logins
 }
 
-func formatEntries(ctx *context.Context, entries []Item) ([]string, error) {
-	var lines []string
+func formatEntries(ctx *context.Context, entries []Item) ([]changelogEntry, error) {
+	var lines []changelogEntry
 	for _, entry := range entries {
 		line, err := formatEntry(ctx, entry)
 		if err != nil {
 			return nil, err
 		}
-		lines = append(lines, line)
+		lines = append(lines, changelogEntry{
+			item: entry,
+			line: line,
+		})
 	}
 	return lines, nil
 }
@@ -381,10 +443,10 @@ func getChangeloger(ctx *context.Context) (changeloger, error) {
 	}
 }
 
-func newCustomizedChangelog(ctx *context.Context) (staticChangeloger, error) {
+func newCustomizedChangelog(ctx *context.Context) (wrappingChangeloger, error) {
 	changeloger, err := getChangeloger(ctx)
 	if err != nil {
-		return nil, err
+		return wrappingChangeloger{}, err
 	}
 	return wrappingChangeloger{
 		changeloger: changeloger,
@@ -470,32 +532,15 @@ type staticChangeloger interface {
 type gitChangeloger struct{}
 
 func (g gitChangeloger) Log(ctx *context.Context) ([]Item, error) {
-	args := []string{
-		"log",
-		"--no-decorate",
-		"--no-color",
-		"--pretty=format:" + gitLogFormat,
-	}
 	// if prev is empty, it means we don't have a previous tag, so we don't
 	// pass any more args, which should everything.
 	// if current is empty, it shouldn't matter, as it will then log
 	// `{prev}..`, which should log everything from prev to HEAD.
 	prev, current := ctx.Git.PreviousTag, ctx.Git.CurrentTag
 	if prev != "" {
-		args = append(args, fmt.Sprintf("%s..%s", prev, current))
-	}
-	out, err := git.Run(ctx, args...)
-	if err != nil {
-		return nil, err
+		return gitLog(ctx, fmt.Sprintf("%s..%s", prev, current))
 	}
-	var entries []Item
-	for line := range strings.SplitSeq(out, commitDivider+"\n") {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		entries = append(entries, decode(line))
-	}
-	return entries, nil
+	return gitLog(ctx)
 }
 
 type scmChangeloger struct {
@@ -514,6 +559,21 @@ type githubNativeChangeloger struct {
 }
 
 func (c *githubNativeChangeloger) Log(ctx *context.Context) (string, error) {
+	enabled, err := contributorsEnabled(ctx)
+	if err != nil {
+		return "", err
+	}
+	if enabled {
+		// the native API doesn't give us the commits, so we rely on git for
+		// the contributors.
+		entries, err := gitChangeloger{}.Log(ctx)
+		if err != nil {
+			return "", err
+		}
+		if err := loadContributors(ctx, entries); err != nil {
+			return "", err
+		}
+	}
 	return c.client.GenerateReleaseNotes(ctx, c.repo, ctx.Git.PreviousTag, ctx.Git.CurrentTag)
 }
 
@@ -522,15 +582,29 @@ type wrappingChangeloger struct {
 }
 
 func (w wrappingChangeloger) Log(ctx *context.Context) (string, error) {
-	entries, err := w.changeloger.Log(ctx)
+	groups, err := w.Groups(ctx)
 	if err != nil {
 		return "", err
 	}
+	return renderChangelog(ctx, groups), nil
+}
+
+// Groups returns the filtered, sorted, and grouped changelog entries.
+func (w wrappingChangeloger) Groups(ctx *context.Context) ([]changelogGroup, error) {
+	entries, err := w.changeloger.Log(ctx)
+	if err != nil {
+		return nil, err
+	}
 	entries, err = filterEntries(ctx, entries)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	// contributors are loaded after filtering, so excluded commits don't
+	// count.
+	if err := loadContributors(ctx, entries); err != nil {
+		return nil, err
 	}
-	return formatChangelog(ctx, sortEntries(ctx, entries))
+	return groupEntries(ctx, sortEntries(ctx, entries))
 }
 
 const (
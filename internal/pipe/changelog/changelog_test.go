@@ -354,7 +354,7 @@ func TestChangelogSort(t *testing.T) {
 	} {
 		t.Run("changelog sort='"+cfg.Sort+"'", func(t *testing.T) {
 			ctx.Config.Changelog.Sort = cfg.Sort
-			log, err := buildChangelog(ctx)
+			log, _, err := buildChangelog(ctx)
 			require.NoError(t, err)
 			entries := strings.Split(strings.TrimSpace(log), "\n")
 			var changes []string
@@ -0,0 +1,224 @@
+package changelog
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/changelog"
+	"github.com/goreleaser/goreleaser/v2/internal/git"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const defaultContributorsTemplate = `{{- with .NewContributors -}}
+### New Contributors
+
+{{ range . -}}
+* {{ with .Username }}@{{ . }}{{ else }}{{ .Name }}{{ end }} made their first contribution
+{{ end }}
+{{ end -}}
+### Contributors
+
+{{ range .Contributors -}}
+* {{ with .Username }}@{{ . }}{{ else }}{{ .Name }}{{ end }} ({{ .Commits }} {{ if eq .Commits 1 }}commit{{ else }}commits{{ end }})
+{{ end -}}`
+
+func contributorsEnabled(ctx *context.Context) (bool, error) {
+	return tmpl.New(ctx).Bool(ctx.Config.Changelog.Contributors.Enabled)
+}
+
+// loadContributors sets [context.Context.Contributors] from the given
+// entries, if the contributors section is enabled.
+func loadContributors(ctx *context.Context, entries []Item) error {
+	enabled, err := contributorsEnabled(ctx)
+	if err != nil || !enabled {
+		return err
+	}
+
+	known, err := previousAuthors(ctx)
+	if err != nil {
+		return err
+	}
+
+	var local map[string]Author
+	var result []*changelog.Contributor
+	byKey := map[string]*changelog.Contributor{}
+	for _, entry := range entries {
+		for _, author := range cleanupAuthors(authorsOf(entry)) {
+			key := cmp.Or(author.Username, author.Email, author.Name)
+			if c, ok := byKey[key]; ok {
+				c.Commits++
+				continue
+			}
+			if author.Email == "" && author.Name == "" && local == nil {
+				local, err = localAuthors(ctx)
+				if err != nil {
+					return err
+				}
+			}
+			c := &changelog.Contributor{
+				Author:  author,
+				Commits: 1,
+				New:     isNewAuthor(known, enrich(author, local[entry.SHA])),
+			}
+			byKey[key] = c
+			result = append(result, c)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *changelog.Contributor) int {
+		return cmp.Or(
+			cmp.Compare(b.Commits, a.Commits),
+			strings.Compare(
+				strings.ToLower(cmp.Or(a.Username, a.Name)),
+				strings.ToLower(cmp.Or(b.Username, b.Name)),
+			),
+		)
+	})
+
+	ctx.Contributors = nil
+	for _, c := range result {
+		ctx.Contributors = append(ctx.Contributors, *c)
+	}
+	log.WithField("contributors", len(ctx.Contributors)).Debug("loaded contributors")
+	return nil
+}
+
+// formatContributors renders the contributors section, if enabled.
+func formatContributors(ctx *context.Context) (string, error) {
+	enabled, err := contributorsEnabled(ctx)
+	if err != nil || !enabled || len(ctx.Contributors) == 0 {
+		return "", err
+	}
+	out, err := tmpl.New(ctx).Apply(ctx.Config.Changelog.Contributors.Template)
+	return strings.TrimSpace(out), err
+}
+
+func authorsOf(entry Item) []Author {
+	if len(entry.Authors) > 0 {
+		return entry.Authors
+	}
+	return []Author{{
+		Name:     entry.AuthorName,
+		Email:    entry.AuthorEmail,
+		Username: entry.AuthorUsername,
+	}}
+}
+
+// previousAuthors returns the identities of everyone who authored or
+// co-authored a commit reachable from the previous tag.
+//
+// If there's no previous tag, it returns an empty set, meaning every author
+// is new.
+func previousAuthors(ctx *context.Context) (map[string]bool, error) {
+	known := map[string]bool{}
+	if ctx.Git.PreviousTag == "" {
+		return known, nil
+	}
+	entries, err := gitLog(ctx, ctx.Git.PreviousTag)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		for _, author := range entry.Authors {
+			for _, id := range identities(author) {
+				known[id] = true
+			}
+		}
+	}
+	return known, nil
+}
+
+// localAuthors returns the primary author of each commit in the current
+// range, as recorded in the local git history, by commit SHA.
+//
+// SCM APIs usually only report usernames, which can't be matched against
+// the local history, so this is used to fill in the name and email.
+func localAuthors(ctx *context.Context) (map[string]Author, error) {
+	entries, err := gitChangeloger{}.Log(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]Author, len(entries))
+	for _, entry := range entries {
+		result[entry.SHA] = entry.Authors[0]
+	}
+	return result, nil
+}
+
+// enrich fills in the name and email of a username-only author from the
+// local history.
+func enrich(author, local Author) Author {
+	if author.Email != "" || author.Name != "" {
+		return author
+	}
+	author.Name = local.Name
+	author.Email = local.Email
+	return author
+}
+
+func isNewAuthor(known map[string]bool, author Author) bool {
+	ids := identities(author)
+	if len(ids) == 0 {
+		return false
+	}
+	for _, id := range ids {
+		if known[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// identities returns the lowercase email, name and, for GitHub noreply
+// emails, the username of the given author.
+func identities(author Author) []string {
+	var result []string
+	for _, s := range []string{
+		author.Email,
+		author.Name,
+		author.Username,
+		usernameFromEmail(author.Email),
+	} {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// usernameFromEmail extracts the username from a GitHub noreply email, in
+// the form of ID+USERNAME@users.noreply.github.com.
+func usernameFromEmail(email string) string {
+	before, ok := strings.CutSuffix(email, "@users.noreply.github.com")
+	if !ok {
+		return ""
+	}
+	if _, username, ok := strings.Cut(before, "+"); ok {
+		return username
+	}
+	return before
+}
+
+func gitLog(ctx *context.Context, revs ...string) ([]Item, error) {
+	args := []string{
+		"log",
+		"--no-decorate",
+		"--no-color",
+		"--pretty=format:" + gitLogFormat,
+	}
+	out, err := git.Run(ctx, append(args, revs...)...)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Item
+	for line := range strings.SplitSeq(out, commitDivider+"\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entries = append(entries, decode(line))
+	}
+	return entries, nil
+}
@@ -0,0 +1,178 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/changelog"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContributors(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitCommitAs(t, "Old Timer <old@example.com>", "old: contribution")
+	testlib.GitTag(t, "v0.0.1")
+	testlib.GitCommit(t, "feat: something")
+	testlib.GitCommitAs(t, "Old Timer <old@example.com>", "fix: another thing")
+	testlib.GitCommitAs(t, "Newbie <new@example.com>", "feat: my first")
+	testlib.GitCommitAs(t, "Newbie <new@example.com>", "fix: my second")
+	testlib.GitCommitAs(t, "Newbie <new@example.com>", "docs: my third")
+	testlib.GitCommit(t, "feat: pairing\n\nCo-authored-by: Pair <pair@example.com>")
+	testlib.GitTag(t, "v0.0.2")
+
+	t.Run("enabled", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: folder,
+			Changelog: config.Changelog{
+				Filters: config.Filters{
+					Exclude: []string{"^docs:"},
+				},
+				Contributors: config.ChangelogContributors{
+					Enabled: "{{ not .IsSnapshot }}",
+				},
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Equal(t, []changelog.Contributor{
+			{
+				Author:  changelog.Author{Name: "GoReleaser", Email: "test@goreleaser.github.com"},
+				Commits: 2,
+			},
+			{
+				// the docs commit is excluded, so it doesn't count.
+				Author:  changelog.Author{Name: "Newbie", Email: "new@example.com"},
+				Commits: 2,
+				New:     true,
+			},
+			{
+				Author:  changelog.Author{Name: "Old Timer", Email: "old@example.com"},
+				Commits: 1,
+			},
+			{
+				Author:  changelog.Author{Name: "Pair", Email: "pair@example.com"},
+				Commits: 1,
+				New:     true,
+			},
+		}, ctx.Contributors)
+
+		require.Contains(t, ctx.ReleaseNotes, "### New Contributors")
+		require.Contains(t, ctx.ReleaseNotes, "* Newbie made their first contribution\n")
+		require.Contains(t, ctx.ReleaseNotes, "* Pair made their first contribution\n")
+		require.NotContains(t, ctx.ReleaseNotes, "* Old Timer made their first contribution")
+		require.Contains(t, ctx.ReleaseNotes, "### Contributors")
+		require.Contains(t, ctx.ReleaseNotes, "* Newbie (2 commits)\n")
+		require.Contains(t, ctx.ReleaseNotes, "* Old Timer (1 commit)\n")
+	})
+
+	t.Run("custom template", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: folder,
+			Changelog: config.Changelog{
+				Contributors: config.ChangelogContributors{
+					Enabled:  "true",
+					Template: "Thanks{{ range .NewContributors }} {{ .Name }}{{ end }}!",
+				},
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Contains(t, ctx.ReleaseNotes, "\n\nThanks Newbie Pair!\n")
+	})
+
+	t.Run("first release", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: folder,
+			Changelog: config.Changelog{
+				Contributors: config.ChangelogContributors{
+					Enabled: "true",
+				},
+			},
+		}, testctx.WithCurrentTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Len(t, ctx.Contributors, 4)
+		for _, c := range ctx.Contributors {
+			require.True(t, c.New, c.Name)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: folder,
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Empty(t, ctx.Contributors)
+		require.NotContains(t, ctx.ReleaseNotes, "Contributors")
+	})
+
+	t.Run("invalid enabled template", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: folder,
+			Changelog: config.Changelog{
+				Contributors: config.ChangelogContributors{
+					Enabled: "{{ .Nope }}",
+				},
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		testlib.RequireTemplateError(t, Pipe{}.Run(ctx))
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: folder,
+			Changelog: config.Changelog{
+				Contributors: config.ChangelogContributors{
+					Enabled:  "true",
+					Template: "{{ .Nope }}",
+				},
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		testlib.RequireTemplateError(t, Pipe{}.Run(ctx))
+	})
+}
+
+func TestIsNewAuthor(t *testing.T) {
+	known := map[string]bool{
+		"old@example.com": true,
+		"old timer":       true,
+		"caarlos0":        true,
+	}
+	for name, tt := range map[string]struct {
+		author Author
+		expect bool
+	}{
+		"known email":      {Author{Email: "OLD@example.com"}, false},
+		"known name":       {Author{Name: "Old Timer", Email: "other@example.com"}, false},
+		"known username":   {Author{Username: "caarlos0"}, false},
+		"noreply email":    {Author{Email: "123+caarlos0@users.noreply.github.com"}, false},
+		"new":              {Author{Name: "Newbie", Email: "new@example.com"}, true},
+		"new username":     {Author{Username: "newbie"}, true},
+		"empty":            {Author{}, false},
+		"enriched unknown": {enrich(Author{Username: "x"}, Author{Email: "x@example.com"}), true},
+		"enriched known":   {enrich(Author{Username: "x"}, Author{Email: "old@example.com"}), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expect, isNewAuthor(known, tt.author))
+		})
+	}
+}
+
+func TestUsernameFromEmail(t *testing.T) {
+	require.Equal(t, "caarlos0", usernameFromEmail("123+caarlos0@users.noreply.github.com"))
+	require.Equal(t, "caarlos0", usernameFromEmail("caarlos0@users.noreply.github.com"))
+	require.Empty(t, usernameFromEmail("caarlos0@example.com"))
+}
@@ -0,0 +1,148 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+func checkFormats(formats []string) error {
+	for _, format := range formats {
+		switch format {
+		case formatMarkdown, formatJSON:
+		default:
+			return fmt.Errorf("invalid changelog format: %q", format)
+		}
+	}
+	return nil
+}
+
+// writeFiles writes the changelog files to the dist directory.
+func writeFiles(ctx *context.Context, changes string, groups []changelogGroup) error {
+	for _, format := range ctx.Config.Changelog.Formats {
+		var err error
+		switch format {
+		case formatMarkdown:
+			err = writeFile(ctx, "CHANGELOG.md", []byte(ctx.ReleaseNotes))
+		case formatJSON:
+			err = writeJSON(ctx, groups)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeKeepAChangelogSection(ctx, changes, groups)
+}
+
+// writeKeepAChangelogSection writes the release section to be later added to
+// the repository changelog file, if enabled.
+func writeKeepAChangelogSection(ctx *context.Context, changes string, groups []changelogGroup) error {
+	enabled, err := keepAChangelogEnabled(ctx)
+	if err != nil || !enabled {
+		return err
+	}
+	return writeFile(ctx, keepAChangelogSectionName, []byte(keepAChangelogSection(ctx, changes, groups)))
+}
+
+func writeFile(ctx *context.Context, name string, content []byte) error {
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.WithField("path", path).Debug("writing changelog")
+	return os.WriteFile(path, content, 0o644) //nolint:gosec
+}
+
+func writeJSON(ctx *context.Context, groups []changelogGroup) error {
+	result := jsonChangelog{
+		ProjectName: ctx.Config.ProjectName,
+		Tag:         ctx.Git.CurrentTag,
+		PreviousTag: ctx.Git.PreviousTag,
+		Version:     ctx.Version,
+		Date:        ctx.Date,
+		Groups:      []jsonGroup{},
+	}
+	for _, group := range groups {
+		if len(group.entries) == 0 {
+			continue
+		}
+		jgroup := jsonGroup{Title: group.title}
+		for _, entry := range group.entries {
+			jentry := jsonEntry{
+				SHA:     entry.item.SHA,
+				Message: entry.item.Message,
+				Line:    strings.TrimPrefix(entry.line, li),
+			}
+			for _, author := range cleanupAuthors(authorsOf(entry.item)) {
+				jentry.Authors = append(jentry.Authors, toJSONAuthor(author))
+			}
+			jgroup.Entries = append(jgroup.Entries, jentry)
+		}
+		result.Groups = append(result.Groups, jgroup)
+	}
+	for _, c := range ctx.Contributors {
+		result.Contributors = append(result.Contributors, jsonContributor{
+			jsonAuthor: toJSONAuthor(c.Author),
+			Commits:    c.Commits,
+			New:        c.New,
+		})
+	}
+
+	bts, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(ctx, "CHANGELOG.json", bts)
+}
+
+func toJSONAuthor(a Author) jsonAuthor {
+	return jsonAuthor{
+		Name:     a.Name,
+		Email:    a.Email,
+		Username: a.Username,
+	}
+}
+
+type jsonChangelog struct {
+	ProjectName  string            `json:"project_name"`
+	Tag          string            `json:"tag"`
+	PreviousTag  string            `json:"previous_tag"`
+	Version      string            `json:"version"`
+	Date         time.Time         `json:"date"`
+	Groups       []jsonGroup       `json:"groups"`
+	Contributors []jsonContributor `json:"contributors,omitempty"`
+}
+
+type jsonGroup struct {
+	Title   string      `json:"title,omitempty"`
+	Entries []jsonEntry `json:"entries"`
+}
+
+type jsonEntry struct {
+	SHA     string       `json:"sha"`
+	Message string       `json:"message"`
+	Line    string       `json:"line"`
+	Authors []jsonAuthor `json:"authors,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+type jsonContributor struct {
+	jsonAuthor
+
+	Commits int  `json:"commits"`
+	New     bool `json:"new"`
+}
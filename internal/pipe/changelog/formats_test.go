@@ -0,0 +1,152 @@
+package changelog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogFormats(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v0.0.1")
+	testlib.GitCommit(t, "feat: added feature 1")
+	testlib.GitCommit(t, "fix: fixed bug 2")
+	testlib.GitCommit(t, "chore: something else")
+	testlib.GitTag(t, "v0.0.2")
+
+	groups := []config.ChangelogGroup{
+		{Title: "Features", Regexp: "^feat:", Order: 0},
+		{Title: "Fixes", Regexp: "^fix:", Order: 1},
+		{Title: "Docs", Regexp: "^docs:", Order: 2},
+		{Title: "Others", Order: 999},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		dist := t.TempDir()
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: dist,
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Equal(t, []string{"markdown"}, ctx.Config.Changelog.Formats)
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.FileExists(t, filepath.Join(dist, "CHANGELOG.md"))
+		require.NoFileExists(t, filepath.Join(dist, "CHANGELOG.json"))
+		require.NoFileExists(t, filepath.Join(dist, keepAChangelogSectionName))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		dist := t.TempDir()
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			ProjectName: "foo",
+			Dist:        dist,
+			Changelog: config.Changelog{
+				Formats: []string{"json"},
+				Groups:  groups,
+				Format:  "{{ .Message }}",
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"), testctx.WithVersion("0.0.2"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.NoFileExists(t, filepath.Join(dist, "CHANGELOG.md"))
+
+		bts, err := os.ReadFile(filepath.Join(dist, "CHANGELOG.json"))
+		require.NoError(t, err)
+		var result jsonChangelog
+		require.NoError(t, json.Unmarshal(bts, &result))
+		require.Equal(t, "foo", result.ProjectName)
+		require.Equal(t, "v0.0.2", result.Tag)
+		require.Equal(t, "v0.0.1", result.PreviousTag)
+		require.Equal(t, "0.0.2", result.Version)
+		require.Len(t, result.Groups, 3)
+		require.Equal(t, "Features", result.Groups[0].Title)
+		require.Equal(t, "Fixes", result.Groups[1].Title)
+		require.Equal(t, "Others", result.Groups[2].Title)
+		require.Len(t, result.Groups[0].Entries, 1)
+		entry := result.Groups[0].Entries[0]
+		require.Equal(t, "feat: added feature 1", entry.Message)
+		require.Equal(t, "feat: added feature 1", entry.Line)
+		require.Len(t, entry.SHA, 40)
+		require.Equal(t, []jsonAuthor{{
+			Name:  "GoReleaser",
+			Email: "test@goreleaser.github.com",
+		}}, entry.Authors)
+	})
+
+	t.Run("json without groups", func(t *testing.T) {
+		dist := t.TempDir()
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: dist,
+			Changelog: config.Changelog{
+				Formats: []string{"markdown", "json"},
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.FileExists(t, filepath.Join(dist, "CHANGELOG.md"))
+
+		bts, err := os.ReadFile(filepath.Join(dist, "CHANGELOG.json"))
+		require.NoError(t, err)
+		var result jsonChangelog
+		require.NoError(t, json.Unmarshal(bts, &result))
+		require.Len(t, result.Groups, 1)
+		require.Empty(t, result.Groups[0].Title)
+		require.Len(t, result.Groups[0].Entries, 3)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: t.TempDir(),
+			Changelog: config.Changelog{
+				Formats: []string{"yaml"},
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.EqualError(t, Pipe{}.Run(ctx), `invalid changelog format: "yaml"`)
+	})
+
+	t.Run("keep a changelog section", func(t *testing.T) {
+		dist := t.TempDir()
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist: dist,
+			Changelog: config.Changelog{
+				Groups: groups,
+				Format: "{{ .Message }}",
+				KeepAChangelog: config.KeepAChangelog{
+					Enabled: "true",
+				},
+			},
+		}, testctx.WithCurrentTag("v0.0.2"), testctx.WithPreviousTag("v0.0.1"), testctx.WithVersion("0.0.2"))
+
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, Pipe{}.Run(ctx))
+
+		bts, err := os.ReadFile(filepath.Join(dist, keepAChangelogSectionName))
+		require.NoError(t, err)
+		require.Equal(t, "## [0.0.2] - "+ctx.Date.UTC().Format("2006-01-02")+`
+
+### Features
+
+- feat: added feature 1
+
+### Fixes
+
+- fix: fixed bug 2
+
+### Others
+
+- chore: something else
+`, string(bts))
+	})
+}
@@ -0,0 +1,217 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/client"
+	"github.com/goreleaser/goreleaser/v2/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	keepAChangelogSectionName = "CHANGELOG.section.md"
+	keepAChangelogDefaultType = "Changed"
+	keepAChangelogHeader      = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to %s.
+`
+)
+
+// KeepAChangelogPipe prepends the release section to a repository-tracked
+// changelog file, and commits it.
+type KeepAChangelogPipe struct{}
+
+func (KeepAChangelogPipe) String() string { return "updating changelog file" }
+
+func (KeepAChangelogPipe) Skip(ctx *context.Context) (bool, error) {
+	if ctx.Snapshot {
+		return true, nil
+	}
+	disabled, err := tmpl.New(ctx).Bool(ctx.Config.Changelog.Disable)
+	if err != nil || disabled {
+		return true, err
+	}
+	enabled, err := keepAChangelogEnabled(ctx)
+	return !enabled, err
+}
+
+func (KeepAChangelogPipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishKeepAChangelog(ctx, cli)
+}
+
+func keepAChangelogEnabled(ctx *context.Context) (bool, error) {
+	return tmpl.New(ctx).Bool(ctx.Config.Changelog.KeepAChangelog.Enabled)
+}
+
+func publishKeepAChangelog(ctx *context.Context, cli client.Client) error {
+	cfg := ctx.Config.Changelog.KeepAChangelog
+
+	section, err := os.ReadFile(filepath.Join(ctx.Config.Dist, keepAChangelogSectionName))
+	if err != nil {
+		return fmt.Errorf("could not read changelog section: %w", err)
+	}
+
+	if cfg.Repository.Name == "" {
+		repo := releaseRepo(ctx)
+		cfg.Repository.Owner = repo.Owner
+		cfg.Repository.Name = repo.Name
+	}
+
+	ref, err := client.TemplateRef(tmpl.New(ctx).Apply, cfg.Repository)
+	if err != nil {
+		return err
+	}
+	repo := client.RepoFromRef(ref)
+
+	msg, err := tmpl.New(ctx).Apply(cfg.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	author, err := commitauthor.Get(ctx, cfg.CommitAuthor)
+	if err != nil {
+		return err
+	}
+
+	var fcli client.FileCreator
+	if ref.Git.URL != "" {
+		fcli = client.NewGitUploadClient(repo.Branch)
+	} else {
+		cli, err = client.NewIfToken(ctx, cli, ref.Token)
+		if err != nil {
+			return err
+		}
+		fcli = cli
+	}
+
+	// the file is read from the target repository, so newer changes there
+	// are kept.
+	getter, ok := fcli.(client.FileGetter)
+	if !ok {
+		return errors.New("client does not support reading files")
+	}
+	current, err := getter.GetFile(ctx, repo, cfg.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not read %s: %w", cfg.Path, err)
+	}
+
+	content, ok := prependKeepAChangelog(string(current), keepAChangelogHeaderFor(ctx), string(section), ctx.Version)
+	if !ok {
+		return pipe.Skipf("%s already has a section for %s", cfg.Path, ctx.Version)
+	}
+
+	log.WithField("path", cfg.Path).
+		WithField("repository", repo.String()).
+		Info("updating changelog file")
+	return fcli.CreateFile(ctx, author, repo, []byte(content), cfg.Path, msg)
+}
+
+func releaseRepo(ctx *context.Context) config.Repo {
+	switch ctx.TokenType {
+	case context.TokenTypeGitLab:
+		return ctx.Config.Release.GitLab
+	case context.TokenTypeGitea:
+		return ctx.Config.Release.Gitea
+	default:
+		return ctx.Config.Release.GitHub
+	}
+}
+
+// keepAChangelogSection renders the release section in the Keep a Changelog
+// format.
+//
+// Each changelog group becomes a subsection.
+// If there are no groups (e.g. when using 'github-native'), the given changes
+// are used instead, with their headings demoted so they fit in the section.
+func keepAChangelogSection(ctx *context.Context, changes string, groups []changelogGroup) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## [%s] - %s\n", ctx.Version, ctx.Date.UTC().Format("2006-01-02"))
+
+	if groups == nil {
+		for line := range strings.SplitSeq(strings.TrimSpace(changes), "\n") {
+			if strings.HasPrefix(line, "#") {
+				line = "#" + line
+			}
+			sb.WriteString("\n" + line)
+		}
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	for _, group := range groups {
+		if len(group.entries) == 0 {
+			continue
+		}
+		title := group.title
+		if title == "" {
+			title = keepAChangelogDefaultType
+		}
+		fmt.Fprintf(&sb, "\n### %s\n\n", title)
+		for _, entry := range group.entries {
+			fmt.Fprintf(&sb, "- %s\n", strings.TrimPrefix(entry.line, li))
+		}
+	}
+	return sb.String()
+}
+
+// keepAChangelogHeaderFor returns the header of a new changelog file, which
+// links to the versioning scheme in use.
+func keepAChangelogHeaderFor(ctx *context.Context) string {
+	if ctx.Config.Versioning.Mode == "calver" {
+		return fmt.Sprintf(keepAChangelogHeader, "[Calendar Versioning](https://calver.org/)")
+	}
+	return fmt.Sprintf(keepAChangelogHeader, "[Semantic Versioning](https://semver.org/spec/v2.0.0.html)")
+}
+
+// prependKeepAChangelog adds the given section to the changelog, right
+// before the latest released version, keeping the Unreleased section (if
+// any) on top.
+//
+// It returns false if the changelog already has a section for the given
+// version.
+func prependKeepAChangelog(current, header, section, version string) (string, bool) {
+	if strings.TrimSpace(current) == "" {
+		return header + "\n" + section, true
+	}
+
+	lines := strings.Split(current, "\n")
+	at := len(lines)
+	for i, line := range lines {
+		heading, ok := strings.CutPrefix(line, "## ")
+		if !ok {
+			continue
+		}
+		heading = strings.TrimSpace(heading)
+		if strings.HasPrefix(heading, "["+version+"]") {
+			return current, false
+		}
+		if strings.EqualFold(strings.Trim(heading, "[]"), "unreleased") {
+			continue
+		}
+		if at == len(lines) {
+			at = i
+		}
+	}
+
+	before := strings.TrimRight(strings.Join(lines[:at], "\n"), "\n")
+	after := strings.Join(lines[at:], "\n")
+	if after == "" {
+		return before + "\n\n" + section, true
+	}
+	return before + "\n\n" + section + "\n" + after, true
+}
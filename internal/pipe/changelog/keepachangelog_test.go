@@ -0,0 +1,246 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/client"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const testSection = `## [1.1.0] - 2026-01-02
+
+### Added
+
+- something new
+`
+
+func TestPrependKeepAChangelog(t *testing.T) {
+	t.Run("new file", func(t *testing.T) {
+		out, ok := prependKeepAChangelog("", "# Changelog\n", testSection, "1.1.0")
+		require.True(t, ok)
+		require.Equal(t, "# Changelog\n\n"+testSection, out)
+	})
+
+	t.Run("existing releases", func(t *testing.T) {
+		out, ok := prependKeepAChangelog(`# Changelog
+
+Some text.
+
+## [1.0.0] - 2025-01-01
+
+### Added
+
+- first release
+`, "", testSection, "1.1.0")
+		require.True(t, ok)
+		require.Equal(t, `# Changelog
+
+Some text.
+
+## [1.1.0] - 2026-01-02
+
+### Added
+
+- something new
+
+## [1.0.0] - 2025-01-01
+
+### Added
+
+- first release
+`, out)
+	})
+
+	t.Run("unreleased", func(t *testing.T) {
+		out, ok := prependKeepAChangelog(`# Changelog
+
+## [Unreleased]
+
+- wip
+
+## [1.0.0] - 2025-01-01
+
+- first release
+`, "", testSection, "1.1.0")
+		require.True(t, ok)
+		require.Equal(t, `# Changelog
+
+## [Unreleased]
+
+- wip
+
+## [1.1.0] - 2026-01-02
+
+### Added
+
+- something new
+
+## [1.0.0] - 2025-01-01
+
+- first release
+`, out)
+	})
+
+	t.Run("no releases yet", func(t *testing.T) {
+		out, ok := prependKeepAChangelog("# Changelog\n\n## Unreleased\n", "", testSection, "1.1.0")
+		require.True(t, ok)
+		require.Equal(t, "# Changelog\n\n## Unreleased\n\n"+testSection, out)
+	})
+
+	t.Run("already there", func(t *testing.T) {
+		current := "# Changelog\n\n" + testSection
+		out, ok := prependKeepAChangelog(current, "", testSection, "1.1.0")
+		require.False(t, ok)
+		require.Equal(t, current, out)
+	})
+}
+
+func TestKeepAChangelogSectionNative(t *testing.T) {
+	ctx := testctx.Wrap(
+		t.Context(),
+		testctx.WithVersion("1.1.0"),
+		testctx.WithDate(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)),
+	)
+	out := keepAChangelogSection(ctx, "## What's Changed\n* foo by @bar\n", nil)
+	require.Equal(t, "## [1.1.0] - 2026-01-02\n\n### What's Changed\n* foo by @bar\n", out)
+}
+
+func TestKeepAChangelogPipe(t *testing.T) {
+	require.NotEmpty(t, KeepAChangelogPipe{}.String())
+
+	t.Run("skip", func(t *testing.T) {
+		for name, ctx := range map[string]*context.Context{
+			"snapshot": testctx.WrapWithCfg(t.Context(), config.Project{
+				Changelog: config.Changelog{
+					KeepAChangelog: config.KeepAChangelog{Enabled: "true"},
+				},
+			}, testctx.Snapshot),
+			"not enabled": testctx.Wrap(t.Context()),
+			"changelog disabled": testctx.WrapWithCfg(t.Context(), config.Project{
+				Changelog: config.Changelog{
+					Disable:        "true",
+					KeepAChangelog: config.KeepAChangelog{Enabled: "true"},
+				},
+			}),
+		} {
+			t.Run(name, func(t *testing.T) {
+				skip, err := KeepAChangelogPipe{}.Skip(ctx)
+				require.NoError(t, err)
+				require.True(t, skip)
+			})
+		}
+	})
+
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Changelog: config.Changelog{
+				KeepAChangelog: config.KeepAChangelog{Enabled: "{{ not .IsSnapshot }}"},
+			},
+		})
+		skip, err := KeepAChangelogPipe{}.Skip(ctx)
+		require.NoError(t, err)
+		require.False(t, skip)
+	})
+
+	t.Run("invalid enabled template", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Changelog: config.Changelog{
+				KeepAChangelog: config.KeepAChangelog{Enabled: "{{ .Nope }}"},
+			},
+		})
+		_, err := KeepAChangelogPipe{}.Skip(ctx)
+		testlib.RequireTemplateError(t, err)
+	})
+}
+
+func TestPublishKeepAChangelog(t *testing.T) {
+	setup := func(tb testing.TB, kac config.KeepAChangelog) *context.Context {
+		tb.Helper()
+		testlib.Mktmp(tb)
+		dist := filepath.Join(tb.TempDir(), "dist")
+		require.NoError(tb, os.Mkdir(dist, 0o755))
+		require.NoError(tb, os.WriteFile(filepath.Join(dist, keepAChangelogSectionName), []byte(testSection), 0o644))
+		ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+			ProjectName: "foo",
+			Dist:        dist,
+			Release: config.Release{
+				GitHub: config.Repo{Owner: "owner", Name: "repo"},
+			},
+			Changelog: config.Changelog{KeepAChangelog: kac},
+		}, testctx.WithVersion("1.1.0"), testctx.WithCurrentTag("v1.1.0"), testctx.GitHubTokenType)
+		require.NoError(tb, Pipe{}.Default(ctx))
+		return ctx
+	}
+
+	t.Run("new file", func(t *testing.T) {
+		ctx := setup(t, config.KeepAChangelog{Enabled: "true"})
+		cli := client.NewMock()
+		require.NoError(t, publishKeepAChangelog(ctx, cli))
+		require.True(t, cli.CreatedFile)
+		require.Equal(t, "CHANGELOG.md", cli.Path)
+		require.Equal(t, keepAChangelogHeaderFor(ctx)+"\n"+testSection, cli.Content)
+		require.Contains(t, cli.Content, "[Semantic Versioning](https://semver.org/spec/v2.0.0.html)")
+		require.Equal(t, []string{"Changelog update for foo version v1.1.0"}, cli.Messages)
+	})
+
+	t.Run("new file calver", func(t *testing.T) {
+		ctx := setup(t, config.KeepAChangelog{Enabled: "true"})
+		ctx.Config.Versioning.Mode = "calver"
+		cli := client.NewMock()
+		require.NoError(t, publishKeepAChangelog(ctx, cli))
+		require.Contains(t, cli.Content, "and this project adheres to [Calendar Versioning](https://calver.org/).\n")
+		require.NotContains(t, cli.Content, "Semantic Versioning")
+	})
+
+	t.Run("existing file", func(t *testing.T) {
+		ctx := setup(t, config.KeepAChangelog{
+			Enabled:               "true",
+			Path:                  "docs/CHANGES.md",
+			CommitMessageTemplate: "docs: {{ .Version }}",
+		})
+		// the local file is outdated, the one in the repository is used.
+		require.NoError(t, os.Mkdir("docs", 0o755))
+		require.NoError(t, os.WriteFile("docs/CHANGES.md", []byte("# Changes\n"), 0o644))
+		cli := client.NewMock()
+		cli.Files = map[string]string{
+			"docs/CHANGES.md": "# Changes\n\n## [1.0.0] - 2025-01-01\n",
+		}
+		require.NoError(t, publishKeepAChangelog(ctx, cli))
+		require.Equal(t, "docs/CHANGES.md", cli.Path)
+		require.Equal(t, "# Changes\n\n"+testSection+"\n## [1.0.0] - 2025-01-01\n", cli.Content)
+		require.Equal(t, []string{"docs: 1.1.0"}, cli.Messages)
+	})
+
+	t.Run("already released", func(t *testing.T) {
+		ctx := setup(t, config.KeepAChangelog{Enabled: "true"})
+		cli := client.NewMock()
+		cli.Files = map[string]string{
+			"CHANGELOG.md": "# Changelog\n\n" + testSection,
+		}
+		err := publishKeepAChangelog(ctx, cli)
+		require.True(t, pipe.IsSkip(err), err)
+		require.False(t, cli.CreatedFile)
+	})
+
+	t.Run("missing section", func(t *testing.T) {
+		ctx := setup(t, config.KeepAChangelog{Enabled: "true"})
+		require.NoError(t, os.Remove(filepath.Join(ctx.Config.Dist, keepAChangelogSectionName)))
+		require.ErrorIs(t, publishKeepAChangelog(ctx, client.NewMock()), os.ErrNotExist)
+	})
+
+	t.Run("invalid commit message template", func(t *testing.T) {
+		ctx := setup(t, config.KeepAChangelog{
+			Enabled:               "true",
+			CommitMessageTemplate: "{{ .Nope }}",
+		})
+		testlib.RequireTemplateError(t, publishKeepAChangelog(ctx, client.NewMock()))
+	})
+}
@@ -6,7 +6,6 @@ import (
 	"cmp"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -17,11 +16,15 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/extrafiles"
 	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
 )
 
 const (
 	artifactChecksumExtra = "Checksum"
+
+	formatGNU = "gnu"
+	formatBSD = "bsd"
 )
 
 var (
@@ -38,13 +41,28 @@ func (Pipe) Skip(ctx *context.Context) bool { return ctx.Config.Checksum.Disable
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
 	cs := &ctx.Config.Checksum
+	if cs.Algorithm == "" && len(cs.Algorithms) > 0 {
+		cs.Algorithm = cs.Algorithms[0]
+	}
 	if cs.Algorithm == "" {
 		cs.Algorithm = "sha256"
 	}
+	if len(cs.Algorithms) > 0 && !slices.Contains(cs.Algorithms, cs.Algorithm) {
+		return fmt.Errorf("checksum: algorithm %q must be one of the algorithms: %s", cs.Algorithm, strings.Join(cs.Algorithms, ", "))
+	}
+	if cs.Format == "" {
+		cs.Format = formatGNU
+	}
+	if cs.Format != formatGNU && cs.Format != formatBSD {
+		return fmt.Errorf("checksum: invalid format: %s", cs.Format)
+	}
 	if cs.NameTemplate == "" {
-		if cs.Split {
+		switch {
+		case cs.Split:
 			cs.NameTemplate = "{{ .ArtifactName }}.{{ .Algorithm }}"
-		} else {
+		case cs.Format == formatGNU && len(cs.Algorithms) > 1:
+			cs.NameTemplate = "{{ .ProjectName }}_{{ .Version }}_checksums_{{ .Algorithm }}.txt"
+		default:
 			cs.NameTemplate = "{{ .ProjectName }}_{{ .Version }}_checksums.txt"
 		}
 	}
@@ -53,42 +71,113 @@ func (Pipe) Default(ctx *context.Context) error {
 
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) error {
+	w, err := newWriter(ctx)
+	if err != nil {
+		return err
+	}
+
 	if ctx.Config.Checksum.Split {
-		return splitChecksum(ctx)
+		return splitChecksum(ctx, w)
 	}
 
-	return singleChecksum(ctx)
+	return singleChecksum(ctx, w)
 }
 
-func splitChecksum(ctx *context.Context) error {
+func errDuplicatedName(name string) error {
+	return fmt.Errorf("checksum: name template must be different for each algorithm, got %q more than once: use {{ .Algorithm }} in it", name)
+}
+
+// algorithms returns the algorithms to use, the primary one first.
+func algorithms(cfg config.Checksum) []string {
+	result := []string{cfg.Algorithm}
+	for _, algorithm := range cfg.Algorithms {
+		if !slices.Contains(result, algorithm) {
+			result = append(result, algorithm)
+		}
+	}
+	return result
+}
+
+func splitChecksum(ctx *context.Context, w writer) error {
 	artifactList, err := buildArtifactList(ctx)
 	if err != nil {
 		return err
 	}
 
+	var names []string
 	for _, art := range artifactList {
+		for _, algorithm := range algorithms(ctx.Config.Checksum) {
+			filename, err := tmpl.New(ctx).
+				WithArtifact(art).
+				WithExtraFields(tmpl.Fields{
+					"Algorithm": algorithm,
+				}).
+				Apply(ctx.Config.Checksum.NameTemplate)
+			if err != nil {
+				return fmt.Errorf("name template: %w", err)
+			}
+			if slices.Contains(names, filename) {
+				return errDuplicatedName(filename)
+			}
+			names = append(names, filename)
+			filepath := filepath.Join(ctx.Config.Dist, filename)
+			if err := refreshOne(ctx, w, *art, algorithm, filepath); err != nil {
+				return fmt.Errorf("%s: %w", art.Path, err)
+			}
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Type: artifact.Checksum,
+				Path: filepath,
+				Name: filename,
+				Extra: map[string]any{
+					artifact.ExtraChecksumOf: art.Path,
+					artifact.ExtraRefresh: func() error {
+						log.WithField("file", filename).Debug("refreshing checksums")
+						return refreshOne(ctx, w, *art, algorithm, filepath)
+					},
+				},
+			})
+		}
+	}
+	return nil
+}
+
+func singleChecksum(ctx *context.Context, w writer) error {
+	// the bsd format has all the algorithms in a single file.
+	algs := algorithms(ctx.Config.Checksum)
+	if ctx.Config.Checksum.Format == formatBSD {
+		algs = algs[:1]
+	}
+
+	var names []string
+	for _, algorithm := range algs {
 		filename, err := tmpl.New(ctx).
-			WithArtifact(art).
 			WithExtraFields(tmpl.Fields{
-				"Algorithm": ctx.Config.Checksum.Algorithm,
+				"Algorithm": algorithm,
 			}).
 			Apply(ctx.Config.Checksum.NameTemplate)
 		if err != nil {
-			return fmt.Errorf("name template: %w", err)
+			return err
 		}
+		if slices.Contains(names, filename) {
+			return errDuplicatedName(filename)
+		}
+		names = append(names, filename)
+
 		filepath := filepath.Join(ctx.Config.Dist, filename)
-		if err := refreshOne(ctx, *art, filepath); err != nil {
-			return fmt.Errorf("%s: %w", art.Path, err)
+		if err := refreshAll(ctx, w, algorithm, filepath); err != nil {
+			if errors.Is(err, errNoArtifacts) {
+				return nil
+			}
+			return err
 		}
 		ctx.Artifacts.Add(&artifact.Artifact{
 			Type: artifact.Checksum,
 			Path: filepath,
 			Name: filename,
 			Extra: map[string]any{
-				artifact.ExtraChecksumOf: art.Path,
 				artifact.ExtraRefresh: func() error {
 					log.WithField("file", filename).Debug("refreshing checksums")
-					return refreshOne(ctx, *art, filepath)
+					return refreshAll(ctx, w, algorithm, filepath)
 				},
 			},
 		})
@@ -96,41 +185,20 @@ func splitChecksum(ctx *context.Context) error {
 	return nil
 }
 
-func singleChecksum(ctx *context.Context) error {
-	filename, err := tmpl.New(ctx).Apply(ctx.Config.Checksum.NameTemplate)
+func refreshOne(ctx *context.Context, w writer, art artifact.Artifact, algorithm, path string) error {
+	check, err := sum(ctx, &art, algorithm)
 	if err != nil {
 		return err
 	}
-	filepath := filepath.Join(ctx.Config.Dist, filename)
-	if err := refreshAll(ctx, filepath); err != nil {
-		if errors.Is(err, errNoArtifacts) {
-			return nil
-		}
-		return err
-	}
-	ctx.Artifacts.Add(&artifact.Artifact{
-		Type: artifact.Checksum,
-		Path: filepath,
-		Name: filename,
-		Extra: map[string]any{
-			artifact.ExtraRefresh: func() error {
-				log.WithField("file", filename).Debug("refreshing checksums")
-				return refreshAll(ctx, filepath)
-			},
-		},
-	})
-	return nil
-}
-
-func refreshOne(ctx *context.Context, art artifact.Artifact, path string) error {
-	check, err := art.Checksum(ctx.Config.Checksum.Algorithm)
-	if err != nil {
-		return err
+	if ctx.Config.Checksum.Format == formatBSD {
+		check = bsdLine(algorithm, check, art.Name)
 	}
-	return os.WriteFile(path, []byte(check), 0o644)
+	return w.write(path, []byte(check))
 }
 
-func refreshAll(ctx *context.Context, filepath string) error {
+// refreshAll writes the checksums file for the given algorithm, or for all
+// algorithms when using the bsd format.
+func refreshAll(ctx *context.Context, w writer, algorithm, path string) error {
 	lock.Lock()
 	defer lock.Unlock()
 
@@ -139,15 +207,24 @@ func refreshAll(ctx *context.Context, filepath string) error {
 		return err
 	}
 
+	algs := []string{algorithm}
+	if ctx.Config.Checksum.Format == formatBSD {
+		algs = algorithms(ctx.Config.Checksum)
+	}
+
 	g := semerrgroup.New(ctx.Parallelism)
 	sumLines := make([]string, len(artifactList))
 	for i, artifact := range artifactList {
 		g.Go(func() error {
-			sumLine, err := checksums(ctx.Config.Checksum.Algorithm, artifact)
-			if err != nil {
-				return err
+			var lines strings.Builder
+			for _, algorithm := range algs {
+				sumLine, err := checksums(ctx, algorithm, artifact)
+				if err != nil {
+					return err
+				}
+				lines.WriteString(sumLine)
 			}
-			sumLines[i] = sumLine
+			sumLines[i] = lines.String()
 			return nil
 		})
 	}
@@ -157,37 +234,31 @@ func refreshAll(ctx *context.Context, filepath string) error {
 		return err
 	}
 
-	file, err := os.OpenFile(
-		filepath,
-		os.O_APPEND|os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
-		0o644,
-	)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
 	// sort to ensure the signature is deterministic downstream
 	slices.SortFunc(sumLines, func(a, b string) int {
-		// sort by the filename of a checksum line ("{checksum}  {filename}\n")
-		_, nameA, okA := strings.Cut(a, "  ")
-		_, nameB, okB := strings.Cut(b, "  ")
-		if !okA {
-			nameA = a
-		}
-		if !okB {
-			nameB = b
-		}
-		return cmp.Compare(nameA, nameB)
+		return cmp.Compare(lineName(a), lineName(b))
 	})
-	_, err = file.WriteString(strings.Join(sumLines, ""))
-	return err
+	return w.write(path, []byte(strings.Join(sumLines, "")))
+}
+
+// lineName returns the filename of a checksum line, either
+// "{checksum}  {filename}\n" or "{ALGORITHM} ({filename}) = {checksum}\n".
+func lineName(line string) string {
+	if _, name, ok := strings.Cut(line, "  "); ok {
+		return name
+	}
+	if _, rest, ok := strings.Cut(line, " ("); ok {
+		if name, _, ok := strings.Cut(rest, ") = "); ok {
+			return name
+		}
+	}
+	return line
 }
 
 func buildArtifactList(ctx *context.Context) ([]*artifact.Artifact, error) {
 	filter := artifact.And(
 		artifact.ByTypes(artifact.ReleaseUploadableTypes()...),
-		artifact.Not(artifact.ByTypes(artifact.Checksum, artifact.Signature, artifact.Certificate)),
+		artifact.Not(artifact.ByTypes(artifact.Checksum, artifact.Provenance, artifact.Signature, artifact.Certificate)),
 		artifact.ByIDs(ctx.Config.Checksum.IDs...),
 	)
 	artifactList := ctx.Artifacts.Filter(filter).List()
@@ -211,17 +282,53 @@ func buildArtifactList(ctx *context.Context) ([]*artifact.Artifact, error) {
 	return artifactList, nil
 }
 
-func checksums(algorithm string, a *artifact.Artifact) (string, error) {
-	log.WithField("file", a.Name).Debug("checksumming")
-	sha, err := a.Checksum(algorithm)
+func checksums(ctx *context.Context, algorithm string, a *artifact.Artifact) (string, error) {
+	log.WithField("file", a.Name).
+		WithField("algorithm", algorithm).
+		Debug("checksumming")
+	sha, err := sum(ctx, a, algorithm)
 	if err != nil {
 		return "", err
 	}
+	if ctx.Config.Checksum.Format == formatBSD {
+		return bsdLine(algorithm, sha, a.Name), nil
+	}
+	return fmt.Sprintf("%v  %v\n", sha, a.Name), nil
+}
 
+// sum calculates the checksum of the given artifact.
+//
+// Only the checksum with the primary algorithm is set in the artifact extra
+// fields.
+func sum(ctx *context.Context, a *artifact.Artifact, algorithm string) (string, error) {
+	if algorithm != ctx.Config.Checksum.Algorithm {
+		tmp := artifact.Artifact{Name: a.Name, Path: a.Path}
+		return tmp.Checksum(algorithm)
+	}
+	sha, err := a.Checksum(algorithm)
+	if err != nil {
+		return "", err
+	}
 	if a.Extra == nil {
 		a.Extra = make(artifact.Extras)
 	}
 	a.Extra[artifactChecksumExtra] = fmt.Sprintf("%s:%s", algorithm, sha)
+	return sha, nil
+}
 
-	return fmt.Sprintf("%v  %v\n", sha, a.Name), nil
+// bsdLine returns a BSD-style tagged checksum line, as created by
+// 'sha256sum --tag', for example.
+func bsdLine(algorithm, sum, name string) string {
+	return fmt.Sprintf("%s (%s) = %s\n", bsdTag(algorithm), name, sum)
+}
+
+func bsdTag(algorithm string) string {
+	switch algorithm {
+	case "blake2b":
+		return "BLAKE2b"
+	case "blake2s":
+		return "BLAKE2s"
+	default:
+		return strings.ToUpper(algorithm)
+	}
 }
@@ -1,6 +1,7 @@
 package checksums
 
 import (
+	"crypto/sha512"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/testctx"
 	"github.com/goreleaser/goreleaser/v2/internal/testlib"
 	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
 	"github.com/stretchr/testify/require"
 )
 
@@ -562,3 +564,186 @@ func TestChecksumExcludesSignaturesAndCertificates(t *testing.T) {
 	require.NotContains(t, content, "binary.sig", "signatures must not be checksummed")
 	require.NotContains(t, content, "binary.pem", "certificates must not be checksummed")
 }
+
+func TestDefaultAlgorithms(t *testing.T) {
+	t.Run("gnu", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Checksum: config.Checksum{
+				Algorithms: []string{"sha512", "sha256"},
+			},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Equal(t, "sha512", ctx.Config.Checksum.Algorithm)
+		require.Equal(t, "gnu", ctx.Config.Checksum.Format)
+		require.Equal(
+			t,
+			"{{ .ProjectName }}_{{ .Version }}_checksums_{{ .Algorithm }}.txt",
+			ctx.Config.Checksum.NameTemplate,
+		)
+	})
+
+	t.Run("bsd", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Checksum: config.Checksum{
+				Algorithms: []string{"sha512", "sha256"},
+				Format:     "bsd",
+			},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Equal(
+			t,
+			"{{ .ProjectName }}_{{ .Version }}_checksums.txt",
+			ctx.Config.Checksum.NameTemplate,
+		)
+	})
+
+	t.Run("algorithm not in algorithms", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Checksum: config.Checksum{
+				Algorithm:  "md5",
+				Algorithms: []string{"sha512", "sha256"},
+			},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), `checksum: algorithm "md5" must be one of the algorithms: sha512, sha256`)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Checksum: config.Checksum{
+				Format: "nope",
+			},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "checksum: invalid format: nope")
+	})
+}
+
+func newAlgorithmsCtx(tb testing.TB, cs config.Checksum) (*context.Context, string) {
+	tb.Helper()
+	folder := tb.TempDir()
+	file := filepath.Join(folder, "binary")
+	require.NoError(tb, os.WriteFile(file, []byte("some string"), 0o644))
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Checksum:    cs,
+	}, testctx.WithVersion("1.2.3"))
+	require.NoError(tb, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "binary",
+		Path: file,
+		Type: artifact.UploadableBinary,
+	})
+	return ctx, folder
+}
+
+func TestPipeAlgorithms(t *testing.T) {
+	const sha256sum = "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc"
+	sha512sum := fmt.Sprintf("%x", sha512.Sum512([]byte("some string")))
+
+	t.Run("gnu", func(t *testing.T) {
+		ctx, folder := newAlgorithmsCtx(t, config.Checksum{
+			Algorithms: []string{"sha256", "sha512"},
+		})
+		require.NoError(t, Pipe{}.Run(ctx))
+
+		checksums := ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List()
+		require.Len(t, checksums, 2)
+		for _, a := range checksums {
+			require.NoError(t, a.Refresh())
+		}
+
+		bts, err := os.ReadFile(filepath.Join(folder, "foo_1.2.3_checksums_sha256.txt"))
+		require.NoError(t, err)
+		require.Equal(t, sha256sum+"  binary\n", string(bts))
+		bts, err = os.ReadFile(filepath.Join(folder, "foo_1.2.3_checksums_sha512.txt"))
+		require.NoError(t, err)
+		require.Equal(t, sha512sum+"  binary\n", string(bts))
+
+		// only the primary algorithm is set in the artifact
+		bin := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableBinary)).List()[0]
+		require.Equal(t, "sha256:"+sha256sum, artifact.ExtraOr(*bin, artifactChecksumExtra, ""))
+	})
+
+	t.Run("gnu duplicated name", func(t *testing.T) {
+		ctx, _ := newAlgorithmsCtx(t, config.Checksum{
+			NameTemplate: "checksums.txt",
+			Algorithms:   []string{"sha256", "sha512"},
+		})
+		require.ErrorContains(t, Pipe{}.Run(ctx), `got "checksums.txt" more than once`)
+	})
+
+	t.Run("bsd", func(t *testing.T) {
+		ctx, folder := newAlgorithmsCtx(t, config.Checksum{
+			Algorithms: []string{"sha256", "sha512"},
+			Format:     "bsd",
+		})
+		require.NoError(t, Pipe{}.Run(ctx))
+
+		checksums := ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List()
+		require.Len(t, checksums, 1)
+		require.NoError(t, checksums[0].Refresh())
+
+		bts, err := os.ReadFile(filepath.Join(folder, "foo_1.2.3_checksums.txt"))
+		require.NoError(t, err)
+		require.Equal(t, strings.Join([]string{
+			"SHA256 (binary) = " + sha256sum,
+			"SHA512 (binary) = " + sha512sum,
+		}, "\n")+"\n", string(bts))
+	})
+
+	t.Run("split", func(t *testing.T) {
+		ctx, folder := newAlgorithmsCtx(t, config.Checksum{
+			Split:      true,
+			Algorithms: []string{"sha256", "sha512"},
+		})
+		require.NoError(t, Pipe{}.Run(ctx))
+
+		checksums := ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List()
+		require.Len(t, checksums, 2)
+		for _, a := range checksums {
+			require.NoError(t, a.Refresh())
+		}
+
+		bts, err := os.ReadFile(filepath.Join(folder, "binary.sha256"))
+		require.NoError(t, err)
+		require.Equal(t, sha256sum, string(bts))
+		bts, err = os.ReadFile(filepath.Join(folder, "binary.sha512"))
+		require.NoError(t, err)
+		require.Equal(t, sha512sum, string(bts))
+	})
+
+	t.Run("split bsd", func(t *testing.T) {
+		ctx, folder := newAlgorithmsCtx(t, config.Checksum{
+			Split:     true,
+			Algorithm: "sha512",
+			Format:    "bsd",
+		})
+		require.NoError(t, Pipe{}.Run(ctx))
+
+		bts, err := os.ReadFile(filepath.Join(folder, "binary.sha512"))
+		require.NoError(t, err)
+		require.Equal(t, "SHA512 (binary) = "+sha512sum+"\n", string(bts))
+	})
+
+	t.Run("split duplicated name", func(t *testing.T) {
+		ctx, _ := newAlgorithmsCtx(t, config.Checksum{
+			Split:        true,
+			NameTemplate: "{{ .ArtifactName }}.sum",
+			Algorithms:   []string{"sha256", "sha512"},
+		})
+		require.ErrorContains(t, Pipe{}.Run(ctx), `got "binary.sum" more than once`)
+	})
+}
+
+func TestBSDTag(t *testing.T) {
+	for algorithm, tag := range map[string]string{
+		"sha256":   "SHA256",
+		"sha3-256": "SHA3-256",
+		"blake2b":  "BLAKE2b",
+		"blake2s":  "BLAKE2s",
+		"blake3":   "BLAKE3",
+		"crc32":    "CRC32",
+	} {
+		require.Equal(t, tag, bsdTag(algorithm))
+	}
+}
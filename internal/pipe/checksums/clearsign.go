@@ -0,0 +1,74 @@
+package checksums
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/goreleaser/goreleaser/v2/internal/keyfile"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// writer writes checksums files, cleartext signing them if enabled.
+//
+// The signing key is loaded once, as files are written again every time the
+// artifacts are refreshed.
+type writer struct {
+	key  *packet.PrivateKey
+	date time.Time
+}
+
+func newWriter(ctx *context.Context) (writer, error) {
+	key, err := clearSignKey(ctx)
+	if err != nil {
+		return writer{}, fmt.Errorf("checksum: clearsign: %w", err)
+	}
+	return writer{key: key, date: ctx.Date}, nil
+}
+
+// write writes the given checksums file.
+func (cw writer) write(path string, content []byte) error {
+	if cw.key == nil {
+		return os.WriteFile(path, content, 0o644)
+	}
+
+	var buf bytes.Buffer
+	w, err := clearsign.Encode(&buf, cw.key, &packet.Config{
+		Time: func() time.Time { return cw.date },
+	})
+	if err != nil {
+		return fmt.Errorf("checksum: clearsign: %w", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("checksum: clearsign: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("checksum: clearsign: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// clearSignKey returns the OpenPGP signing key, or nil if cleartext signing
+// is disabled.
+func clearSignKey(ctx *context.Context) (*packet.PrivateKey, error) {
+	cfg := ctx.Config.Checksum.ClearSign
+	t := tmpl.New(ctx)
+	enabled, err := t.Bool(cfg.Enabled)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	key, password := cfg.Key, cfg.Password
+	if err := t.ApplyAll(&key, &password); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, errors.New("key is required")
+	}
+	return keyfile.OpenPGPSigningKey(key, password, ctx.Date)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"text/template"
 
 	"github.com/caarlos0/log"
@@ -103,7 +104,10 @@ func (Pipe) Publish(ctx *context.Context) error {
 func doRun(ctx *context.Context, cl client.ReleaseURLTemplater, choco config.Chocolatey) error {
 	filters := []artifact.Filter{
 		artifact.ByGoos("windows"),
-		artifact.ByType(artifact.UploadableArchive),
+		artifact.Or(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.MSI),
+		),
 		artifact.Or(
 			artifact.And(
 				artifact.ByGoarch("amd64"),
@@ -121,6 +125,13 @@ func doRun(ctx *context.Context, cl client.ReleaseURLTemplater, choco config.Cho
 		Filter(artifact.And(filters...)).
 		List()
 
+	// prefer msi installers over archives, if any.
+	if msis := slices.DeleteFunc(slices.Clone(artifacts), func(a *artifact.Artifact) bool {
+		return a.Type != artifact.MSI
+	}); len(msis) > 0 {
+		artifacts = msis
+	}
+
 	if len(artifacts) == 0 {
 		return errNoWindowsArchive
 	}
@@ -147,6 +158,9 @@ func doRun(ctx *context.Context, cl client.ReleaseURLTemplater, choco config.Cho
 	if err != nil {
 		return err
 	}
+	if artifacts[0].Type == artifact.MSI {
+		data.FileType = "msi"
+	}
 
 	script, err := buildTemplate(choco.Name, scriptTemplate, data)
 	if err != nil {
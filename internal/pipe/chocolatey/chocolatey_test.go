@@ -169,6 +169,57 @@ func Test_doRun(t *testing.T) {
 	}
 }
 
+func Test_doRunMSI(t *testing.T) {
+	folder := t.TempDir()
+	file := filepath.Join(folder, "archive")
+	require.NoError(t, os.WriteFile(file, []byte("lorem ipsum"), 0o644))
+
+	cmd = fakeCmd{execFn: func(cmd string, args ...string) ([]byte, error) {
+		checkPackCmd(t, cmd, args...)
+		return []byte("success"), nil
+	}}
+	t.Cleanup(func() {
+		cmd = stdCmd{}
+	})
+
+	ctx := testctx.WrapWithCfg(t.Context(),
+		config.Project{
+			Dist:        folder,
+			ProjectName: "run-all",
+		},
+		testctx.WithCurrentTag("v1.0.1"),
+		testctx.WithVersion("1.0.1"))
+
+	for _, a := range []*artifact.Artifact{
+		{
+			Name:    "app_1.0.1_windows_amd64.zip",
+			Type:    artifact.UploadableArchive,
+			Extra:   map[string]any{artifact.ExtraID: "app", artifact.ExtraFormat: "zip"},
+			Goamd64: "v1",
+		},
+		{
+			Name:    "app_x64.msi",
+			Type:    artifact.MSI,
+			Extra:   map[string]any{artifact.ExtraID: "app", artifact.ExtraFormat: "msi"},
+			Goamd64: "v1",
+		},
+	} {
+		a.Path = file
+		a.Goos = "windows"
+		a.Goarch = "amd64"
+		ctx.Artifacts.Add(a)
+	}
+
+	require.NoError(t, doRun(ctx, client.NewMock(), config.Chocolatey{
+		Name:    "app",
+		Goamd64: "v1",
+	}))
+
+	bts, err := os.ReadFile(filepath.Join(folder, "app.choco", "tools", "chocolateyinstall.ps1"))
+	require.NoError(t, err)
+	golden.RequireEqualExt(t, bts, ".script.ps1")
+}
+
 func Test_buildNuspec(t *testing.T) {
 	ctx := testctx.Wrap(t.Context(), testctx.WithVersion("1.12.3"))
 	choco := config.Chocolatey{
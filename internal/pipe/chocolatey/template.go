@@ -2,6 +2,8 @@ package chocolatey
 
 type templateData struct {
 	Packages []releasePackage
+	// FileType is "msi" when installing msi packages, empty for zip archives.
+	FileType string
 }
 
 type releasePackage struct {
@@ -19,7 +21,13 @@ $toolsDir = "$(Split-Path -parent $MyInvocation.MyCommand.Definition)"
 
 $packageArgs = @{
     packageName    = $packageName
+    {{- if eq .FileType "msi" }}
+    fileType       = 'msi'
+    silentArgs     = '/qn /norestart'
+    validExitCodes = @(0, 3010, 1641)
+    {{- else }}
     unzipLocation  = $toolsDir
+    {{- end }}
     {{- range $release := .Packages }}
     {{- if eq $release.Arch "amd64" }}
     url64bit       = '{{ $release.DownloadURL }}'
@@ -32,6 +40,9 @@ $packageArgs = @{
     {{- end }}
     {{- end }}
 }
-
+{{ if eq .FileType "msi" }}
+Install-ChocolateyPackage @packageArgs
+{{- else }}
 Install-ChocolateyZipPackage @packageArgs
+{{- end }}
 `
@@ -0,0 +1,578 @@
+// Package deploy updates the Kubernetes manifests of a GitOps repository with
+// the digests of the pushed images.
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/client"
+	"github.com/goreleaser/goreleaser/v2/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	kustomizeAPIVersion = "kustomize.config.k8s.io/v1beta1"
+
+	// keyImages is the template field holding the pinned image references.
+	keyImages = "Images"
+)
+
+// Pipe that updates deployment manifests.
+type Pipe struct{}
+
+func (Pipe) String() string { return "deployment manifests" }
+
+func (Pipe) Skip(ctx *context.Context) bool {
+	return skips.Any(ctx, skips.Deploy) || len(ctx.Config.DeployManifests) == 0
+}
+
+// Default sets the Pipes defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("deploy_manifests")
+	for i := range ctx.Config.DeployManifests {
+		m := &ctx.Config.DeployManifests[i]
+		if m.ID == "" {
+			m.ID = ctx.Config.ProjectName
+		}
+		if m.CommitMessageTemplate == "" {
+			m.CommitMessageTemplate = "Deploy {{ .ProjectName }} {{ .Tag }}"
+		}
+		m.CommitAuthor = commitauthor.Default(m.CommitAuthor)
+		ids.Inc(m.ID)
+	}
+	return ids.Validate()
+}
+
+// Publish renders the manifests, and commits them to their repositories.
+// It runs in the publishing phase, as the images are only pushed (and their
+// digests known) then.
+func (Pipe) Publish(ctx *context.Context) error {
+	return publishAll(ctx, nil)
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, cfg := range ctx.Config.DeployManifests {
+		err := doPublish(ctx, cfg, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, cfg config.DeployManifest, cli client.Client) error {
+	tpl := tmpl.New(ctx)
+	disable, err := tpl.Bool(cfg.Disable)
+	if err != nil {
+		return err
+	}
+	if disable {
+		return pipe.Skip("configuration is disabled")
+	}
+
+	images, err := pinnedImages(ctx, cfg.IDs)
+	if err != nil {
+		return fmt.Errorf("deploy_manifests: %s: %w", cfg.ID, err)
+	}
+	if len(images) == 0 {
+		return pipe.Skipf("deploy_manifests: %s: no images to deploy", cfg.ID)
+	}
+
+	fcli, repo, err := repoClient(ctx, &cfg, cli)
+	if err != nil {
+		return err
+	}
+
+	files, err := render(ctx, cfg, images, func(path string) ([]byte, error) {
+		return currentFile(ctx, fcli, repo, path)
+	})
+	if err != nil {
+		return fmt.Errorf("deploy_manifests: %s: %w", cfg.ID, err)
+	}
+	if len(files) == 0 {
+		return pipe.Skipf("deploy_manifests: %s: no templates or kustomize set", cfg.ID)
+	}
+
+	dist := filepath.Join(ctx.Config.Dist, "deploy", cfg.ID)
+	for _, file := range files {
+		dst := filepath.Join(dist, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return fmt.Errorf("deploy_manifests: %w", err)
+		}
+		if err := os.WriteFile(dst, file.Content, 0o644); err != nil {
+			return fmt.Errorf("deploy_manifests: %w", err)
+		}
+		log.WithField("id", cfg.ID).
+			WithField("path", file.Path).
+			Info("rendered manifest")
+	}
+
+	if fcli == nil {
+		return pipe.Skipf("deploy_manifests: %s: no repository set", cfg.ID)
+	}
+	return publishRepository(ctx, cfg, repo, fcli, files)
+}
+
+// pinnedImages returns the digest of each pushed image repository, e.g.
+// ghcr.io/user/repo: sha256:abc.
+func pinnedImages(ctx *context.Context, ids []string) (map[string]string, error) {
+	filters := []artifact.Filter{
+		artifact.ByTypes(
+			artifact.DockerImageV2,
+			artifact.DockerImage,
+			artifact.DockerManifest,
+		),
+	}
+	if len(ids) > 0 {
+		filters = append(filters, artifact.ByIDs(ids...))
+	}
+
+	arts := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	manifests := map[string]bool{}
+	for _, img := range arts {
+		if img.Type == artifact.DockerManifest {
+			manifests[repository(img.Name)] = true
+		}
+	}
+
+	images := map[string]string{}
+	for _, img := range arts {
+		repo := repository(img.Name)
+		// the per-platform images of a docker manifest are deployed through
+		// the manifest.
+		if img.Type == artifact.DockerImage && manifests[repo] {
+			continue
+		}
+		digest := artifact.ExtraOr(*img, artifact.ExtraDigest, "")
+		if digest == "" {
+			log.WithField("image", img.Name).Warn("image has no digest, ignoring")
+			continue
+		}
+		if prev, ok := images[repo]; ok && prev != digest {
+			return nil, fmt.Errorf("image %s was pushed with different digests (%s and %s), use ids to select which one to deploy", repo, prev, digest)
+		}
+		images[repo] = digest
+	}
+	return images, nil
+}
+
+// repository returns the given image reference without its tag and digest.
+func repository(ref string) string {
+	ref, _, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// render renders the templates and the Kustomization file of the given
+// configuration, validating them.
+// The current contents of the Kustomization file are read with the given
+// function.
+func render(ctx *context.Context, cfg config.DeployManifest, images map[string]string, current func(path string) ([]byte, error)) ([]client.RepoFile, error) {
+	pinned := map[string]string{}
+	for repo, digest := range images {
+		pinned[repo] = repo + "@" + digest
+	}
+	tpl := tmpl.New(ctx).WithExtraFields(tmpl.Fields{
+		keyImages: pinned,
+	})
+
+	var files []client.RepoFile
+	for _, t := range cfg.Templates {
+		src, dst := t.Src, t.Dst
+		if err := tpl.ApplyAll(&src, &dst); err != nil {
+			return nil, err
+		}
+		if dst == "" {
+			dst = path.Base(filepath.ToSlash(src))
+		}
+		bts, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("could not read template: %w", err)
+		}
+		content, err := tpl.Apply(string(bts))
+		if err != nil {
+			return nil, err
+		}
+		if err := validate([]byte(content), images); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", src, err)
+		}
+		files = append(files, client.RepoFile{
+			Content: []byte(content),
+			Path:    path.Clean(dst),
+		})
+	}
+
+	if cfg.Kustomize.Path != "" {
+		file, err := renderKustomization(tpl, cfg.Kustomize, images, current)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+type kustomization struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Resources  []string         `yaml:"resources,omitempty"`
+	Images     []kustomizeImage `yaml:"images"`
+}
+
+type kustomizeImage struct {
+	Name   string `yaml:"name"`
+	Digest string `yaml:"digest"`
+}
+
+// renderKustomization updates the images of the current Kustomization file,
+// or creates a new one if there's none.
+func renderKustomization(
+	tpl *tmpl.Template,
+	cfg config.DeployManifestKustomize,
+	images map[string]string,
+	current func(path string) ([]byte, error),
+) (client.RepoFile, error) {
+	dst := cfg.Path
+	if err := tpl.ApplyAll(&dst); err != nil {
+		return client.RepoFile{}, err
+	}
+	dst = path.Clean(dst)
+	resources := slices.Clone(cfg.Resources)
+	if err := tpl.ApplySlice(&resources, tmpl.NonEmpty()); err != nil {
+		return client.RepoFile{}, err
+	}
+
+	content, err := current(dst)
+	if err != nil {
+		return client.RepoFile{}, err
+	}
+	if len(bytes.TrimSpace(content)) > 0 {
+		content, err = updateKustomization(content, images)
+		if err != nil {
+			return client.RepoFile{}, fmt.Errorf("invalid %s: %w", dst, err)
+		}
+		return client.RepoFile{Content: content, Path: dst}, nil
+	}
+
+	k := kustomization{
+		APIVersion: kustomizeAPIVersion,
+		Kind:       "Kustomization",
+		Resources:  resources,
+	}
+	for _, repo := range slices.Sorted(maps.Keys(images)) {
+		k.Images = append(k.Images, kustomizeImage{
+			Name:   repo,
+			Digest: images[repo],
+		})
+	}
+	content, err = encode(k)
+	if err != nil {
+		return client.RepoFile{}, err
+	}
+	return client.RepoFile{Content: content, Path: dst}, nil
+}
+
+// updateKustomization sets the digests of the given images in the `images`
+// of a Kustomization file, leaving everything else as is.
+//
+// An entry matches an image if either its name or its newName is the image
+// repository.
+// Entries are added for the images that have none.
+func updateKustomization(content []byte, images map[string]string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("not a mapping")
+	}
+	root := doc.Content[0]
+
+	entries := field(root, "images")
+	if entries == nil {
+		entries = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "images"},
+			entries,
+		)
+	}
+	if entries.Kind != yaml.SequenceNode {
+		return nil, errors.New("images is not a list")
+	}
+
+	for _, repo := range slices.Sorted(maps.Keys(images)) {
+		var found bool
+		for _, entry := range entries.Content {
+			if !isImageEntry(entry, repo) {
+				continue
+			}
+			found = true
+			setScalar(entry, "digest", images[repo])
+			// the digest is what's deployed, a tag would only be misleading.
+			removeKey(entry, "newTag")
+		}
+		if found {
+			continue
+		}
+		entry := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setScalar(entry, "name", repo)
+		setScalar(entry, "digest", images[repo])
+		entries.Content = append(entries.Content, entry)
+	}
+	return encode(&doc)
+}
+
+// isImageEntry reports whether the given Kustomization images entry is the
+// one of the given repository.
+func isImageEntry(entry *yaml.Node, repo string) bool {
+	for _, key := range []string{"name", "newName"} {
+		if v := field(entry, key); v != nil && v.Kind == yaml.ScalarNode && v.Value == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// setScalar sets the given key of a mapping node to a string.
+func setScalar(node *yaml.Node, key, value string) {
+	if v := field(node, key); v != nil {
+		*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+		return
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// removeKey removes the given key from a mapping node.
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = slices.Delete(node.Content, i, i+2)
+			return
+		}
+	}
+}
+
+func encode(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// validate checks that all the documents in the given manifest are
+// Kubernetes objects, and that the images that were pushed are referenced by
+// digest.
+func validate(content []byte, images map[string]string) error {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	var errs []error
+	for i := 1; ; i++ {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue
+		}
+		for _, problem := range validateObject(doc.Content[0], images) {
+			errs = append(errs, fmt.Errorf("document %d: %s", i, problem))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateObject(node *yaml.Node, images map[string]string) []string {
+	if node.Kind != yaml.MappingNode {
+		return []string{"not a Kubernetes object"}
+	}
+	var problems []string
+	for _, key := range []string{"apiVersion", "kind"} {
+		if v := field(node, key); v == nil || v.Kind != yaml.ScalarNode || v.Value == "" {
+			problems = append(problems, "missing "+key)
+		}
+	}
+	// Kustomize files are not objects, and have no name.
+	if v := field(node, "apiVersion"); v == nil || !strings.HasPrefix(v.Value, "kustomize.config.k8s.io/") {
+		if name := field(field(node, "metadata"), "name"); name == nil || name.Kind != yaml.ScalarNode || name.Value == "" {
+			problems = append(problems, "missing metadata.name")
+		}
+	}
+	walk(node, func(key, value *yaml.Node) {
+		if key.Value != "image" || value.Kind != yaml.ScalarNode {
+			return
+		}
+		if _, ok := images[repository(value.Value)]; ok && !strings.Contains(value.Value, "@sha256:") {
+			problems = append(problems, fmt.Sprintf("image %s is not pinned by digest", value.Value))
+		}
+	})
+	return problems
+}
+
+// field returns the value of the given key in a mapping node, or nil.
+func field(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// walk calls fn for every key and value of all the mappings in the node.
+func walk(node *yaml.Node, fn func(key, value *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fn(node.Content[i], node.Content[i+1])
+		}
+	}
+	for _, child := range node.Content {
+		walk(child, fn)
+	}
+}
+
+// repoClient returns the client to read and write the files of the
+// repository of the given configuration, and the repository itself.
+// The client is nil if no repository is set.
+//
+// The fork is synced first if a pull request is to be opened, so the files
+// are read from an up to date branch.
+func repoClient(ctx *context.Context, cfg *config.DeployManifest, cli client.Client) (client.FileCreator, client.Repo, error) {
+	if cfg.Repository.Name == "" && cfg.Repository.Git.URL == "" {
+		return nil, client.Repo{}, nil
+	}
+	ref, err := client.TemplateRef(tmpl.New(ctx).Apply, cfg.Repository)
+	if err != nil {
+		return nil, client.Repo{}, err
+	}
+	cfg.Repository = ref
+	repo := client.RepoFromRef(cfg.Repository)
+
+	if cfg.Repository.Git.URL != "" {
+		return client.NewGitUploadClient(repo.Branch), repo, nil
+	}
+
+	cli, err = client.NewIfToken(ctx, cli, cfg.Repository.Token)
+	if err != nil {
+		return nil, client.Repo{}, err
+	}
+
+	// try to sync branch
+	fscli, ok := cli.(client.ForkSyncer)
+	if ok && cfg.Repository.PullRequest.Enabled {
+		if err := fscli.SyncFork(ctx, repo, pullRequestBase(cfg.Repository)); err != nil {
+			log.WithError(err).Warn("could not sync fork")
+		}
+	}
+	return cli, repo, nil
+}
+
+func pullRequestBase(ref config.RepoRef) client.Repo {
+	return client.Repo{
+		Name:   ref.PullRequest.Base.Name,
+		Owner:  ref.PullRequest.Base.Owner,
+		Branch: ref.PullRequest.Base.Branch,
+	}
+}
+
+// currentFile reads the given file from the repository, returning nil if it
+// doesn't exist, or if there's no repository.
+func currentFile(ctx *context.Context, fcli client.FileCreator, repo client.Repo, path string) ([]byte, error) {
+	if fcli == nil {
+		return nil, nil
+	}
+	getter, ok := fcli.(client.FileGetter)
+	if !ok {
+		return nil, errors.New("client does not support reading files")
+	}
+	bts, err := getter.GetFile(ctx, repo, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return bts, nil
+}
+
+func publishRepository(ctx *context.Context, cfg config.DeployManifest, repo client.Repo, fcli client.FileCreator, files []client.RepoFile) error {
+	tpl := tmpl.New(ctx)
+	msg, err := tpl.Apply(cfg.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+	author, err := commitauthor.Get(ctx, cfg.CommitAuthor)
+	if err != nil {
+		return err
+	}
+
+	if mcli, ok := fcli.(client.FilesCreator); ok {
+		if err := mcli.CreateFiles(ctx, author, repo, msg, files); err != nil {
+			return err
+		}
+	} else {
+		for _, file := range files {
+			if err := fcli.CreateFile(ctx, author, repo, file.Content, file.Path, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.Repository.Git.URL != "" {
+		return nil
+	}
+	if !cfg.Repository.PullRequest.Enabled {
+		log.Debug("deploy_manifests.pull_request disabled")
+		return nil
+	}
+
+	log.Info("deploy_manifests.pull_request enabled, creating a PR")
+	cli, _ := fcli.(client.Client)
+	prcl, err := client.NewIfToken(ctx, cli, cfg.Repository.PullRequest.Token)
+	if err != nil {
+		return err
+	}
+	pcl, ok := prcl.(client.PullRequestOpener)
+	if !ok {
+		return errors.New("client does not support pull requests")
+	}
+	return pcl.OpenPullRequest(ctx, pullRequestBase(cfg.Repository), repo, msg, cfg.Repository.PullRequest.Draft)
+}
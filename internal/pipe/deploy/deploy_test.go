@@ -0,0 +1,441 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/client"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	digestApp    = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	digestWorker = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+const deploymentYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: {{ index .Images "ghcr.io/foo/app" }}
+        - name: sidecar
+          image: docker.io/library/nginx:1.27
+`
+
+func TestString(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			DeployManifests: []config.DeployManifest{{}},
+		}, testctx.Skip(skips.Deploy))
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+	t.Run("no config", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(testctx.Wrap(t.Context())))
+	})
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			DeployManifests: []config.DeployManifest{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName:     "myapp",
+		DeployManifests: []config.DeployManifest{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	cfg := ctx.Config.DeployManifests[0]
+	require.Equal(t, "myapp", cfg.ID)
+	require.Equal(t, "Deploy {{ .ProjectName }} {{ .Tag }}", cfg.CommitMessageTemplate)
+	require.NotEmpty(t, cfg.CommitAuthor.Name)
+	require.NotEmpty(t, cfg.CommitAuthor.Email)
+
+	t.Run("duplicated ids", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			ProjectName:     "myapp",
+			DeployManifests: []config.DeployManifest{{}, {}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "found 2 deploy_manifests with the ID 'myapp', please fix your config")
+	})
+}
+
+func TestPublish(t *testing.T) {
+	ctx := newContext(t, config.DeployManifest{
+		Templates: []config.DeployManifestTemplate{{
+			Src: "{{ .Env.TEMPLATE }}",
+			Dst: "apps/{{ .ProjectName }}/deployment.yaml",
+		}},
+		Kustomize: config.DeployManifestKustomize{
+			Path:      "overlays/prod/kustomization.yaml",
+			Resources: []string{"../../base", "{{ if .IsSnapshot }}nope{{ end }}"},
+		},
+		Repository: config.RepoRef{
+			Owner: "foo",
+			Name:  "gitops",
+			PullRequest: config.PullRequest{
+				Enabled: true,
+			},
+		},
+	})
+
+	cli := client.NewMock()
+	require.NoError(t, publishAll(ctx, cli))
+	require.True(t, cli.CreatedFile)
+	require.True(t, cli.SyncedFork)
+	require.True(t, cli.OpenedPullRequest)
+	require.Equal(t, []string{
+		"Deploy myapp v1.2.3",
+		"Deploy myapp v1.2.3",
+	}, cli.Messages)
+
+	kustomization := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - ../../base
+images:
+  - name: ghcr.io/foo/app
+    digest: ` + digestApp + `
+  - name: ghcr.io/foo/worker
+    digest: ` + digestWorker + `
+`
+	require.Equal(t, "overlays/prod/kustomization.yaml", cli.Path)
+	require.Equal(t, kustomization, cli.Content)
+
+	dist := filepath.Join(ctx.Config.Dist, "deploy", "myapp")
+	bts, err := os.ReadFile(filepath.Join(dist, "overlays", "prod", "kustomization.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, kustomization, string(bts))
+	bts, err = os.ReadFile(filepath.Join(dist, "apps", "myapp", "deployment.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "image: ghcr.io/foo/app@"+digestApp+"\n")
+	require.Contains(t, string(bts), "image: docker.io/library/nginx:1.27\n")
+}
+
+func TestPublishIDs(t *testing.T) {
+	ctx := newContext(t, config.DeployManifest{
+		IDs:       []string{"worker"},
+		Kustomize: config.DeployManifestKustomize{Path: "kustomization.yaml"},
+		Repository: config.RepoRef{
+			Owner: "foo",
+			Name:  "gitops",
+		},
+	})
+	cli := client.NewMock()
+	require.NoError(t, publishAll(ctx, cli))
+	require.False(t, cli.OpenedPullRequest)
+	require.Equal(t, "kustomization.yaml", cli.Path)
+	require.NotContains(t, cli.Content, "ghcr.io/foo/app")
+	require.Contains(t, cli.Content, "ghcr.io/foo/worker")
+}
+
+func TestPublishExistingKustomization(t *testing.T) {
+	ctx := newContext(t, config.DeployManifest{
+		Kustomize: config.DeployManifestKustomize{
+			Path:      "overlays/prod/kustomization.yaml",
+			Resources: []string{"ignored"},
+		},
+		Repository: config.RepoRef{
+			Owner: "foo",
+			Name:  "gitops",
+		},
+	})
+
+	cli := client.NewMock()
+	cli.Files = map[string]string{
+		"overlays/prod/kustomization.yaml": `# production overlay
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: prod
+resources:
+  - ../../base
+images:
+  - name: docker.io/library/nginx
+    newTag: "1.27"
+  # the app
+  - name: ghcr.io/foo/app
+    newTag: v1.0.0
+  - name: worker
+    newName: ghcr.io/foo/worker
+    digest: sha256:0000000000000000000000000000000000000000000000000000000000000000
+`,
+	}
+	require.NoError(t, publishAll(ctx, cli))
+	require.Equal(t, "overlays/prod/kustomization.yaml", cli.Path)
+	require.Equal(t, `# production overlay
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: prod
+resources:
+  - ../../base
+images:
+  - name: docker.io/library/nginx
+    newTag: "1.27"
+  # the app
+  - name: ghcr.io/foo/app
+    digest: `+digestApp+`
+  - name: worker
+    newName: ghcr.io/foo/worker
+    digest: `+digestWorker+`
+`, cli.Content)
+
+	t.Run("no images", func(t *testing.T) {
+		ctx := newContext(t, config.DeployManifest{
+			Kustomize:  config.DeployManifestKustomize{Path: "kustomization.yaml"},
+			Repository: config.RepoRef{Owner: "foo", Name: "gitops"},
+		})
+		cli := client.NewMock()
+		cli.Files = map[string]string{
+			"kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\n",
+		}
+		require.NoError(t, publishAll(ctx, cli))
+		require.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+images:
+  - name: ghcr.io/foo/app
+    digest: `+digestApp+`
+  - name: ghcr.io/foo/worker
+    digest: `+digestWorker+`
+`, cli.Content)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for content, msg := range map[string]string{
+			"- foo\n":      "not a mapping",
+			"images: {}\n": "images is not a list",
+			"{{{":          "yaml: ",
+		} {
+			ctx := newContext(t, config.DeployManifest{
+				Kustomize:  config.DeployManifestKustomize{Path: "kustomization.yaml"},
+				Repository: config.RepoRef{Owner: "foo", Name: "gitops"},
+			})
+			cli := client.NewMock()
+			cli.Files = map[string]string{"kustomization.yaml": content}
+			err := publishAll(ctx, cli)
+			require.ErrorContains(t, err, "deploy_manifests: myapp: invalid kustomization.yaml: "+msg)
+		}
+	})
+}
+
+func TestPinnedImagesDockerManifests(t *testing.T) {
+	ctx := testctx.Wrap(t.Context())
+	for _, img := range []struct {
+		name   string
+		typ    artifact.Type
+		digest string
+	}{
+		{"ghcr.io/foo/app:v1.2.3-amd64", artifact.DockerImage, "sha256:amd64"},
+		{"ghcr.io/foo/app:v1.2.3-arm64", artifact.DockerImage, "sha256:arm64"},
+		{"ghcr.io/foo/app:v1.2.3", artifact.DockerManifest, digestApp},
+		{"ghcr.io/foo/app:latest", artifact.DockerManifest, digestApp},
+		{"ghcr.io/foo/worker:v1.2.3", artifact.DockerImage, digestWorker},
+	} {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:  img.name,
+			Path:  img.name,
+			Type:  img.typ,
+			Extra: map[string]any{artifact.ExtraDigest: img.digest},
+		})
+	}
+	images, err := pinnedImages(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"ghcr.io/foo/app":    digestApp,
+		"ghcr.io/foo/worker": digestWorker,
+	}, images)
+}
+
+func TestPublishSkips(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		ctx := newContext(t, config.DeployManifest{Disable: "true"})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("no images", func(t *testing.T) {
+		ctx := newContext(t, config.DeployManifest{
+			IDs:       []string{"nope"},
+			Kustomize: config.DeployManifestKustomize{Path: "kustomization.yaml"},
+		})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("nothing to render", func(t *testing.T) {
+		ctx := newContext(t, config.DeployManifest{})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("no repository", func(t *testing.T) {
+		ctx := newContext(t, config.DeployManifest{
+			Kustomize: config.DeployManifestKustomize{Path: "kustomization.yaml"},
+		})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+		require.FileExists(t, filepath.Join(ctx.Config.Dist, "deploy", "myapp", "kustomization.yaml"))
+	})
+}
+
+func TestPublishErrors(t *testing.T) {
+	repo := config.RepoRef{Owner: "foo", Name: "gitops"}
+	kustomize := config.DeployManifestKustomize{Path: "kustomization.yaml"}
+
+	t.Run("conflicting digests", func(t *testing.T) {
+		ctx := newContext(t, config.DeployManifest{Kustomize: kustomize})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: "ghcr.io/foo/app:latest-debug",
+			Type: artifact.DockerImageV2,
+			Extra: map[string]any{
+				artifact.ExtraID:     "app-debug",
+				artifact.ExtraDigest: digestWorker,
+			},
+		})
+		require.ErrorContains(t, Pipe{}.Publish(ctx), "image ghcr.io/foo/app was pushed with different digests")
+	})
+	t.Run("missing template", func(t *testing.T) {
+		ctx := newContext(t, config.DeployManifest{
+			Templates: []config.DeployManifestTemplate{{Src: "nope.yaml"}},
+		})
+		require.ErrorContains(t, Pipe{}.Publish(ctx), "deploy_manifests: myapp: could not read template")
+	})
+	t.Run("invalid manifest", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "deployment.yaml")
+		require.NoError(t, os.WriteFile(src, []byte("kind: Deployment\n"), 0o644))
+		ctx := newContext(t, config.DeployManifest{
+			Templates: []config.DeployManifestTemplate{{Src: src}},
+		})
+		require.ErrorContains(t, Pipe{}.Publish(ctx), "invalid manifest "+src+": document 1: missing apiVersion")
+	})
+	for name, cfg := range map[string]config.DeployManifest{
+		"disable":            {Disable: "{{ .Nope }}"},
+		"template src":       {Templates: []config.DeployManifestTemplate{{Src: "{{ .Nope }}"}}},
+		"template dst":       {Templates: []config.DeployManifestTemplate{{Src: "a.yaml", Dst: "{{ .Nope }}"}}},
+		"kustomize path":     {Kustomize: config.DeployManifestKustomize{Path: "{{ .Nope }}"}},
+		"kustomize resource": {Kustomize: config.DeployManifestKustomize{Path: "k.yaml", Resources: []string{"{{ .Nope }}"}}},
+		"repository":         {Kustomize: kustomize, Repository: config.RepoRef{Owner: "{{ .Nope }}", Name: "gitops"}},
+		"commit message":     {Kustomize: kustomize, Repository: repo, CommitMessageTemplate: "{{ .Nope }}"},
+	} {
+		t.Run("template "+name, func(t *testing.T) {
+			ctx := newContext(t, cfg)
+			testlib.RequireTemplateError(t, publishAll(ctx, client.NewMock()))
+		})
+	}
+	t.Run("template content", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "deployment.yaml")
+		require.NoError(t, os.WriteFile(src, []byte("image: {{ .Nope }}\n"), 0o644))
+		ctx := newContext(t, config.DeployManifest{
+			Templates: []config.DeployManifestTemplate{{Src: src}},
+		})
+		testlib.RequireTemplateError(t, Pipe{}.Publish(ctx))
+	})
+}
+
+func TestRepository(t *testing.T) {
+	for ref, repo := range map[string]string{
+		"ghcr.io/foo/app":                   "ghcr.io/foo/app",
+		"ghcr.io/foo/app:v1.2.3":            "ghcr.io/foo/app",
+		"ghcr.io/foo/app@sha256:abc":        "ghcr.io/foo/app",
+		"ghcr.io/foo/app:v1.2.3@sha256:abc": "ghcr.io/foo/app",
+		"localhost:5000/app":                "localhost:5000/app",
+		"localhost:5000/app:latest":         "localhost:5000/app",
+		"nginx":                             "nginx",
+	} {
+		t.Run(ref, func(t *testing.T) {
+			require.Equal(t, repo, repository(ref))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	images := map[string]string{"ghcr.io/foo/app": digestApp}
+	for name, tt := range map[string]struct {
+		content string
+		errs    []string
+	}{
+		"valid": {
+			content: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n---\n# empty\n",
+		},
+		"kustomization": {
+			content: "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources: [base]\n",
+		},
+		"not pinned": {
+			content: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: foo\nspec:\n  containers:\n    - image: ghcr.io/foo/app:v1.2.3\n",
+			errs:    []string{"document 1: image ghcr.io/foo/app:v1.2.3 is not pinned by digest"},
+		},
+		"missing fields": {
+			content: "apiVersion: v1\nkind: ConfigMap\n---\nmetadata:\n  name: foo\n",
+			errs: []string{
+				"document 1: missing metadata.name",
+				"document 2: missing apiVersion",
+				"document 2: missing kind",
+			},
+		},
+		"not an object": {
+			content: "- foo\n- bar\n",
+			errs:    []string{"document 1: not a Kubernetes object"},
+		},
+		"invalid yaml": {
+			content: "{{{",
+			errs:    []string{"yaml: "},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validate([]byte(tt.content), images)
+			if len(tt.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, e := range tt.errs {
+				require.ErrorContains(t, err, e)
+			}
+		})
+	}
+}
+
+func newContext(tb testing.TB, cfg config.DeployManifest) *context.Context {
+	tb.Helper()
+	src := filepath.Join(tb.TempDir(), "deployment.yaml")
+	require.NoError(tb, os.WriteFile(src, []byte(deploymentYAML), 0o644))
+
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		ProjectName:     "myapp",
+		Dist:            tb.TempDir(),
+		DeployManifests: []config.DeployManifest{cfg},
+	},
+		testctx.WithCurrentTag("v1.2.3"),
+		testctx.WithVersion("1.2.3"),
+		testctx.WithEnv(map[string]string{"TEMPLATE": src}),
+	)
+	require.NoError(tb, Pipe{}.Default(ctx))
+
+	for _, img := range []struct {
+		name, id, digest string
+	}{
+		{"ghcr.io/foo/app:v1.2.3", "app", digestApp},
+		{"ghcr.io/foo/app:latest", "app", digestApp},
+		{"ghcr.io/foo/worker:v1.2.3", "worker", digestWorker},
+		{"ghcr.io/foo/nodigest:v1.2.3", "app", ""},
+	} {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: img.name,
+			Path: img.name,
+			Type: artifact.DockerImageV2,
+			Extra: map[string]any{
+				artifact.ExtraID:     img.id,
+				artifact.ExtraDigest: img.digest,
+			},
+		})
+	}
+	return ctx
+}
@@ -5,11 +5,19 @@ package dist
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 
 	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
 )
 
+// CacheDir is the directory inside the distribution directory that is kept
+// when cleaning it if a cache is configured, so caches (e.g. docker build
+// caches) survive across runs.
+const CacheDir = "cache"
+
 // CleanPipe cleans the distribution directory.
 type CleanPipe struct{}
 
@@ -20,7 +28,51 @@ func (CleanPipe) Run(ctx *context.Context) error {
 	// this is needed because when this run, the defaults are not set yet
 	// there's no good way of handling this...
 	_ = Pipe{}.Default(ctx)
-	return os.RemoveAll(ctx.Config.Dist)
+	if !hasCache(ctx) {
+		return os.RemoveAll(ctx.Config.Dist)
+	}
+	files, err := os.ReadDir(ctx.Config.Dist)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if isCacheDir(file) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(ctx.Config.Dist, file.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasCache reports whether any cache inside the cache directory is
+// configured.
+//
+// This runs before the defaults and the git state are set, so a cache
+// directory that can't be templated yet is assumed to be inside it, so it is
+// never removed by mistake.
+func hasCache(ctx *context.Context) bool {
+	for _, d := range ctx.Config.DockersV2 {
+		tpl := tmpl.New(ctx).WithExtraFields(tmpl.Fields{
+			"ID":       d.ID,
+			"Platform": "",
+		})
+		for _, cache := range slices.Concat(d.CacheFrom, d.CacheTo) {
+			dir, err := tpl.Apply(cache.Dir)
+			if err != nil || (dir != "" && !filepath.IsAbs(dir)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isCacheDir(file os.DirEntry) bool {
+	return file.IsDir() && file.Name() == CacheDir
 }
 
 // Pipe for dist.
@@ -45,6 +97,9 @@ func (Pipe) Run(ctx *context.Context) error {
 	if err != nil {
 		return err
 	}
+	if hasCache(ctx) {
+		files = slices.DeleteFunc(files, isCacheDir)
+	}
 	if len(files) != 0 {
 		log.Debugf("there are %d files on %s", len(files), ctx.Config.Dist)
 		return fmt.Errorf(
@@ -69,3 +69,61 @@ func TestCleanSetDist(t *testing.T) {
 	require.NoError(t, CleanPipe{}.Run(ctx))
 	require.Equal(t, "dist", ctx.Config.Dist)
 }
+
+func TestCleanKeepsCache(t *testing.T) {
+	dist := filepath.Join(t.TempDir(), "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, CacheDir, "docker"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "foo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "mybin"), nil, 0o644))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Dist: dist,
+		DockersV2: []config.DockerV2{{
+			CacheTo: []config.DockerV2Cache{{Dir: "docker"}},
+		}},
+	})
+	require.Error(t, Pipe{}.Run(ctx))
+	require.NoError(t, CleanPipe{}.Run(ctx))
+	require.DirExists(t, filepath.Join(dist, CacheDir, "docker"))
+	require.NoDirExists(t, filepath.Join(dist, "foo"))
+	require.NoFileExists(t, filepath.Join(dist, "mybin"))
+	require.NoError(t, Pipe{}.Run(ctx))
+}
+
+func TestCleanCacheNotConfigured(t *testing.T) {
+	dist := filepath.Join(t.TempDir(), "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, CacheDir, "docker"), 0o755))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Dist: dist,
+		DockersV2: []config.DockerV2{{
+			CacheTo: []config.DockerV2Cache{
+				{Ref: "ghcr.io/foo/bar:cache"},
+				{Dir: "{{ .Env.CACHE_DIR }}"},
+			},
+		}},
+	}, testctx.WithEnv(map[string]string{"CACHE_DIR": t.TempDir()}))
+	require.Error(t, Pipe{}.Run(ctx))
+	require.NoError(t, CleanPipe{}.Run(ctx))
+	require.NoDirExists(t, dist)
+	require.NoError(t, Pipe{}.Run(ctx))
+}
+
+func TestCleanTemplatedCache(t *testing.T) {
+	dist := filepath.Join(t.TempDir(), "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, CacheDir, "docker"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "mybin"), nil, 0o644))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Dist: dist,
+		DockersV2: []config.DockerV2{{
+			CacheFrom: []config.DockerV2Cache{
+				{Dir: "{{ .Env.CACHE_DIR }}"},
+				{Dir: "docker-{{ .Platform }}"},
+			},
+		}},
+	}, testctx.WithEnv(map[string]string{"CACHE_DIR": t.TempDir()}))
+	require.NoError(t, CleanPipe{}.Run(ctx))
+	require.DirExists(t, filepath.Join(dist, CacheDir, "docker"))
+	require.NoFileExists(t, filepath.Join(dist, "mybin"))
+}
@@ -0,0 +1,188 @@
+// Package dmg implements the Pipe interface creating macOS disk images.
+package dmg
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/extrafiles"
+	"github.com/goreleaser/goreleaser/v2/internal/gio"
+	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/iso9660"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/internal/udif"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const defaultNameTemplate = `{{ .ProjectName }}_{{ .Arch }}`
+
+// Pipe for macOS disk images.
+type Pipe struct{}
+
+func (Pipe) String() string { return "macOS disk images" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return skips.Any(ctx, skips.MacOSDMG) || len(ctx.Config.MacOSDMGs) == 0
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("macos_dmgs")
+	for i := range ctx.Config.MacOSDMGs {
+		dmg := &ctx.Config.MacOSDMGs[i]
+		if dmg.ID == "" {
+			dmg.ID = ctx.Config.ProjectName
+		}
+		if dmg.Name == "" {
+			dmg.Name = defaultNameTemplate
+		}
+		if dmg.Goamd64 == "" {
+			dmg.Goamd64 = "v1"
+		}
+		ids.Inc(dmg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, cfg := range ctx.Config.MacOSDMGs {
+		g.Go(func() error {
+			return doRun(ctx, cfg)
+		})
+	}
+	return g.Wait()
+}
+
+func doRun(ctx *context.Context, cfg config.MacOSDMG) error {
+	groups := map[string][]*artifact.Artifact{}
+	for _, a := range ctx.Artifacts.Filter(artifact.And(
+		artifact.ByGoos("darwin"),
+		artifact.ByTypes(artifact.Binary, artifact.UniversalBinary),
+		artifact.ByIDs(cfg.IDs...),
+		artifact.ByGoamd64OrOtherArch(cfg.Goamd64),
+	)).List() {
+		groups[a.Goarch] = append(groups[a.Goarch], a)
+	}
+	if len(groups) == 0 {
+		return fmt.Errorf("no darwin binaries found for builds %v", cfg.IDs)
+	}
+
+	extraFiles, err := extrafiles.Find(ctx, cfg.ExtraFiles)
+	if err != nil {
+		return err
+	}
+
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, binaries := range groups {
+		g.Go(func() error {
+			return create(ctx, cfg, binaries, extraFiles)
+		})
+	}
+	return g.Wait()
+}
+
+func create(ctx *context.Context, cfg config.MacOSDMG, binaries []*artifact.Artifact, extraFiles map[string]string) error {
+	binary := binaries[0]
+	tpl := tmpl.New(ctx).WithArtifact(binary)
+
+	name := cfg.Name
+	modTimestamp := cfg.ModTimestamp
+	if err := tpl.ApplyAll(&name, &modTimestamp); err != nil {
+		return err
+	}
+	mtime, err := gio.ModTime(modTimestamp, ctx.Date)
+	if err != nil {
+		return err
+	}
+
+	filename := name + ".dmg"
+	path := filepath.Join(ctx.Config.Dist, filename)
+	log.WithField("image", path).Info("creating")
+
+	files := make([]iso9660.File, 0, len(binaries)+len(extraFiles))
+	for _, bin := range binaries {
+		files = append(files, iso9660.File{
+			Name:    bin.Name,
+			Mode:    0o755,
+			ModTime: mtime,
+			Path:    bin.Path,
+		})
+	}
+	for _, dst := range slices.Sorted(maps.Keys(extraFiles)) {
+		src := extraFiles[dst]
+		info, err := os.Stat(src)
+		if err != nil {
+			return fmt.Errorf("could not read extra file: %w", err)
+		}
+		files = append(files, iso9660.File{
+			Name:    dst,
+			Mode:    info.Mode().Perm(),
+			ModTime: mtime,
+			Path:    src,
+		})
+	}
+
+	if err := os.MkdirAll(ctx.Config.Dist, 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// the filesystem is compressed into the image as it is written, so
+	// neither is ever fully kept in memory.
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(iso9660.Write(pw, name, files, mtime))
+	}()
+	err = udif.Write(out, pr, "ISO")
+	_ = pr.CloseWithError(err)
+	if err != nil {
+		return fmt.Errorf("could not create image: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := gio.Chtimes(path, modTimestamp); err != nil {
+		return err
+	}
+
+	if cfg.Replace {
+		if err := ctx.Artifacts.Remove(artifact.And(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByGoos("darwin"),
+			artifact.ByGoarch(binary.Goarch),
+			artifact.ByGoamd64OrOtherArch(cfg.Goamd64),
+		)); err != nil {
+			return err
+		}
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:    artifact.DMG,
+		Name:    filename,
+		Path:    path,
+		Goos:    binary.Goos,
+		Goarch:  binary.Goarch,
+		Goamd64: binary.Goamd64,
+		Target:  binary.Target,
+		Extra: map[string]any{
+			artifact.ExtraID:     cfg.ID,
+			artifact.ExtraFormat: "dmg",
+			artifact.ExtraExt:    ".dmg",
+		},
+	})
+	return nil
+}
@@ -0,0 +1,159 @@
+package dmg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(testctx.Wrap(t.Context())))
+	})
+	t.Run("skip flag", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			MacOSDMGs: []config.MacOSDMG{{}},
+		}, testctx.Skip(skips.MacOSDMG))
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			MacOSDMGs: []config.MacOSDMG{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		MacOSDMGs:   []config.MacOSDMG{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.MacOSDMG{
+		ID:      "foo",
+		Name:    defaultNameTemplate,
+		Goamd64: "v1",
+	}, ctx.Config.MacOSDMGs[0])
+}
+
+func TestDefaultDuplicatedID(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		MacOSDMGs:   []config.MacOSDMG{{}, {}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 macos_dmgs with the ID 'foo', please fix your config")
+}
+
+func TestRun(t *testing.T) {
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		MacOSDMGs: []config.MacOSDMG{{
+			Name:         "{{ .ProjectName }}_{{ .Version }}_{{ .Arch }}",
+			ExtraFiles:   []config.ExtraFile{{Glob: "./testdata/README.md"}},
+			Replace:      true,
+			ModTimestamp: "{{ .CommitTimestamp }}",
+		}},
+	}, testctx.WithVersion("1.2.3"), testctx.WithCommitDate(time.Unix(1704164645, 0)))
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo-bin", "darwin_amd64", "darwin_arm64", "darwin_all", "linux_amd64")
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	images := ctx.Artifacts.Filter(artifact.ByType(artifact.DMG)).List()
+	require.Len(t, images, 3)
+	for _, img := range images {
+		require.Equal(t, "darwin", img.Goos)
+		require.Equal(t, "foo_1.2.3_"+img.Goarch+".dmg", img.Name)
+		require.Equal(t, filepath.Join(dist, img.Name), img.Path)
+		require.Equal(t, "foo", artifact.ExtraOr(*img, artifact.ExtraID, ""))
+		require.Equal(t, "dmg", artifact.ExtraOr(*img, artifact.ExtraFormat, ""))
+		require.Equal(t, ".dmg", artifact.ExtraOr(*img, artifact.ExtraExt, ""))
+
+		info, err := os.Stat(img.Path)
+		require.NoError(t, err)
+		require.Equal(t, int64(1704164645), info.ModTime().Unix())
+
+		bts, err := os.ReadFile(img.Path)
+		require.NoError(t, err)
+		require.Equal(t, "koly", string(bts[len(bts)-512:len(bts)-508]))
+		require.Contains(t, string(bts), "whole disk (ISO : 0)")
+	}
+
+	// only the linux archive is kept.
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "linux", archives[0].Goos)
+
+	t.Run("reproducible", func(t *testing.T) {
+		path := filepath.Join(dist, "foo_1.2.3_arm64.dmg")
+		before, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, Pipe{}.Run(ctx))
+		after, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+}
+
+func TestRunErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		dmg config.MacOSDMG
+		err string
+	}{
+		"bad name": {
+			dmg: config.MacOSDMG{Name: "{{ .Nope }"},
+			err: "template: failed to apply",
+		},
+		"invalid mod_timestamp": {
+			dmg: config.MacOSDMG{ModTimestamp: "nope"},
+			err: "invalid mod_timestamp",
+		},
+		"bad extra files": {
+			dmg: config.MacOSDMG{ExtraFiles: []config.ExtraFile{{Glob: "{{ .Nope }"}}},
+			err: "failed to apply template to glob",
+		},
+		"no binaries": {
+			dmg: config.MacOSDMG{IDs: []string{"nope"}},
+			err: "no darwin binaries found for builds [nope]",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dist := t.TempDir()
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+				ProjectName: "foo",
+				Dist:        dist,
+				MacOSDMGs:   []config.MacOSDMG{tc.dmg},
+			})
+			testlib.AddBinaries(t, ctx.Artifacts, dist, "foo-bin", "darwin_amd64", "darwin_arm64", "darwin_all", "linux_amd64")
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.ErrorContains(t, Pipe{}.Run(ctx), tc.err)
+		})
+	}
+}
+
+func TestRunMissingBinary(t *testing.T) {
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		MacOSDMGs:   []config.MacOSDMG{{}},
+	})
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo-bin", "darwin_arm64")
+	require.NoError(t, os.Remove(ctx.Artifacts.List()[0].Path))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.ErrorIs(t, Pipe{}.Run(ctx), os.ErrNotExist)
+}
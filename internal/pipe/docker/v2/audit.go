@@ -0,0 +1,379 @@
+package docker
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/docker/go-units"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/goreleaser/goreleaser/v2/internal/oci"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// maxScanSize is the maximum size of a file scanned for secrets.
+const maxScanSize = 64 * 1024 * 1024
+
+// secretPatterns are the patterns of secrets that should never be in an
+// image.
+var secretPatterns = map[string]*regexp.Regexp{
+	"private key":      regexp.MustCompile(`-----BEGIN (RSA |EC |DSA |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY( BLOCK)?-----`),
+	"aws access key":   regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+	"github token":     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{82})\b`),
+	"gitlab token":     regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`),
+	"slack token":      regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`),
+	"google api key":   regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+	"stripe live key":  regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`),
+	"npm access token": regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`),
+}
+
+// auditRules are the checks done on each image of an index.
+type auditRules struct {
+	nonRoot bool
+	secrets map[string]*regexp.Regexp
+	labels  []string
+	maxSize int64
+}
+
+// auditAndPush checks the images in the OCI layout in the given directory
+// against the audit rules, and pushes that same layout if they pass, so
+// images that fail them are never pushed.
+//
+// It returns the digest of the pushed manifest.
+func auditAndPush(ctx *context.Context, d config.DockerV2, dir string, da dockerArgs) (string, error) {
+	tpl := tmpl.New(ctx)
+	rules, pinned, err := makeAuditRules(tpl, d.Audit)
+	if err != nil {
+		return "", fmt.Errorf("dockers_v2.audit: %w", err)
+	}
+	labels, findings, err := auditLabels(tpl, d.Labels)
+	if err != nil {
+		return "", fmt.Errorf("dockers_v2.audit: %w", err)
+	}
+	rules.labels = append(rules.labels, labels...)
+	slices.Sort(rules.labels)
+	rules.labels = slices.Compact(rules.labels)
+
+	if pinned {
+		findings = append(findings, auditBaseImage(da.baseImage)...)
+	}
+
+	log.WithField("id", d.ID).Info("auditing images")
+	layoutFindings, err := auditLayout(dir, rules)
+	if err != nil {
+		return "", fmt.Errorf("dockers_v2.audit: %w", err)
+	}
+	findings = append(findings, layoutFindings...)
+	if len(findings) > 0 {
+		return "", fmt.Errorf(
+			"dockers_v2.audit: %s: %d problem(s) found:\n  - %s",
+			d.ID, len(findings), strings.Join(findings, "\n  - "),
+		)
+	}
+	return pushLayout(ctx, dir, da.images)
+}
+
+func makeAuditRules(tpl *tmpl.Template, cfg config.DockerV2Audit) (auditRules, bool, error) {
+	var rules auditRules
+	var err error
+	if rules.nonRoot, err = tpl.Bool(cfg.NonRoot); err != nil {
+		return rules, false, err
+	}
+	rules.labels = slices.Clone(cfg.RequiredLabels)
+	secrets, err := tpl.Bool(cfg.Secrets)
+	if err != nil {
+		return rules, false, err
+	}
+	if secrets {
+		rules.secrets = maps.Clone(secretPatterns)
+		for _, pattern := range cfg.SecretPatterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return rules, false, fmt.Errorf("invalid secret pattern: %w", err)
+			}
+			rules.secrets[pattern] = re
+		}
+	}
+	maxSize, err := tpl.Apply(cfg.MaxSize)
+	if err != nil {
+		return rules, false, err
+	}
+	if maxSize != "" {
+		if rules.maxSize, err = units.FromHumanSize(maxSize); err != nil {
+			return rules, false, fmt.Errorf("invalid max_size: %w", err)
+		}
+	}
+	pinned, err := tpl.Bool(cfg.PinnedBaseImage)
+	return rules, pinned, err
+}
+
+// auditLabels templates the labels set in the configuration, and returns the
+// ones the images must have, along with a finding for each of them that is
+// empty, as those are never set in the image.
+func auditLabels(tpl *tmpl.Template, labels map[string]string) ([]string, []string, error) {
+	var keys, findings []string
+	for k, v := range labels {
+		if err := tpl.ApplyAll(&k, &v); err != nil {
+			return nil, nil, fmt.Errorf("invalid labels: %w", err)
+		}
+		if strings.TrimSpace(k) == "" {
+			continue
+		}
+		if strings.TrimSpace(v) == "" {
+			findings = append(findings, fmt.Sprintf("label %s is empty", k))
+			continue
+		}
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	slices.Sort(findings)
+	return keys, findings, nil
+}
+
+// pushLayout pushes the manifest of the OCI layout in the given directory, as
+// written by buildx, to all the given images.
+func pushLayout(ctx *context.Context, dir string, images []string) (string, error) {
+	idx, err := layout.ImageIndexFromPath(dir)
+	if err != nil {
+		return "", fmt.Errorf("dockers_v2: could not read image layout: %w", err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return "", err
+	}
+	if len(manifest.Manifests) != 1 {
+		return "", fmt.Errorf("dockers_v2: expected a single manifest in the image layout, got %d", len(manifest.Manifests))
+	}
+	desc := manifest.Manifests[0]
+
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(oci.Keychain),
+		remote.WithJobs(ctx.Parallelism),
+	}
+	var write func(ref name.Reference) error
+	switch {
+	case desc.MediaType.IsIndex():
+		child, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return "", err
+		}
+		write = func(ref name.Reference) error { return remote.WriteIndex(ref, child, opts...) }
+	case desc.MediaType.IsImage():
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return "", err
+		}
+		write = func(ref name.Reference) error { return remote.Write(ref, img, opts...) }
+	default:
+		return "", fmt.Errorf("dockers_v2: unexpected media type in the image layout: %s", desc.MediaType)
+	}
+
+	for _, image := range images {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return "", fmt.Errorf("dockers_v2: invalid image %q: %w", image, err)
+		}
+		log.WithField("image", ref.String()).
+			WithField("digest", desc.Digest.String()).
+			Info("pushing")
+		if err := write(ref); err != nil {
+			return "", fmt.Errorf("dockers_v2: could not push %s: %w", ref, err)
+		}
+	}
+	return desc.Digest.String(), nil
+}
+
+// auditBaseImage checks that the base image is pinned by digest.
+func auditBaseImage(base dockerImage) []string {
+	if base.name == "" || strings.Contains(base.name, "@sha256:") {
+		return nil
+	}
+	if base.digest == "" {
+		return []string{fmt.Sprintf("base image %s is not pinned by digest", base.name)}
+	}
+	return []string{fmt.Sprintf(
+		"base image %s is not pinned by digest, use %s@%s instead",
+		base.name, base.name, base.digest,
+	)}
+}
+
+// auditLayout checks all the images in the OCI layout in the given
+// directory, skipping attestations.
+func auditLayout(dir string, rules auditRules) ([]string, error) {
+	idx, err := layout.ImageIndexFromPath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read image layout: %w", err)
+	}
+	return auditIndex(idx, rules)
+}
+
+func auditIndex(idx v1.ImageIndex, rules auditRules) ([]string, error) {
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var findings []string
+	for _, desc := range manifest.Manifests {
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			childFindings, err := auditIndex(child, rules)
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, childFindings...)
+		case desc.MediaType.IsImage():
+			if isAttestation(desc) {
+				continue
+			}
+			img, err := idx.Image(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			platform := "unknown"
+			if desc.Platform != nil {
+				platform = desc.Platform.String()
+			}
+			imgFindings, err := auditPlatformImage(img, rules)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", platform, err)
+			}
+			for _, finding := range imgFindings {
+				findings = append(findings, platform+": "+finding)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// isAttestation reports whether the given descriptor is a buildx attestation
+// manifest.
+func isAttestation(desc v1.Descriptor) bool {
+	return desc.Annotations["vnd.docker.reference.type"] == "attestation-manifest" ||
+		(desc.Platform != nil && desc.Platform.OS == "unknown")
+}
+
+// auditPlatformImage checks a single platform image against the rules.
+func auditPlatformImage(img v1.Image, rules auditRules) ([]string, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	var findings []string
+	if rules.nonRoot && isRoot(cfg.Config.User) {
+		findings = append(findings, "runs as root, set a non-root USER")
+	}
+	for _, k := range rules.labels {
+		if cfg.Config.Labels[k] == "" {
+			findings = append(findings, fmt.Sprintf("missing label %s", k))
+		}
+	}
+	if rules.maxSize > 0 {
+		size, err := imageSize(img)
+		if err != nil {
+			return nil, err
+		}
+		if size > rules.maxSize {
+			findings = append(findings, fmt.Sprintf(
+				"size is %s, larger than the maximum of %s",
+				units.HumanSize(float64(size)), units.HumanSize(float64(rules.maxSize)),
+			))
+		}
+	}
+	if len(rules.secrets) > 0 {
+		secrets, err := findSecrets(img, rules.secrets)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, secrets...)
+	}
+	return findings, nil
+}
+
+// isRoot reports whether the given USER is root, which is the default if it
+// is not set.
+func isRoot(user string) bool {
+	user, _, _ = strings.Cut(strings.TrimSpace(user), ":")
+	return user == "" || user == "root" || user == "0"
+}
+
+// imageSize returns the compressed size of the image, as pushed.
+func imageSize(img v1.Image) (int64, error) {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return 0, err
+	}
+	size := manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size, nil
+}
+
+// findSecrets scans the files in the image layers for secrets.
+func findSecrets(img v1.Image, patterns map[string]*regexp.Regexp) ([]string, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	names := slices.Sorted(maps.Keys(patterns))
+	var findings []string
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, err
+		}
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return nil, err
+		}
+		layerFindings, err := scanLayer(rc, names, patterns)
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not scan layer %s: %w", digest, err)
+		}
+		findings = append(findings, layerFindings...)
+	}
+	return findings, nil
+}
+
+func scanLayer(r io.Reader, names []string, patterns map[string]*regexp.Regexp) ([]string, error) {
+	var findings []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return findings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxScanSize {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if patterns[name].Match(content) {
+				findings = append(findings, fmt.Sprintf("/%s contains a possible secret (%s)", strings.TrimPrefix(hdr.Name, "/"), name))
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package docker
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/dist"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// Template fields exposed to cache_from and cache_to.
+const (
+	keyID       = "ID"
+	keyPlatform = "Platform"
+)
+
+// cacheFlags returns the --cache-from and --cache-to flags of the given
+// image.
+// Caches are evaluated once for each platform, so a multi-platform build
+// imports the caches of all the single-platform (e.g. snapshot) builds.
+//
+// Local caches are always stored per platform, as the single-platform builds
+// run in parallel, and would otherwise export to the same directory.
+func cacheFlags(ctx *context.Context, tpl *tmpl.Template, d config.DockerV2) ([]string, error) {
+	var from, to []string
+	for _, platform := range d.Platforms {
+		tpl := tpl.WithExtraFields(tmpl.Fields{
+			keyID:       d.ID,
+			keyPlatform: platform,
+		})
+		for _, cache := range d.CacheFrom {
+			spec, err := cacheSpec(ctx, tpl, cache, platform, false)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cache_from: %w", err)
+			}
+			if spec != "" && !slices.Contains(from, spec) {
+				from = append(from, spec)
+			}
+		}
+		for _, cache := range d.CacheTo {
+			spec, err := cacheSpec(ctx, tpl, cache, platform, true)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cache_to: %w", err)
+			}
+			if spec != "" && !slices.Contains(to, spec) {
+				to = append(to, spec)
+			}
+		}
+	}
+
+	var flags []string
+	for _, spec := range from {
+		flags = append(flags, "--cache-from", spec)
+	}
+	for _, spec := range to {
+		flags = append(flags, "--cache-to", spec)
+	}
+	return flags, nil
+}
+
+// cacheSpec returns the buildx cache spec of the given cache, or an empty
+// string if it should not be used.
+func cacheSpec(ctx *context.Context, tpl *tmpl.Template, cache config.DockerV2Cache, platform string, export bool) (string, error) {
+	dir, ref, mode := cache.Dir, cache.Ref, cache.Mode
+	if err := tpl.ApplyAll(&dir, &ref, &mode); err != nil {
+		return "", err
+	}
+	if dir == "" && ref == "" {
+		return "", nil
+	}
+	if dir != "" && ref != "" {
+		return "", errors.New("dir and ref are mutually exclusive")
+	}
+	mode = cmp.Or(mode, "max")
+	if mode != "min" && mode != "max" {
+		return "", fmt.Errorf("invalid mode %q, must be either min or max", mode)
+	}
+
+	if ref != "" {
+		if export {
+			return "type=registry,ref=" + ref + ",mode=" + mode, nil
+		}
+		return "type=registry,ref=" + ref, nil
+	}
+
+	// builds run in a temporary directory, so the path must be absolute.
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(ctx.Config.Dist, dist.CacheDir, dir)
+	}
+	if !strings.Contains(cache.Dir, "."+keyPlatform) {
+		dir = filepath.Join(dir, strings.ReplaceAll(platform, "/", "-"))
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if export {
+		return "type=local,dest=" + dir + ",mode=" + mode, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		log.WithField("dir", dir).Debug("build cache not found, ignoring")
+		return "", nil
+	}
+	return "type=local,src=" + dir, nil
+}
@@ -0,0 +1,167 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheFlags(t *testing.T) {
+	dist := t.TempDir()
+	cache := filepath.Join(dist, "cache")
+	for _, dir := range []string{"myimg/linux-amd64", "myimg/linux-arm64"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(cache, dir), 0o755))
+	}
+	abs := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(abs, "linux", "amd64"), 0o755))
+
+	for name, tt := range map[string]struct {
+		platforms []string
+		from, to  []config.DockerV2Cache
+		expect    []string
+	}{
+		"none": {
+			platforms: []string{"linux/amd64"},
+		},
+		"local": {
+			platforms: []string{"linux/amd64"},
+			from:      []config.DockerV2Cache{{Dir: `{{ .ID }}/{{ replace .Platform "/" "-" }}`}},
+			to:        []config.DockerV2Cache{{Dir: `{{ .ID }}/{{ replace .Platform "/" "-" }}`}},
+			expect: []string{
+				"--cache-from", "type=local,src=" + filepath.Join(cache, "myimg/linux-amd64"),
+				"--cache-to", "type=local,dest=" + filepath.Join(cache, "myimg/linux-amd64") + ",mode=max",
+			},
+		},
+		"local multi-platform": {
+			platforms: []string{"linux/amd64", "linux/arm64", "linux/386"},
+			from:      []config.DockerV2Cache{{Dir: `{{ .ID }}/{{ replace .Platform "/" "-" }}`}},
+			to:        []config.DockerV2Cache{{Dir: `{{ .ID }}/all`, Mode: "min"}},
+			expect: []string{
+				"--cache-from", "type=local,src=" + filepath.Join(cache, "myimg/linux-amd64"),
+				"--cache-from", "type=local,src=" + filepath.Join(cache, "myimg/linux-arm64"),
+				"--cache-to", "type=local,dest=" + filepath.Join(cache, "myimg/all/linux-amd64") + ",mode=min",
+				"--cache-to", "type=local,dest=" + filepath.Join(cache, "myimg/all/linux-arm64") + ",mode=min",
+				"--cache-to", "type=local,dest=" + filepath.Join(cache, "myimg/all/linux-386") + ",mode=min",
+			},
+		},
+		"local per platform by default": {
+			platforms: []string{"linux/arm64"},
+			from:      []config.DockerV2Cache{{Dir: `{{ .ID }}`}},
+			to:        []config.DockerV2Cache{{Dir: `{{ .ID }}`}},
+			expect: []string{
+				"--cache-from", "type=local,src=" + filepath.Join(cache, "myimg/linux-arm64"),
+				"--cache-to", "type=local,dest=" + filepath.Join(cache, "myimg/linux-arm64") + ",mode=max",
+			},
+		},
+		"local absolute": {
+			platforms: []string{"linux/amd64"},
+			from:      []config.DockerV2Cache{{Dir: abs + `/{{ .Platform }}`}},
+			expect: []string{
+				"--cache-from", "type=local,src=" + filepath.Join(abs, "linux/amd64"),
+			},
+		},
+		"registry": {
+			platforms: []string{"linux/amd64", "linux/arm64"},
+			from: []config.DockerV2Cache{
+				{Ref: "ghcr.io/foo/cache:{{ .ID }}"},
+				{Ref: `{{ if .IsSnapshot }}ghcr.io/foo/cache:snapshot{{ end }}`},
+			},
+			to: []config.DockerV2Cache{{Ref: "ghcr.io/foo/cache:{{ .ID }}"}},
+			expect: []string{
+				"--cache-from", "type=registry,ref=ghcr.io/foo/cache:myimg",
+				"--cache-to", "type=registry,ref=ghcr.io/foo/cache:myimg,mode=max",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{Dist: dist})
+			flags, err := cacheFlags(ctx, tmpl.New(ctx), config.DockerV2{
+				ID:        "myimg",
+				Platforms: tt.platforms,
+				CacheFrom: tt.from,
+				CacheTo:   tt.to,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, flags)
+		})
+	}
+}
+
+func TestCacheFlagsErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		from, to []config.DockerV2Cache
+		err      string
+	}{
+		"dir and ref": {
+			from: []config.DockerV2Cache{{Dir: "foo", Ref: "ghcr.io/foo/cache"}},
+			err:  "invalid cache_from: dir and ref are mutually exclusive",
+		},
+		"invalid mode": {
+			to:  []config.DockerV2Cache{{Ref: "ghcr.io/foo/cache", Mode: "all"}},
+			err: `invalid cache_to: invalid mode "all", must be either min or max`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := testctx.Wrap(t.Context())
+			_, err := cacheFlags(ctx, tmpl.New(ctx), config.DockerV2{
+				Platforms: []string{"linux/amd64"},
+				CacheFrom: tt.from,
+				CacheTo:   tt.to,
+			})
+			require.EqualError(t, err, tt.err)
+		})
+	}
+	for name, cache := range map[string]config.DockerV2Cache{
+		"dir":  {Dir: "{{ .Nope }}"},
+		"ref":  {Ref: "{{ .Nope }}"},
+		"mode": {Ref: "foo", Mode: "{{ .Nope }}"},
+	} {
+		t.Run("template "+name, func(t *testing.T) {
+			ctx := testctx.Wrap(t.Context())
+			_, err := cacheFlags(ctx, tmpl.New(ctx), config.DockerV2{
+				Platforms: []string{"linux/amd64"},
+				CacheTo:   []config.DockerV2Cache{cache},
+			})
+			testlib.RequireTemplateError(t, err)
+		})
+	}
+}
+
+func TestMakeArgsCache(t *testing.T) {
+	ctx := testctx.Wrap(t.Context())
+	da, err := makeArgs(ctx, config.DockerV2{
+		ID:        "myimg",
+		Images:    []string{"ghcr.io/foo/bar"},
+		Tags:      []string{"latest"},
+		Platforms: []string{"linux/amd64"},
+		CacheFrom: []config.DockerV2Cache{{Ref: "ghcr.io/foo/bar:cache"}},
+		CacheTo:   []config.DockerV2Cache{{Ref: "ghcr.io/foo/bar:cache"}},
+		Flags:     []string{"--progress=plain"},
+	}, []string{"--push"})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"buildx", "build",
+		"--platform", "linux/amd64",
+		"-t", "ghcr.io/foo/bar:latest",
+		"--push",
+		"--iidfile=id.txt",
+		"--cache-from", "type=registry,ref=ghcr.io/foo/bar:cache",
+		"--cache-to", "type=registry,ref=ghcr.io/foo/bar:cache,mode=max",
+		"--progress=plain",
+		".",
+	}, da.args)
+
+	_, err = makeArgs(ctx, config.DockerV2{
+		Images:    []string{"ghcr.io/foo/bar"},
+		Tags:      []string{"latest"},
+		Platforms: []string{"linux/amd64"},
+		CacheTo:   []config.DockerV2Cache{{Ref: "{{ .Nope }}"}},
+	}, nil)
+	testlib.RequireTemplateError(t, err)
+}
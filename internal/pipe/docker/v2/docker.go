@@ -19,6 +19,7 @@ import (
 	"github.com/caarlos0/log"
 	"github.com/goreleaser/go-shellwords"
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/channel"
 	"github.com/goreleaser/goreleaser/v2/internal/deprecate"
 	"github.com/goreleaser/goreleaser/v2/internal/gerrors"
 	"github.com/goreleaser/goreleaser/v2/internal/gio"
@@ -107,6 +108,15 @@ func (Base) Default(ctx *context.Context) error {
 		if docker.SBOM == "" {
 			docker.SBOM = "true"
 		}
+		audit, err := tmpl.New(ctx).Bool(docker.Audit.Enabled)
+		if err != nil {
+			return fmt.Errorf("dockers_v2.audit: %w", err)
+		}
+		if audit {
+			docker.Audit.NonRoot = cmp.Or(docker.Audit.NonRoot, "true")
+			docker.Audit.Secrets = cmp.Or(docker.Audit.Secrets, "true")
+			docker.Audit.PinnedBaseImage = cmp.Or(docker.Audit.PinnedBaseImage, "true")
+		}
 		if docker.Retry.Attempts > 0 ||
 			docker.Retry.MaxDelay > 0 ||
 			docker.Retry.Delay > 0 {
@@ -139,7 +149,7 @@ func (p Snapshot) Run(ctx *context.Context) error {
 			// not running on a docker daemon, `--load` won't work, and
 			// without it, images will have `--output=type=cacheonly`.
 			g.Go(func() error {
-				return buildImage(ctx, d)
+				return buildImage(ctx, d, false)
 			})
 			continue
 		}
@@ -150,7 +160,7 @@ func (p Snapshot) Run(ctx *context.Context) error {
 			g.Go(func() error {
 				d := d
 				d.Platforms = []string{plat}
-				return buildImage(ctx, d, "--load")
+				return buildImage(ctx, d, false, "--load")
 			})
 		}
 	}
@@ -163,11 +173,15 @@ func (p Publish) Publish(ctx *context.Context) error {
 	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
 	for _, d := range ctx.Config.DockersV2 {
 		g.Go(func() error {
+			// snapshots are never pushed, so channels only apply here.
+			if !channel.Includes(ctx, d.Channels) {
+				return pipe.Skipf("release channel %q is not in %v", channel.Of(ctx), d.Channels)
+			}
 			extraArgs, err := p.extraArgs(ctx, d)
 			if err != nil {
 				return fmt.Errorf("dockers_v2.sbom: %w", err)
 			}
-			return buildImage(ctx, d, extraArgs...)
+			return buildImage(ctx, d, true, extraArgs...)
 		})
 	}
 	return g.Wait()
@@ -178,14 +192,18 @@ func (Publish) extraArgs(ctx *context.Context, d config.DockerV2) ([]string, err
 	if err != nil {
 		return nil, fmt.Errorf("dockers_v2.sbom: %w", err)
 	}
-	extraArgs := []string{"--push"}
+	var extraArgs []string
 	if sbom {
 		extraArgs = append(extraArgs, "--attest=type=sbom")
 	}
 	return extraArgs, nil
 }
 
-func buildImage(ctx *context.Context, d config.DockerV2, extraArgs ...string) error {
+// buildImage builds the given image, and pushes it if push is true.
+//
+// If the audit is enabled, the pushed images are built into a local OCI
+// layout instead, which is audited and then pushed as is.
+func buildImage(ctx *context.Context, d config.DockerV2, push bool, extraArgs ...string) error {
 	tpl := tmpl.New(ctx)
 	if err := tpl.ApplySlice(&d.Platforms, tmpl.NonEmpty()); err != nil {
 		return err
@@ -202,6 +220,25 @@ func buildImage(ctx *context.Context, d config.DockerV2, extraArgs ...string) er
 		return pipe.Skip("configuration is disabled")
 	}
 
+	var audit bool
+	if push {
+		if audit, err = tpl.Bool(d.Audit.Enabled); err != nil {
+			return fmt.Errorf("dockers_v2.audit: %w", err)
+		}
+	}
+	var layoutDir string
+	switch {
+	case audit:
+		layoutDir, err = os.MkdirTemp("", "goreleaserdockeraudit")
+		if err != nil {
+			return fmt.Errorf("dockers_v2.audit: %w", err)
+		}
+		defer os.RemoveAll(layoutDir)
+		extraArgs = append(extraArgs, "--output=type=oci,tar=false,dest="+layoutDir)
+	case push:
+		extraArgs = append(extraArgs, "--push")
+	}
+
 	da, err := makeArgs(ctx, d, extraArgs)
 	if err != nil {
 		return err
@@ -230,6 +267,11 @@ func buildImage(ctx *context.Context, d config.DockerV2, extraArgs ...string) er
 	if err != nil {
 		return err
 	}
+	if audit {
+		if digest, err = auditAndPush(ctx, d, layoutDir, da); err != nil {
+			return err
+		}
+	}
 
 	hookFields[keyDigest] = digest
 	if err := runHook(ctx, hookFields, d.Hooks.Post); err != nil {
@@ -315,6 +357,7 @@ type dockerArgs struct {
 	dockerfile string
 	args       []string
 	images     []string
+	baseImage  dockerImage
 }
 
 func makeArgs(ctx *context.Context, d config.DockerV2, extraArgs []string) (dockerArgs, error) {
@@ -387,6 +430,11 @@ func makeArgs(ctx *context.Context, d config.DockerV2, extraArgs []string) (dock
 		return dockerArgs{}, fmt.Errorf("invalid flags: %w", err)
 	}
 
+	cacheArgs, err := cacheFlags(ctx, tpl, d)
+	if err != nil {
+		return dockerArgs{}, err
+	}
+
 	arg := []string{
 		"buildx",
 		"build",
@@ -400,12 +448,14 @@ func makeArgs(ctx *context.Context, d config.DockerV2, extraArgs []string) (dock
 	arg = append(arg, labelFlags...)
 	arg = append(arg, annotationFlags...)
 	arg = append(arg, buildFlags...)
+	arg = append(arg, cacheArgs...)
 	arg = append(arg, flags...)
 	arg = append(arg, ".")
 	return dockerArgs{
 		dockerfile: dockerfile,
 		args:       arg,
 		images:     allImages,
+		baseImage:  baseImg,
 	}, nil
 }
 
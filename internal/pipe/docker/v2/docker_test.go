@@ -125,14 +125,14 @@ func TestPublishExtraArgs(t *testing.T) {
 			SBOM: "{{ .IsSnapshot }}",
 		})
 		require.NoError(t, err)
-		require.Equal(t, []string{"--push"}, args)
+		require.Empty(t, args)
 	})
 	t.Run("sbom enabled", func(t *testing.T) {
 		args, err := Publish{}.extraArgs(ctx, config.DockerV2{
 			SBOM: "{{ not .IsSnapshot }}",
 		})
 		require.NoError(t, err)
-		require.Equal(t, []string{"--push", "--attest=type=sbom"}, args)
+		require.Equal(t, []string{"--attest=type=sbom"}, args)
 	})
 	t.Run("tmpl err", func(t *testing.T) {
 		_, err := Publish{}.extraArgs(ctx, config.DockerV2{
@@ -373,6 +373,21 @@ func TestDisable(t *testing.T) {
 	})
 }
 
+func TestPublishChannels(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		DockersV2: []config.DockerV2{
+			{
+				Images:   []string{"ghcr.io/foo/bar"},
+				Channels: []string{"stable"},
+			},
+		},
+	}, testctx.WithSemver(1, 0, 0, "beta.1"))
+	require.NoError(t, Base{}.Default(ctx))
+	err := Publish{}.Publish(ctx)
+	testlib.AssertSkipped(t, err)
+	require.ErrorContains(t, err, `release channel "beta" is not in [stable]`)
+}
+
 func TestIsDockerDaemonAvailableNoDaemon(t *testing.T) {
 	t.Setenv("DOCKER_HOST", "unix:///nonexistent.sock")
 	require.False(t, isDockerDaemonAvailable(t.Context()))
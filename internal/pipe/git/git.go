@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/calver"
 	"github.com/goreleaser/goreleaser/v2/internal/git"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
 	"github.com/goreleaser/goreleaser/v2/internal/skips"
@@ -19,6 +20,9 @@ import (
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
 )
 
+// tagSortCalVer sorts tags by their calendar version, newest first.
+const tagSortCalVer = "calver"
+
 // Pipe that sets up git state.
 type Pipe struct{}
 
@@ -31,6 +35,9 @@ func (Pipe) String() string {
 func setDefaults(ctx *context.Context) {
 	if ctx.Config.Git.TagSort == "" {
 		ctx.Config.Git.TagSort = "-version:refname"
+		if ctx.Config.Versioning.Mode == "calver" {
+			ctx.Config.Git.TagSort = tagSortCalVer
+		}
 	}
 }
 
@@ -40,6 +47,11 @@ func (Pipe) Run(ctx *context.Context) error {
 		return ErrNoGit
 	}
 	setDefaults(ctx)
+	if ctx.Config.Git.TagSort == tagSortCalVer {
+		if _, err := calver.ParseScheme(ctx.Config.Versioning.Scheme); err != nil {
+			return err
+		}
+	}
 	info, err := getInfo(ctx)
 	if err != nil {
 		return err
@@ -295,6 +307,9 @@ func getPreviousTag(ctx *context.Context, current string, excluding []string) (s
 	for _, fn := range []func() ([]string, error){
 		getFromEnv("GORELEASER_PREVIOUS_TAG"),
 		func() ([]string, error) {
+			if ctx.Config.Git.TagSort == tagSortCalVer {
+				return previousCalVerTags(ctx, current)
+			}
 			sha, err := previousTagSha(ctx, current, excluding)
 			if err != nil {
 				return nil, err
@@ -315,6 +330,14 @@ func getPreviousTag(ctx *context.Context, current string, excluding []string) (s
 }
 
 func gitTagsPointingAt(ctx *context.Context, ref string) ([]string, error) {
+	if ctx.Config.Git.TagSort == tagSortCalVer {
+		tags, err := git.CleanAllLines(git.Run(ctx, "tag", "--points-at", ref))
+		if err != nil {
+			return nil, err
+		}
+		return sortCalVer(ctx, tags)
+	}
+
 	args := []string{}
 	if ctx.Config.Git.PrereleaseSuffix != "" {
 		args = append(
@@ -334,6 +357,91 @@ func gitTagsPointingAt(ctx *context.Context, ref string) ([]string, error) {
 	return git.CleanAllLines(git.Run(ctx, args...))
 }
 
+// sortCalVer sorts the given tags from newest to oldest calendar version.
+// Tags that are not valid for the configured scheme are kept at the end.
+func sortCalVer(ctx *context.Context, tags []string) ([]string, error) {
+	scheme, err := calver.ParseScheme(ctx.Config.Versioning.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	versions := map[string]calver.Version{}
+	for _, tag := range tags {
+		if v, err := scheme.Parse(tag); err == nil {
+			versions[tag] = v
+		}
+	}
+	slices.SortStableFunc(tags, func(a, b string) int {
+		va, oka := versions[a]
+		vb, okb := versions[b]
+		switch {
+		case oka && okb:
+			return compareCalVer(ctx, vb, va)
+		case oka:
+			return -1
+		case okb:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return tags, nil
+}
+
+// previousCalVerTags returns the tags reachable from the parent of the
+// current tag that have a lower calendar version than it, newest first.
+func previousCalVerTags(ctx *context.Context, current string) ([]string, error) {
+	scheme, err := calver.ParseScheme(ctx.Config.Versioning.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	cv, err := scheme.Parse(current)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := git.CleanAllLines(git.Run(ctx, "tag", "--merged", fmt.Sprintf("tags/%s^", current)))
+	if err != nil {
+		return nil, err
+	}
+	tags = slices.DeleteFunc(tags, func(tag string) bool {
+		v, err := scheme.Parse(tag)
+		return err != nil || compareCalVer(ctx, v, cv) >= 0
+	})
+	return sortCalVer(ctx, tags)
+}
+
+// compareCalVer compares two calendar versions.
+//
+// Like git's versionsort.suffix, if a prerelease suffix is set, only the
+// modifiers starting with it are prereleases, and the others sort after the
+// version without a modifier.
+func compareCalVer(ctx *context.Context, a, b calver.Version) int {
+	suffix := ctx.Config.Git.PrereleaseSuffix
+	if suffix == "" || a.Modifier == b.Modifier {
+		return calver.Compare(a, b)
+	}
+	base := func(v calver.Version) calver.Version {
+		v.Modifier = ""
+		return v
+	}
+	if c := calver.Compare(base(a), base(b)); c != 0 {
+		return c
+	}
+	rank := func(v calver.Version) int {
+		switch {
+		case v.Modifier == "":
+			return 0
+		case strings.HasPrefix("-"+v.Modifier, suffix):
+			return -1
+		default:
+			return 1
+		}
+	}
+	if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Modifier, b.Modifier)
+}
+
 func gitDescribe(ctx *context.Context, ref string, excluding []string) (string, error) {
 	args := []string{
 		"describe",
@@ -224,6 +224,92 @@ func TestTagSortOrderPrerelease(t *testing.T) {
 	require.Equal(t, "v0.0.1", ctx.Git.CurrentTag)
 }
 
+func TestTagSortOrderCalVer(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:foo/bar.git")
+	testlib.GitCommit(t, "commit1")
+	testlib.GitTag(t, "v2023.12.10")
+	testlib.GitTag(t, "nightly")
+	testlib.GitTag(t, "v2023.12.9")
+	testlib.GitCommit(t, "commit2")
+	testlib.GitTag(t, "v2024.01.1")
+	testlib.GitTag(t, "v2024.01.1-rc1")
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{
+			Mode: "calver",
+		},
+	})
+
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "calver", ctx.Config.Git.TagSort)
+	require.Equal(t, "v2024.01.1", ctx.Git.CurrentTag)
+	require.Equal(t, "v2023.12.10", ctx.Git.PreviousTag)
+}
+
+func TestTagSortOrderCalVerPrevious(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:foo/bar.git")
+	testlib.GitCommit(t, "commit1")
+	testlib.GitTag(t, "v2023.12.9")
+	testlib.GitCommit(t, "commit2")
+	testlib.GitTag(t, "v2023.12.10")
+	testlib.GitCommit(t, "commit3")
+	testlib.GitTag(t, "nightly")
+	testlib.GitTag(t, "v2023.12.8")
+	testlib.GitCommit(t, "commit4")
+	testlib.GitTag(t, "v2024.01.1")
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{
+			Mode: "calver",
+		},
+	})
+
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "v2024.01.1", ctx.Git.CurrentTag)
+	require.Equal(t, "v2023.12.10", ctx.Git.PreviousTag)
+}
+
+func TestTagSortOrderCalVerPrereleaseSuffix(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:foo/bar.git")
+	testlib.GitCommit(t, "commit1")
+	testlib.GitTag(t, "v2024.01.1-rc1")
+	testlib.GitCommit(t, "commit2")
+	testlib.GitTag(t, "v2024.01.1")
+	testlib.GitTag(t, "v2024.01.1-hotfix")
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Git: config.Git{
+			PrereleaseSuffix: "-rc",
+		},
+		Versioning: config.Versioning{
+			Mode: "calver",
+		},
+	})
+
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "v2024.01.1-hotfix", ctx.Git.CurrentTag)
+	require.Equal(t, "v2024.01.1-rc1", ctx.Git.PreviousTag)
+}
+
+func TestTagSortOrderCalVerInvalidScheme(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:foo/bar.git")
+	testlib.GitCommit(t, "commit1")
+	testlib.GitTag(t, "v2024.01.1")
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{
+			Mode:   "calver",
+			Scheme: "FOO",
+		},
+	})
+
+	require.ErrorContains(t, Pipe{}.Run(ctx), `invalid calver scheme "FOO"`)
+}
+
 func TestTagIsNotLastCommit(t *testing.T) {
 	testlib.Mktmp(t)
 	testlib.GitInit(t)
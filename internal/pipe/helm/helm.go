@@ -0,0 +1,534 @@
+// Package helm packages and publishes Helm charts.
+package helm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/v2/internal/gio"
+	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/keyfile"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	chartFile  = "Chart.yaml"
+	valuesFile = "values.yaml"
+	// imageTagField is the conventional values field for the image tag.
+	imageTagField = "image.tag"
+	ignoreFile    = ".helmignore"
+	configExtra   = "HelmChartConfig"
+	chartExtra    = "HelmChartMetadata"
+)
+
+// Pipe that packages Helm charts.
+type Pipe struct{}
+
+func (Pipe) String() string { return "helm charts" }
+
+func (Pipe) Skip(ctx *context.Context) bool {
+	return skips.Any(ctx, skips.Helm) || len(ctx.Config.HelmCharts) == 0
+}
+
+// Default sets the Pipes defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("helm_charts")
+	for i := range ctx.Config.HelmCharts {
+		chart := &ctx.Config.HelmCharts[i]
+		if chart.ID == "" {
+			chart.ID = ctx.Config.ProjectName
+		}
+		if chart.Path == "" {
+			chart.Path = "charts/{{ .ProjectName }}"
+		}
+		if chart.Version == "" {
+			chart.Version = "{{ .Version }}"
+		}
+		if chart.AppVersion == "" {
+			chart.AppVersion = "{{ .Version }}"
+		}
+		if chart.ModTimestamp == "" {
+			chart.ModTimestamp = "{{ .CommitTimestamp }}"
+		}
+		if chart.CommitMessageTemplate == "" {
+			chart.CommitMessageTemplate = "Helm chart update for {{ .ProjectName }} version {{ .Tag }}"
+		}
+		chart.CommitAuthor = commitauthor.Default(chart.CommitAuthor)
+		ids.Inc(chart.ID)
+	}
+	return ids.Validate()
+}
+
+// Run packages the charts.
+func (Pipe) Run(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, cfg := range ctx.Config.HelmCharts {
+		g.Go(func() error {
+			return doRun(ctx, cfg)
+		})
+	}
+	return g.Wait()
+}
+
+func doRun(ctx *context.Context, cfg config.HelmChart) error {
+	tpl := tmpl.New(ctx)
+	disable, err := tpl.Bool(cfg.Disable)
+	if err != nil {
+		return err
+	}
+	if disable {
+		return pipe.Skip("configuration is disabled")
+	}
+
+	dir, version, appVersion := cfg.Path, cfg.Version, cfg.AppVersion
+	if err := tpl.ApplyAll(&dir, &version, &appVersion); err != nil {
+		return err
+	}
+	modTimestamp, err := tpl.Apply(cfg.ModTimestamp)
+	if err != nil {
+		return err
+	}
+	modTime, err := gio.ModTime(modTimestamp, ctx.Date)
+	if err != nil {
+		return fmt.Errorf("helm_charts: %w", err)
+	}
+
+	files, err := loadChart(tpl, dir)
+	if err != nil {
+		return fmt.Errorf("helm_charts: %w", err)
+	}
+	content, err := setFields(files[chartFile], map[string]string{
+		"version":    version,
+		"appVersion": appVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("helm_charts: invalid %s: %w", chartFile, err)
+	}
+	files[chartFile] = content
+
+	values := make(map[string]string, len(cfg.Values)+1)
+	for k, v := range cfg.Values {
+		if values[k], err = tpl.Apply(v); err != nil {
+			return err
+		}
+	}
+	if _, ok := values[imageTagField]; !ok {
+		if values[imageTagField], err = dockerImageTag(tpl, ctx.Config.DockersV2, files[valuesFile]); err != nil {
+			return err
+		}
+	}
+	maps.DeleteFunc(values, func(_, v string) bool { return v == "" })
+	if len(values) > 0 {
+		bts, err := setFields(files[valuesFile], values)
+		if err != nil {
+			return fmt.Errorf("helm_charts: invalid %s: %w", valuesFile, err)
+		}
+		files[valuesFile] = bts
+	}
+
+	metadata, err := parseMetadata(content)
+	if err != nil {
+		return fmt.Errorf("helm_charts: invalid %s: %w", chartFile, err)
+	}
+	name := metadata["name"].(string)
+	version = metadata["version"].(string)
+
+	outDir := filepath.Join(ctx.Config.Dist, "helm", cfg.ID)
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	filename := name + "-" + version + ".tgz"
+	out := filepath.Join(outDir, filename)
+	log.WithField("chart", name).
+		WithField("version", version).
+		WithField("path", out).
+		Info("packaging")
+	bts, err := pack(name, files, modTime)
+	if err != nil {
+		return fmt.Errorf("helm_charts: could not package %s: %w", name, err)
+	}
+	if err := os.WriteFile(out, bts, 0o644); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename,
+		Path: out,
+		Type: artifact.HelmChart,
+		Extra: map[string]any{
+			artifact.ExtraID: cfg.ID,
+			configExtra:      cfg,
+			chartExtra:       metadata,
+		},
+	})
+
+	key, err := signingKey(ctx, cfg.Sign)
+	if err != nil {
+		return fmt.Errorf("helm_charts: sign: %w", err)
+	}
+	if key == nil {
+		return nil
+	}
+	prov, err := provenance(ctx, key, content, filename, bts)
+	if err != nil {
+		return fmt.Errorf("helm_charts: sign: %w", err)
+	}
+	if err := os.WriteFile(out+".prov", prov, 0o644); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename + ".prov",
+		Path: out + ".prov",
+		Type: artifact.HelmChartProvenance,
+		Extra: map[string]any{
+			artifact.ExtraID:          cfg.ID,
+			artifact.ExtraSignatureOf: filename,
+		},
+	})
+	return nil
+}
+
+// loadChart reads all the files of the chart in the given directory, skipping
+// the ones matching its .helmignore.
+//
+// Chart.yaml and values.yaml are evaluated as templates, the other files are
+// left as is, as they are usually Helm templates themselves.
+func loadChart(tpl *tmpl.Template, dir string) (map[string][]byte, error) {
+	if _, err := os.Stat(filepath.Join(dir, chartFile)); err != nil {
+		return nil, fmt.Errorf("no chart found at %s: %w", dir, err)
+	}
+	ignores, err := readIgnores(filepath.Join(dir, ignoreFile))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if ignored(ignores, rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		bts, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[rel] = bts
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{chartFile, valuesFile} {
+		bts, ok := files[name]
+		if !ok {
+			continue
+		}
+		s, err := tpl.Apply(string(bts))
+		if err != nil {
+			return nil, err
+		}
+		files[name] = []byte(s)
+	}
+	return files, nil
+}
+
+// dockerImageTag returns the first tag of the dockers_v2 configuration whose
+// images include the image.repository of the given values, unless they
+// already set an image.tag.
+func dockerImageTag(tpl *tmpl.Template, dockers []config.DockerV2, values []byte) (string, error) {
+	var v map[string]any
+	if err := yaml.Unmarshal(values, &v); err != nil {
+		// not our business, helm will complain about it.
+		return "", nil
+	}
+	image, _ := v["image"].(map[string]any)
+	repository, _ := image["repository"].(string)
+	if repository == "" || image["tag"] != nil && image["tag"] != "" {
+		return "", nil
+	}
+	for _, docker := range dockers {
+		images, err := tpl.Slice(docker.Images, tmpl.NonEmpty())
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(images, repository) {
+			continue
+		}
+		tags, err := tpl.Slice(docker.Tags, tmpl.NonEmpty())
+		if err != nil || len(tags) == 0 {
+			return "", err
+		}
+		return tags[0], nil
+	}
+	return "", nil
+}
+
+func readIgnores(path string) ([]string, error) {
+	bts, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ignores []string
+	for line := range strings.Lines(string(bts)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ignores = append(ignores, line)
+	}
+	return ignores, nil
+}
+
+// ignored reports whether the given path matches any of the given
+// .helmignore patterns.
+//
+// Patterns with a slash are matched against the whole path, the others
+// against the file name only, and a trailing slash only matches directories.
+// Negations are not supported.
+func ignored(ignores []string, rel string, isDir bool) bool {
+	for _, pattern := range ignores {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		name := path.Base(rel)
+		if strings.Contains(pattern, "/") {
+			name = rel
+			pattern = strings.TrimPrefix(pattern, "/")
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// setFields sets the given fields of a YAML document, keeping the document as
+// is if they are already set to the same values.
+//
+// Nested fields are set using their dotted path, e.g. image.tag.
+func setFields(content []byte, fields map[string]string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 && len(bytes.TrimSpace(content)) == 0 {
+		doc = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("not a mapping")
+	}
+	root := doc.Content[0]
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var changed bool
+	for _, key := range keys {
+		value := fields[key]
+		if value == "" {
+			continue
+		}
+		if setField(root, key, value) {
+			changed = true
+		}
+	}
+	if !changed {
+		return content, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func setField(root *yaml.Node, key, value string) bool {
+	key, rest, nested := strings.Cut(key, ".")
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != key {
+			continue
+		}
+		node := root.Content[i+1]
+		if nested {
+			if node.Kind != yaml.MappingNode {
+				*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			return setField(node, rest, value)
+		}
+		if node.Kind != yaml.ScalarNode {
+			*node = yaml.Node{Kind: yaml.ScalarNode}
+		} else if node.Value == value {
+			return false
+		}
+		node.Tag = "!!str"
+		node.Value = value
+		return true
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if nested {
+		node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setField(node, rest, value)
+	}
+	root.Content = append(root.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		node,
+	)
+	return true
+}
+
+// parseMetadata parses and validates the given Chart.yaml.
+func parseMetadata(content []byte) (map[string]any, error) {
+	var metadata map[string]any
+	if err := yaml.Unmarshal(content, &metadata); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"apiVersion", "name", "version"} {
+		if s, ok := metadata[key].(string); !ok || s == "" {
+			return nil, fmt.Errorf("missing %s", key)
+		}
+	}
+	if _, err := semver.StrictNewVersion(metadata["version"].(string)); err != nil {
+		return nil, fmt.Errorf("version %q is not a valid semver: %w", metadata["version"], err)
+	}
+	return metadata, nil
+}
+
+// pack writes the given files as a gzipped tarball, under a directory with
+// the name of the chart.
+//
+// All files have the same mode and modification time, and are sorted, so the
+// package is reproducible.
+func pack(name string, files map[string][]byte, modTime time.Time) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, file := range names {
+		content := files[file]
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(name, file),
+			Size:     int64(len(content)),
+			Mode:     0o644,
+			ModTime:  modTime,
+			Format:   tar.FormatPAX,
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// provenance creates the Helm provenance file of the given package: its
+// Chart.yaml and checksum, cleartext signed.
+func provenance(ctx *context.Context, key *packet.PrivateKey, chart []byte, filename string, pkg []byte) ([]byte, error) {
+	sum := sha256.Sum256(pkg)
+	files := fmt.Sprintf("files:\n  %s: sha256:%s\n", filename, hex.EncodeToString(sum[:]))
+
+	var buf bytes.Buffer
+	w, err := clearsign.Encode(&buf, key, &packet.Config{
+		DefaultHash: crypto.SHA512,
+		Time:        func() time.Time { return ctx.Date },
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range [][]byte{
+		bytes.TrimRight(chart, "\n"),
+		[]byte("\n\n...\n"),
+		[]byte(files),
+	} {
+		if _, err := w.Write(part); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// signingKey returns the OpenPGP signing key, or nil if signing is disabled.
+func signingKey(ctx *context.Context, cfg config.HelmChartSign) (*packet.PrivateKey, error) {
+	key, password := cfg.Key, cfg.Password
+	if err := tmpl.New(ctx).ApplyAll(&key, &password); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, nil
+	}
+	return keyfile.OpenPGPSigningKey(key, password, ctx.Date)
+}
@@ -0,0 +1,492 @@
+package helm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const chartYAML = `# the chart
+apiVersion: v2
+name: mychart
+description: My chart
+version: 0.0.0
+appVersion: "0.0.0"
+`
+
+const valuesYAML = `# the values
+image:
+  repository: ghcr.io/user/mychart
+  tag: ""
+podLabels:
+  version: "{{ .Version }}"
+`
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(testctx.Wrap(t.Context())))
+	})
+	t.Run("skip flag", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			HelmCharts: []config.HelmChart{{}},
+		}, testctx.Skip(skips.Helm))
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			HelmCharts: []config.HelmChart{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "proj",
+		HelmCharts:  []config.HelmChart{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.HelmChart{
+		ID:                    "proj",
+		Path:                  "charts/{{ .ProjectName }}",
+		Version:               "{{ .Version }}",
+		AppVersion:            "{{ .Version }}",
+		ModTimestamp:          "{{ .CommitTimestamp }}",
+		CommitMessageTemplate: "Helm chart update for {{ .ProjectName }} version {{ .Tag }}",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "bot@goreleaser.com",
+		},
+	}, ctx.Config.HelmCharts[0])
+}
+
+func TestDefaultDuplicateID(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		HelmCharts: []config.HelmChart{{ID: "a"}, {ID: "a"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 helm_charts with the ID 'a', please fix your config")
+}
+
+func TestRun(t *testing.T) {
+	ctx := newContext(t, config.HelmChart{})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+	require.Len(t, charts, 1)
+	chart := charts[0]
+	require.Equal(t, "mychart-1.2.3.tgz", chart.Name)
+	require.Equal(t, filepath.Join(ctx.Config.Dist, "helm", "mychart", "mychart-1.2.3.tgz"), chart.Path)
+	require.Equal(t, "mychart", chart.ID())
+	require.Equal(t, map[string]any{
+		"apiVersion":  "v2",
+		"name":        "mychart",
+		"description": "My chart",
+		"version":     "1.2.3",
+		"appVersion":  "1.2.3",
+	}, artifact.MustExtra[map[string]any](*chart, chartExtra))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChartProvenance)).List())
+
+	files := readPackage(t, chart.Path)
+	require.Equal(t, []string{
+		"mychart/Chart.yaml",
+		"mychart/templates/deployment.yaml",
+		"mychart/values.yaml",
+	}, keys(files))
+	require.Equal(t, `# the chart
+apiVersion: v2
+name: mychart
+description: My chart
+version: 1.2.3
+appVersion: "1.2.3"
+`, files["mychart/Chart.yaml"])
+	require.Equal(t, `# the values
+image:
+  repository: ghcr.io/user/mychart
+  tag: ""
+podLabels:
+  version: "1.2.3"
+`, files["mychart/values.yaml"])
+	require.Equal(t, "image: {{ .Values.image.tag }}\n", files["mychart/templates/deployment.yaml"])
+}
+
+func TestRunChartTemplate(t *testing.T) {
+	ctx := newContext(t, config.HelmChart{
+		Version:    "{{ .Major }}.0.0",
+		AppVersion: "{{ .Tag }}",
+	})
+	require.NoError(t, os.WriteFile(
+		filepath.Join(ctx.Config.HelmCharts[0].Path, chartFile),
+		[]byte("apiVersion: v2\nname: {{ .ProjectName }}\nversion: 0.0.0\n"),
+		0o644,
+	))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+	require.Len(t, charts, 1)
+	require.Equal(t, "mychart-1.0.0.tgz", charts[0].Name)
+	require.Equal(t,
+		"apiVersion: v2\nname: mychart\nversion: 1.0.0\nappVersion: v1.2.3\n",
+		readPackage(t, charts[0].Path)["mychart/Chart.yaml"],
+	)
+}
+
+func TestRunValues(t *testing.T) {
+	ctx := newContext(t, config.HelmChart{
+		Values: map[string]string{
+			"image.tag":              "{{ .Tag }}",
+			"image.pullPolicy":       "Always",
+			"podAnnotations.version": "{{ .Version }}",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+	require.Len(t, charts, 1)
+	require.Equal(t, `# the values
+image:
+  repository: ghcr.io/user/mychart
+  tag: "v1.2.3"
+  pullPolicy: Always
+podLabels:
+  version: "1.2.3"
+podAnnotations:
+  version: 1.2.3
+`, readPackage(t, charts[0].Path)["mychart/values.yaml"])
+}
+
+func TestRunDockerImageTag(t *testing.T) {
+	dockers := []config.DockerV2{
+		{
+			Images: []string{"ghcr.io/user/other"},
+			Tags:   []string{"other"},
+		},
+		{
+			Images: []string{"docker.io/user/mychart", "ghcr.io/{{ .Env.OWNER }}/mychart"},
+			Tags:   []string{"", "{{ .Tag }}", "latest"},
+		},
+	}
+	run := func(tb testing.TB, cfg config.HelmChart, values string) string {
+		tb.Helper()
+		ctx := newContext(tb, cfg)
+		ctx.Env["OWNER"] = "user"
+		ctx.Config.DockersV2 = dockers
+		if values != "" {
+			require.NoError(tb, os.WriteFile(filepath.Join(ctx.Config.HelmCharts[0].Path, valuesFile), []byte(values), 0o644))
+		}
+		require.NoError(tb, Pipe{}.Run(ctx))
+		charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+		require.Len(tb, charts, 1)
+		return readPackage(tb, charts[0].Path)["mychart/values.yaml"]
+	}
+
+	t.Run("matching image", func(t *testing.T) {
+		require.Equal(t, `# the values
+image:
+  repository: ghcr.io/user/mychart
+  tag: "v1.2.3"
+podLabels:
+  version: "1.2.3"
+`, run(t, config.HelmChart{}, ""))
+	})
+
+	t.Run("tag already set", func(t *testing.T) {
+		values := "image:\n  repository: ghcr.io/user/mychart\n  tag: stable\n"
+		require.Equal(t, values, run(t, config.HelmChart{}, values))
+	})
+
+	t.Run("values take precedence", func(t *testing.T) {
+		require.Contains(t, run(t, config.HelmChart{
+			Values: map[string]string{"image.tag": "{{ .Version }}"},
+		}, ""), "  tag: \"1.2.3\"\n")
+	})
+
+	t.Run("no matching image", func(t *testing.T) {
+		values := "image:\n  repository: ghcr.io/user/nope\n"
+		require.Equal(t, values, run(t, config.HelmChart{}, values))
+	})
+
+	t.Run("image is not a mapping", func(t *testing.T) {
+		values := "image: ghcr.io/user/mychart\n"
+		require.Equal(t, values, run(t, config.HelmChart{}, values))
+	})
+}
+
+func TestRunValuesNoFile(t *testing.T) {
+	ctx := newContext(t, config.HelmChart{
+		Values: map[string]string{"image.tag": "{{ .Tag }}"},
+	})
+	require.NoError(t, os.Remove(filepath.Join(ctx.Config.HelmCharts[0].Path, valuesFile)))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+	require.Len(t, charts, 1)
+	require.Equal(t,
+		"image:\n  tag: v1.2.3\n",
+		readPackage(t, charts[0].Path)["mychart/values.yaml"],
+	)
+}
+
+func TestRunReproducible(t *testing.T) {
+	var digests []string
+	for range 2 {
+		ctx := newContext(t, config.HelmChart{})
+		require.NoError(t, Pipe{}.Run(ctx))
+		charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+		require.Len(t, charts, 1)
+		sum, err := charts[0].Checksum("sha256")
+		require.NoError(t, err)
+		digests = append(digests, sum)
+
+		hdr := readHeaders(t, charts[0].Path)
+		for _, h := range hdr {
+			require.Equal(t, time.Unix(1700000000, 0).UTC(), h.ModTime.UTC())
+			require.Equal(t, int64(0o644), h.Mode)
+		}
+	}
+	require.Equal(t, digests[0], digests[1])
+}
+
+func TestRunHelmIgnore(t *testing.T) {
+	ctx := newContext(t, config.HelmChart{})
+	dir := ctx.Config.HelmCharts[0].Path
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ignoreFile), []byte("# comment\n*.tmp\nci/\n/templates/tests/\n"), 0o644))
+	for _, name := range []string{"foo.tmp", "ci/values.yaml", "templates/tests/test.yaml", "templates/tests.txt"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644))
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+	require.Len(t, charts, 1)
+	require.Equal(t, []string{
+		"mychart/.helmignore",
+		"mychart/Chart.yaml",
+		"mychart/templates/deployment.yaml",
+		"mychart/templates/tests.txt",
+		"mychart/values.yaml",
+	}, keys(readPackage(t, charts[0].Path)))
+}
+
+func TestRunSign(t *testing.T) {
+	entity, key := newKey(t, "secret")
+	path := filepath.Join(t.TempDir(), "key.asc")
+	require.NoError(t, os.WriteFile(path, []byte(key), 0o600))
+	ctx := newContext(t, config.HelmChart{
+		Sign: config.HelmChartSign{
+			Key:      "{{ .Env.KEY }}",
+			Password: "{{ .Env.PASSWORD }}",
+		},
+	})
+	ctx.Env["KEY"] = path
+	ctx.Env["PASSWORD"] = "secret"
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	charts := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()
+	require.Len(t, charts, 1)
+	sum, err := charts[0].Checksum("sha256")
+	require.NoError(t, err)
+
+	provs := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChartProvenance)).List()
+	require.Len(t, provs, 1)
+	prov := provs[0]
+	require.Equal(t, "mychart-1.2.3.tgz.prov", prov.Name)
+	require.Equal(t, charts[0].Path+".prov", prov.Path)
+	require.Equal(t, "mychart-1.2.3.tgz", artifact.MustExtra[string](*prov, artifact.ExtraSignatureOf))
+
+	bts, err := os.ReadFile(prov.Path)
+	require.NoError(t, err)
+	block, _ := clearsign.Decode(bts)
+	require.NotNil(t, block)
+	_, err = block.VerifySignature(openpgp.EntityList{entity}, nil)
+	require.NoError(t, err)
+	require.Equal(t, `# the chart
+apiVersion: v2
+name: mychart
+description: My chart
+version: 1.2.3
+appVersion: "1.2.3"
+
+...
+files:
+  mychart-1.2.3.tgz: sha256:`+sum+"\n", string(block.Plaintext))
+}
+
+func TestRunSkips(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Disable: "{{ .IsSnapshot }}"})
+		ctx.Snapshot = true
+		testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+	})
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Run("missing chart", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Path: filepath.Join(t.TempDir(), "nope")})
+		require.ErrorContains(t, Pipe{}.Run(ctx), "helm_charts: no chart found at")
+	})
+	t.Run("invalid chart", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{})
+		require.NoError(t, os.WriteFile(filepath.Join(ctx.Config.HelmCharts[0].Path, chartFile), []byte("- foo\n"), 0o644))
+		require.EqualError(t, Pipe{}.Run(ctx), "helm_charts: invalid Chart.yaml: not a mapping")
+	})
+	t.Run("missing name", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{})
+		require.NoError(t, os.WriteFile(filepath.Join(ctx.Config.HelmCharts[0].Path, chartFile), []byte("apiVersion: v2\n"), 0o644))
+		require.EqualError(t, Pipe{}.Run(ctx), "helm_charts: invalid Chart.yaml: missing name")
+	})
+	t.Run("invalid version", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Version: "latest"})
+		require.ErrorContains(t, Pipe{}.Run(ctx), `helm_charts: invalid Chart.yaml: version "latest" is not a valid semver`)
+	})
+	t.Run("invalid mod_timestamp", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{ModTimestamp: "yesterday"})
+		require.ErrorContains(t, Pipe{}.Run(ctx), "helm_charts: invalid mod_timestamp")
+	})
+	t.Run("invalid key", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Sign: config.HelmChartSign{Key: filepath.Join(t.TempDir(), "nope")}})
+		require.ErrorContains(t, Pipe{}.Run(ctx), "helm_charts: sign: could not read key")
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		_, key := newKey(t, "secret")
+		ctx := newContext(t, config.HelmChart{Sign: config.HelmChartSign{Key: key, Password: "nope"}})
+		require.ErrorContains(t, Pipe{}.Run(ctx), "helm_charts: sign: could not decrypt key")
+	})
+	for name, cfg := range map[string]config.HelmChart{
+		"disable":       {Disable: "{{ .Nope }}"},
+		"path":          {Path: "{{ .Nope }}"},
+		"version":       {Version: "{{ .Nope }}"},
+		"app_version":   {AppVersion: "{{ .Nope }}"},
+		"mod_timestamp": {ModTimestamp: "{{ .Nope }}"},
+		"sign key":      {Sign: config.HelmChartSign{Key: "{{ .Nope }}"}},
+		"values":        {Values: map[string]string{"image.tag": "{{ .Nope }}"}},
+	} {
+		t.Run("template "+name, func(t *testing.T) {
+			testlib.RequireTemplateError(t, Pipe{}.Run(newContext(t, cfg)))
+		})
+	}
+	t.Run("template values.yaml", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{})
+		require.NoError(t, os.WriteFile(filepath.Join(ctx.Config.HelmCharts[0].Path, valuesFile), []byte("tag: {{ .Nope }}\n"), 0o644))
+		testlib.RequireTemplateError(t, Pipe{}.Run(ctx))
+	})
+	t.Run("template dockers_v2 images", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{})
+		ctx.Config.DockersV2 = []config.DockerV2{{Images: []string{"{{ .Nope }}"}}}
+		testlib.RequireTemplateError(t, Pipe{}.Run(ctx))
+	})
+	t.Run("invalid values.yaml", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Values: map[string]string{"image.tag": "v1"}})
+		require.NoError(t, os.WriteFile(filepath.Join(ctx.Config.HelmCharts[0].Path, valuesFile), []byte("- foo\n"), 0o644))
+		require.EqualError(t, Pipe{}.Run(ctx), "helm_charts: invalid values.yaml: not a mapping")
+	})
+}
+
+// newContext creates a context with a helm chart configuration based on the
+// given one, and a chart to package.
+func newContext(tb testing.TB, cfg config.HelmChart) *context.Context {
+	tb.Helper()
+	dir := filepath.Join(tb.TempDir(), "mychart")
+	require.NoError(tb, os.MkdirAll(filepath.Join(dir, "templates"), 0o755))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, chartFile), []byte(chartYAML), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, valuesFile), []byte(valuesYAML), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "templates", "deployment.yaml"), []byte("image: {{ .Values.image.tag }}\n"), 0o644))
+
+	if cfg.ID == "" {
+		cfg.ID = "mychart"
+	}
+	if cfg.Path == "" {
+		cfg.Path = dir
+	}
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		ProjectName: "mychart",
+		Dist:        tb.TempDir(),
+		HelmCharts:  []config.HelmChart{cfg},
+	},
+		testctx.WithCurrentTag("v1.2.3"),
+		testctx.WithVersion("1.2.3"),
+		testctx.WithSemver(1, 2, 3, ""),
+		testctx.WithCommitDate(time.Unix(1700000000, 0)),
+		testctx.WithDate(time.Unix(1700000000, 0)),
+	)
+	require.NoError(tb, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func newKey(tb testing.TB, password string) (*openpgp.Entity, string) {
+	tb.Helper()
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	require.NoError(tb, err)
+	if password != "" {
+		require.NoError(tb, entity.EncryptPrivateKeys([]byte(password), nil))
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	require.NoError(tb, err)
+	require.NoError(tb, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(tb, w.Close())
+	return entity, buf.String()
+}
+
+func readPackage(tb testing.TB, path string) map[string]string {
+	tb.Helper()
+	files := map[string]string{}
+	forEachEntry(tb, path, func(h *tar.Header, r io.Reader) {
+		bts, err := io.ReadAll(r)
+		require.NoError(tb, err)
+		files[h.Name] = string(bts)
+	})
+	return files
+}
+
+func readHeaders(tb testing.TB, path string) []*tar.Header {
+	tb.Helper()
+	var headers []*tar.Header
+	forEachEntry(tb, path, func(h *tar.Header, _ io.Reader) {
+		headers = append(headers, h)
+	})
+	return headers
+}
+
+func forEachEntry(tb testing.TB, path string, fn func(*tar.Header, io.Reader)) {
+	tb.Helper()
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(tb, err)
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(tb, err)
+		fn(h, tr)
+	}
+}
+
+func keys(m map[string]string) []string {
+	return slices.Sorted(maps.Keys(m))
+}
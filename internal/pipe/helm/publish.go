@@ -0,0 +1,366 @@
+package helm
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/caarlos0/log"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/client"
+	"github.com/goreleaser/goreleaser/v2/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/v2/internal/oci"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"go.yaml.in/yaml/v3"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+
+	// Import the blob packages we want to be able to open.
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+)
+
+const (
+	indexFile = "index.yaml"
+
+	mediaTypeConfig     types.MediaType = "application/vnd.cncf.helm.config.v1+json"
+	mediaTypeChart      types.MediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	mediaTypeProvenance types.MediaType = "application/vnd.cncf.helm.chart.provenance.v1.prov"
+)
+
+// Publish pushes the charts to their registries and repositories.
+func (Pipe) Publish(ctx *context.Context) error {
+	return publishAll(ctx, nil)
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, chart := range ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List() {
+		err := doPublish(ctx, chart, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, chart *artifact.Artifact, cli client.Client) error {
+	cfg := artifact.MustExtra[config.HelmChart](*chart, configExtra)
+	if cfg.Registry == "" && cfg.Blob.Bucket == "" &&
+		cfg.Repository.Name == "" && cfg.Repository.Git.URL == "" {
+		return pipe.Skipf("helm_charts: %s: no registry, blob or repository set", cfg.ID)
+	}
+
+	var prov *artifact.Artifact
+	if provs := ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.HelmChartProvenance),
+		artifact.ByIDs(cfg.ID),
+	)).List(); len(provs) > 0 {
+		prov = provs[0]
+	}
+
+	if cfg.Registry != "" {
+		if err := publishRegistry(ctx, cfg, chart, prov); err != nil {
+			return fmt.Errorf("helm_charts: %w", err)
+		}
+	}
+	if cfg.Blob.Bucket != "" {
+		if err := publishBlob(ctx, cfg, chart, prov); err != nil {
+			return fmt.Errorf("helm_charts: %w", err)
+		}
+	}
+	if cfg.Repository.Name != "" || cfg.Repository.Git.URL != "" {
+		if err := publishRepository(ctx, cfg, chart, prov, cli); err != nil {
+			return fmt.Errorf("helm_charts: %w", err)
+		}
+	}
+	return nil
+}
+
+// publishRegistry pushes the chart to an OCI registry, the same way
+// `helm push` does.
+func publishRegistry(ctx *context.Context, cfg config.HelmChart, chart, prov *artifact.Artifact) error {
+	metadata := artifact.MustExtra[map[string]any](*chart, chartExtra)
+	chartName, version := metadata["name"].(string), metadata["version"].(string)
+
+	registry, err := tmpl.New(ctx).Apply(cfg.Registry)
+	if err != nil {
+		return err
+	}
+	registry = strings.TrimSuffix(strings.TrimPrefix(registry, "oci://"), "/")
+	repo, err := name.NewRepository(registry + "/" + chartName)
+	if err != nil {
+		return fmt.Errorf("invalid registry %q: %w", registry, err)
+	}
+	// tags can't have '+', so helm uses '_' instead.
+	ref := repo.Tag(strings.ReplaceAll(version, "+", "_"))
+
+	chartConfig, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	layer, err := oci.FileLayer(chart.Path, mediaTypeChart)
+	if err != nil {
+		return err
+	}
+	manifest := oci.Artifact{
+		Config: static.NewLayer(chartConfig, mediaTypeConfig),
+		Layers: []oci.Layer{{Layer: layer}},
+		Annotations: map[string]string{
+			"org.opencontainers.image.title":   chartName,
+			"org.opencontainers.image.version": version,
+			"org.opencontainers.image.created": ctx.Date.UTC().Format(time.RFC3339),
+		},
+	}
+	if description, ok := metadata["description"].(string); ok && description != "" {
+		manifest.Annotations["org.opencontainers.image.description"] = description
+	}
+	if prov != nil {
+		layer, err := oci.FileLayer(prov.Path, mediaTypeProvenance)
+		if err != nil {
+			return err
+		}
+		manifest.Layers = append(manifest.Layers, oci.Layer{Layer: layer})
+	}
+
+	log.WithField("ref", ref.String()).Info("pushing")
+	desc, err := oci.Push(ctx, ref, manifest)
+	if err != nil {
+		return fmt.Errorf("could not push %s: %w", ref, err)
+	}
+	log.WithField("ref", ref.String()).
+		WithField("digest", desc.Digest.String()).
+		Info("pushed")
+	return nil
+}
+
+// publishBlob uploads the chart to a chart repository in a blob storage
+// bucket, and adds it to the repository's index.yaml.
+func publishBlob(ctx *context.Context, cfg config.HelmChart, chart, prov *artifact.Artifact) error {
+	tpl := tmpl.New(ctx)
+	bucketURL, dir, baseURL := cfg.Blob.Bucket, cfg.Blob.Directory, cfg.Blob.URL
+	if err := tpl.ApplyAll(&bucketURL, &dir, &baseURL); err != nil {
+		return err
+	}
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return fmt.Errorf("could not open bucket %s: %w", bucketURL, err)
+	}
+	defer bucket.Close()
+
+	dir = strings.Trim(dir, "/")
+	for _, art := range []*artifact.Artifact{chart, prov} {
+		if art == nil {
+			continue
+		}
+		bts, err := os.ReadFile(art.Path)
+		if err != nil {
+			return err
+		}
+		key := path.Join(dir, art.Name)
+		log.WithField("bucket", bucketURL).WithField("file", key).Info("uploading")
+		if err := bucket.WriteAll(ctx, key, bts, &blob.WriterOptions{
+			ContentType: contentTypeOf(art),
+		}); err != nil {
+			return fmt.Errorf("could not upload %s: %w", key, err)
+		}
+	}
+
+	key := path.Join(dir, indexFile)
+	current, err := bucket.ReadAll(ctx, key)
+	if err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return fmt.Errorf("could not read %s: %w", key, err)
+	}
+	url := chart.Name
+	if baseURL != "" {
+		url = strings.TrimSuffix(baseURL, "/") + "/" + chart.Name
+	}
+	index, err := addToIndex(ctx, current, chart, url)
+	if err != nil {
+		return fmt.Errorf("could not update %s: %w", key, err)
+	}
+	log.WithField("bucket", bucketURL).WithField("file", key).Info("updating index")
+	if err := bucket.WriteAll(ctx, key, index, &blob.WriterOptions{
+		ContentType: "application/yaml",
+	}); err != nil {
+		return fmt.Errorf("could not upload %s: %w", key, err)
+	}
+	return nil
+}
+
+// publishRepository commits the chart to a git repository.
+func publishRepository(ctx *context.Context, cfg config.HelmChart, chart, prov *artifact.Artifact, cli client.Client) error {
+	tpl := tmpl.New(ctx)
+	ref, err := client.TemplateRef(tpl.Apply, cfg.Repository)
+	if err != nil {
+		return err
+	}
+	cfg.Repository = ref
+	repo := client.RepoFromRef(cfg.Repository)
+
+	dir := cfg.Directory
+	msg := cfg.CommitMessageTemplate
+	if err := tpl.ApplyAll(&dir, &msg); err != nil {
+		return err
+	}
+	author, err := commitauthor.Get(ctx, cfg.CommitAuthor)
+	if err != nil {
+		return err
+	}
+
+	var files []client.RepoFile
+	for _, art := range []*artifact.Artifact{chart, prov} {
+		if art == nil {
+			continue
+		}
+		bts, err := os.ReadFile(art.Path)
+		if err != nil {
+			return err
+		}
+		files = append(files, client.RepoFile{
+			Content: bts,
+			Path:    path.Join(dir, art.Name),
+		})
+	}
+
+	if cfg.Repository.Git.URL != "" {
+		return client.NewGitUploadClient(repo.Branch).
+			CreateFiles(ctx, author, repo, msg, files)
+	}
+
+	cli, err = client.NewIfToken(ctx, cli, cfg.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	base := client.Repo{
+		Name:   cfg.Repository.PullRequest.Base.Name,
+		Owner:  cfg.Repository.PullRequest.Base.Owner,
+		Branch: cfg.Repository.PullRequest.Base.Branch,
+	}
+
+	// try to sync branch
+	fscli, ok := cli.(client.ForkSyncer)
+	if ok && cfg.Repository.PullRequest.Enabled {
+		if err := fscli.SyncFork(ctx, repo, base); err != nil {
+			log.WithError(err).Warn("could not sync fork")
+		}
+	}
+
+	if fcli, ok := cli.(client.FilesCreator); ok {
+		if err := fcli.CreateFiles(ctx, author, repo, msg, files); err != nil {
+			return err
+		}
+	} else {
+		for _, file := range files {
+			if err := cli.CreateFile(ctx, author, repo, file.Content, file.Path, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !cfg.Repository.PullRequest.Enabled {
+		log.Debug("helm_charts.pull_request disabled")
+		return nil
+	}
+
+	log.Info("helm_charts.pull_request enabled, creating a PR")
+	prcl, err := client.NewIfToken(ctx, cli, cfg.Repository.PullRequest.Token)
+	if err != nil {
+		return err
+	}
+	pcl, ok := prcl.(client.PullRequestOpener)
+	if !ok {
+		return errors.New("client does not support pull requests")
+	}
+	return pcl.OpenPullRequest(ctx, base, repo, msg, cfg.Repository.PullRequest.Draft)
+}
+
+// index is a Helm chart repository index.
+type index struct {
+	APIVersion string                      `yaml:"apiVersion"`
+	Entries    map[string][]map[string]any `yaml:"entries"`
+	Generated  string                      `yaml:"generated"`
+}
+
+// addToIndex adds the given chart to the given index.yaml contents,
+// replacing the previous entry of the same version, if any.
+func addToIndex(ctx *context.Context, current []byte, chart *artifact.Artifact, url string) ([]byte, error) {
+	idx := index{APIVersion: "v1"}
+	if len(current) > 0 {
+		if err := yaml.Unmarshal(current, &idx); err != nil {
+			return nil, err
+		}
+	}
+	if idx.Entries == nil {
+		idx.Entries = map[string][]map[string]any{}
+	}
+
+	bts, err := os.ReadFile(chart.Path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(bts)
+
+	entry := maps.Clone(artifact.MustExtra[map[string]any](*chart, chartExtra))
+	entry["created"] = ctx.Date.UTC().Format(time.RFC3339Nano)
+	entry["digest"] = hex.EncodeToString(sum[:])
+	entry["urls"] = []string{url}
+
+	chartName, version := entry["name"].(string), entry["version"].(string)
+	entries := slices.DeleteFunc(idx.Entries[chartName], func(e map[string]any) bool {
+		return fmt.Sprint(e["version"]) == version
+	})
+	entries = append(entries, entry)
+	// newest versions first, as helm does.
+	slices.SortStableFunc(entries, func(a, b map[string]any) int {
+		va, erra := semver.NewVersion(fmt.Sprint(a["version"]))
+		vb, errb := semver.NewVersion(fmt.Sprint(b["version"]))
+		if erra != nil || errb != nil {
+			return cmp.Compare(fmt.Sprint(b["version"]), fmt.Sprint(a["version"]))
+		}
+		return vb.Compare(va)
+	})
+	idx.Entries[chartName] = entries
+	idx.Generated = ctx.Date.UTC().Format(time.RFC3339Nano)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(idx); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func contentTypeOf(art *artifact.Artifact) string {
+	if art.Type == artifact.HelmChartProvenance {
+		return "text/plain"
+	}
+	return "application/gzip"
+}
@@ -0,0 +1,230 @@
+package helm
+
+import (
+	"encoding/json"
+	"io"
+	stdlog "log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/client"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestPublishRegistry(t *testing.T) {
+	srv := httptest.NewServer(registry.New(registry.Logger(stdlog.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	_, key := newKey(t, "")
+	ctx := newContext(t, config.HelmChart{
+		Version:  "1.2.3+build",
+		Registry: "oci://" + host + "/charts/",
+		Sign:     config.HelmChartSign{Key: key},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	ref, err := name.ParseReference(host + "/charts/mychart:1.2.3_build")
+	require.NoError(t, err)
+	desc, err := remote.Get(ref)
+	require.NoError(t, err)
+	var manifest v1.Manifest
+	require.NoError(t, json.Unmarshal(desc.Manifest, &manifest))
+	require.Equal(t, mediaTypeConfig, manifest.Config.MediaType)
+	require.Len(t, manifest.Layers, 2)
+	require.Equal(t, mediaTypeChart, manifest.Layers[0].MediaType)
+	require.Equal(t, mediaTypeProvenance, manifest.Layers[1].MediaType)
+	require.Equal(t, "mychart", manifest.Annotations["org.opencontainers.image.title"])
+	require.Equal(t, "1.2.3+build", manifest.Annotations["org.opencontainers.image.version"])
+	require.Equal(t, "My chart", manifest.Annotations["org.opencontainers.image.description"])
+
+	chart := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()[0]
+	sum, err := chart.Checksum("sha256")
+	require.NoError(t, err)
+	require.Equal(t, "sha256:"+sum, manifest.Layers[0].Digest.String())
+
+	layer, err := remote.Layer(ref.Context().Digest(manifest.Config.Digest.String()))
+	require.NoError(t, err)
+	rc, err := layer.Compressed()
+	require.NoError(t, err)
+	defer rc.Close()
+	bts, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"apiVersion": "v2",
+		"name": "mychart",
+		"description": "My chart",
+		"version": "1.2.3+build",
+		"appVersion": "1.2.3"
+	}`, string(bts))
+}
+
+func TestPublishBlob(t *testing.T) {
+	bucket := t.TempDir()
+	dir := filepath.Join(bucket, "stable")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, indexFile), []byte(`apiVersion: v1
+entries:
+  mychart:
+    - name: mychart
+      version: 1.0.0
+      urls: [https://charts.example.com/stable/mychart-1.0.0.tgz]
+    - name: mychart
+      version: 1.2.3
+      urls: [https://charts.example.com/stable/old.tgz]
+    - name: mychart
+      version: 2.0.0
+      urls: [https://charts.example.com/stable/mychart-2.0.0.tgz]
+  other:
+    - name: other
+      version: 0.1.0
+generated: "2020-01-01T00:00:00Z"
+`), 0o644))
+
+	_, key := newKey(t, "")
+	ctx := newContext(t, config.HelmChart{
+		Sign: config.HelmChartSign{Key: key},
+		Blob: config.HelmChartBlob{
+			Bucket:    "file://" + bucket,
+			Directory: "/{{ .Env.CHANNEL }}/",
+			URL:       "https://charts.example.com/stable/",
+		},
+	})
+	ctx.Env["CHANNEL"] = "stable"
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	require.FileExists(t, filepath.Join(dir, "mychart-1.2.3.tgz"))
+	require.FileExists(t, filepath.Join(dir, "mychart-1.2.3.tgz.prov"))
+
+	chart := ctx.Artifacts.Filter(artifact.ByType(artifact.HelmChart)).List()[0]
+	sum, err := chart.Checksum("sha256")
+	require.NoError(t, err)
+
+	bts, err := os.ReadFile(filepath.Join(dir, indexFile))
+	require.NoError(t, err)
+	var idx index
+	require.NoError(t, yaml.Unmarshal(bts, &idx))
+	require.Equal(t, "v1", idx.APIVersion)
+	require.Equal(t, "2023-11-14T22:13:20Z", idx.Generated)
+	require.Len(t, idx.Entries["other"], 1)
+	entries := idx.Entries["mychart"]
+	require.Len(t, entries, 3)
+	require.Equal(t, "2.0.0", entries[0]["version"])
+	require.Equal(t, "1.0.0", entries[2]["version"])
+	require.Equal(t, map[string]any{
+		"apiVersion":  "v2",
+		"name":        "mychart",
+		"description": "My chart",
+		"version":     "1.2.3",
+		"appVersion":  "1.2.3",
+		"created":     "2023-11-14T22:13:20Z",
+		"digest":      sum,
+		"urls":        []any{"https://charts.example.com/stable/mychart-1.2.3.tgz"},
+	}, entries[1])
+}
+
+func TestPublishBlobNewIndex(t *testing.T) {
+	bucket := t.TempDir()
+	ctx := newContext(t, config.HelmChart{
+		Blob: config.HelmChartBlob{Bucket: "file://" + bucket},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	require.FileExists(t, filepath.Join(bucket, "mychart-1.2.3.tgz"))
+	require.NoFileExists(t, filepath.Join(bucket, "mychart-1.2.3.tgz.prov"))
+	bts, err := os.ReadFile(filepath.Join(bucket, indexFile))
+	require.NoError(t, err)
+	var idx index
+	require.NoError(t, yaml.Unmarshal(bts, &idx))
+	require.Len(t, idx.Entries["mychart"], 1)
+	require.Equal(t, []any{"mychart-1.2.3.tgz"}, idx.Entries["mychart"][0]["urls"])
+}
+
+func TestPublishRepository(t *testing.T) {
+	_, key := newKey(t, "")
+	ctx := newContext(t, config.HelmChart{
+		Sign: config.HelmChartSign{Key: key},
+		Repository: config.RepoRef{
+			Owner: "foo",
+			Name:  "charts",
+			PullRequest: config.PullRequest{
+				Enabled: true,
+			},
+		},
+		Directory: "charts/{{ .ProjectName }}",
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	cli := client.NewMock()
+	require.NoError(t, publishAll(ctx, cli))
+	require.True(t, cli.CreatedFile)
+	require.True(t, cli.OpenedPullRequest)
+	require.True(t, cli.SyncedFork)
+	require.Equal(t, "charts/mychart/mychart-1.2.3.tgz.prov", cli.Path)
+	require.Equal(t, []string{
+		"Helm chart update for mychart version v1.2.3",
+		"Helm chart update for mychart version v1.2.3",
+	}, cli.Messages)
+}
+
+func TestPublishSkips(t *testing.T) {
+	t.Run("no targets", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{})
+		require.NoError(t, Pipe{}.Run(ctx))
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("nothing to publish", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Registry: "localhost:5000"})
+		require.NoError(t, Pipe{}.Publish(ctx))
+	})
+}
+
+func TestPublishErrors(t *testing.T) {
+	t.Run("invalid registry", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Registry: "not a registry"})
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.ErrorContains(t, Pipe{}.Publish(ctx), `helm_charts: invalid registry "not a registry"`)
+	})
+	t.Run("invalid bucket", func(t *testing.T) {
+		ctx := newContext(t, config.HelmChart{Blob: config.HelmChartBlob{Bucket: "nope://foo"}})
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.ErrorContains(t, Pipe{}.Publish(ctx), "helm_charts: could not open bucket nope://foo")
+	})
+	t.Run("invalid index", func(t *testing.T) {
+		bucket := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(bucket, indexFile), []byte("{{{"), 0o644))
+		ctx := newContext(t, config.HelmChart{Blob: config.HelmChartBlob{Bucket: "file://" + bucket}})
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.ErrorContains(t, Pipe{}.Publish(ctx), "helm_charts: could not update index.yaml")
+	})
+	for name, cfg := range map[string]config.HelmChart{
+		"registry":       {Registry: "{{ .Nope }}"},
+		"bucket":         {Blob: config.HelmChartBlob{Bucket: "{{ .Nope }}"}},
+		"blob directory": {Blob: config.HelmChartBlob{Bucket: "file:///tmp", Directory: "{{ .Nope }}"}},
+		"repository":     {Repository: config.RepoRef{Owner: "{{ .Nope }}", Name: "charts"}},
+		"directory":      {Repository: config.RepoRef{Owner: "foo", Name: "charts"}, Directory: "{{ .Nope }}"},
+		"commit message": {Repository: config.RepoRef{Owner: "foo", Name: "charts"}, CommitMessageTemplate: "{{ .Nope }}"},
+	} {
+		t.Run("template "+name, func(t *testing.T) {
+			ctx := newContext(t, cfg)
+			require.NoError(t, Pipe{}.Run(ctx))
+			testlib.RequireTemplateError(t, publishAll(ctx, client.NewMock()))
+		})
+	}
+}
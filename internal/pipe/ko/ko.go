@@ -6,7 +6,6 @@ import (
 	stdctx "context"
 	"errors"
 	"fmt"
-	"io"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -14,16 +13,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/awslabs/amazon-ecr-credential-helper/ecr-login"
 	"github.com/caarlos0/log"
-	"github.com/chrismellard/docker-credential-acr-env/pkg/credhelper"
-	"github.com/google/go-containerregistry/pkg/authn"
-	"github.com/google/go-containerregistry/pkg/authn/github"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
-	"github.com/google/go-containerregistry/pkg/v1/google"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/ko/pkg/build"
 	"github.com/google/ko/pkg/commands/options"
@@ -31,6 +25,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
 	"github.com/goreleaser/goreleaser/v2/internal/deprecate"
 	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/oci"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
 	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/v2/internal/skips"
@@ -43,16 +38,7 @@ import (
 const chainguardStatic = "cgr.dev/chainguard/static"
 
 var (
-	baseImages     sync.Map
-	amazonKeychain authn.Keychain = authn.NewKeychainFromHelper(ecr.NewECRHelper(ecr.WithLogger(io.Discard)))
-	azureKeychain  authn.Keychain = authn.NewKeychainFromHelper(credhelper.NewACRCredentialsHelper())
-	keychain                      = authn.NewMultiKeychain(
-		amazonKeychain,
-		authn.DefaultKeychain,
-		google.Keychain,
-		github.Keychain,
-		azureKeychain,
-	)
+	baseImages sync.Map
 
 	errNoRepositories    = errors.New("ko: missing repositories: please set either the repository field or a $KO_DOCKER_REPO environment variable")
 	errInvalidMainPath   = errors.New("ko: invalid Main path: ko.main (or build.main if ko.main is not set) should be a relative path")
@@ -226,7 +212,7 @@ func (o *buildOptions) makeBuilder(ctx *context.Context) (*build.Caching, error)
 
 			desc, err := remote.Get(
 				ref,
-				remote.WithAuthFromKeychain(keychain),
+				remote.WithAuthFromKeychain(oci.Keychain),
 			)
 			if err != nil {
 				return nil, nil, err
@@ -326,7 +312,7 @@ func doBuild(ctx *context.Context, ko config.Ko) error {
 			opts.imageRepos[0],
 			publish.WithTags(opts.tags),
 			publish.WithNamer(options.MakeNamer(po)),
-			publish.WithAuthFromKeychain(keychain),
+			publish.WithAuthFromKeychain(oci.Keychain),
 		)
 	}
 	if err != nil {
@@ -574,10 +560,10 @@ func copyImage(src, dst string) (string, error) {
 	log.WithField("src", src).
 		WithField("dst", dst).
 		Info("copying manifest")
-	if err := crane.Copy(src, dst, crane.WithAuthFromKeychain(keychain)); err != nil {
+	if err := crane.Copy(src, dst, crane.WithAuthFromKeychain(oci.Keychain)); err != nil {
 		return "", fmt.Errorf("could not copy %q to %q: %w", src, dst, err)
 	}
-	digest, err := crane.Digest(dst, crane.WithAuthFromKeychain(keychain))
+	digest, err := crane.Digest(dst, crane.WithAuthFromKeychain(oci.Keychain))
 	if err != nil {
 		return "", fmt.Errorf("could not get digest of %q: %w", dst, err)
 	}
@@ -0,0 +1,93 @@
+package macospkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// cpioEntry is a file in a cpio archive.
+type cpioEntry struct {
+	name    string
+	mode    fs.FileMode
+	modTime time.Time
+	data    []byte
+	// path is the file to copy the contents from instead of data, with the
+	// given size, so they are streamed into the archive.
+	path string
+	size int64
+}
+
+// copy writes the contents of the entry to w.
+func (e cpioEntry) copy(w io.Writer) error {
+	if e.path == "" {
+		_, err := w.Write(e.data)
+		return err
+	}
+	f, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(w, f)
+	if err != nil {
+		return err
+	}
+	if n != e.size {
+		return fmt.Errorf("%s: file changed while writing the archive", e.path)
+	}
+	return nil
+}
+
+// cpioArchive creates a gzipped cpio archive in the "odc" format, which is
+// what installer packages use for their Payload and Scripts.
+func cpioArchive(entries []cpioEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	write := func(e cpioEntry, ino int) error {
+		mode := uint32(e.mode.Perm())
+		if e.mode.IsDir() {
+			mode |= 0o040000
+		} else {
+			mode |= 0o100000
+		}
+		size := int64(len(e.data))
+		if e.path != "" {
+			size = e.size
+		}
+		if _, err := fmt.Fprintf(
+			gw,
+			"070707%06o%06o%06o%06o%06o%06o%06o%011o%06o%011o%s\x00",
+			0,   // dev
+			ino, // ino
+			mode,
+			0, // uid
+			0, // gid
+			1, // nlink
+			0, // rdev
+			max(0, e.modTime.Unix()),
+			len(e.name)+1,
+			size,
+			e.name,
+		); err != nil {
+			return err
+		}
+		return e.copy(gw)
+	}
+	for i, e := range entries {
+		if err := write(e, i+1); err != nil {
+			return nil, fmt.Errorf("cpio: %w", err)
+		}
+	}
+	if err := write(cpioEntry{name: "TRAILER!!!"}, 0); err != nil {
+		return nil, fmt.Errorf("cpio: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("cpio: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,423 @@
+// Package macospkg implements the Pipe interface creating macOS flat installer
+// packages (.pkg).
+package macospkg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/bom"
+	"github.com/goreleaser/goreleaser/v2/internal/gio"
+	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/internal/xar"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	defaultNameTemplate    = `{{ .ProjectName }}_{{ .Arch }}`
+	defaultInstallLocation = "/usr/local/bin"
+)
+
+// Pipe for macOS installer packages.
+type Pipe struct{}
+
+func (Pipe) String() string { return "macOS packages" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return skips.Any(ctx, skips.MacOSPkg) || len(ctx.Config.MacOSPkgs) == 0
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("macos_pkgs")
+	for i := range ctx.Config.MacOSPkgs {
+		pkg := &ctx.Config.MacOSPkgs[i]
+		if pkg.ID == "" {
+			pkg.ID = ctx.Config.ProjectName
+		}
+		if pkg.Name == "" {
+			pkg.Name = defaultNameTemplate
+		}
+		if pkg.InstallLocation == "" {
+			pkg.InstallLocation = defaultInstallLocation
+		}
+		if pkg.Goamd64 == "" {
+			pkg.Goamd64 = "v1"
+		}
+		ids.Inc(pkg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, cfg := range ctx.Config.MacOSPkgs {
+		g.Go(func() error {
+			return doRun(ctx, cfg)
+		})
+	}
+	return g.Wait()
+}
+
+func doRun(ctx *context.Context, cfg config.MacOSPkg) error {
+	groups := darwinBinaries(ctx, cfg.IDs, cfg.Goamd64)
+	if len(groups) == 0 {
+		return fmt.Errorf("no darwin binaries found for builds %v", cfg.IDs)
+	}
+
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, binaries := range groups {
+		g.Go(func() error {
+			return create(ctx, cfg, binaries)
+		})
+	}
+	return g.Wait()
+}
+
+// darwinBinaries returns the darwin binaries and universal binaries with the
+// given ids, grouped by architecture.
+func darwinBinaries(ctx *context.Context, ids []string, goamd64 string) map[string][]*artifact.Artifact {
+	result := map[string][]*artifact.Artifact{}
+	for _, a := range ctx.Artifacts.Filter(artifact.And(
+		artifact.ByGoos("darwin"),
+		artifact.ByTypes(artifact.Binary, artifact.UniversalBinary),
+		artifact.ByIDs(ids...),
+		artifact.ByGoamd64OrOtherArch(goamd64),
+	)).List() {
+		result[a.Goarch] = append(result[a.Goarch], a)
+	}
+	return result
+}
+
+// replaceArchives removes the darwin archives with the given architecture
+// from the artifact list.
+func replaceArchives(ctx *context.Context, goarch, goamd64 string) error {
+	return ctx.Artifacts.Remove(artifact.And(
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByGoos("darwin"),
+		artifact.ByGoarch(goarch),
+		artifact.ByGoamd64OrOtherArch(goamd64),
+	))
+}
+
+func create(ctx *context.Context, cfg config.MacOSPkg, binaries []*artifact.Artifact) error {
+	binary := binaries[0]
+	tpl := tmpl.New(ctx).WithArtifact(binary)
+
+	name := cfg.Name
+	identifier := cfg.Identifier
+	installLocation := cfg.InstallLocation
+	scripts := cfg.Scripts
+	modTimestamp := cfg.ModTimestamp
+	if err := tpl.ApplyAll(
+		&name,
+		&identifier,
+		&installLocation,
+		&scripts,
+		&modTimestamp,
+	); err != nil {
+		return err
+	}
+	if identifier == "" {
+		return errors.New("identifier is required")
+	}
+	mtime, err := gio.ModTime(modTimestamp, ctx.Date)
+	if err != nil {
+		return err
+	}
+
+	filename := name + ".pkg"
+	path := filepath.Join(ctx.Config.Dist, filename)
+	log.WithField("package", path).Info("creating")
+
+	payload := []cpioEntry{{name: ".", mode: fs.ModeDir | 0o755, modTime: mtime}}
+	entries := make([]bom.Entry, 0, len(binaries))
+	var installBytes int64
+	for _, bin := range binaries {
+		sum, err := checksum(bin.Path)
+		if err != nil {
+			return fmt.Errorf("could not read binary: %w", err)
+		}
+		payload = append(payload, cpioEntry{
+			name:    "./" + bin.Name,
+			mode:    0o755,
+			modTime: mtime,
+			path:    bin.Path,
+			size:    sum.Size(),
+		})
+		entries = append(entries, bom.Entry{
+			Path:     bin.Name,
+			Mode:     0o755,
+			ModTime:  mtime,
+			Size:     uint32(sum.Size()),
+			Checksum: sum.Sum32(),
+		})
+		installBytes += sum.Size()
+	}
+
+	payloadArchive, err := cpioArchive(payload)
+	if err != nil {
+		return err
+	}
+	var bomFile bytes.Buffer
+	if err := bom.Write(&bomFile, bom.Entry{Mode: 0o755, ModTime: mtime}, entries); err != nil {
+		return err
+	}
+
+	info := pkgInfo{
+		FormatVersion:   2,
+		Identifier:      identifier,
+		Version:         ctx.Version,
+		InstallLocation: installLocation,
+		Auth:            "root",
+		Payload: pkgPayload{
+			NumberOfFiles: len(payload),
+			InstallKBytes: (installBytes + 1023) / 1024,
+		},
+	}
+
+	component := name + ".pkg/"
+	files := []xar.File{
+		{Name: component + "Bom", Mode: 0o644, ModTime: mtime, Data: bomFile.Bytes()},
+		{Name: component + "Payload", Mode: 0o644, ModTime: mtime, Data: payloadArchive},
+	}
+
+	if scripts != "" {
+		archive, names, err := scriptsArchive(scripts, mtime)
+		if err != nil {
+			return err
+		}
+		info.Scripts = &pkgScripts{}
+		if slices.Contains(names, "preinstall") {
+			info.Scripts.Preinstall = &pkgScript{File: "./preinstall"}
+		}
+		if slices.Contains(names, "postinstall") {
+			info.Scripts.Postinstall = &pkgScript{File: "./postinstall"}
+		}
+		files = append(files, xar.File{Name: component + "Scripts", Mode: 0o644, ModTime: mtime, Data: archive})
+	}
+
+	infoXML, err := marshal(info)
+	if err != nil {
+		return err
+	}
+	dist, err := marshal(newDistribution(ctx.Config.ProjectName, identifier, ctx.Version, binary.Goarch, component, info.Payload.InstallKBytes))
+	if err != nil {
+		return err
+	}
+	files = append(
+		files,
+		xar.File{Name: component + "PackageInfo", Mode: 0o644, ModTime: mtime, Data: infoXML},
+		xar.File{Name: "Distribution", Mode: 0o644, ModTime: mtime, Data: dist},
+	)
+
+	if err := os.MkdirAll(ctx.Config.Dist, 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := xar.Write(out, files, mtime); err != nil {
+		return fmt.Errorf("could not create package: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := gio.Chtimes(path, modTimestamp); err != nil {
+		return err
+	}
+
+	if cfg.Replace {
+		if err := replaceArchives(ctx, binary.Goarch, cfg.Goamd64); err != nil {
+			return err
+		}
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:    artifact.MacOSPkg,
+		Name:    filename,
+		Path:    path,
+		Goos:    binary.Goos,
+		Goarch:  binary.Goarch,
+		Goamd64: binary.Goamd64,
+		Target:  binary.Target,
+		Extra: map[string]any{
+			artifact.ExtraID:     cfg.ID,
+			artifact.ExtraFormat: "pkg",
+			artifact.ExtraExt:    ".pkg",
+		},
+	})
+	return nil
+}
+
+// checksum streams the given file into a bill of materials checksum.
+func checksum(path string) (*bom.Cksum, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var sum bom.Cksum
+	if _, err := io.Copy(&sum, f); err != nil {
+		return nil, err
+	}
+	return &sum, nil
+}
+
+// scriptsArchive creates the Scripts archive from the files in the given
+// directory, returning it and the names of the files in it.
+func scriptsArchive(dir string, mtime time.Time) ([]byte, []string, error) {
+	entries := []cpioEntry{{name: ".", mode: fs.ModeDir | 0o755, modTime: mtime}}
+	var names []string
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			entries = append(entries, cpioEntry{name: "./" + rel, mode: fs.ModeDir | 0o755, modTime: mtime})
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, cpioEntry{name: "./" + rel, mode: 0o755, modTime: mtime, data: data})
+		names = append(names, rel)
+		return nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("could not read scripts: %w", err)
+	}
+	archive, err := cpioArchive(entries)
+	return archive, names, err
+}
+
+func marshal(v any) ([]byte, error) {
+	bts, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), bts...), nil
+}
+
+// hostArchitectures returns the macOS architectures for the given goarch.
+func hostArchitectures(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "arm64"
+	default:
+		return "x86_64,arm64"
+	}
+}
+
+type pkgInfo struct {
+	XMLName         xml.Name    `xml:"pkg-info"`
+	FormatVersion   int         `xml:"format-version,attr"`
+	Identifier      string      `xml:"identifier,attr"`
+	Version         string      `xml:"version,attr"`
+	InstallLocation string      `xml:"install-location,attr"`
+	Auth            string      `xml:"auth,attr"`
+	Payload         pkgPayload  `xml:"payload"`
+	Scripts         *pkgScripts `xml:"scripts,omitempty"`
+}
+
+type pkgPayload struct {
+	NumberOfFiles int   `xml:"numberOfFiles,attr"`
+	InstallKBytes int64 `xml:"installKBytes,attr"`
+}
+
+type pkgScripts struct {
+	Preinstall  *pkgScript `xml:"preinstall,omitempty"`
+	Postinstall *pkgScript `xml:"postinstall,omitempty"`
+}
+
+type pkgScript struct {
+	File string `xml:"file,attr"`
+}
+
+// distribution is the Distribution file of a product archive, which is what
+// the macOS installer, and MDM solutions expect.
+type distribution struct {
+	XMLName        xml.Name    `xml:"installer-gui-script"`
+	MinSpecVersion int         `xml:"minSpecVersion,attr"`
+	Title          string      `xml:"title"`
+	Options        distOptions `xml:"options"`
+	Outline        distLine    `xml:"choices-outline>line"`
+	Choices        []distChoice
+	PkgRefs        []distPkgRef
+}
+
+type distOptions struct {
+	Customize         string `xml:"customize,attr"`
+	RequireScripts    bool   `xml:"require-scripts,attr"`
+	HostArchitectures string `xml:"hostArchitectures,attr"`
+}
+
+type distLine struct {
+	Choice string     `xml:"choice,attr"`
+	Lines  []distLine `xml:"line"`
+}
+
+type distChoice struct {
+	XMLName xml.Name     `xml:"choice"`
+	ID      string       `xml:"id,attr"`
+	Visible *bool        `xml:"visible,attr"`
+	PkgRefs []distPkgRef `xml:"pkg-ref"`
+}
+
+type distPkgRef struct {
+	XMLName       xml.Name `xml:"pkg-ref"`
+	ID            string   `xml:"id,attr"`
+	Version       string   `xml:"version,attr,omitempty"`
+	InstallKBytes int64    `xml:"installKBytes,attr,omitempty"`
+	Path          string   `xml:",chardata"`
+}
+
+func newDistribution(title, identifier, version, goarch, component string, installKBytes int64) distribution {
+	visible := false
+	return distribution{
+		MinSpecVersion: 2,
+		Title:          title,
+		Options: distOptions{
+			Customize:         "never",
+			HostArchitectures: hostArchitectures(goarch),
+		},
+		Outline: distLine{
+			Choice: "default",
+			Lines:  []distLine{{Choice: identifier}},
+		},
+		Choices: []distChoice{
+			{ID: "default"},
+			{ID: identifier, Visible: &visible, PkgRefs: []distPkgRef{{ID: identifier}}},
+		},
+		PkgRefs: []distPkgRef{{
+			ID:            identifier,
+			Version:       version,
+			InstallKBytes: installKBytes,
+			Path:          "#" + strings.TrimSuffix(component, "/"),
+		}},
+	}
+}
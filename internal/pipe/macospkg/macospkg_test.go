@@ -0,0 +1,249 @@
+package macospkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/internal/xar"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(testctx.Wrap(t.Context())))
+	})
+	t.Run("skip flag", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			MacOSPkgs: []config.MacOSPkg{{}},
+		}, testctx.Skip(skips.MacOSPkg))
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			MacOSPkgs: []config.MacOSPkg{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		MacOSPkgs:   []config.MacOSPkg{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.MacOSPkg{
+		ID:              "foo",
+		Name:            defaultNameTemplate,
+		InstallLocation: defaultInstallLocation,
+		Goamd64:         "v1",
+	}, ctx.Config.MacOSPkgs[0])
+}
+
+func TestDefaultDuplicatedID(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		MacOSPkgs:   []config.MacOSPkg{{}, {}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 macos_pkgs with the ID 'foo', please fix your config")
+}
+
+func TestRun(t *testing.T) {
+	dist := t.TempDir()
+	scripts := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(scripts, "postinstall"), []byte("#!/bin/sh\necho hi"), 0o644))
+
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		MacOSPkgs: []config.MacOSPkg{{
+			Identifier:   "com.example.{{ .ProjectName }}",
+			Scripts:      scripts,
+			Replace:      true,
+			ModTimestamp: "{{ .CommitTimestamp }}",
+		}},
+	}, testctx.WithVersion("1.2.3"), testctx.WithCommitDate(time.Unix(1704164645, 0)))
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo-bin", "darwin_amd64", "darwin_arm64", "darwin_all", "linux_amd64")
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	pkgs := ctx.Artifacts.Filter(artifact.ByType(artifact.MacOSPkg)).List()
+	require.Len(t, pkgs, 3)
+	for _, pkg := range pkgs {
+		require.Equal(t, "darwin", pkg.Goos)
+		require.Equal(t, "foo_"+pkg.Goarch+".pkg", pkg.Name)
+		require.Equal(t, filepath.Join(dist, pkg.Name), pkg.Path)
+		require.Equal(t, "foo", artifact.ExtraOr(*pkg, artifact.ExtraID, ""))
+		require.Equal(t, "pkg", artifact.ExtraOr(*pkg, artifact.ExtraFormat, ""))
+		require.Equal(t, ".pkg", artifact.ExtraOr(*pkg, artifact.ExtraExt, ""))
+
+		info, err := os.Stat(pkg.Path)
+		require.NoError(t, err)
+		require.Equal(t, int64(1704164645), info.ModTime().Unix())
+	}
+
+	// only the linux archive is kept.
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "linux", archives[0].Goos)
+
+	bts, err := os.ReadFile(filepath.Join(dist, "foo_arm64.pkg"))
+	require.NoError(t, err)
+	archive, err := xar.Read(bts)
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range archive.Files {
+		files[f.Name] = f.Data
+		require.Equal(t, int64(1704164645), f.ModTime.Unix(), f.Name)
+	}
+	require.Contains(t, files, "foo_arm64.pkg/Bom")
+
+	var info pkgInfo
+	require.NoError(t, xml.Unmarshal(files["foo_arm64.pkg/PackageInfo"], &info))
+	require.Equal(t, "com.example.foo", info.Identifier)
+	require.Equal(t, "1.2.3", info.Version)
+	require.Equal(t, "/usr/local/bin", info.InstallLocation)
+	require.Equal(t, pkgPayload{NumberOfFiles: 2, InstallKBytes: 1}, info.Payload)
+	require.Equal(t, &pkgScripts{Postinstall: &pkgScript{File: "./postinstall"}}, info.Scripts)
+
+	var distFile distribution
+	require.NoError(t, xml.Unmarshal(files["Distribution"], &distFile))
+	require.Equal(t, "foo", distFile.Title)
+	require.Equal(t, "arm64", distFile.Options.HostArchitectures)
+
+	require.Equal(t, map[string]string{
+		".":         "",
+		"./foo-bin": "darwin/arm64",
+	}, readCPIO(t, files["foo_arm64.pkg/Payload"]))
+	require.Equal(t, map[string]string{
+		".":             "",
+		"./postinstall": "#!/bin/sh\necho hi",
+	}, readCPIO(t, files["foo_arm64.pkg/Scripts"]))
+
+	t.Run("universal", func(t *testing.T) {
+		bts, err := os.ReadFile(filepath.Join(dist, "foo_all.pkg"))
+		require.NoError(t, err)
+		archive, err := xar.Read(bts)
+		require.NoError(t, err)
+		for _, f := range archive.Files {
+			if f.Name != "Distribution" {
+				continue
+			}
+			var dist distribution
+			require.NoError(t, xml.Unmarshal(f.Data, &dist))
+			require.Equal(t, "x86_64,arm64", dist.Options.HostArchitectures)
+		}
+	})
+}
+
+func TestRunErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		pkg config.MacOSPkg
+		err string
+	}{
+		"no identifier": {
+			pkg: config.MacOSPkg{},
+			err: "identifier is required",
+		},
+		"bad name": {
+			pkg: config.MacOSPkg{Identifier: "foo", Name: "{{ .Nope }"},
+			err: "template: failed to apply",
+		},
+		"bad identifier": {
+			pkg: config.MacOSPkg{Identifier: "{{ .Nope }"},
+			err: "template: failed to apply",
+		},
+		"invalid mod_timestamp": {
+			pkg: config.MacOSPkg{Identifier: "foo", ModTimestamp: "nope"},
+			err: "invalid mod_timestamp",
+		},
+		"missing scripts": {
+			pkg: config.MacOSPkg{Identifier: "foo", Scripts: "./testdata/nope"},
+			err: "could not read scripts",
+		},
+		"no binaries": {
+			pkg: config.MacOSPkg{Identifier: "foo", IDs: []string{"nope"}},
+			err: "no darwin binaries found for builds [nope]",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dist := t.TempDir()
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+				ProjectName: "foo",
+				Dist:        dist,
+				MacOSPkgs:   []config.MacOSPkg{tc.pkg},
+			})
+			testlib.AddBinaries(t, ctx.Artifacts, dist, "foo-bin", "darwin_amd64", "darwin_arm64", "darwin_all", "linux_amd64")
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.ErrorContains(t, Pipe{}.Run(ctx), tc.err)
+		})
+	}
+}
+
+func TestRunMissingBinary(t *testing.T) {
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		MacOSPkgs:   []config.MacOSPkg{{Identifier: "foo"}},
+	})
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo-bin", "darwin_arm64")
+	require.NoError(t, os.Remove(ctx.Artifacts.List()[0].Path))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.ErrorIs(t, Pipe{}.Run(ctx), os.ErrNotExist)
+}
+
+func TestRunKeepArchives(t *testing.T) {
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		MacOSPkgs:   []config.MacOSPkg{{Identifier: "foo"}},
+	})
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo-bin", "darwin_amd64", "darwin_arm64", "darwin_all", "linux_amd64")
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List(), 4)
+}
+
+// readCPIO reads a gzipped odc cpio archive, returning the contents of each
+// entry by name.
+func readCPIO(tb testing.TB, bts []byte) map[string]string {
+	tb.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(bts))
+	require.NoError(tb, err)
+	raw, err := io.ReadAll(gr)
+	require.NoError(tb, err)
+
+	result := map[string]string{}
+	for {
+		require.Equal(tb, "070707", string(raw[:6]))
+		nameSize, err := strconv.ParseUint(string(raw[59:65]), 8, 32)
+		require.NoError(tb, err)
+		size, err := strconv.ParseUint(string(raw[65:76]), 8, 64)
+		require.NoError(tb, err)
+		name := string(raw[76 : 76+nameSize-1])
+		raw = raw[76+nameSize:]
+		if name == "TRAILER!!!" {
+			return result
+		}
+		result[name] = string(raw[:size])
+		raw = raw[size:]
+	}
+}
@@ -0,0 +1,381 @@
+// Package msi implements the Pipe interface creating Windows installer
+// packages (.msi).
+package msi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/google/uuid"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/gerrors"
+	"github.com/goreleaser/goreleaser/v2/internal/gio"
+	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/logext"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/redact"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	defaultNameTemplate = `{{ .ProjectName }}_{{ .MsiArch }}`
+	defaultScope        = "perMachine"
+
+	schemaV3 = "v3"
+	schemaV4 = "v4"
+)
+
+// ErrNoWixl happens when msitools' wixl is not available.
+var ErrNoWixl = errors.New("wixl not present in $PATH")
+
+// Pipe for MSI installers.
+type Pipe struct{}
+
+func (Pipe) String() string { return "msi installers" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return skips.Any(ctx, skips.WindowsMSI) || len(ctx.Config.WindowsMSIs) == 0
+}
+
+func (Pipe) Dependencies(*context.Context) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"wix"}
+	}
+	return []string{"wixl"}
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("windows_msis")
+	for i := range ctx.Config.WindowsMSIs {
+		msi := &ctx.Config.WindowsMSIs[i]
+		if msi.ID == "" {
+			msi.ID = ctx.Config.ProjectName
+		}
+		if msi.Name == "" {
+			msi.Name = defaultNameTemplate
+		}
+		if msi.Goamd64 == "" {
+			msi.Goamd64 = "v1"
+		}
+		if msi.Manufacturer == "" {
+			msi.Manufacturer = "{{ .ProjectName }}"
+		}
+		if msi.UpgradeCode == "" {
+			msi.UpgradeCode = upgradeCode(ctx.Config.ProjectName, msi.ID)
+		}
+		if msi.Scope == "" {
+			msi.Scope = defaultScope
+		}
+		if msi.Scope != "perMachine" && msi.Scope != "perUser" {
+			return fmt.Errorf("msi: invalid scope %q, valid options are perMachine and perUser", msi.Scope)
+		}
+		if msi.Version != "" && msi.Version != schemaV3 && msi.Version != schemaV4 {
+			return fmt.Errorf("msi: invalid version %q, valid options are v3 and v4", msi.Version)
+		}
+		ids.Inc(msi.ID)
+	}
+	return ids.Validate()
+}
+
+// upgradeCode derives a stable upgrade code from the project name and the msi
+// id, so new versions replace the old ones when installed.
+func upgradeCode(projectName, id string) string {
+	return strings.ToUpper(uuid.NewSHA1(
+		uuid.NameSpaceURL,
+		[]byte("https://goreleaser.com/msi/"+projectName+"/"+id),
+	).String())
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	// even if one of them is disabled, we still go through all of them, and
+	// return the skips all at once in the end.
+	skips := pipe.SkipMemento{}
+	for _, msi := range ctx.Config.WindowsMSIs {
+		err := doRun(ctx, msi)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, msi config.WindowsMSI) error {
+	disable, err := tmpl.New(ctx).Bool(msi.Disable)
+	if err != nil {
+		return err
+	}
+	if disable {
+		return pipe.Skip("configuration is disabled")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.ByType(artifact.Binary),
+		artifact.ByGoarches("amd64", "386", "arm64"),
+		artifact.Or(
+			artifact.Not(artifact.ByGoarch("amd64")),
+			artifact.ByGoamd64(msi.Goamd64),
+		),
+	}
+	if len(msi.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(msi.IDs...))
+	}
+	groups := ctx.Artifacts.Filter(artifact.And(filters...)).GroupByPlatform()
+	if len(groups) == 0 {
+		return fmt.Errorf("no windows binaries found for builds %v", msi.IDs)
+	}
+
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, binaries := range groups {
+		g.Go(func() error {
+			return create(ctx, msi, binaries)
+		})
+	}
+	return g.Wait()
+}
+
+func create(ctx *context.Context, msi config.WindowsMSI, binaries []*artifact.Artifact) error {
+	binary := binaries[0]
+	arch := msiArch(binary.Goarch)
+	tpl := tmpl.New(ctx).
+		WithArtifact(binary).
+		WithExtraFields(tmpl.Fields{
+			"MsiArch": arch,
+		})
+
+	name := msi.Name
+	wxs := msi.WXS
+	manufacturer := msi.Manufacturer
+	code := msi.UpgradeCode
+	modTimestamp := msi.ModTimestamp
+	if err := tpl.ApplyAll(
+		&name,
+		&wxs,
+		&manufacturer,
+		&code,
+		&modTimestamp,
+	); err != nil {
+		return err
+	}
+	extensions, err := tpl.Slice(msi.Extensions, tmpl.NonEmpty())
+	if err != nil {
+		return err
+	}
+	extraFiles, err := tpl.Slice(msi.ExtraFiles, tmpl.NonEmpty())
+	if err != nil {
+		return err
+	}
+
+	upgradeCode, err := uuid.Parse(code)
+	if err != nil {
+		return fmt.Errorf("invalid upgrade_code: %w", err)
+	}
+	version, err := productVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	workDir := filepath.Join(ctx.Config.Dist, "msi", name)
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create msi work directory: %w", err)
+	}
+
+	files := make([]msiFile, 0, len(binaries)+len(extraFiles))
+	for _, bin := range binaries {
+		files = append(files, msiFile{Name: bin.Name, Source: bin.Name})
+		if err := gio.Copy(bin.Path, filepath.Join(workDir, bin.Name)); err != nil {
+			return fmt.Errorf("failed to copy binary: %w", err)
+		}
+	}
+	for _, src := range extraFiles {
+		base := filepath.Base(src)
+		// all files are installed in the same directory.
+		if slices.ContainsFunc(files, func(f msiFile) bool { return f.Name == base }) {
+			return fmt.Errorf("extra file %s: a file named %q is already in the msi", src, base)
+		}
+		files = append(files, msiFile{Name: base, Source: base})
+		if err := gio.Copy(src, filepath.Join(workDir, base)); err != nil {
+			return fmt.Errorf("failed to copy extra file: %w", err)
+		}
+	}
+	for i := range files {
+		files[i].ID = fmt.Sprintf("File%d", i)
+	}
+
+	content := defaultWXS
+	if wxs != "" {
+		bts, err := os.ReadFile(wxs)
+		if err != nil {
+			return fmt.Errorf("failed to read wxs: %w", err)
+		}
+		content = string(bts)
+	}
+	content, err = tpl.WithExtraFields(tmpl.Fields{
+		"ProductVersion": version,
+		"UpgradeCode":    strings.ToUpper(upgradeCode.String()),
+		"ComponentGUID":  strings.ToUpper(uuid.NewSHA1(upgradeCode, []byte("files/"+arch)).String()),
+		"Manufacturer":   manufacturer,
+		"Scope":          msi.Scope,
+		"AddToPath":      msi.AddToPath,
+		"Files":          files,
+	}).Apply(content)
+	if err != nil {
+		return err
+	}
+
+	schema := msi.Version
+	if schema == "" {
+		schema = schemaV3
+		if strings.Contains(content, "http://wixtoolset.org/schemas/v4/wxs") {
+			schema = schemaV4
+		}
+	}
+
+	wxsName := name + ".wxs"
+	if err := os.WriteFile(filepath.Join(workDir, wxsName), []byte(content), 0o644); err != nil {
+		return err
+	}
+
+	if runtime.GOOS != "windows" {
+		if schema != schemaV3 {
+			return fmt.Errorf("msitools only supports the %s schema, got %s", schemaV3, schema)
+		}
+		if _, err := exec.LookPath("wixl"); err != nil {
+			return ErrNoWixl
+		}
+	}
+
+	filename := name + ".msi"
+	path, err := filepath.Abs(filepath.Join(ctx.Config.Dist, filename))
+	if err != nil {
+		return err
+	}
+	log.WithField("installer", filename).WithField("schema", schema).Info("creating")
+	for _, args := range buildCommands(schema, arch, wxsName, path, extensions) {
+		if err := runCmd(ctx, workDir, args...); err != nil {
+			return err
+		}
+	}
+	if err := gio.Chtimes(path, modTimestamp); err != nil {
+		return err
+	}
+
+	if msi.Replace {
+		if err := ctx.Artifacts.Remove(artifact.And(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByGoos("windows"),
+			artifact.ByGoarch(binary.Goarch),
+			artifact.Or(
+				artifact.Not(artifact.ByGoarch("amd64")),
+				artifact.ByGoamd64(msi.Goamd64),
+			),
+		)); err != nil {
+			return err
+		}
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:    artifact.MSI,
+		Name:    filename,
+		Path:    filepath.Join(ctx.Config.Dist, filename),
+		Goos:    binary.Goos,
+		Goarch:  binary.Goarch,
+		Goamd64: binary.Goamd64,
+		Target:  binary.Target,
+		Extra: map[string]any{
+			artifact.ExtraID:     msi.ID,
+			artifact.ExtraFormat: "msi",
+			artifact.ExtraExt:    ".msi",
+		},
+	})
+	return nil
+}
+
+// buildCommands returns the commands needed to build the installer.
+//
+// msitools is used everywhere but on Windows, where the WiX Toolset is used
+// instead.
+func buildCommands(schema, arch, wxs, out string, extensions []string) [][]string {
+	var exts []string
+	for _, ext := range extensions {
+		exts = append(exts, "-ext", ext)
+	}
+	if runtime.GOOS != "windows" {
+		var wixlExts []string
+		for _, ext := range extensions {
+			wixlExts = append(wixlExts, "--ext", ext)
+		}
+		return [][]string{
+			append(append([]string{"wixl", "-a", arch}, wixlExts...), "-o", out, wxs),
+		}
+	}
+	if schema == schemaV4 {
+		return [][]string{
+			append(append([]string{"wix", "build", "-nologo", "-arch", arch}, exts...), "-o", out, wxs),
+		}
+	}
+	obj := strings.TrimSuffix(wxs, ".wxs") + ".wixobj"
+	return [][]string{
+		append(append([]string{"candle", "-nologo", "-arch", arch}, exts...), "-out", obj, wxs),
+		append(append([]string{"light", "-nologo"}, exts...), "-out", out, obj),
+	}
+}
+
+// productVersion returns the MSI ProductVersion for the current version.
+//
+// It only allows numbers, and the major and minor versions are limited to 255.
+func productVersion(ctx *context.Context) (string, error) {
+	v := ctx.Semver
+	if v.Major > 255 || v.Minor > 255 || v.Patch > 65535 {
+		return "", fmt.Errorf("version %s can't be used as a msi product version: major and minor must be at most 255, and patch at most 65535", ctx.Version)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch), nil
+}
+
+func msiArch(goarch string) string {
+	switch goarch {
+	case "386":
+		return "x86"
+	case "arm64":
+		return "arm64"
+	default:
+		return "x64"
+	}
+}
+
+func runCmd(ctx *context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(ctx.Env.Strings(), cmd.Environ()...)
+	var b bytes.Buffer
+	w := gio.Safe(&b)
+	cmd.Stderr = redact.Writer(io.MultiWriter(logext.NewWriter(), w), cmd.Env)
+	cmd.Stdout = redact.Writer(io.MultiWriter(logext.NewWriter(), w), cmd.Env)
+	if err := cmd.Run(); err != nil {
+		return gerrors.Wrap(
+			err,
+			gerrors.WithMessage("failed to create msi"),
+			gerrors.WithDetails("args", strings.Join(cmd.Args, " ")),
+			gerrors.WithOutput(b.String()),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package msi
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(testctx.Wrap(t.Context())))
+	})
+	t.Run("skip flag", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			WindowsMSIs: []config.WindowsMSI{{}},
+		}, testctx.Skip(skips.WindowsMSI))
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			WindowsMSIs: []config.WindowsMSI{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.Dependencies(nil))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		WindowsMSIs: []config.WindowsMSI{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.WindowsMSI{
+		ID:           "foo",
+		Name:         defaultNameTemplate,
+		Goamd64:      "v1",
+		Manufacturer: "{{ .ProjectName }}",
+		UpgradeCode:  "FFAFA809-33A8-507B-BE3D-E98F40E6EC2E",
+		Scope:        "perMachine",
+	}, ctx.Config.WindowsMSIs[0])
+}
+
+func TestDefaultUpgradeCode(t *testing.T) {
+	require.Equal(t, upgradeCode("foo", "foo"), upgradeCode("foo", "foo"))
+	require.NotEqual(t, upgradeCode("foo", "foo"), upgradeCode("foo", "bar"))
+	require.NotEqual(t, upgradeCode("foo", "foo"), upgradeCode("bar", "foo"))
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		msis []config.WindowsMSI
+		err  string
+	}{
+		"duplicated id": {
+			msis: []config.WindowsMSI{{}, {}},
+			err:  "found 2 windows_msis with the ID 'foo', please fix your config",
+		},
+		"invalid scope": {
+			msis: []config.WindowsMSI{{Scope: "nope"}},
+			err:  `msi: invalid scope "nope", valid options are perMachine and perUser`,
+		},
+		"invalid version": {
+			msis: []config.WindowsMSI{{Version: "v5"}},
+			err:  `msi: invalid version "v5", valid options are v3 and v4`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+				ProjectName: "foo",
+				WindowsMSIs: tc.msis,
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tc.err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	testlib.SkipIfWindows(t, "uses a fake wixl script")
+	fakeWixl(t)
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		WindowsMSIs: []config.WindowsMSI{{
+			Name:         "{{ .ProjectName }}_{{ .Version }}_{{ .MsiArch }}",
+			ExtraFiles:   []string{"./testdata/README.md"},
+			Extensions:   []string{"ui"},
+			AddToPath:    true,
+			Replace:      true,
+			ModTimestamp: "{{ .CommitTimestamp }}",
+		}},
+	},
+		testctx.WithVersion("1.2.3"),
+		testctx.WithSemver(1, 2, 3, ""),
+		testctx.WithCommitDate(time.Unix(1704164645, 0)),
+	)
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo", "windows_amd64", "windows_386", "windows_arm64", "linux_amd64")
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	installers := ctx.Artifacts.Filter(artifact.ByType(artifact.MSI)).List()
+	require.Len(t, installers, 3)
+	for _, msi := range installers {
+		arch := msiArch(msi.Goarch)
+		require.Equal(t, "windows", msi.Goos)
+		require.Equal(t, "foo_1.2.3_"+arch+".msi", msi.Name)
+		require.Equal(t, filepath.Join(dist, msi.Name), msi.Path)
+		require.Equal(t, "foo", artifact.ExtraOr(*msi, artifact.ExtraID, ""))
+		require.Equal(t, "msi", artifact.ExtraOr(*msi, artifact.ExtraFormat, ""))
+		require.Equal(t, ".msi", artifact.ExtraOr(*msi, artifact.ExtraExt, ""))
+
+		info, err := os.Stat(msi.Path)
+		require.NoError(t, err)
+		require.Equal(t, int64(1704164645), info.ModTime().Unix())
+
+		abs, err := filepath.Abs(msi.Path)
+		require.NoError(t, err)
+		bts, err := os.ReadFile(msi.Path)
+		require.NoError(t, err)
+		require.Equal(t, "-a "+arch+" --ext ui -o "+abs+" foo_1.2.3_"+arch+".wxs\n", string(bts))
+
+		workDir := filepath.Join(dist, "msi", "foo_1.2.3_"+arch)
+		require.FileExists(t, filepath.Join(workDir, "foo.exe"))
+		require.FileExists(t, filepath.Join(workDir, "README.md"))
+
+		wxs := readWXS(t, filepath.Join(workDir, "foo_1.2.3_"+arch+".wxs"))
+		require.Equal(t, "1.2.3", wxs.Product.Version)
+		require.Equal(t, "foo", wxs.Product.Manufacturer)
+		require.Equal(t, ctx.Config.WindowsMSIs[0].UpgradeCode, wxs.Product.UpgradeCode)
+		require.Equal(t, "perMachine", wxs.Product.Package.InstallScope)
+
+		dir := wxs.Product.Directory.Directory
+		if arch == "x86" {
+			require.Equal(t, "ProgramFilesFolder", dir.ID)
+		} else {
+			require.Equal(t, "ProgramFiles64Folder", dir.ID)
+		}
+		component := dir.Directory.Component
+		require.Equal(t, []wxsFile{
+			{ID: "File0", Name: "foo.exe", Source: "foo.exe", KeyPath: "yes"},
+			{ID: "File1", Name: "README.md", Source: "README.md"},
+		}, component.Files)
+		require.Equal(t, "[INSTALLDIR]", component.Environment.Value)
+		require.Equal(t, "yes", component.Environment.System)
+	}
+
+	// only the linux archive is kept.
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "linux", archives[0].Goos)
+}
+
+func TestRunPerUser(t *testing.T) {
+	testlib.SkipIfWindows(t, "uses a fake wixl script")
+	fakeWixl(t)
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		WindowsMSIs: []config.WindowsMSI{{
+			IDs:       []string{"foo"},
+			Scope:     "perUser",
+			AddToPath: true,
+		}},
+	}, testctx.WithVersion("1.2.3"), testctx.WithSemver(1, 2, 3, ""))
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo", "windows_amd64", "windows_386", "windows_arm64", "linux_amd64")
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	wxs := readWXS(t, filepath.Join(dist, "msi", "foo_x64", "foo_x64.wxs"))
+	require.Equal(t, "perUser", wxs.Product.Package.InstallScope)
+	dir := wxs.Product.Directory.Directory
+	require.Equal(t, "LocalAppDataFolder", dir.ID)
+	component := dir.Directory.Directory.Component
+	require.Equal(t, []wxsFile{
+		{ID: "File0", Name: "foo.exe", Source: "foo.exe"},
+	}, component.Files)
+	require.Equal(t, "no", component.Environment.System)
+
+	// archives are kept.
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List(), 4)
+}
+
+func TestRunCustomWXS(t *testing.T) {
+	testlib.SkipIfWindows(t, "uses a fake wixl script")
+	fakeWixl(t)
+	dist := t.TempDir()
+	wxs := filepath.Join(t.TempDir(), "app.wxs")
+	require.NoError(t, os.WriteFile(wxs, []byte("{{ .ProductVersion }} {{ .UpgradeCode }} {{ .MsiArch }}"), 0o644))
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		WindowsMSIs: []config.WindowsMSI{{
+			WXS:         wxs,
+			UpgradeCode: "8a9e3d2c-0b7e-4d8e-9f4c-2b8f1d8e4c11",
+		}},
+	}, testctx.WithVersion("1.2.3-rc1"), testctx.WithSemver(1, 2, 3, "rc1"))
+	testlib.AddBinaries(t, ctx.Artifacts, dist, "foo", "windows_amd64", "windows_386", "windows_arm64", "linux_amd64")
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	bts, err := os.ReadFile(filepath.Join(dist, "msi", "foo_arm64", "foo_arm64.wxs"))
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3 8A9E3D2C-0B7E-4D8E-9F4C-2B8F1D8E4C11 arm64", string(bts))
+}
+
+func TestRunErrors(t *testing.T) {
+	testlib.SkipIfWindows(t, "uses a fake wixl script")
+	v4 := filepath.Join(t.TempDir(), "app.wxs")
+	require.NoError(t, os.WriteFile(v4, []byte(`<Wix xmlns="http://wixtoolset.org/schemas/v4/wxs"></Wix>`), 0o644))
+
+	for name, tc := range map[string]struct {
+		msi    config.WindowsMSI
+		semver context.Semver
+		noWixl bool
+		err    string
+	}{
+		"bad name": {
+			msi: config.WindowsMSI{Name: "{{ .Nope }"},
+			err: "template: failed to apply",
+		},
+		"bad extra files": {
+			msi: config.WindowsMSI{ExtraFiles: []string{"{{ .Nope }"}},
+			err: "template: failed to apply",
+		},
+		"missing extra file": {
+			msi: config.WindowsMSI{ExtraFiles: []string{"./testdata/nope.txt"}},
+			err: "failed to copy extra file",
+		},
+		"duplicated extra file name": {
+			msi: config.WindowsMSI{ExtraFiles: []string{"./testdata/README.md", "../../../README.md"}},
+			err: `a file named "README.md" is already in the msi`,
+		},
+		"invalid mod_timestamp": {
+			msi: config.WindowsMSI{ModTimestamp: "nope"},
+			err: "parsing \"nope\": invalid syntax",
+		},
+		"bad disable": {
+			msi: config.WindowsMSI{Disable: "{{ .Nope }"},
+			err: "template: failed to apply",
+		},
+		"bad upgrade code": {
+			msi: config.WindowsMSI{UpgradeCode: "nope"},
+			err: "invalid upgrade_code",
+		},
+		"missing wxs": {
+			msi: config.WindowsMSI{WXS: "./testdata/nope.wxs"},
+			err: "failed to read wxs",
+		},
+		"v4 schema": {
+			msi: config.WindowsMSI{WXS: v4},
+			err: "msitools only supports the v3 schema, got v4",
+		},
+		"invalid product version": {
+			msi:    config.WindowsMSI{},
+			semver: context.Semver{Major: 256},
+			err:    "can't be used as a msi product version",
+		},
+		"no binaries": {
+			msi: config.WindowsMSI{IDs: []string{"nope"}},
+			err: "no windows binaries found for builds [nope]",
+		},
+		"no wixl": {
+			msi:    config.WindowsMSI{},
+			noWixl: true,
+			err:    ErrNoWixl.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tc.noWixl {
+				t.Setenv("PATH", t.TempDir())
+			} else {
+				fakeWixl(t)
+			}
+			dist := t.TempDir()
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+				ProjectName: "foo",
+				Dist:        dist,
+				WindowsMSIs: []config.WindowsMSI{tc.msi},
+			})
+			ctx.Semver = tc.semver
+			testlib.AddBinaries(t, ctx.Artifacts, dist, "foo", "windows_amd64", "windows_386", "windows_arm64", "linux_amd64")
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.ErrorContains(t, Pipe{}.Run(ctx), tc.err)
+		})
+	}
+}
+
+func TestRunDisabled(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "foo",
+		WindowsMSIs: []config.WindowsMSI{{Disable: "true"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func TestProductVersion(t *testing.T) {
+	for _, tc := range []struct {
+		semver context.Semver
+		expect string
+		err    bool
+	}{
+		{semver: context.Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc1"}, expect: "1.2.3"},
+		{semver: context.Semver{Major: 255, Minor: 255, Patch: 65535}, expect: "255.255.65535"},
+		{semver: context.Semver{Minor: 256}, err: true},
+		{semver: context.Semver{Patch: 65536}, err: true},
+	} {
+		ctx := testctx.Wrap(t.Context())
+		ctx.Semver = tc.semver
+		got, err := productVersion(ctx)
+		if tc.err {
+			require.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.expect, got)
+	}
+}
+
+// fakeWixl puts a wixl in the $PATH that writes its arguments to the output
+// file.
+func fakeWixl(tb testing.TB) {
+	tb.Helper()
+	bin := tb.TempDir()
+	script := `#!/bin/sh
+out=""
+prev=""
+for arg in "$@"; do
+	if [ "$prev" = "-o" ]; then
+		out="$arg"
+	fi
+	prev="$arg"
+done
+echo "$@" > "$out"
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(bin, "wixl"), []byte(script), 0o755))
+	tb.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+type wxsFile struct {
+	ID      string `xml:"Id,attr"`
+	Name    string `xml:"Name,attr"`
+	Source  string `xml:"Source,attr"`
+	KeyPath string `xml:"KeyPath,attr"`
+}
+
+type wxsComponent struct {
+	Files       []wxsFile `xml:"File"`
+	Environment struct {
+		Value  string `xml:"Value,attr"`
+		System string `xml:"System,attr"`
+	} `xml:"Environment"`
+}
+
+type wxsDirectory struct {
+	ID        string        `xml:"Id,attr"`
+	Directory *wxsDirectory `xml:"Directory"`
+	Component wxsComponent  `xml:"Component"`
+}
+
+type wxs struct {
+	Product struct {
+		Version      string `xml:"Version,attr"`
+		Manufacturer string `xml:"Manufacturer,attr"`
+		UpgradeCode  string `xml:"UpgradeCode,attr"`
+		Package      struct {
+			InstallScope string `xml:"InstallScope,attr"`
+		} `xml:"Package"`
+		Directory wxsDirectory `xml:"Directory"`
+	} `xml:"Product"`
+}
+
+func readWXS(tb testing.TB, path string) wxs {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	var result wxs
+	require.NoError(tb, xml.Unmarshal(bts, &result))
+	require.False(tb, strings.Contains(string(bts), "{{"), "unrendered template")
+	return result
+}
@@ -0,0 +1,18 @@
+package msi
+
+import _ "embed"
+
+// defaultWXS is used when no wxs file is provided.
+//
+// It installs all the binaries (and extra files) of a given architecture, and
+// uses WiX v3 schema, so it works with both msitools and the WiX Toolset.
+//
+//go:embed app.wxs
+var defaultWXS string
+
+// msiFile is a file installed by the default template.
+type msiFile struct {
+	ID     string
+	Name   string
+	Source string
+}
@@ -0,0 +1,242 @@
+package notary
+
+import (
+	"bytes"
+	stdctx "context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/goreleaser/quill/quill"
+	"github.com/goreleaser/quill/quill/notary"
+	"github.com/goreleaser/quill/quill/pki/load"
+	"github.com/sassoftware/relic/v7/lib/certloader"
+	"github.com/sassoftware/relic/v7/lib/fruit/xar"
+)
+
+// developerIDInstallerOID is the extension that identifies "Developer ID
+// Installer" certificates, which are required to sign installer packages.
+var developerIDInstallerOID = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 1, 14}
+
+// MacOSPackages signs macOS installer packages, and notarizes them and disk
+// images.
+type MacOSPackages struct{}
+
+func (MacOSPackages) String() string { return "sign & notarize macOS packages and disk images" }
+
+func (MacOSPackages) Skip(ctx *context.Context) bool {
+	return MacOS{}.Skip(ctx)
+}
+
+func (MacOSPackages) Run(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, cfg := range ctx.Config.Notarize.MacOS {
+		g.Go(func() error {
+			return signAndNotarizePackages(ctx, cfg)
+		})
+	}
+	return g.Wait()
+}
+
+func signAndNotarizePackages(ctx *context.Context, cfg config.MacOSSignNotarize) error {
+	ok, err := tmpl.New(ctx).Bool(cfg.Enabled)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pipe.Skip("disabled")
+	}
+
+	artifacts := ctx.Artifacts.Filter(artifact.And(
+		artifact.ByTypes(artifact.MacOSPkg, artifact.DMG),
+		artifact.ByIDs(cfg.IDs...),
+	))
+	if len(artifacts.List()) == 0 {
+		return pipe.Skipf("no macOS packages or disk images found with ids: %s", strings.Join(cfg.IDs, ", "))
+	}
+
+	if err := tmpl.New(ctx).ApplyAll(
+		&cfg.Sign.InstallerCertificate,
+		&cfg.Sign.InstallerPassword,
+		&cfg.Sign.TimestampURL,
+		&cfg.Notarize.Key,
+		&cfg.Notarize.KeyID,
+		&cfg.Notarize.IssuerID,
+	); err != nil {
+		return err
+	}
+
+	var cert *certloader.Certificate
+	if slices.ContainsFunc(artifacts.List(), func(a *artifact.Artifact) bool {
+		return a.Type == artifact.MacOSPkg
+	}) {
+		cert, err = loadInstallerCertificate(cfg.Sign)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, art := range artifacts.List() {
+		log := log.WithField("artifact", art.Path)
+		if art.Type == artifact.MacOSPkg {
+			log.Info("signing")
+			if err := signPkg(ctx, art.Path, cert); err != nil {
+				return fmt.Errorf("%s: %w", art.Path, err)
+			}
+		}
+
+		if cfg.Notarize.IssuerID == "" ||
+			cfg.Notarize.KeyID == "" ||
+			cfg.Notarize.Key == "" {
+			log.Info("will not try to notarize")
+			continue
+		}
+
+		if cfg.Notarize.Wait {
+			log.Info("notarizing and waiting - this might take a while")
+		} else {
+			log.Info("sending notarize request")
+		}
+		status, err := notarizePackage(ctx, art.Path, cfg.Notarize)
+		if err != nil {
+			return fmt.Errorf("%s: %w", art.Path, err)
+		}
+
+		switch status {
+		case notary.AcceptedStatus:
+			log.Info("notarized")
+		case notary.InvalidStatus:
+			return fmt.Errorf("%s: invalid", art.Path)
+		case notary.RejectedStatus:
+			return fmt.Errorf("%s: rejected", art.Path)
+		case notary.TimeoutStatus:
+			log.Info("notarize timeout")
+		default:
+			log.Info("notarize still pending")
+		}
+	}
+
+	if err := artifacts.Refresh(); err != nil {
+		return fmt.Errorf("refresh artifacts: %w", err)
+	}
+	return nil
+}
+
+func isInstallerCertificate(cert *x509.Certificate) bool {
+	return slices.ContainsFunc(cert.Extensions, func(ext pkix.Extension) bool {
+		return ext.Id.Equal(developerIDInstallerOID)
+	})
+}
+
+// loadInstallerCertificate loads the "Developer ID Installer" certificate
+// used to sign installer packages.
+func loadInstallerCertificate(cfg config.MacOSSign) (*certloader.Certificate, error) {
+	if cfg.InstallerCertificate == "" {
+		return nil, errors.New("installer_certificate is required to sign installer packages")
+	}
+	p12, err := load.P12(cfg.InstallerCertificate, cfg.InstallerPassword)
+	if err != nil {
+		return nil, err
+	}
+	if !isInstallerCertificate(p12.Certificate) {
+		return nil, errors.New("installer_certificate is not a 'Developer ID Installer' certificate")
+	}
+	if _, ok := p12.PrivateKey.(crypto.Signer); !ok {
+		return nil, errors.New("installer_certificate: unsupported private key")
+	}
+	cert := &certloader.Certificate{
+		PrivateKey:   p12.PrivateKey,
+		Leaf:         p12.Certificate,
+		Certificates: []*x509.Certificate{p12.Certificate},
+	}
+	for _, c := range p12.Certificates {
+		if !c.Equal(p12.Certificate) {
+			cert.Certificates = append(cert.Certificates, c)
+		}
+	}
+	if cfg.TimestampURL != "" {
+		cert.Timestamper = timestamper{url: cfg.TimestampURL}
+	}
+	return cert, nil
+}
+
+// signPkg signs the given installer package in-place, the same way
+// productsign does.
+func signPkg(ctx stdctx.Context, path string, cert *certloader.Certificate) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	patch, _, err := xar.Sign(ctx, f, cert, crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	if err := patch.Apply(f, path); err != nil {
+		return err
+	}
+	// keep the modification time, which might have been set by mod_timestamp.
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// notarizePackage submits the given installer package or disk image to the
+// notary service.
+//
+// Unlike binaries, these are uploaded as-is.
+func notarizePackage(ctx stdctx.Context, path string, cfg config.MacOSNotarize) (notary.SubmissionStatus, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bts)
+
+	notarizeCfg := quill.NewNotarizeConfig(
+		cfg.IssuerID,
+		cfg.KeyID,
+		cfg.Key,
+	).WithStatusConfig(notary.StatusConfig{
+		Timeout: cfg.Timeout,
+		Poll:    10 * time.Second,
+		Wait:    cfg.Wait,
+	})
+
+	token, err := notary.NewSignedToken(notarizeCfg.TokenConfig)
+	if err != nil {
+		return "", err
+	}
+	sub := notary.NewSubmission(
+		notary.NewAPIClient(token, notarizeCfg.HTTPTimeout),
+		&notary.Payload{
+			Reader: bytes.NewReader(bts),
+			Path:   path,
+			Digest: hex.EncodeToString(sum[:]),
+		},
+	)
+	if err := sub.Start(ctx); err != nil {
+		return "", fmt.Errorf("unable to start submission: %w", err)
+	}
+	if !cfg.Wait {
+		return "", nil
+	}
+	return notary.PollStatus(ctx, sub, notarizeCfg.StatusConfig)
+}
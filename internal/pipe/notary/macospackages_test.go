@@ -0,0 +1,210 @@
+package notary
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/internal/xar"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	relicxar "github.com/sassoftware/relic/v7/lib/fruit/xar"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestMacOSPackagesString(t *testing.T) {
+	require.NotEmpty(t, MacOSPackages{}.String())
+}
+
+func TestMacOSPackagesRun(t *testing.T) {
+	installer := newP12(t, true)
+
+	t.Run("sign pkg", func(t *testing.T) {
+		pkg := newPkg(t)
+		before, err := os.Stat(pkg.Path)
+		require.NoError(t, err)
+		ctx := newMacOSPackagesCtx(t, installer, pkg)
+		require.NoError(t, MacOSPackages{}.Run(ctx))
+
+		sig := requirePkgSignature(t, pkg.Path)
+		require.True(t, isInstallerCertificate(sig.Signature.Certificate))
+		require.Nil(t, sig.Signature.CounterSignature)
+
+		after, err := os.Stat(pkg.Path)
+		require.NoError(t, err)
+		require.Equal(t, before.ModTime(), after.ModTime())
+	})
+
+	t.Run("sign pkg with timestamp", func(t *testing.T) {
+		pkg := newPkg(t)
+		ctx := newMacOSPackagesCtx(t, installer, pkg)
+		ctx.Config.Notarize.MacOS[0].Sign.TimestampURL = newTimestampServer(t).URL
+		require.NoError(t, MacOSPackages{}.Run(ctx))
+
+		sig := requirePkgSignature(t, pkg.Path)
+		require.NotNil(t, sig.Signature.CounterSignature)
+	})
+
+	t.Run("timestamp error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(srv.Close)
+		pkg := newPkg(t)
+		ctx := newMacOSPackagesCtx(t, installer, pkg)
+		ctx.Config.Notarize.MacOS[0].Sign.TimestampURL = srv.URL
+		require.ErrorContains(t, MacOSPackages{}.Run(ctx), "timestamp: "+srv.URL+": 500 Internal Server Error")
+	})
+
+	t.Run("not an installer certificate", func(t *testing.T) {
+		ctx := newMacOSPackagesCtx(t, newP12(t, false), newPkg(t))
+		require.EqualError(t, MacOSPackages{}.Run(ctx), "installer_certificate is not a 'Developer ID Installer' certificate")
+	})
+
+	t.Run("no installer certificate", func(t *testing.T) {
+		ctx := newMacOSPackagesCtx(t, "", newPkg(t))
+		require.EqualError(t, MacOSPackages{}.Run(ctx), "installer_certificate is required to sign installer packages")
+	})
+
+	t.Run("dmg is not signed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "foo.dmg")
+		require.NoError(t, os.WriteFile(path, []byte("dmg"), 0o644))
+		ctx := newMacOSPackagesCtx(t, "", &artifact.Artifact{
+			Type: artifact.DMG,
+			Name: "foo.dmg",
+			Path: path,
+			Goos: "darwin",
+		})
+		require.NoError(t, MacOSPackages{}.Run(ctx))
+		bts, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "dmg", string(bts))
+	})
+
+	t.Run("invalid pkg", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "foo.pkg")
+		require.NoError(t, os.WriteFile(path, []byte("nope"), 0o644))
+		ctx := newMacOSPackagesCtx(t, installer, &artifact.Artifact{
+			Type: artifact.MacOSPkg,
+			Name: "foo.pkg",
+			Path: path,
+			Goos: "darwin",
+		})
+		require.EqualError(t, MacOSPackages{}.Run(ctx), path+": unexpected EOF")
+	})
+
+	t.Run("no packages", func(t *testing.T) {
+		ctx := newMacOSPackagesCtx(t, installer)
+		testlib.AssertSkipped(t, MacOSPackages{}.Run(ctx))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx := newMacOSPackagesCtx(t, installer, newPkg(t))
+		ctx.Config.Notarize.MacOS[0].Enabled = "false"
+		testlib.AssertSkipped(t, MacOSPackages{}.Run(ctx))
+	})
+
+	t.Run("bad tmpl", func(t *testing.T) {
+		ctx := newMacOSPackagesCtx(t, installer, newPkg(t))
+		ctx.Config.Notarize.MacOS[0].Sign.InstallerPassword = "{{ .Nope }}"
+		testlib.RequireTemplateError(t, MacOSPackages{}.Run(ctx))
+	})
+
+	t.Run("bad certificate", func(t *testing.T) {
+		ctx := newMacOSPackagesCtx(t, filepath.Join(t.TempDir(), "nope.p12"), newPkg(t))
+		require.ErrorContains(t, MacOSPackages{}.Run(ctx), "unable to read p12 bytes")
+	})
+}
+
+func requirePkgSignature(tb testing.TB, path string) *relicxar.Signature {
+	tb.Helper()
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(tb, err)
+	archive, err := relicxar.Open(f, info.Size())
+	require.NoError(tb, err)
+	sig, err := archive.Verify(false)
+	require.NoError(tb, err)
+	return sig
+}
+
+func newMacOSPackagesCtx(tb testing.TB, certificate string, artifacts ...*artifact.Artifact) *context.Context {
+	tb.Helper()
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		Notarize: config.Notarize{
+			MacOS: []config.MacOSSignNotarize{{
+				Enabled: "true",
+				Sign: config.MacOSSign{
+					InstallerCertificate: certificate,
+					InstallerPassword:    "secret",
+				},
+			}},
+		},
+	})
+	for _, a := range artifacts {
+		ctx.Artifacts.Add(a)
+	}
+	return ctx
+}
+
+// newPkg creates a small unsigned installer package.
+func newPkg(tb testing.TB) *artifact.Artifact {
+	tb.Helper()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	require.NoError(tb, xar.Write(&buf, []xar.File{
+		{Name: "Distribution", Mode: 0o644, ModTime: now, Data: []byte("<xml/>")},
+		{Name: "foo.pkg/Payload", Mode: 0o644, ModTime: now, Data: []byte("payload")},
+	}, now))
+	path := filepath.Join(tb.TempDir(), "foo.pkg")
+	require.NoError(tb, os.WriteFile(path, buf.Bytes(), 0o644))
+	require.NoError(tb, os.Chtimes(path, now, now))
+	return &artifact.Artifact{
+		Type: artifact.MacOSPkg,
+		Name: "foo.pkg",
+		Path: path,
+		Goos: "darwin",
+	}
+}
+
+// newP12 writes a self-signed p12 certificate, optionally marked as a
+// "Developer ID Installer" one, and returns its path.
+func newP12(tb testing.TB, installer bool) string {
+	tb.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(tb, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Developer ID Installer: goreleaser"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if installer {
+		tmpl.ExtraExtensions = []pkix.Extension{{Id: developerIDInstallerOID, Value: []byte{5, 0}}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(tb, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(tb, err)
+	bts, err := pkcs12.Modern.Encode(key, cert, nil, "secret")
+	require.NoError(tb, err)
+	path := filepath.Join(tb.TempDir(), "cert.p12")
+	require.NoError(tb, os.WriteFile(path, bts, 0o600))
+	return path
+}
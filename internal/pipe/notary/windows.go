@@ -0,0 +1,351 @@
+package notary
+
+import (
+	"bytes"
+	stdctx "context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/sassoftware/relic/v7/lib/authenticode"
+	"github.com/sassoftware/relic/v7/lib/certloader"
+	"github.com/sassoftware/relic/v7/lib/comdoc"
+	"github.com/sassoftware/relic/v7/lib/pkcs7"
+	"github.com/sassoftware/relic/v7/lib/pkcs9"
+	"github.com/sassoftware/relic/v7/lib/signappx"
+	"github.com/sassoftware/relic/v7/lib/zipslicer"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// authenticodeHash is the digest used in the Authenticode signatures.
+const authenticodeHash = crypto.SHA256
+
+// Windows signs Windows binaries with Authenticode.
+//
+// It runs right after the build, so the signed binaries are the ones that end
+// up in archives and packages.
+type Windows struct{}
+
+func (Windows) String() string { return "sign windows binaries" }
+
+func (Windows) Skip(ctx *context.Context) bool {
+	return skips.Any(ctx, skips.Notarize) || len(ctx.Config.Notarize.Windows) == 0
+}
+
+func (Windows) Run(ctx *context.Context) error {
+	return runWindows(ctx, func(cfg config.WindowsSign) artifact.Filter {
+		return artifact.And(
+			artifact.ByGoos("windows"),
+			artifact.ByType(artifact.Binary),
+			artifact.ByIDs(cfg.IDs...),
+		)
+	})
+}
+
+// WindowsPackages signs Windows installers (MSI and MSIX) with Authenticode.
+type WindowsPackages struct{}
+
+func (WindowsPackages) String() string { return "sign windows installers" }
+
+func (WindowsPackages) Skip(ctx *context.Context) bool {
+	return Windows{}.Skip(ctx)
+}
+
+func (WindowsPackages) Run(ctx *context.Context) error {
+	return runWindows(ctx, func(cfg config.WindowsSign) artifact.Filter {
+		return artifact.And(
+			artifact.ByTypes(artifact.ReleaseUploadableTypes()...),
+			byWindowsPackageExt,
+			artifact.ByIDs(cfg.IDs...),
+		)
+	})
+}
+
+// byWindowsPackageExt filters artifacts that are Windows installers.
+func byWindowsPackageExt(a *artifact.Artifact) bool {
+	switch strings.ToLower(filepath.Ext(a.Path)) {
+	case ".msi", ".msix", ".appx":
+		return true
+	default:
+		return false
+	}
+}
+
+func runWindows(ctx *context.Context, filter func(cfg config.WindowsSign) artifact.Filter) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, cfg := range ctx.Config.Notarize.Windows {
+		g.Go(func() error {
+			return signWindows(ctx, cfg, filter(cfg))
+		})
+	}
+	return g.Wait()
+}
+
+func signWindows(ctx *context.Context, cfg config.WindowsSign, filter artifact.Filter) error {
+	ok, err := tmpl.New(ctx).Bool(cfg.Enabled)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pipe.Skip("disabled")
+	}
+
+	artifacts := ctx.Artifacts.Filter(filter)
+	if len(artifacts.List()) == 0 {
+		return pipe.Skipf("no windows artifacts found with ids: %s", strings.Join(cfg.IDs, ", "))
+	}
+
+	if err := tmpl.New(ctx).ApplyAll(
+		&cfg.Certificate,
+		&cfg.Key,
+		&cfg.Password,
+		&cfg.Description,
+		&cfg.URL,
+		&cfg.TimestampURL,
+	); err != nil {
+		return err
+	}
+
+	cert, err := loadAuthenticodeCertificate(cfg.Certificate, cfg.Key, cfg.Password)
+	if err != nil {
+		return err
+	}
+	if cfg.TimestampURL != "" {
+		cert.Timestamper = timestamper{url: cfg.TimestampURL}
+	}
+	params := &authenticode.OpusParams{
+		Description: cfg.Description,
+		URL:         cfg.URL,
+	}
+
+	for _, art := range artifacts.List() {
+		log.WithField("artifact", art.Path).Info("signing")
+		if err := authenticodeSign(ctx, art.Path, cert, params); err != nil {
+			return fmt.Errorf("%s: %w", art.Path, err)
+		}
+	}
+
+	if err := artifacts.Refresh(); err != nil {
+		return fmt.Errorf("refresh artifacts: %w", err)
+	}
+	return nil
+}
+
+// authenticodeSign signs the given file in-place, picking the right format
+// based on its extension.
+func authenticodeSign(ctx stdctx.Context, path string, cert *certloader.Certificate, params *authenticode.OpusParams) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".msi":
+		return signMSI(ctx, path, cert, params)
+	case ".msix", ".appx":
+		return signAppx(ctx, path, cert, params)
+	default:
+		return signPE(ctx, path, cert, params)
+	}
+}
+
+func signPE(ctx stdctx.Context, path string, cert *certloader.Certificate, params *authenticode.OpusParams) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	digest, err := authenticode.DigestPE(f, authenticodeHash, false)
+	if err != nil {
+		return err
+	}
+	patch, _, err := digest.Sign(ctx, cert, params)
+	if err != nil {
+		return err
+	}
+	if err := patch.Apply(f, path); err != nil {
+		return err
+	}
+	// the patch might have replaced the file, so reopen it.
+	out, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return authenticode.FixPEChecksum(out)
+}
+
+func signMSI(ctx stdctx.Context, path string, cert *certloader.Certificate, params *authenticode.OpusParams) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cdf, err := comdoc.WriteFile(f)
+	if err != nil {
+		return err
+	}
+	imprint, prehash, err := authenticode.DigestMSI(cdf, authenticodeHash, true)
+	if err != nil {
+		return err
+	}
+	sig, err := authenticode.SignMSIImprint(ctx, imprint, authenticodeHash, cert, params)
+	if err != nil {
+		return err
+	}
+	if err := authenticode.InsertMSISignature(cdf, sig.Raw, prehash); err != nil {
+		return err
+	}
+	return cdf.Close()
+}
+
+func signAppx(ctx stdctx.Context, path string, cert *certloader.Certificate, params *authenticode.OpusParams) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, w := io.Pipe()
+	go func() {
+		_ = w.CloseWithError(zipslicer.ZipToTar(f, w))
+	}()
+	digest, err := signappx.DigestAppxTar(r, authenticodeHash, false)
+	_ = r.Close()
+	if err != nil {
+		return err
+	}
+	patch, _, _, err := digest.Sign(ctx, cert, params)
+	if err != nil {
+		return err
+	}
+	return patch.Apply(f, path)
+}
+
+// loadAuthenticodeCertificate loads the signing certificate and its private
+// key.
+//
+// The certificate can be either a PKCS#12 (PFX) file, in which case it must
+// also contain the private key, or PEM encoded certificates, in which case the
+// key can be either in the same PEM or given separately.
+// Both can be either paths or their contents, base64 encoded or not.
+func loadAuthenticodeCertificate(certificate, key, password string) (*certloader.Certificate, error) {
+	if certificate == "" {
+		return nil, errors.New("certificate is required")
+	}
+	bts, err := readCertificate(certificate)
+	if err != nil {
+		return nil, fmt.Errorf("could not read certificate: %w", err)
+	}
+
+	if !bytes.Contains(bts, []byte("-----BEGIN ")) {
+		priv, leaf, chain, err := pkcs12.DecodeChain(bts, password)
+		if err != nil {
+			return nil, fmt.Errorf("could not load pfx certificate: %w", err)
+		}
+		return &certloader.Certificate{
+			PrivateKey:   priv,
+			Leaf:         leaf,
+			Certificates: append([]*x509.Certificate{leaf}, chain...),
+		}, nil
+	}
+
+	certs, err := certloader.ParseX509Certificates(bts)
+	if err != nil {
+		return nil, fmt.Errorf("could not load pem certificate: %w", err)
+	}
+	keyBts := bts
+	if key != "" {
+		keyBts, err = readCertificate(key)
+		if err != nil {
+			return nil, fmt.Errorf("could not read key: %w", err)
+		}
+	}
+	priv, err := certloader.ParseAnyPrivateKey(keyBts, staticPassword(password))
+	if err != nil {
+		return nil, fmt.Errorf("could not load key: %w", err)
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("could not load key: unsupported key type")
+	}
+	idx := slices.IndexFunc(certs, func(c *x509.Certificate) bool {
+		pub, ok := c.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+		return ok && pub.Equal(signer.Public())
+	})
+	if idx == -1 {
+		return nil, errors.New("no certificate matches the private key")
+	}
+	return &certloader.Certificate{
+		PrivateKey:   priv,
+		Leaf:         certs[idx],
+		Certificates: certs,
+	}, nil
+}
+
+// readCertificate reads the given certificate or key, which can be a path,
+// its PEM contents, or its base64 encoded contents.
+func readCertificate(s string) ([]byte, error) {
+	if trimmed := strings.TrimSpace(s); strings.HasPrefix(trimmed, "-----BEGIN ") {
+		return []byte(trimmed), nil
+	}
+	if _, err := os.Stat(s); err == nil {
+		return os.ReadFile(s)
+	}
+	bts, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: not a file nor base64 encoded", s)
+	}
+	return bts, nil
+}
+
+// staticPassword provides the configured password to encrypted private keys.
+type staticPassword string
+
+func (p staticPassword) GetPasswd(string) (string, error) {
+	if p == "" {
+		return "", errors.New("private key is encrypted and no password was provided")
+	}
+	return string(p), nil
+}
+
+// timestamper gets RFC3161 timestamps from a timestamp authority.
+type timestamper struct {
+	url string
+}
+
+func (t timestamper) Timestamp(ctx stdctx.Context, req *pkcs9.Request) (*pkcs7.ContentInfoSignedData, error) {
+	h := req.Hash.New()
+	h.Write(req.EncryptedDigest)
+	msg, httpReq, err := pkcs9.NewRequest(t.url, req.Hash, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("timestamp: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp: %s: %s", t.url, resp.Status)
+	}
+	token, err := msg.ParseResponse(body)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp: %w", err)
+	}
+	return token, nil
+}
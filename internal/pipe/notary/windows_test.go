@@ -0,0 +1,381 @@
+package notary
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/sassoftware/relic/v7/lib/authenticode"
+	"github.com/sassoftware/relic/v7/lib/pkcs7"
+	"github.com/sassoftware/relic/v7/lib/pkcs9"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestWindowsString(t *testing.T) {
+	require.NotEmpty(t, Windows{}.String())
+	require.NotEmpty(t, WindowsPackages{}.String())
+}
+
+func TestWindowsSkip(t *testing.T) {
+	for name, p := range map[string]interface {
+		Skip(ctx *context.Context) bool
+	}{
+		"binaries": Windows{},
+		"packages": WindowsPackages{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("skip notarize", func(t *testing.T) {
+				require.True(t,
+					p.Skip(testctx.WrapWithCfg(t.Context(), config.Project{
+						Notarize: config.Notarize{
+							Windows: []config.WindowsSign{{}},
+						},
+					}, testctx.Skip(skips.Notarize))))
+			})
+			t.Run("skip no configs", func(t *testing.T) {
+				require.True(t,
+					p.Skip(testctx.WrapWithCfg(t.Context(), config.Project{})))
+			})
+			t.Run("dont skip", func(t *testing.T) {
+				require.False(t,
+					p.Skip(testctx.WrapWithCfg(t.Context(), config.Project{
+						Notarize: config.Notarize{
+							Windows: []config.WindowsSign{{}},
+						},
+					})))
+			})
+		})
+	}
+}
+
+type codeSigningCert struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newCodeSigningCert(tb testing.TB, usage x509.ExtKeyUsage) codeSigningCert {
+	tb.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(tb, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "goreleaser"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(tb, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(tb, err)
+	return codeSigningCert{key: key, cert: cert}
+}
+
+func (c codeSigningCert) pfx(tb testing.TB, password string) string {
+	tb.Helper()
+	bts, err := pkcs12.Modern.Encode(c.key, c.cert, nil, password)
+	require.NoError(tb, err)
+	return base64.StdEncoding.EncodeToString(bts)
+}
+
+func (c codeSigningCert) pem(tb testing.TB) (string, string) {
+	tb.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(c.key)
+	require.NoError(tb, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// buildWindowsBinary builds a small Windows executable.
+func buildWindowsBinary(tb testing.TB) string {
+	tb.Helper()
+	testlib.CheckPath(tb, "go")
+	dir := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/foo\n"), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	bin := filepath.Join(dir, "foo.exe")
+	cmd := exec.Command("go", "build", "-ldflags=-s -w", "-o", bin, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=windows", "GOARCH=amd64", "GOFLAGS=")
+	output, err := cmd.CombinedOutput()
+	require.NoError(tb, err, "go build failed: %s", output)
+	return bin
+}
+
+// newTimestampServer starts a minimal RFC3161 timestamp authority.
+func newTimestampServer(tb testing.TB) *httptest.Server {
+	tb.Helper()
+	tsa := newCodeSigningCert(tb, x509.ExtKeyUsageTimeStamping)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(tb, err)
+		var req pkcs9.TimeStampReq
+		_, err = asn1.Unmarshal(body, &req)
+		require.NoError(tb, err)
+
+		genTime, err := asn1.MarshalWithParams(time.Now().UTC(), "generalized")
+		require.NoError(tb, err)
+		info, err := asn1.Marshal(pkcs9.TSTInfo{
+			Version:        1,
+			Policy:         asn1.ObjectIdentifier{1, 2, 3, 4},
+			MessageImprint: req.MessageImprint,
+			SerialNumber:   big.NewInt(1),
+			GenTime:        asn1.RawValue{FullBytes: genTime},
+			Nonce:          req.Nonce,
+		})
+		require.NoError(tb, err)
+
+		builder := pkcs7.NewBuilder(tsa.key, []*x509.Certificate{tsa.cert}, crypto.SHA256)
+		require.NoError(tb, builder.SetContent(pkcs9.OidTSTInfo, info))
+		token, err := builder.Sign()
+		require.NoError(tb, err)
+		resp, err := asn1.Marshal(pkcs9.TimeStampResp{
+			Status:         pkcs9.PKIStatusInfo{Status: pkcs9.StatusGranted},
+			TimeStampToken: *token,
+		})
+		require.NoError(tb, err)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(resp)
+	}))
+	tb.Cleanup(srv.Close)
+	return srv
+}
+
+func newWindowsCtx(tb testing.TB, cfg config.WindowsSign, arts ...*artifact.Artifact) *context.Context {
+	tb.Helper()
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		Notarize: config.Notarize{
+			Windows: []config.WindowsSign{cfg},
+		},
+	})
+	for _, a := range arts {
+		ctx.Artifacts.Add(a)
+	}
+	return ctx
+}
+
+func windowsBinary(path string) *artifact.Artifact {
+	return &artifact.Artifact{
+		Name:   "foo.exe",
+		Path:   path,
+		Goos:   "windows",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]any{
+			artifact.ExtraID: "foo",
+		},
+	}
+}
+
+func requireAuthenticode(tb testing.TB, path string, cert *x509.Certificate, timestamped bool) {
+	tb.Helper()
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	sigs, err := authenticode.VerifyPE(f, false)
+	require.NoError(tb, err)
+	require.Len(tb, sigs, 1)
+	require.True(tb, cert.Equal(sigs[0].Certificate))
+	require.Equal(tb, "goreleaser", sigs[0].OpusInfo.ProgramName.String())
+	require.Equal(tb, timestamped, sigs[0].CounterSignature != nil)
+}
+
+func TestWindowsRun(t *testing.T) {
+	signer := newCodeSigningCert(t, x509.ExtKeyUsageCodeSigning)
+	bin := buildWindowsBinary(t)
+	binary := func(tb testing.TB) string {
+		tb.Helper()
+		bts, err := os.ReadFile(bin)
+		require.NoError(tb, err)
+		path := filepath.Join(tb.TempDir(), "foo.exe")
+		require.NoError(tb, os.WriteFile(path, bts, 0o755))
+		return path
+	}
+
+	t.Run("pfx", func(t *testing.T) {
+		path := binary(t)
+		ctx := newWindowsCtx(t, config.WindowsSign{
+			Enabled:     "true",
+			Certificate: signer.pfx(t, "secret"),
+			Password:    "{{ .Env.PASSWORD }}",
+			Description: "{{ .Env.DESCRIPTION }}",
+		}, windowsBinary(path))
+		ctx.Env["PASSWORD"] = "secret"
+		ctx.Env["DESCRIPTION"] = "goreleaser"
+		require.NoError(t, Windows{}.Run(ctx))
+		requireAuthenticode(t, path, signer.cert, false)
+
+		// signing again replaces the signature
+		require.NoError(t, Windows{}.Run(ctx))
+		requireAuthenticode(t, path, signer.cert, false)
+	})
+
+	t.Run("pem with timestamp", func(t *testing.T) {
+		path := binary(t)
+		certPEM, keyPEM := signer.pem(t)
+		certPath := filepath.Join(t.TempDir(), "cert.pem")
+		require.NoError(t, os.WriteFile(certPath, []byte(certPEM), 0o600))
+		srv := newTimestampServer(t)
+		ctx := newWindowsCtx(t, config.WindowsSign{
+			IDs:          []string{"foo"},
+			Enabled:      "true",
+			Certificate:  certPath,
+			Key:          keyPEM,
+			Description:  "goreleaser",
+			URL:          "https://goreleaser.com",
+			TimestampURL: srv.URL,
+		}, windowsBinary(path))
+		require.NoError(t, Windows{}.Run(ctx))
+		requireAuthenticode(t, path, signer.cert, true)
+	})
+
+	t.Run("timestamp error", func(t *testing.T) {
+		path := binary(t)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(srv.Close)
+		ctx := newWindowsCtx(t, config.WindowsSign{
+			Enabled:      "true",
+			Certificate:  signer.pfx(t, ""),
+			TimestampURL: srv.URL,
+		}, windowsBinary(path))
+		require.ErrorContains(t, Windows{}.Run(ctx), "timestamp: "+srv.URL+": 500 Internal Server Error")
+	})
+
+	t.Run("not a pe file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "foo.exe")
+		require.NoError(t, os.WriteFile(path, []byte("nope"), 0o755))
+		ctx := newWindowsCtx(t, config.WindowsSign{
+			Enabled:     "true",
+			Certificate: signer.pfx(t, ""),
+		}, windowsBinary(path))
+		require.ErrorContains(t, Windows{}.Run(ctx), path)
+	})
+
+	t.Run("invalid certificates", func(t *testing.T) {
+		certPEM, keyPEM := signer.pem(t)
+		_, otherKeyPEM := newCodeSigningCert(t, x509.ExtKeyUsageCodeSigning).pem(t)
+		for name, tt := range map[string]struct {
+			cfg      config.WindowsSign
+			expected string
+		}{
+			"no certificate": {
+				cfg:      config.WindowsSign{},
+				expected: "certificate is required",
+			},
+			"not a file nor base64": {
+				cfg:      config.WindowsSign{Certificate: "testdata/nope.pfx"},
+				expected: "could not read certificate: testdata/nope.pfx: not a file nor base64 encoded",
+			},
+			"wrong password": {
+				cfg:      config.WindowsSign{Certificate: signer.pfx(t, "secret"), Password: "nope"},
+				expected: "could not load pfx certificate",
+			},
+			"missing key": {
+				cfg:      config.WindowsSign{Certificate: certPEM},
+				expected: "could not load key",
+			},
+			"key mismatch": {
+				cfg:      config.WindowsSign{Certificate: certPEM, Key: otherKeyPEM},
+				expected: "no certificate matches the private key",
+			},
+			"same pem": {
+				cfg: config.WindowsSign{Certificate: certPEM + keyPEM},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := loadAuthenticodeCertificate(tt.cfg.Certificate, tt.cfg.Key, tt.cfg.Password)
+				if tt.expected == "" {
+					require.NoError(t, err)
+					return
+				}
+				require.ErrorContains(t, err, tt.expected)
+			})
+		}
+	})
+
+	t.Run("bad tmpl", func(t *testing.T) {
+		for name, cfg := range map[string]config.WindowsSign{
+			"enabled":       {Enabled: "{{.Nope}}"},
+			"certificate":   {Enabled: "true", Certificate: "{{.Nope}}"},
+			"key":           {Enabled: "true", Key: "{{.Nope}}"},
+			"password":      {Enabled: "true", Password: "{{.Nope}}"},
+			"description":   {Enabled: "true", Description: "{{.Nope}}"},
+			"url":           {Enabled: "true", URL: "{{.Nope}}"},
+			"timestamp_url": {Enabled: "true", TimestampURL: "{{.Nope}}"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx := newWindowsCtx(t, cfg, windowsBinary(bin))
+				testlib.RequireTemplateError(t, Windows{}.Run(ctx))
+			})
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		ctx := newWindowsCtx(t, config.WindowsSign{
+			Enabled: "{{.Env.SIGN}}",
+		}, windowsBinary(bin))
+		ctx.Env["SIGN"] = "false"
+		testlib.AssertSkipped(t, Windows{}.Run(ctx))
+	})
+
+	t.Run("no binaries", func(t *testing.T) {
+		ctx := newWindowsCtx(t, config.WindowsSign{
+			IDs:     []string{"bar"},
+			Enabled: "true",
+		}, windowsBinary(bin))
+		testlib.AssertSkipped(t, Windows{}.Run(ctx))
+	})
+}
+
+func TestWindowsPackagesRun(t *testing.T) {
+	signer := newCodeSigningCert(t, x509.ExtKeyUsageCodeSigning)
+
+	t.Run("no packages", func(t *testing.T) {
+		ctx := newWindowsCtx(t, config.WindowsSign{Enabled: "true"}, windowsBinary("foo.exe"))
+		testlib.AssertSkipped(t, WindowsPackages{}.Run(ctx))
+	})
+
+	for _, ext := range []string{".msi", ".msix"} {
+		t.Run("invalid "+ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "foo"+ext)
+			require.NoError(t, os.WriteFile(path, []byte("nope"), 0o644))
+			ctx := newWindowsCtx(t, config.WindowsSign{
+				Enabled:     "true",
+				Certificate: signer.pfx(t, ""),
+			}, &artifact.Artifact{
+				Name: "foo" + ext,
+				Path: path,
+				Goos: "windows",
+				Type: artifact.MSIX,
+			})
+			require.ErrorContains(t, WindowsPackages{}.Run(ctx), path)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+// Package ociartifact publishes release artifacts to OCI registries, as OCI
+// artifacts.
+package ociartifact
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/oci"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	defaultArtifactType = "application/vnd.goreleaser.release.v1"
+	signatureType       = "application/vnd.goreleaser.signature.v1"
+	certificateType     = "application/vnd.goreleaser.certificate.v1"
+	sbomType            = "application/vnd.goreleaser.sbom.v1"
+	spdxType            = "application/spdx+json"
+	cyclonedxType       = "application/vnd.cyclonedx+json"
+
+	// annotationSignatureOf is the annotation holding the name of the file a
+	// signature or certificate is of.
+	annotationSignatureOf = "com.goreleaser.signature.of"
+)
+
+var errNoRepository = errors.New("oci_artifacts: missing repository")
+
+// Pipe that publishes artifacts to OCI registries.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "oci artifacts" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.OCIArtifacts) == 0 }
+
+// Default sets the Pipes defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("oci_artifacts")
+	for i := range ctx.Config.OCIArtifacts {
+		art := &ctx.Config.OCIArtifacts[i]
+		if art.ID == "" {
+			art.ID = ctx.Config.ProjectName
+		}
+		if art.Artifacts == "" {
+			art.Artifacts = "all"
+		}
+		if len(art.Tags) == 0 {
+			art.Tags = []string{"{{ .Tag }}"}
+		}
+		if art.ArtifactType == "" {
+			art.ArtifactType = defaultArtifactType
+		}
+		if art.Repository == "" {
+			return errNoRepository
+		}
+		ids.Inc(art.ID)
+	}
+	return ids.Validate()
+}
+
+// Publish pushes the artifacts.
+func (Pipe) Publish(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, cfg := range ctx.Config.OCIArtifacts {
+		g.Go(func() error {
+			return doPublish(ctx, cfg)
+		})
+	}
+	return g.Wait()
+}
+
+func doPublish(ctx *context.Context, cfg config.OCIArtifact) error {
+	tpl := tmpl.New(ctx)
+	disable, err := tpl.Bool(cfg.Disable)
+	if err != nil {
+		return err
+	}
+	if disable {
+		return pipe.Skip("configuration is disabled")
+	}
+
+	filter, err := artifactsFilter(cfg)
+	if err != nil {
+		return err
+	}
+	artifacts := ctx.Artifacts.Filter(filter).List()
+	if len(artifacts) == 0 {
+		return pipe.Skip("no artifacts to publish")
+	}
+
+	repository, err := tpl.Apply(cfg.Repository)
+	if err != nil {
+		return err
+	}
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return fmt.Errorf("oci_artifacts: invalid repository %q: %w", repository, err)
+	}
+	if err := tpl.ApplySlice(&cfg.Tags, tmpl.NonEmpty()); err != nil {
+		return err
+	}
+	if len(cfg.Tags) == 0 {
+		return pipe.Skip("no tags to publish")
+	}
+	annotations := make(map[string]string, len(cfg.Annotations))
+	for k, v := range cfg.Annotations {
+		if annotations[k], err = tpl.Apply(v); err != nil {
+			return err
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	manifest := oci.Artifact{
+		ArtifactType: cfg.ArtifactType,
+		Annotations:  annotations,
+	}
+	names := map[string]bool{}
+	paths := map[string]bool{}
+	for _, art := range artifacts {
+		layer, err := fileLayer(art.Path, art.Name, mediaTypeOf(art))
+		if err != nil {
+			return fmt.Errorf("oci_artifacts: %w", err)
+		}
+		manifest.Layers = append(manifest.Layers, layer)
+		names[art.Name] = true
+		paths[art.Path] = true
+	}
+
+	var desc v1.Descriptor
+	for _, tag := range cfg.Tags {
+		ref := repo.Tag(tag)
+		log.WithField("ref", ref.String()).
+			WithField("files", len(manifest.Layers)).
+			Info("pushing")
+		desc, err = oci.Push(ctx, ref, manifest)
+		if err != nil {
+			return fmt.Errorf("oci_artifacts: could not push %s: %w", ref, err)
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: ref.String(),
+			Path: ref.String(),
+			Type: artifact.OCIArtifact,
+			Extra: map[string]any{
+				artifact.ExtraID:     cfg.ID,
+				artifact.ExtraDigest: desc.Digest.String(),
+			},
+		})
+	}
+
+	return pushReferrers(ctx, repo, &desc, names, paths)
+}
+
+// pushReferrers attaches the signatures and the SBOMs of the published files
+// to the given manifest.
+func pushReferrers(ctx *context.Context, repo name.Repository, subject *v1.Descriptor, names, paths map[string]bool) error {
+	referrers := ctx.Artifacts.Filter(artifact.Or(
+		artifact.And(
+			artifact.ByTypes(artifact.Signature, artifact.Certificate),
+			func(a *artifact.Artifact) bool {
+				return names[artifact.ExtraOr(*a, artifact.ExtraSignatureOf, "")]
+			},
+		),
+		artifact.And(
+			artifact.ByType(artifact.SBOM),
+			func(a *artifact.Artifact) bool {
+				return slices.ContainsFunc(
+					artifact.ExtraOr[[]string](*a, artifact.ExtraSBOMOf, nil),
+					func(path string) bool { return paths[path] },
+				)
+			},
+		),
+	)).List()
+
+	for _, art := range referrers {
+		var artifactType string
+		var annotations map[string]string
+		switch art.Type {
+		case artifact.Signature:
+			artifactType = signatureType
+		case artifact.Certificate:
+			artifactType = certificateType
+		default:
+			var err error
+			artifactType, err = sbomTypeOf(art.Path)
+			if err != nil {
+				return fmt.Errorf("oci_artifacts: %w", err)
+			}
+		}
+		if of := artifact.ExtraOr(*art, artifact.ExtraSignatureOf, ""); of != "" {
+			annotations = map[string]string{annotationSignatureOf: of}
+		}
+
+		layer, err := fileLayer(art.Path, art.Name, types.MediaType(artifactType))
+		if err != nil {
+			return fmt.Errorf("oci_artifacts: %w", err)
+		}
+		referrer := oci.Artifact{
+			ArtifactType: artifactType,
+			Layers:       []oci.Layer{layer},
+			Annotations:  annotations,
+			Subject:      subject,
+		}
+		// referrers are only tracked by their digest, so we push them to
+		// their own digest.
+		desc, err := referrer.Descriptor()
+		if err != nil {
+			return err
+		}
+		log.WithField("file", art.Name).
+			WithField("subject", subject.Digest.String()).
+			Info("attaching")
+		if _, err := oci.Push(ctx, repo.Digest(desc.Digest.String()), referrer); err != nil {
+			return fmt.Errorf("oci_artifacts: could not attach %s: %w", art.Name, err)
+		}
+	}
+	return nil
+}
+
+func artifactsFilter(cfg config.OCIArtifact) (artifact.Filter, error) {
+	var filter artifact.Filter
+	switch cfg.Artifacts {
+	case "all":
+		filter = artifact.And(
+			artifact.ByTypes(artifact.ReleaseUploadableTypes()...),
+			artifact.Not(artifact.ByTypes(
+				artifact.Signature,
+				artifact.Certificate,
+				artifact.SBOM,
+			)),
+		)
+	case "archive":
+		filter = artifact.ByType(artifact.UploadableArchive)
+	case "binary":
+		filter = artifact.ByType(artifact.UploadableBinary)
+	case "package":
+		filter = artifact.ByType(artifact.LinuxPackage)
+	case "checksum":
+		return artifact.ByType(artifact.Checksum), nil
+	case "source":
+		return artifact.ByType(artifact.UploadableSourceArchive), nil
+	default:
+		return nil, fmt.Errorf("oci_artifacts: invalid artifacts: %s", cfg.Artifacts)
+	}
+	if len(cfg.IDs) == 0 {
+		return filter, nil
+	}
+	// checksums and source archives have no IDs.
+	return artifact.And(filter, artifact.Or(
+		artifact.ByTypes(artifact.Checksum, artifact.UploadableSourceArchive),
+		artifact.ByIDs(cfg.IDs...),
+	)), nil
+}
+
+// mediaTypeOf guesses the media type of the given artifact from its
+// extension.
+func mediaTypeOf(art *artifact.Artifact) types.MediaType {
+	if art.Type == artifact.Checksum {
+		return "text/plain"
+	}
+	for _, mt := range []struct {
+		ext string
+		mt  types.MediaType
+	}{
+		{".tar.gz", types.OCILayer},
+		{".tgz", types.OCILayer},
+		{".tar.zst", types.OCILayerZStd},
+		{".tar", types.OCIUncompressedLayer},
+		{".gz", "application/gzip"},
+		{".xz", "application/x-xz"},
+		{".zip", "application/zip"},
+		{".deb", "application/vnd.debian.binary-package"},
+		{".rpm", "application/x-rpm"},
+		{".json", "application/json"},
+		{".txt", "text/plain"},
+	} {
+		if strings.HasSuffix(art.Name, mt.ext) {
+			return mt.mt
+		}
+	}
+	return "application/octet-stream"
+}
+
+// sbomTypeOf detects the format of the given SBOM.
+func sbomTypeOf(path string) (string, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if json.Unmarshal(bts, &doc) != nil {
+		return sbomType, nil
+	}
+	switch {
+	case doc.SPDXVersion != "":
+		return spdxType, nil
+	case doc.BOMFormat == "CycloneDX":
+		return cyclonedxType, nil
+	default:
+		return sbomType, nil
+	}
+}
+
+func fileLayer(path, name string, mt types.MediaType) (oci.Layer, error) {
+	layer, err := oci.FileLayer(path, mt)
+	if err != nil {
+		return oci.Layer{}, err
+	}
+	return oci.Layer{
+		Layer:       layer,
+		Annotations: map[string]string{oci.AnnotationTitle: name},
+	}, nil
+}
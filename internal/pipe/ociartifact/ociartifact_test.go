@@ -0,0 +1,446 @@
+package ociartifact
+
+import (
+	"io"
+	stdlog "log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/oci"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/sbom"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(testctx.Wrap(t.Context())))
+	})
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			OCIArtifacts: []config.OCIArtifact{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName:  "proj",
+		OCIArtifacts: []config.OCIArtifact{{Repository: "ghcr.io/foo/bar"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.OCIArtifact{
+		ID:           "proj",
+		Artifacts:    "all",
+		Repository:   "ghcr.io/foo/bar",
+		Tags:         []string{"{{ .Tag }}"},
+		ArtifactType: "application/vnd.goreleaser.release.v1",
+	}, ctx.Config.OCIArtifacts[0])
+}
+
+func TestDefaultErrors(t *testing.T) {
+	t.Run("no repository", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			OCIArtifacts: []config.OCIArtifact{{}},
+		})
+		require.ErrorIs(t, Pipe{}.Default(ctx), errNoRepository)
+	})
+	t.Run("duplicate id", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			OCIArtifacts: []config.OCIArtifact{
+				{ID: "a", Repository: "foo"},
+				{ID: "a", Repository: "bar"},
+			},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "found 2 oci_artifacts with the ID 'a', please fix your config")
+	})
+}
+
+func TestPublish(t *testing.T) {
+	for desc, referrers := range map[string]bool{
+		"referrers api": true,
+		"fallback tag":  false,
+	} {
+		t.Run(desc, func(t *testing.T) {
+			host := newRegistry(t, referrers)
+			ctx := newContext(t, config.OCIArtifact{
+				Repository:  host + "/{{ .ProjectName }}",
+				Tags:        []string{"{{ .Tag }}", "latest"},
+				Annotations: map[string]string{"org.opencontainers.image.version": "{{ .Version }}"},
+			})
+			addArtifacts(t, ctx)
+			require.NoError(t, Pipe{}.Publish(ctx))
+
+			published := ctx.Artifacts.Filter(artifact.ByType(artifact.OCIArtifact)).List()
+			require.Len(t, published, 2)
+			require.Equal(t, host+"/proj:v1.0.0", published[0].Name)
+			require.Equal(t, host+"/proj:latest", published[1].Name)
+			digest := artifact.MustExtra[string](*published[0], artifact.ExtraDigest)
+			require.Equal(t, digest, artifact.MustExtra[string](*published[1], artifact.ExtraDigest))
+			require.Equal(t, "proj", artifact.MustExtra[string](*published[0], artifact.ExtraID))
+
+			manifest := getManifest(t, host+"/proj:v1.0.0")
+			require.Equal(t, digest, manifest.digest.String())
+			require.Equal(t, "application/vnd.goreleaser.release.v1", manifest.ArtifactType)
+			require.Equal(t, oci.MediaTypeEmpty, manifest.Config.MediaType)
+			require.Equal(t, "1.0.0", manifest.Annotations["org.opencontainers.image.version"])
+			require.Equal(t, map[string]types.MediaType{
+				"proj_linux_amd64.tar.gz": types.OCILayer,
+				"proj_windows_amd64.zip":  "application/zip",
+				"proj_amd64.deb":          "application/vnd.debian.binary-package",
+				"checksums.txt":           "text/plain",
+			}, manifest.layers())
+			require.Equal(t, "deb contents", readBlob(t, host+"/proj", manifest.Layers[2].Digest))
+
+			ref, err := name.NewDigest(host + "/proj@" + digest)
+			require.NoError(t, err)
+			idx, err := remote.Referrers(ref)
+			require.NoError(t, err)
+			im, err := idx.IndexManifest()
+			require.NoError(t, err)
+			artifactTypes := map[string]string{}
+			for _, desc := range im.Manifests {
+				m := getManifest(t, host+"/proj@"+desc.Digest.String())
+				require.Equal(t, digest, m.Subject.Digest.String())
+				require.Len(t, m.Layers, 1)
+				artifactTypes[m.Layers[0].Annotations[oci.AnnotationTitle]] = m.ArtifactType
+			}
+			require.Equal(t, map[string]string{
+				"checksums.txt.sig": "application/vnd.goreleaser.signature.v1",
+				"checksums.txt.pem": "application/vnd.goreleaser.certificate.v1",
+				"proj.spdx.json":    "application/spdx+json",
+				"proj.cdx.json":     "application/vnd.cyclonedx+json",
+				"proj.sbom":         "application/vnd.goreleaser.sbom.v1",
+			}, artifactTypes)
+		})
+	}
+}
+
+func TestPublishNativeSBOM(t *testing.T) {
+	host := newRegistry(t, true)
+	dist := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName:  "proj",
+		Dist:         dist,
+		SBOMs:        []config.SBOM{{Backend: "go"}},
+		OCIArtifacts: []config.OCIArtifact{{Repository: host + "/proj"}},
+	},
+		testctx.WithCurrentTag("v1.0.0"),
+		testctx.WithVersion("1.0.0"),
+	)
+
+	// the test binary itself has go build info embedded.
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	bts, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	bin := filepath.Join(dist, "proj")
+	require.NoError(t, os.WriteFile(bin, bts, 0o755))
+	archive := filepath.Join(dist, "proj_linux_amd64.tar.gz")
+	require.NoError(t, os.WriteFile(archive, []byte("tar.gz contents"), 0o644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.Binary,
+		Name:   "proj",
+		Path:   bin,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Target: "linux_amd64_v1",
+		Extra: map[string]any{
+			artifact.ExtraID:     "proj",
+			artifact.ExtraBinary: "proj",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.UploadableArchive,
+		Name:   "proj_linux_amd64.tar.gz",
+		Path:   archive,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Target: "linux_amd64_v1",
+		Extra: map[string]any{
+			artifact.ExtraID:       "proj",
+			artifact.ExtraBinaries: []string{"proj"},
+		},
+	})
+
+	require.NoError(t, sbom.Pipe{}.Default(ctx))
+	require.NoError(t, sbom.Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	published := ctx.Artifacts.Filter(artifact.ByType(artifact.OCIArtifact)).List()
+	require.NotEmpty(t, published)
+	digest := artifact.MustExtra[string](*published[0], artifact.ExtraDigest)
+	ref, err := name.NewDigest(host + "/proj@" + digest)
+	require.NoError(t, err)
+	idx, err := remote.Referrers(ref)
+	require.NoError(t, err)
+	im, err := idx.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, im.Manifests, 1)
+	m := getManifest(t, host+"/proj@"+im.Manifests[0].Digest.String())
+	require.Equal(t, digest, m.Subject.Digest.String())
+	require.Equal(t, "application/spdx+json", m.ArtifactType)
+	require.Equal(t, "proj_linux_amd64.tar.gz.sbom.json", m.Layers[0].Annotations[oci.AnnotationTitle])
+}
+
+func TestPublishArtifacts(t *testing.T) {
+	for artifacts, expected := range map[string][]string{
+		"archive":  {"proj_linux_amd64.tar.gz", "proj_windows_amd64.zip"},
+		"package":  {"proj_amd64.deb"},
+		"checksum": {"checksums.txt"},
+	} {
+		t.Run(artifacts, func(t *testing.T) {
+			host := newRegistry(t, true)
+			ctx := newContext(t, config.OCIArtifact{
+				Repository: host + "/proj",
+				Artifacts:  artifacts,
+			})
+			addArtifacts(t, ctx)
+			require.NoError(t, Pipe{}.Publish(ctx))
+			var got []string
+			for file := range getManifest(t, host+"/proj:v1.0.0").layers() {
+				got = append(got, file)
+			}
+			require.ElementsMatch(t, expected, got)
+		})
+	}
+
+	t.Run("ids", func(t *testing.T) {
+		host := newRegistry(t, true)
+		ctx := newContext(t, config.OCIArtifact{
+			Repository: host + "/proj",
+			IDs:        []string{"windows"},
+		})
+		addArtifacts(t, ctx)
+		require.NoError(t, Pipe{}.Publish(ctx))
+		var got []string
+		for file := range getManifest(t, host+"/proj:v1.0.0").layers() {
+			got = append(got, file)
+		}
+		require.ElementsMatch(t, []string{"proj_windows_amd64.zip", "checksums.txt"}, got)
+	})
+}
+
+func TestPublishSkips(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		ctx := newContext(t, config.OCIArtifact{
+			Repository: "example.com/proj",
+			Disable:    "true",
+		})
+		addArtifacts(t, ctx)
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("no tags", func(t *testing.T) {
+		ctx := newContext(t, config.OCIArtifact{
+			Repository: "example.com/proj",
+			Tags:       []string{"{{ if .IsSnapshot }}snapshot{{ end }}"},
+		})
+		addArtifacts(t, ctx)
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("no artifacts", func(t *testing.T) {
+		ctx := newContext(t, config.OCIArtifact{
+			Repository: "example.com/proj",
+		})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+}
+
+func TestPublishErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg config.OCIArtifact
+		err string
+	}{
+		"invalid artifacts": {
+			cfg: config.OCIArtifact{Repository: "example.com/proj", Artifacts: "nope"},
+			err: "oci_artifacts: invalid artifacts: nope",
+		},
+		"invalid repository": {
+			cfg: config.OCIArtifact{Repository: "UPPER/case"},
+			err: `oci_artifacts: invalid repository "UPPER/case"`,
+		},
+		"push": {
+			cfg: config.OCIArtifact{Repository: "localhost:1/proj"},
+			err: "oci_artifacts: could not push localhost:1/proj:v1.0.0",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := newContext(t, tc.cfg)
+			addArtifacts(t, ctx)
+			require.ErrorContains(t, Pipe{}.Publish(ctx), tc.err)
+		})
+	}
+
+	t.Run("templates", func(t *testing.T) {
+		for name, cfg := range map[string]config.OCIArtifact{
+			"disable":     {Repository: "example.com/proj", Disable: "{{ .Nope }}"},
+			"repository":  {Repository: "{{ .Nope }}"},
+			"tags":        {Repository: "example.com/proj", Tags: []string{"{{ .Nope }}"}},
+			"annotations": {Repository: "example.com/proj", Annotations: map[string]string{"a": "{{ .Nope }}"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx := newContext(t, cfg)
+				addArtifacts(t, ctx)
+				testlib.RequireTemplateError(t, Pipe{}.Publish(ctx))
+			})
+		}
+	})
+}
+
+func newRegistry(tb testing.TB, referrers bool) string {
+	tb.Helper()
+	srv := httptest.NewServer(registry.New(
+		registry.Logger(stdlog.New(io.Discard, "", 0)),
+		registry.WithReferrersSupport(referrers),
+	))
+	tb.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func newContext(tb testing.TB, cfg config.OCIArtifact) *context.Context {
+	tb.Helper()
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		ProjectName:  "proj",
+		OCIArtifacts: []config.OCIArtifact{cfg},
+	},
+		testctx.WithCurrentTag("v1.0.0"),
+		testctx.WithVersion("1.0.0"),
+	)
+	require.NoError(tb, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func addArtifacts(tb testing.TB, ctx *context.Context) {
+	tb.Helper()
+	dir := tb.TempDir()
+	add := func(a *artifact.Artifact, content string) {
+		a.Path = filepath.Join(dir, a.Name)
+		require.NoError(tb, os.WriteFile(a.Path, []byte(content), 0o644))
+		ctx.Artifacts.Add(a)
+	}
+	add(&artifact.Artifact{
+		Name:  "proj_linux_amd64.tar.gz",
+		Type:  artifact.UploadableArchive,
+		Extra: map[string]any{artifact.ExtraID: "linux"},
+	}, "tar.gz contents")
+	add(&artifact.Artifact{
+		Name:  "proj_windows_amd64.zip",
+		Type:  artifact.UploadableArchive,
+		Extra: map[string]any{artifact.ExtraID: "windows"},
+	}, "zip contents")
+	add(&artifact.Artifact{
+		Name:  "proj_amd64.deb",
+		Type:  artifact.LinuxPackage,
+		Extra: map[string]any{artifact.ExtraID: "linux"},
+	}, "deb contents")
+	add(&artifact.Artifact{
+		Name: "checksums.txt",
+		Type: artifact.Checksum,
+	}, "checksums")
+	add(&artifact.Artifact{
+		Name:  "checksums.txt.sig",
+		Type:  artifact.Signature,
+		Extra: map[string]any{artifact.ExtraSignatureOf: "checksums.txt"},
+	}, "signature")
+	add(&artifact.Artifact{
+		Name:  "checksums.txt.pem",
+		Type:  artifact.Certificate,
+		Extra: map[string]any{artifact.ExtraSignatureOf: "checksums.txt"},
+	}, "certificate")
+	add(&artifact.Artifact{
+		Name:  "proj.bin.sig",
+		Type:  artifact.Signature,
+		Extra: map[string]any{artifact.ExtraSignatureOf: "proj.bin"},
+	}, "signature of something not published")
+	add(&artifact.Artifact{
+		Name: "proj.spdx.json",
+		Type: artifact.SBOM,
+		Extra: map[string]any{artifact.ExtraSBOMOf: []string{
+			filepath.Join(dir, "proj_linux_amd64.tar.gz"),
+		}},
+	}, `{"spdxVersion":"SPDX-2.3"}`)
+	add(&artifact.Artifact{
+		Name: "proj.cdx.json",
+		Type: artifact.SBOM,
+		Extra: map[string]any{artifact.ExtraSBOMOf: []string{
+			filepath.Join(dir, "proj_windows_amd64.zip"),
+			filepath.Join(dir, "proj.bin"),
+		}},
+	}, `{"bomFormat":"CycloneDX"}`)
+	add(&artifact.Artifact{
+		Name: "proj.sbom",
+		Type: artifact.SBOM,
+		Extra: map[string]any{artifact.ExtraSBOMOf: []string{
+			filepath.Join(dir, "proj_amd64.deb"),
+		}},
+	}, "not json")
+	add(&artifact.Artifact{
+		Name: "proj.bin.spdx.json",
+		Type: artifact.SBOM,
+		Extra: map[string]any{artifact.ExtraSBOMOf: []string{
+			filepath.Join(dir, "proj.bin"),
+		}},
+	}, `{"spdxVersion":"SPDX-2.3"}`)
+	add(&artifact.Artifact{
+		Name: "source.spdx.json",
+		Type: artifact.SBOM,
+	}, `{"spdxVersion":"SPDX-2.3"}`)
+}
+
+type manifest struct {
+	v1.Manifest
+	digest v1.Hash
+}
+
+// layers maps the file names of the layers to their media types.
+func (m manifest) layers() map[string]types.MediaType {
+	result := map[string]types.MediaType{}
+	for _, layer := range m.Layers {
+		result[layer.Annotations[oci.AnnotationTitle]] = layer.MediaType
+	}
+	return result
+}
+
+func getManifest(tb testing.TB, s string) manifest {
+	tb.Helper()
+	ref, err := name.ParseReference(s)
+	require.NoError(tb, err)
+	desc, err := remote.Get(ref)
+	require.NoError(tb, err)
+	require.Equal(tb, types.OCIManifestSchema1, desc.MediaType)
+	m, err := v1.ParseManifest(strings.NewReader(string(desc.Manifest)))
+	require.NoError(tb, err)
+	return manifest{Manifest: *m, digest: desc.Digest}
+}
+
+func readBlob(tb testing.TB, repo string, digest v1.Hash) string {
+	tb.Helper()
+	ref, err := name.NewDigest(repo + "@" + digest.String())
+	require.NoError(tb, err)
+	layer, err := remote.Layer(ref)
+	require.NoError(tb, err)
+	rc, err := layer.Compressed()
+	require.NoError(tb, err)
+	defer rc.Close()
+	bts, err := io.ReadAll(rc)
+	require.NoError(tb, err)
+	return string(bts)
+}
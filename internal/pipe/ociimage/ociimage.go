@@ -0,0 +1,540 @@
+// Package ociimage assembles OCI images from prebuilt binaries, without
+// needing a Docker daemon.
+package ociimage
+
+import (
+	"archive/tar"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/goreleaser/goreleaser/v2/internal/archivefiles"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/gio"
+	"github.com/goreleaser/goreleaser/v2/internal/ids"
+	"github.com/goreleaser/goreleaser/v2/internal/oci"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe"
+	dockerv2 "github.com/goreleaser/goreleaser/v2/internal/pipe/docker/v2"
+	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// scratch is the base image name for an empty image.
+const scratch = "scratch"
+
+// Pipe that assembles OCI images from the built binaries.
+type Pipe struct{}
+
+func (Pipe) String() string { return "oci images" }
+
+func (Pipe) Skip(ctx *context.Context) bool {
+	return skips.Any(ctx, skips.OCIImage) || len(ctx.Config.OCIImages) == 0
+}
+
+// Default sets the Pipes defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("oci_images")
+	for i := range ctx.Config.OCIImages {
+		img := &ctx.Config.OCIImages[i]
+		if img.ID == "" {
+			img.ID = ctx.Config.ProjectName
+		}
+		if img.BaseImage == "" {
+			img.BaseImage = scratch
+		}
+		if len(img.Tags) == 0 {
+			img.Tags = []string{"{{ .Tag }}"}
+		}
+		if len(img.Platforms) == 0 {
+			img.Platforms = []string{"linux/amd64", "linux/arm64"}
+		}
+		if img.BinaryDir == "" {
+			img.BinaryDir = "/usr/local/bin"
+		}
+		if img.ModTimestamp == "" {
+			img.ModTimestamp = "{{ .CommitTimestamp }}"
+		}
+		ids.Inc(img.ID)
+	}
+	return ids.Validate()
+}
+
+// Run assembles the images, writing them as OCI image layouts to the dist
+// directory.
+func (Pipe) Run(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, img := range ctx.Config.OCIImages {
+		g.Go(func() error {
+			return doRun(ctx, img)
+		})
+	}
+	return g.Wait()
+}
+
+// Publish pushes the previously assembled images to their registries.
+func (Pipe) Publish(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, img := range ctx.Config.OCIImages {
+		g.Go(func() error {
+			return doPublish(ctx, img)
+		})
+	}
+	return g.Wait()
+}
+
+// layoutPath is where the OCI image layout of the given image is written.
+func layoutPath(ctx *context.Context, img config.OCIImage) string {
+	return filepath.Join(ctx.Config.Dist, "oci", img.ID)
+}
+
+func disabled(ctx *context.Context, img config.OCIImage) error {
+	disable, err := tmpl.New(ctx).Bool(img.Disable)
+	if err != nil {
+		return err
+	}
+	if disable {
+		return pipe.Skip("configuration is disabled")
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, img config.OCIImage) error {
+	if err := disabled(ctx, img); err != nil {
+		return err
+	}
+
+	tpl := tmpl.New(ctx)
+	if err := tpl.ApplySlice(&img.Platforms, tmpl.NonEmpty()); err != nil {
+		return err
+	}
+	if err := tpl.ApplyAll(&img.BaseImage, &img.User); err != nil {
+		return err
+	}
+	for _, s := range []*[]string{&img.Entrypoint, &img.Cmd, &img.Env} {
+		if err := tpl.ApplySlice(s); err != nil {
+			return err
+		}
+	}
+	labels, err := applyMap(tpl, img.Labels)
+	if err != nil {
+		return err
+	}
+	annotations, err := applyMap(tpl, img.Annotations)
+	if err != nil {
+		return err
+	}
+	modTimestamp, err := tpl.Apply(img.ModTimestamp)
+	if err != nil {
+		return err
+	}
+	modTime, err := gio.ModTime(modTimestamp, ctx.Date)
+	if err != nil {
+		return fmt.Errorf("oci_images: %w", err)
+	}
+	files, err := archivefiles.Eval(tpl, img.ExtraFiles)
+	if err != nil {
+		return fmt.Errorf("oci_images: %w", err)
+	}
+
+	idx := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	var platforms []string
+	for _, plat := range img.Platforms {
+		platform, err := v1.ParsePlatform(plat)
+		if err != nil {
+			return fmt.Errorf("oci_images: invalid platform %q: %w", plat, err)
+		}
+		binaries := ctx.Artifacts.Filter(binariesFilter(img, *platform)).List()
+		if len(binaries) == 0 {
+			log.WithField("id", img.ID).
+				WithField("platform", plat).
+				Warn("no binaries found, skipping platform")
+			continue
+		}
+
+		image, err := buildImage(ctx, img, *platform, binaries, files, modTime)
+		if err != nil {
+			return err
+		}
+		image, err = mutateImage(image, img, labels, binaries, modTime)
+		if err != nil {
+			return err
+		}
+		if len(annotations) > 0 {
+			image = mutate.Annotations(image, annotations).(v1.Image)
+		}
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+			Add: image,
+			Descriptor: v1.Descriptor{
+				Platform: platform,
+			},
+		})
+		platforms = append(platforms, plat)
+	}
+	if len(platforms) == 0 {
+		return pipe.Skip("no binaries found for any of the platforms")
+	}
+	if len(annotations) > 0 {
+		idx = mutate.Annotations(idx, annotations).(v1.ImageIndex)
+	}
+
+	dir := layoutPath(ctx, img)
+	log.WithField("id", img.ID).
+		WithField("platforms", strings.Join(platforms, ", ")).
+		WithField("path", dir).
+		Info("writing image layout")
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if _, err := layout.Write(dir, idx); err != nil {
+		return fmt.Errorf("oci_images: could not write image layout: %w", err)
+	}
+	return nil
+}
+
+func binariesFilter(img config.OCIImage, platform v1.Platform) artifact.Filter {
+	filters := []artifact.Filter{
+		artifact.ByType(artifact.Binary),
+		artifact.ByGoos(platform.OS),
+		artifact.ByGoarch(platform.Architecture),
+		artifact.ByIDs(img.IDs...),
+	}
+	switch platform.Architecture {
+	case "arm":
+		if platform.Variant != "" {
+			filters = append(filters, artifact.ByGoarm(strings.TrimPrefix(platform.Variant, "v")))
+		}
+	case "amd64":
+		filters = append(filters, artifact.ByGoamd64(cmp.Or(platform.Variant, "v1")))
+	}
+	return artifact.And(filters...)
+}
+
+// buildImage appends a layer with the binaries and extra files on top of the
+// base image for the given platform.
+func buildImage(
+	ctx *context.Context,
+	img config.OCIImage,
+	platform v1.Platform,
+	binaries []*artifact.Artifact,
+	files []config.File,
+	modTime time.Time,
+) (v1.Image, error) {
+	base, err := baseImage(ctx, img.BaseImage, platform)
+	if err != nil {
+		return nil, err
+	}
+	mt, err := base.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	layerType := types.OCILayer
+	if mt == types.DockerManifestSchema2 {
+		layerType = types.DockerLayer
+	}
+
+	var entries []layerEntry
+	for _, bin := range binaries {
+		entries = append(entries, layerEntry{
+			src:  bin.Path,
+			dst:  path.Join(img.BinaryDir, bin.Name),
+			mode: 0o755,
+		})
+	}
+	for _, f := range files {
+		entries = append(entries, layerEntry{
+			src:   f.Source,
+			dst:   f.Destination,
+			mode:  f.Info.Mode,
+			owner: f.Info.Owner,
+			group: f.Info.Group,
+			mtime: f.Info.ParsedMTime,
+		})
+	}
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		r, w := io.Pipe()
+		go func() {
+			_ = w.CloseWithError(writeLayer(w, entries, modTime))
+		}()
+		return r, nil
+	}, tarball.WithMediaType(layerType))
+	if err != nil {
+		return nil, fmt.Errorf("oci_images: could not create layer: %w", err)
+	}
+
+	return mutate.Append(base, mutate.Addendum{
+		Layer: layer,
+		History: v1.History{
+			Author:    "goreleaser",
+			Created:   v1.Time{Time: modTime},
+			CreatedBy: "goreleaser oci_images " + img.ID,
+		},
+		MediaType: layerType,
+	})
+}
+
+// baseImage gets the base image for the given platform.
+func baseImage(ctx *context.Context, base string, platform v1.Platform) (v1.Image, error) {
+	if base == scratch {
+		img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+		img = mutate.ConfigMediaType(img, types.OCIConfigJSON)
+		cf, err := img.ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		cf = cf.DeepCopy()
+		cf.OS = platform.OS
+		cf.Architecture = platform.Architecture
+		cf.Variant = platform.Variant
+		return mutate.ConfigFile(img, cf)
+	}
+
+	ref, err := name.ParseReference(base)
+	if err != nil {
+		return nil, fmt.Errorf("oci_images: invalid base image %q: %w", base, err)
+	}
+	img, err := remote.Image(
+		ref,
+		remote.WithContext(ctx),
+		remote.WithPlatform(platform),
+		remote.WithAuthFromKeychain(oci.Keychain),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oci_images: could not get base image %q: %w", base, err)
+	}
+	return img, nil
+}
+
+// mutateImage sets the image configuration and creation time.
+func mutateImage(
+	image v1.Image,
+	img config.OCIImage,
+	labels map[string]string,
+	binaries []*artifact.Artifact,
+	modTime time.Time,
+) (v1.Image, error) {
+	cf, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg := *cf.Config.DeepCopy()
+	switch {
+	case len(img.Entrypoint) > 0:
+		cfg.Entrypoint = img.Entrypoint
+	case len(binaries) == 1:
+		cfg.Entrypoint = []string{path.Join(img.BinaryDir, binaries[0].Name)}
+	}
+	if len(img.Cmd) > 0 {
+		cfg.Cmd = img.Cmd
+	}
+	if img.User != "" {
+		cfg.User = img.User
+	}
+	cfg.Env = append(cfg.Env, img.Env...)
+	if len(labels) > 0 {
+		if cfg.Labels == nil {
+			cfg.Labels = map[string]string{}
+		}
+		maps.Copy(cfg.Labels, labels)
+	}
+
+	image, err = mutate.Config(image, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mutate.CreatedAt(image, v1.Time{Time: modTime})
+}
+
+type layerEntry struct {
+	src, dst     string
+	mode         os.FileMode
+	owner, group string
+	mtime        time.Time
+}
+
+// writeLayer writes the given entries as a tar stream, along with their
+// parent directories.
+//
+// All entries are owned by root unless specified otherwise, and have the
+// same modification time, so the layer is reproducible.
+func writeLayer(w io.Writer, entries []layerEntry, modTime time.Time) error {
+	tw := tar.NewWriter(w)
+	slices.SortFunc(entries, func(a, b layerEntry) int {
+		return strings.Compare(cleanPath(a.dst), cleanPath(b.dst))
+	})
+
+	dirs := map[string]bool{}
+	for _, entry := range entries {
+		dst := cleanPath(entry.dst)
+		if err := writeDirs(tw, dirs, path.Dir(dst), modTime); err != nil {
+			return err
+		}
+
+		f, err := os.Open(entry.src)
+		if err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		mode := entry.mode
+		if mode == 0 {
+			mode = info.Mode().Perm()
+		}
+		mtime := entry.mtime
+		if mtime.IsZero() {
+			mtime = modTime
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     dst,
+			Size:     info.Size(),
+			Mode:     int64(mode),
+			Uname:    entry.owner,
+			Gname:    entry.group,
+			ModTime:  mtime,
+			Format:   tar.FormatPAX,
+		}); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if _, err := io.Copy(tw, f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeDirs(tw *tar.Writer, dirs map[string]bool, dir string, modTime time.Time) error {
+	if dir == "." || dirs[dir] {
+		return nil
+	}
+	if err := writeDirs(tw, dirs, path.Dir(dir), modTime); err != nil {
+		return err
+	}
+	dirs[dir] = true
+	return tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir + "/",
+		Mode:     0o755,
+		ModTime:  modTime,
+		Format:   tar.FormatPAX,
+	})
+}
+
+// cleanPath makes the given path relative to the image root.
+func cleanPath(s string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(s)), "/")
+}
+
+func doPublish(ctx *context.Context, img config.OCIImage) error {
+	if err := disabled(ctx, img); err != nil {
+		return err
+	}
+
+	tpl := tmpl.New(ctx)
+	images := slices.Clone(img.Images)
+	if err := tpl.ApplySlice(&images, tmpl.NonEmpty()); err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return pipe.Skip("no images to push")
+	}
+	tags := slices.Clone(img.Tags)
+	if err := tpl.ApplySlice(&tags, tmpl.NonEmpty()); err != nil {
+		return err
+	}
+
+	dir := layoutPath(ctx, img)
+	idx, err := layout.ImageIndexFromPath(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return pipe.Skipf("no image layout found at %s", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("oci_images: could not read image layout: %w", err)
+	}
+	digest, err := idx.Digest()
+	if err != nil {
+		return err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	var platforms []string
+	for _, desc := range manifest.Manifests {
+		if desc.Platform != nil {
+			platforms = append(platforms, desc.Platform.String())
+		}
+	}
+
+	for _, image := range images {
+		for _, tag := range tags {
+			ref, err := name.NewTag(image + ":" + tag)
+			if err != nil {
+				return fmt.Errorf("oci_images: invalid image %q: %w", image+":"+tag, err)
+			}
+			log.WithField("image", ref.String()).
+				WithField("digest", digest.String()).
+				Info("pushing")
+			if err := remote.WriteIndex(
+				ref,
+				idx,
+				remote.WithContext(ctx),
+				remote.WithAuthFromKeychain(oci.Keychain),
+				remote.WithJobs(ctx.Parallelism),
+			); err != nil {
+				return fmt.Errorf("oci_images: could not push %s: %w", ref, err)
+			}
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name: ref.String(),
+				Path: ref.String(),
+				Type: artifact.DockerImageV2,
+				Extra: map[string]any{
+					artifact.ExtraID:        img.ID,
+					artifact.ExtraDigest:    digest.String(),
+					dockerv2.ExtraPlatforms: slices.Clone(platforms),
+				},
+			})
+		}
+	}
+	return nil
+}
+
+func applyMap(tpl *tmpl.Template, m map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		s, err := tpl.Apply(v)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = s
+	}
+	return result, nil
+}
@@ -0,0 +1,362 @@
+package ociimage
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	stdlog "log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var commitDate = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(testctx.Wrap(t.Context())))
+	})
+	t.Run("skip flag", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			OCIImages: []config.OCIImage{{}},
+		}, testctx.Skip(skips.OCIImage))
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			OCIImages: []config.OCIImage{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		ProjectName: "proj",
+		OCIImages:   []config.OCIImage{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.OCIImage{
+		ID:           "proj",
+		BaseImage:    "scratch",
+		Tags:         []string{"{{ .Tag }}"},
+		Platforms:    []string{"linux/amd64", "linux/arm64"},
+		BinaryDir:    "/usr/local/bin",
+		ModTimestamp: "{{ .CommitTimestamp }}",
+	}, ctx.Config.OCIImages[0])
+}
+
+func TestDefaultDuplicateID(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		OCIImages: []config.OCIImage{{ID: "a"}, {ID: "a"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 oci_images with the ID 'a', please fix your config")
+}
+
+func TestRun(t *testing.T) {
+	ctx := newContext(t, config.OCIImage{
+		Labels:      map[string]string{"org.opencontainers.image.version": "{{ .Version }}"},
+		Annotations: map[string]string{"org.opencontainers.image.title": "{{ .ProjectName }}"},
+		User:        "65532",
+		Env:         []string{"FOO=bar"},
+		ExtraFiles: []config.File{{
+			Source:      "./testdata/config.yaml",
+			Destination: "/etc/proj",
+			StripParent: true,
+		}},
+	})
+	addBinaries(t, ctx, "proj")
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	idx, err := layout.ImageIndexFromPath(filepath.Join(ctx.Config.Dist, "oci", "proj"))
+	require.NoError(t, err)
+	manifest, err := idx.IndexManifest()
+	require.NoError(t, err)
+	require.Equal(t, "proj", manifest.Annotations["org.opencontainers.image.title"])
+	require.Len(t, manifest.Manifests, 2)
+	require.Equal(t, "linux/amd64", manifest.Manifests[0].Platform.String())
+	require.Equal(t, "linux/arm64", manifest.Manifests[1].Platform.String())
+
+	for _, desc := range manifest.Manifests {
+		img, err := idx.Image(desc.Digest)
+		require.NoError(t, err)
+
+		cf, err := img.ConfigFile()
+		require.NoError(t, err)
+		require.Equal(t, desc.Platform.OS, cf.OS)
+		require.Equal(t, desc.Platform.Architecture, cf.Architecture)
+		require.Equal(t, commitDate, cf.Created.UTC())
+		require.Equal(t, []string{"/usr/local/bin/proj"}, cf.Config.Entrypoint)
+		require.Equal(t, "65532", cf.Config.User)
+		require.Equal(t, []string{"FOO=bar"}, cf.Config.Env)
+		require.Equal(t, "1.0.0", cf.Config.Labels["org.opencontainers.image.version"])
+
+		m, err := img.Manifest()
+		require.NoError(t, err)
+		require.Equal(t, "proj", m.Annotations["org.opencontainers.image.title"])
+
+		files := layerFiles(t, img)
+		require.Equal(t, []string{
+			"etc/",
+			"etc/proj/",
+			"etc/proj/config.yaml",
+			"usr/",
+			"usr/local/",
+			"usr/local/bin/",
+			"usr/local/bin/proj",
+		}, files)
+	}
+}
+
+func TestRunReproducible(t *testing.T) {
+	digest := func(tb testing.TB) v1.Hash {
+		tb.Helper()
+		ctx := newContext(t, config.OCIImage{})
+		addBinaries(t, ctx, "proj")
+		require.NoError(tb, Pipe{}.Run(ctx))
+		idx, err := layout.ImageIndexFromPath(filepath.Join(ctx.Config.Dist, "oci", "proj"))
+		require.NoError(tb, err)
+		h, err := idx.Digest()
+		require.NoError(tb, err)
+		return h
+	}
+	require.Equal(t, digest(t), digest(t))
+}
+
+func TestRunBaseImage(t *testing.T) {
+	host := newRegistry(t)
+	base, err := random.Index(1024, 2, 2)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(host + "/base:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(ref, base))
+
+	ctx := newContext(t, config.OCIImage{
+		BaseImage:  host + "/base:latest",
+		Platforms:  []string{"linux/amd64"},
+		Entrypoint: []string{"/usr/local/bin/proj", "serve"},
+	})
+	addBinaries(t, ctx, "proj")
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	idx, err := layout.ImageIndexFromPath(filepath.Join(ctx.Config.Dist, "oci", "proj"))
+	require.NoError(t, err)
+	manifest, err := idx.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, manifest.Manifests, 1)
+	img, err := idx.Image(manifest.Manifests[0].Digest)
+	require.NoError(t, err)
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	require.Len(t, layers, 3)
+	cf, err := img.ConfigFile()
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/local/bin/proj", "serve"}, cf.Config.Entrypoint)
+}
+
+func TestRunSkips(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		ctx := newContext(t, config.OCIImage{Disable: "true"})
+		addBinaries(t, ctx, "proj")
+		testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+	})
+	t.Run("no binaries", func(t *testing.T) {
+		ctx := newContext(t, config.OCIImage{IDs: []string{"nope"}})
+		addBinaries(t, ctx, "proj")
+		testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+	})
+}
+
+func TestRunErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		img config.OCIImage
+		err string
+	}{
+		"invalid platform": {
+			img: config.OCIImage{Platforms: []string{"linux/amd64/v2/nope"}},
+			err: `oci_images: invalid platform "linux/amd64/v2/nope"`,
+		},
+		"invalid mod_timestamp": {
+			img: config.OCIImage{ModTimestamp: "nope"},
+			err: "oci_images: invalid mod_timestamp",
+		},
+		"invalid base image": {
+			img: config.OCIImage{BaseImage: "UPPER/case:nope"},
+			err: `oci_images: invalid base image "UPPER/case:nope"`,
+		},
+		"missing base image": {
+			img: config.OCIImage{BaseImage: newRegistry(t) + "/nope:latest"},
+			err: "oci_images: could not get base image",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := newContext(t, tc.img)
+			addBinaries(t, ctx, "proj")
+			require.ErrorContains(t, Pipe{}.Run(ctx), tc.err)
+		})
+	}
+
+	t.Run("templates", func(t *testing.T) {
+		for name, img := range map[string]config.OCIImage{
+			"disable":     {Disable: "{{ .Nope }}"},
+			"platforms":   {Platforms: []string{"{{ .Nope }}"}},
+			"base_image":  {BaseImage: "{{ .Nope }}"},
+			"entrypoint":  {Entrypoint: []string{"{{ .Nope }}"}},
+			"labels":      {Labels: map[string]string{"a": "{{ .Nope }}"}},
+			"annotations": {Annotations: map[string]string{"a": "{{ .Nope }}"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx := newContext(t, img)
+				addBinaries(t, ctx, "proj")
+				testlib.RequireTemplateError(t, Pipe{}.Run(ctx))
+			})
+		}
+	})
+}
+
+func TestPublish(t *testing.T) {
+	host := newRegistry(t)
+	ctx := newContext(t, config.OCIImage{
+		Images: []string{host + "/proj", host + "/mirror"},
+		Tags:   []string{"{{ .Tag }}", "latest"},
+	})
+	addBinaries(t, ctx, "proj")
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	idx, err := layout.ImageIndexFromPath(filepath.Join(ctx.Config.Dist, "oci", "proj"))
+	require.NoError(t, err)
+	digest, err := idx.Digest()
+	require.NoError(t, err)
+
+	images := ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImageV2)).List()
+	require.Len(t, images, 4)
+	for _, img := range images {
+		require.Equal(t, digest.String(), artifact.MustExtra[string](*img, artifact.ExtraDigest))
+		require.Equal(t, "proj", artifact.MustExtra[string](*img, artifact.ExtraID))
+
+		ref, err := name.ParseReference(img.Name)
+		require.NoError(t, err)
+		desc, err := remote.Get(ref)
+		require.NoError(t, err)
+		require.Equal(t, digest, desc.Digest)
+	}
+	require.Equal(t, host+"/proj:v1.0.0", images[0].Name)
+	require.Equal(t, host+"/mirror:latest", images[3].Name)
+}
+
+func TestPublishSkips(t *testing.T) {
+	t.Run("no images", func(t *testing.T) {
+		ctx := newContext(t, config.OCIImage{})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		ctx := newContext(t, config.OCIImage{
+			Images:  []string{"example.com/proj"},
+			Disable: "true",
+		})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+	t.Run("no layout", func(t *testing.T) {
+		ctx := newContext(t, config.OCIImage{
+			Images: []string{"example.com/proj"},
+		})
+		testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+	})
+}
+
+func newRegistry(tb testing.TB) string {
+	tb.Helper()
+	srv := httptest.NewServer(registry.New(registry.Logger(stdlog.New(io.Discard, "", 0))))
+	tb.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func newContext(tb testing.TB, img config.OCIImage) *context.Context {
+	tb.Helper()
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		ProjectName: "proj",
+		Dist:        tb.TempDir(),
+		OCIImages:   []config.OCIImage{img},
+	},
+		testctx.WithCurrentTag("v1.0.0"),
+		testctx.WithVersion("1.0.0"),
+		testctx.WithCommitDate(commitDate),
+	)
+	require.NoError(tb, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func addBinaries(tb testing.TB, ctx *context.Context, id string) {
+	tb.Helper()
+	for _, goarch := range []string{"amd64", "arm64"} {
+		path := filepath.Join(tb.TempDir(), "proj")
+		require.NoError(tb, os.WriteFile(path, []byte("fake "+goarch), 0o755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "proj",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: goarch,
+			Type:   artifact.Binary,
+			Extra: map[string]any{
+				artifact.ExtraID: id,
+			},
+		})
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "proj.exe",
+		Path:   "nope.exe",
+		Goos:   "windows",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]any{
+			artifact.ExtraID: id,
+		},
+	})
+}
+
+// layerFiles lists the files in the last layer of the image, checking their
+// ownership and modification time along the way.
+func layerFiles(tb testing.TB, img v1.Image) []string {
+	tb.Helper()
+	layers, err := img.Layers()
+	require.NoError(tb, err)
+	rc, err := layers[len(layers)-1].Uncompressed()
+	require.NoError(tb, err)
+	defer rc.Close()
+
+	var files []string
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(tb, err)
+		require.Equal(tb, 0, hdr.Uid)
+		require.Equal(tb, commitDate, hdr.ModTime.UTC())
+		files = append(files, hdr.Name)
+	}
+	return files
+}
@@ -0,0 +1,433 @@
+// Package provenance generates a SLSA provenance attestation for the release
+// artifacts.
+//
+// The attestation is an in-toto statement with a SLSA v1 provenance predicate,
+// wrapped in a DSSE envelope signed with a local key.
+//
+// See https://slsa.dev/spec/v1.0/provenance and
+// https://github.com/secure-systems-lab/dsse.
+package provenance
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	statementType = "https://in-toto.io/Statement/v1"
+	predicateType = "https://slsa.dev/provenance/v1"
+	buildType     = "https://goreleaser.com/provenance/v1"
+	payloadType   = "application/vnd.in-toto+json"
+)
+
+var errNoKey = errors.New("provenance: key is required")
+
+// Pipe that generates the provenance attestation.
+type Pipe struct{}
+
+func (Pipe) String() string { return "slsa provenance" }
+
+func (Pipe) Skip(ctx *context.Context) (bool, error) {
+	if skips.Any(ctx, skips.Provenance) {
+		return true, nil
+	}
+	enabled, err := tmpl.New(ctx).Bool(ctx.Config.Provenance.Enabled)
+	return !enabled, err
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	cfg := &ctx.Config.Provenance
+	if cfg.NameTemplate == "" {
+		cfg.NameTemplate = "{{ .ProjectName }}_{{ .Version }}.intoto.jsonl"
+	}
+	if cfg.BuilderID == "" {
+		cfg.BuilderID = "https://goreleaser.com"
+	}
+	return nil
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	cfg := ctx.Config.Provenance
+	tpl := tmpl.New(ctx)
+
+	keyPath, err := tpl.Apply(cfg.Key)
+	if err != nil {
+		return fmt.Errorf("provenance: key: %w", err)
+	}
+	if keyPath == "" {
+		return errNoKey
+	}
+	signer, err := loadSigner(keyPath)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+
+	filename, err := tpl.Apply(cfg.NameTemplate)
+	if err != nil {
+		return fmt.Errorf("provenance: name template: %w", err)
+	}
+	builderID, err := tpl.Apply(cfg.BuilderID)
+	if err != nil {
+		return fmt.Errorf("provenance: builder id: %w", err)
+	}
+
+	path := filepath.Join(ctx.Config.Dist, filename)
+	var last []byte
+	write := func() error {
+		payload, err := statementPayload(ctx, builderID)
+		if err != nil {
+			return err
+		}
+		// signatures might be randomized, so the envelope is only rewritten
+		// if the statement changed, keeping any signature of it valid.
+		if bytes.Equal(payload, last) {
+			return nil
+		}
+		if err := writeEnvelope(signer, payload, path); err != nil {
+			return err
+		}
+		last = payload
+		return nil
+	}
+	if err := write(); err != nil {
+		return err
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Provenance,
+		Name: filename,
+		Path: path,
+		Extra: map[string]any{
+			artifact.ExtraRefresh: func() error {
+				log.WithField("file", filename).Debug("refreshing provenance")
+				return write()
+			},
+		},
+	})
+	return nil
+}
+
+func statementPayload(ctx *context.Context, builderID string) ([]byte, error) {
+	st, err := statement(ctx, builderID)
+	if err != nil {
+		return nil, fmt.Errorf("provenance: %w", err)
+	}
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, fmt.Errorf("provenance: %w", err)
+	}
+	return payload, nil
+}
+
+func writeEnvelope(signer crypto.Signer, payload []byte, path string) error {
+	env, err := sign(signer, payload)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	bts, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	log.WithField("file", path).Info("writing")
+	return os.WriteFile(path, append(bts, '\n'), 0o644)
+}
+
+// Statement is an in-toto v1 statement.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact the statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate is a SLSA v1 provenance predicate.
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes how the artifacts were built.
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   ExternalParameters   `json:"externalParameters"`
+	InternalParameters   InternalParameters   `json:"internalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ExternalParameters are the parameters under the user control.
+type ExternalParameters struct {
+	ProjectName string  `json:"projectName"`
+	Tag         string  `json:"tag"`
+	Snapshot    bool    `json:"snapshot"`
+	Builds      []Build `json:"builds"`
+}
+
+// Build is the build configuration used.
+type Build struct {
+	ID      string   `json:"id"`
+	Builder string   `json:"builder"`
+	Dir     string   `json:"dir,omitempty"`
+	Main    string   `json:"main,omitempty"`
+	Binary  string   `json:"binary,omitempty"`
+	Targets []string `json:"targets,omitempty"`
+	Flags   []string `json:"flags,omitempty"`
+	Ldflags []string `json:"ldflags,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// InternalParameters are the parameters set by the build platform.
+type InternalParameters struct {
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// ResourceDescriptor describes a dependency of the build.
+type ResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// RunDetails describes the build run.
+type RunDetails struct {
+	Builder  Builder  `json:"builder"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// Builder identifies the build platform.
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// Metadata about the build run.
+type Metadata struct {
+	StartedOn time.Time `json:"startedOn"`
+}
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a DSSE signature.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+func statement(ctx *context.Context, builderID string) (Statement, error) {
+	subjects, err := subjects(ctx)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	var builds []Build
+	for _, b := range ctx.Config.Builds {
+		builds = append(builds, Build{
+			ID:      b.ID,
+			Builder: b.Builder,
+			Dir:     b.Dir,
+			Main:    b.Main,
+			Binary:  b.Binary,
+			Targets: b.Targets,
+			Flags:   b.Flags,
+			Ldflags: b.Ldflags,
+			Tags:    b.Tags,
+		})
+	}
+
+	env := map[string]string{}
+	for _, key := range ctx.Config.Provenance.Env {
+		if v, ok := ctx.Env[key]; ok {
+			env[key] = v
+		}
+	}
+
+	var deps []ResourceDescriptor
+	if ctx.Git.FullCommit != "" {
+		uri := "git+" + ctx.Git.URL
+		if ctx.Git.CurrentTag != "" {
+			uri += "@refs/tags/" + ctx.Git.CurrentTag
+		}
+		deps = append(deps, ResourceDescriptor{
+			URI:    uri,
+			Digest: map[string]string{"gitCommit": ctx.Git.FullCommit},
+		})
+	}
+
+	return Statement{
+		Type:          statementType,
+		Subject:       subjects,
+		PredicateType: predicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType: buildType,
+				ExternalParameters: ExternalParameters{
+					ProjectName: ctx.Config.ProjectName,
+					Tag:         ctx.Git.CurrentTag,
+					Snapshot:    ctx.Snapshot,
+					Builds:      builds,
+				},
+				InternalParameters: InternalParameters{
+					Env: env,
+				},
+				ResolvedDependencies: deps,
+			},
+			RunDetails: RunDetails{
+				Builder: Builder{ID: builderID},
+				Metadata: Metadata{
+					StartedOn: ctx.Date.UTC(),
+				},
+			},
+		},
+	}, nil
+}
+
+func subjects(ctx *context.Context) ([]Subject, error) {
+	arts := ctx.Artifacts.Filter(artifact.ByTypes(
+		artifact.UploadableArchive,
+		artifact.UploadableBinary,
+		artifact.LinuxPackage,
+		artifact.Checksum,
+	)).List()
+	if len(arts) == 0 {
+		return nil, errors.New("no artifacts to attest")
+	}
+
+	result := make([]Subject, 0, len(arts))
+	for _, a := range arts {
+		sum, err := sha256sum(a.Path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Subject{
+			Name:   a.Name,
+			Digest: map[string]string{"sha256": sum},
+		})
+	}
+	// sort so the provenance is deterministic
+	slices.SortFunc(result, func(a, b Subject) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+func sha256sum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pae is the DSSE pre-authentication encoding.
+func pae(typ string, body []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(typ), typ, len(body), body)
+}
+
+func sign(signer crypto.Signer, payload []byte) (Envelope, error) {
+	msg := pae(payloadType, payload)
+	var opts crypto.SignerOpts = crypto.SHA256
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		opts = crypto.Hash(0)
+	} else {
+		sum := sha256.Sum256(msg)
+		msg = sum[:]
+	}
+	sig, err := signer.Sign(rand.Reader, msg, opts)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("could not sign: %w", err)
+	}
+	keyID, err := keyID(signer.Public())
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{{
+			KeyID: keyID,
+			Sig:   base64.StdEncoding.EncodeToString(sig),
+		}},
+	}, nil
+}
+
+// keyID is the hex encoded sha256 of the public key in PKIX form.
+func keyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func loadSigner(path string) (crypto.Signer, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read key: %w", err)
+	}
+	block, _ := pem.Decode(bts)
+	if block == nil {
+		return nil, fmt.Errorf("could not read key: %s: no PEM data found", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("could not read key: %s: unsupported PEM type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey, *rsa.PrivateKey:
+		return key.(crypto.Signer), nil
+	default:
+		return nil, fmt.Errorf("could not read key: %s: unsupported key type %T", path, key)
+	}
+}
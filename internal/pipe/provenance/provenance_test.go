@@ -0,0 +1,332 @@
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.Wrap(t.Context())
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "{{ .ProjectName }}_{{ .Version }}.intoto.jsonl", ctx.Config.Provenance.NameTemplate)
+	require.Equal(t, "https://goreleaser.com", ctx.Config.Provenance.BuilderID)
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		skip, err := Pipe{}.Skip(testctx.Wrap(t.Context()))
+		require.NoError(t, err)
+		require.True(t, skip)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Provenance: config.Provenance{Enabled: "true"},
+		})
+		skip, err := Pipe{}.Skip(ctx)
+		require.NoError(t, err)
+		require.False(t, skip)
+	})
+
+	t.Run("skip flag", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Provenance: config.Provenance{Enabled: "true"},
+		}, testctx.Skip(skips.Provenance))
+		skip, err := Pipe{}.Skip(ctx)
+		require.NoError(t, err)
+		require.True(t, skip)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Provenance: config.Provenance{Enabled: "{{ .Nope }}"},
+		})
+		_, err := Pipe{}.Skip(ctx)
+		testlib.RequireTemplateError(t, err)
+	})
+}
+
+func TestRun(t *testing.T) {
+	for name, key := range map[string]crypto.Signer{
+		"ecdsa":   mustECDSA(t),
+		"ed25519": mustEd25519(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, dist := setup(t, key)
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			provs := ctx.Artifacts.Filter(artifact.ByTypes(artifact.Provenance)).List()
+			require.Len(t, provs, 1)
+			prov := provs[0]
+			require.Equal(t, "foo_1.0.0.intoto.jsonl", prov.Name)
+			require.Equal(t, filepath.Join(dist, "foo_1.0.0.intoto.jsonl"), prov.Path)
+
+			st := verify(t, prov.Path, key.Public())
+			require.Equal(t, statementType, st.Type)
+			require.Equal(t, predicateType, st.PredicateType)
+			require.Equal(t, []Subject{
+				{Name: "checksums.txt", Digest: map[string]string{"sha256": sha256hex("checksums")}},
+				{Name: "foo.deb", Digest: map[string]string{"sha256": sha256hex("deb")}},
+				{Name: "foo.tar.gz", Digest: map[string]string{"sha256": sha256hex("archive")}},
+				{Name: "foo_linux_amd64", Digest: map[string]string{"sha256": sha256hex("binary")}},
+			}, st.Subject)
+
+			def := st.Predicate.BuildDefinition
+			require.Equal(t, buildType, def.BuildType)
+			require.Equal(t, ExternalParameters{
+				ProjectName: "foo",
+				Tag:         "v1.0.0",
+				Builds: []Build{{
+					ID:      "foo",
+					Builder: "go",
+					Main:    ".",
+					Binary:  "foo",
+					Targets: []string{"linux_amd64_v1"},
+					Ldflags: []string{"-s -w"},
+				}},
+			}, def.ExternalParameters)
+			require.Equal(t, map[string]string{"GITHUB_RUN_ID": "123"}, def.InternalParameters.Env)
+			require.Equal(t, []ResourceDescriptor{{
+				URI:    "git+https://github.com/goreleaser/foo.git@refs/tags/v1.0.0",
+				Digest: map[string]string{"gitCommit": "3d3bf1a4c2e1b9a1a2f0e9c7b1d2e3f4a5b6c7d8"},
+			}}, def.ResolvedDependencies)
+
+			run := st.Predicate.RunDetails
+			require.Equal(t, "https://example.com/builder", run.Builder.ID)
+			require.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), run.Metadata.StartedOn)
+		})
+	}
+}
+
+func TestRunRefresh(t *testing.T) {
+	key := mustECDSA(t)
+	ctx, dist := setup(t, key)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "checksums.txt"), []byte("refreshed"), 0o644))
+	require.NoError(t, ctx.Artifacts.Refresh())
+
+	prov := ctx.Artifacts.Filter(artifact.ByTypes(artifact.Provenance)).List()[0]
+	st := verify(t, prov.Path, key.Public())
+	require.Equal(t, Subject{
+		Name:   "checksums.txt",
+		Digest: map[string]string{"sha256": sha256hex("refreshed")},
+	}, st.Subject[0])
+}
+
+func TestRunRefreshUnchanged(t *testing.T) {
+	key := mustECDSA(t)
+	ctx, _ := setup(t, key)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	// ecdsa signatures are randomized, so signing the same statement again
+	// would change the file, and invalidate any signature of it.
+	prov := ctx.Artifacts.Filter(artifact.ByTypes(artifact.Provenance)).List()[0]
+	before, err := os.ReadFile(prov.Path)
+	require.NoError(t, err)
+	require.NoError(t, ctx.Artifacts.Refresh())
+	after, err := os.ReadFile(prov.Path)
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Run("no key", func(t *testing.T) {
+		ctx, _ := setup(t, mustECDSA(t))
+		ctx.Config.Provenance.Key = ""
+		require.ErrorIs(t, Pipe{}.Run(ctx), errNoKey)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		ctx, _ := setup(t, mustECDSA(t))
+		ctx.Config.Provenance.Key = filepath.Join(t.TempDir(), "nope.pem")
+		require.ErrorIs(t, Pipe{}.Run(ctx), os.ErrNotExist)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		ctx, _ := setup(t, mustECDSA(t))
+		path := filepath.Join(t.TempDir(), "key.pem")
+		require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+		ctx.Config.Provenance.Key = path
+		require.ErrorContains(t, Pipe{}.Run(ctx), "no PEM data found")
+	})
+
+	t.Run("unsupported pem", func(t *testing.T) {
+		ctx, _ := setup(t, mustECDSA(t))
+		path := filepath.Join(t.TempDir(), "key.pem")
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY"}), 0o600))
+		ctx.Config.Provenance.Key = path
+		require.ErrorContains(t, Pipe{}.Run(ctx), `unsupported PEM type "PUBLIC KEY"`)
+	})
+
+	t.Run("no artifacts", func(t *testing.T) {
+		ctx, _ := setup(t, mustECDSA(t))
+		ctx.Artifacts = artifact.New()
+		require.EqualError(t, Pipe{}.Run(ctx), "provenance: no artifacts to attest")
+	})
+
+	for name, fn := range map[string]func(cfg *config.Provenance){
+		"key":           func(cfg *config.Provenance) { cfg.Key = "{{ .Nope }}" },
+		"name template": func(cfg *config.Provenance) { cfg.NameTemplate = "{{ .Nope }}" },
+		"builder id":    func(cfg *config.Provenance) { cfg.BuilderID = "{{ .Nope }}" },
+	} {
+		t.Run("invalid "+name, func(t *testing.T) {
+			ctx, _ := setup(t, mustECDSA(t))
+			fn(&ctx.Config.Provenance)
+			testlib.RequireTemplateError(t, Pipe{}.Run(ctx))
+		})
+	}
+}
+
+func setup(tb testing.TB, key crypto.Signer) (*context.Context, string) {
+	tb.Helper()
+	dist := tb.TempDir()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(tb, err)
+	keyPath := filepath.Join(tb.TempDir(), "key.pem")
+	require.NoError(tb, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	}), 0o600))
+
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		Builds: []config.Build{{
+			ID:      "foo",
+			Builder: "go",
+			Main:    ".",
+			Binary:  "foo",
+			Targets: []string{"linux_amd64_v1"},
+			BuildDetails: config.BuildDetails{
+				Ldflags: []string{"-s -w"},
+			},
+		}},
+		Provenance: config.Provenance{
+			Enabled:   "true",
+			Key:       keyPath,
+			BuilderID: "https://example.com/builder",
+			Env:       []string{"GITHUB_RUN_ID", "NOT_SET"},
+		},
+	},
+		testctx.WithVersion("1.0.0"),
+		testctx.WithCurrentTag("v1.0.0"),
+		testctx.WithGitInfo(context.GitInfo{
+			CurrentTag: "v1.0.0",
+			FullCommit: "3d3bf1a4c2e1b9a1a2f0e9c7b1d2e3f4a5b6c7d8",
+			URL:        "https://github.com/goreleaser/foo.git",
+		}),
+		testctx.WithEnv(map[string]string{
+			"GITHUB_RUN_ID": "123",
+			"SECRET_TOKEN":  "nope",
+		}),
+		testctx.WithDate(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	)
+	require.NoError(tb, Pipe{}.Default(ctx))
+
+	for _, a := range []struct {
+		name    string
+		content string
+		typ     artifact.Type
+	}{
+		{"foo.tar.gz", "archive", artifact.UploadableArchive},
+		{"foo_linux_amd64", "binary", artifact.UploadableBinary},
+		{"foo.deb", "deb", artifact.LinuxPackage},
+		{"checksums.txt", "checksums", artifact.Checksum},
+		{"README.md", "readme", artifact.UploadableFile},
+		{"foo.sbom.json", "sbom", artifact.SBOM},
+	} {
+		path := filepath.Join(dist, a.name)
+		require.NoError(tb, os.WriteFile(path, []byte(a.content), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: a.name,
+			Path: path,
+			Type: a.typ,
+		})
+	}
+	return ctx, dist
+}
+
+func verify(tb testing.TB, path string, pub crypto.PublicKey) Statement {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+
+	var env Envelope
+	require.NoError(tb, json.Unmarshal(bts, &env))
+	require.Equal(tb, payloadType, env.PayloadType)
+	require.Len(tb, env.Signatures, 1)
+
+	expectedID, err := keyID(pub)
+	require.NoError(tb, err)
+	require.Equal(tb, expectedID, env.Signatures[0].KeyID)
+
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	require.NoError(tb, err)
+	sig, err := base64.StdEncoding.DecodeString(env.Signatures[0].Sig)
+	require.NoError(tb, err)
+
+	msg := pae(payloadType, payload)
+	switch pub := pub.(type) {
+	case ed25519.PublicKey:
+		require.True(tb, ed25519.Verify(pub, msg, sig))
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(msg)
+		require.True(tb, ecdsa.VerifyASN1(pub, sum[:], sig))
+	default:
+		tb.Fatalf("unexpected key type %T", pub)
+	}
+
+	var st Statement
+	require.NoError(tb, json.Unmarshal(payload, &st))
+	return st
+}
+
+func TestPAE(t *testing.T) {
+	require.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world", string(pae("http://example.com/HelloWorld", []byte("hello world"))))
+}
+
+func mustECDSA(tb testing.TB) crypto.Signer {
+	tb.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	return key
+}
+
+func mustEd25519(tb testing.TB) crypto.Signer {
+	tb.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(tb, err)
+	return key
+}
+
+func sha256hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
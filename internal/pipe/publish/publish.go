@@ -13,17 +13,22 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/blob"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/brew"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/cask"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/changelog"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/chocolatey"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/custompublishers"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/deploy"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/docker"
 	dockerv2 "github.com/goreleaser/goreleaser/v2/internal/pipe/docker/v2"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/dockerdigest"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/helm"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/iru"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/ko"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/krew"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/mcp"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/milestone"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/nix"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/ociartifact"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/ociimage"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/scoop"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/sign"
@@ -53,8 +58,11 @@ func New() Pipe {
 			docker.Pipe{},
 			docker.ManifestPipe{},
 			dockerv2.Publish{},
+			ociimage.Pipe{},
 			dockerdigest.Pipe{},
 			ko.Pipe{},
+			ociartifact.Pipe{},
+			helm.Pipe{},
 			sign.DockerPipe{},
 			snapcraft.Pipe{},
 			// This should be one of the last steps
@@ -69,8 +77,12 @@ func New() Pipe {
 			krew.Pipe{},
 			scoop.Pipe{},
 			chocolatey.Pipe{},
+			// deployments use the image digests, and should happen once
+			// everything else is published
+			deploy.Pipe{},
 			mcp.New(),
 			milestone.Pipe{},
+			changelog.KeepAChangelogPipe{},
 			custompublishers.Pipe{},
 		},
 	}
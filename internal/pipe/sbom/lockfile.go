@@ -0,0 +1,248 @@
+package sbom
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/cargo"
+	"github.com/goreleaser/goreleaser/v2/internal/packagejson"
+	"github.com/goreleaser/goreleaser/v2/internal/pyproject"
+	"github.com/goreleaser/goreleaser/v2/internal/sbom"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// lockfiles are the lock files supported by each builder, in order of
+// preference.
+var lockfiles = map[string][]string{
+	"rust":   {"Cargo.lock"},
+	"bun":    {"bun.lock", "package-lock.json"},
+	"deno":   {"package-lock.json", "bun.lock"},
+	"node":   {"package-lock.json", "bun.lock"},
+	"poetry": {"poetry.lock"},
+	"uv":     {"uv.lock"},
+}
+
+// catalogLockfiles creates a SBOM for each build ID, based on the lock file
+// of its project.
+func catalogLockfiles(ctx *context.Context, cfg config.SBOM) error {
+	filters := []artifact.Filter{
+		artifact.ByTypes(
+			artifact.Binary,
+			artifact.UniversalBinary,
+			artifact.PyWheel,
+			artifact.PySdist,
+		),
+		func(a *artifact.Artifact) bool {
+			_, ok := lockfiles[artifact.ExtraOr(*a, artifact.ExtraBuilder, "")]
+			return ok
+		},
+	}
+	if len(cfg.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cfg.IDs...))
+	}
+	groups := ctx.Artifacts.Filter(artifact.And(filters...)).GroupByID()
+	if len(groups) == 0 {
+		log.Warn("no artifacts matching current filters")
+	}
+	for _, id := range slices.Sorted(maps.Keys(groups)) {
+		sboms, err := catalogBuild(ctx, cfg, id, groups[id])
+		if err != nil {
+			return err
+		}
+		for _, a := range sboms {
+			ctx.Artifacts.Add(a)
+		}
+	}
+	return nil
+}
+
+func catalogBuild(ctx *context.Context, cfg config.SBOM, id string, arts []*artifact.Artifact) ([]*artifact.Artifact, error) {
+	idx := slices.IndexFunc(ctx.Config.Builds, func(b config.Build) bool {
+		return b.ID == id
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("cataloging artifacts: build %q not found", id)
+	}
+	build := ctx.Config.Builds[idx]
+	builder := artifact.ExtraOr(*arts[0], artifact.ExtraBuilder, "")
+
+	lock := findLockfile(build.Dir, lockfiles[builder]...)
+	if lock == "" {
+		return nil, fmt.Errorf(
+			"cataloging artifacts: build %q: could not find any of %s",
+			id, strings.Join(lockfiles[builder], ", "),
+		)
+	}
+	log.WithField("build", id).
+		WithField("lockfile", lock).
+		Info("cataloging")
+
+	name, components, err := lockfileComponents(builder, build.Dir, lock)
+	if err != nil {
+		return nil, fmt.Errorf("cataloging artifacts: %s: %w", lock, err)
+	}
+	if name == "" {
+		name = id
+	}
+
+	doc := sbom.Document{
+		Subject: sbom.Component{
+			Ref:     "build:" + id,
+			Type:    sbom.TypeApplication,
+			Name:    name,
+			Version: ctx.Version,
+		},
+		Date: ctx.Date,
+	}
+	var binaries []string
+	for _, a := range arts {
+		sum, err := sha256sum(a.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cataloging artifacts: %w", err)
+		}
+		file := sbom.Component{
+			Ref:    "file:" + a.Name,
+			Type:   sbom.TypeFile,
+			Name:   a.Name,
+			SHA256: sum,
+		}
+		doc.Subject.Contains = append(doc.Subject.Contains, file.Ref)
+		doc.Add(file)
+		binaries = append(binaries, a.Path)
+	}
+	for _, c := range components {
+		doc.Subject.DependsOn = append(doc.Subject.DependsOn, c.Ref)
+	}
+	doc.Add(components...)
+
+	_, _, paths, err := applyTemplate(ctx, cfg, arts[0])
+	if err != nil {
+		return nil, fmt.Errorf("cataloging artifacts failed: %w", err)
+	}
+	path := paths[0]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ctx.Config.Dist, path)
+	}
+	if err := writeDocument(doc, cfg.Format, path); err != nil {
+		return nil, fmt.Errorf("cataloging artifacts: %w", err)
+	}
+	return []*artifact.Artifact{{
+		Type: artifact.SBOM,
+		Name: filepath.Base(path),
+		Path: path,
+		Extra: map[string]any{
+			artifact.ExtraID:     cfg.ID,
+			artifact.ExtraSBOMOf: binaries,
+		},
+	}}, nil
+}
+
+// findLockfile looks for any of the given lock files in the given directory
+// and its parents, as it might be in the root of a workspace.
+func findLockfile(dir string, names ...string) string {
+	if dir == "" {
+		dir = "."
+	}
+	for {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if dir == "." || parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// lockfileComponents returns the project name and its locked dependencies.
+//
+// Development dependencies are ignored, as they are not shipped.
+func lockfileComponents(builder, dir, lock string) (string, []sbom.Component, error) {
+	var name string
+	var components []sbom.Component
+	switch builder {
+	case "rust":
+		if c, err := cargo.Open(filepath.Join(dir, "Cargo.toml")); err == nil {
+			name = c.Package.Name
+		}
+		l, err := cargo.OpenLock(lock)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, p := range l.Packages {
+			// local packages (the project itself, and workspace members)
+			// have no source.
+			if p.Source == "" {
+				continue
+			}
+			c := sbom.Library(sbom.PURLCargo, p.Name, p.Version)
+			c.SHA256 = p.Checksum
+			components = append(components, c)
+		}
+	case "bun", "deno", "node":
+		if p, err := packagejson.Open(filepath.Join(dir, "package.json")); err == nil {
+			name = p.Name
+		}
+		l, err := packagejson.OpenLock(lock)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, p := range l.Packages {
+			if p.Dev {
+				continue
+			}
+			c := sbom.Library(sbom.PURLNPM, p.Name, p.Version)
+			c.SHA512 = integrityToHex(p.Integrity, "sha512-")
+			components = append(components, c)
+		}
+	case "poetry", "uv":
+		if p, err := pyproject.Open(filepath.Join(dir, "pyproject.toml")); err == nil {
+			name = p.Project.Name
+		}
+		l, err := pyproject.OpenLock(lock)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, p := range l.RuntimePackages() {
+			if p.IsLocal() {
+				continue
+			}
+			c := sbom.Library(sbom.PURLPyPI, p.Name, p.Version)
+			if sum, ok := strings.CutPrefix(p.Sdist.Hash, "sha256:"); ok {
+				c.SHA256 = sum
+			}
+			components = append(components, c)
+		}
+	default:
+		return "", nil, errors.New("unsupported builder: " + builder)
+	}
+	return name, components, nil
+}
+
+// integrityToHex converts a Subresource Integrity string into a hex encoded
+// digest, if it uses the given algorithm prefix.
+func integrityToHex(integrity, prefix string) string {
+	b64, ok := strings.CutPrefix(integrity, prefix)
+	if !ok {
+		return ""
+	}
+	bts, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(bts)
+}
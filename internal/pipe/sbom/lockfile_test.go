@@ -0,0 +1,196 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfileDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		SBOMs: []config.SBOM{{Backend: "lockfile"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	cfg := ctx.Config.SBOMs[0]
+	require.Empty(t, cfg.Cmd)
+	require.Equal(t, "cyclonedx", cfg.Format)
+	require.Equal(t, "binary", cfg.Artifacts)
+	require.Equal(t, []string{"{{ .ProjectName }}_{{ .Version }}_${artifactID}.sbom.json"}, cfg.Documents)
+	require.Empty(t, Pipe{}.Dependencies(ctx))
+
+	ctx = testctx.WrapWithCfg(t.Context(), config.Project{
+		SBOMs: []config.SBOM{{Backend: "lockfile", Artifacts: "archive"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `backend "lockfile" only supports artifacts=binary, got "archive"`)
+}
+
+type cdxTestDocument struct {
+	Metadata struct {
+		Component struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"component"`
+	} `json:"metadata"`
+	Components []struct {
+		BOMRef string `json:"bom-ref"`
+		Type   string `json:"type"`
+		Name   string `json:"name"`
+		PURL   string `json:"purl"`
+		Hashes []struct {
+			Alg     string `json:"alg"`
+			Content string `json:"content"`
+		} `json:"hashes"`
+	} `json:"components"`
+}
+
+func TestLockfileCatalog(t *testing.T) {
+	setup := func(tb testing.TB, builder, dir string, types ...artifact.Type) (*artifact.Artifacts, cdxTestDocument) {
+		tb.Helper()
+		dist := tb.TempDir()
+		ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+			ProjectName: "proj",
+			Dist:        dist,
+			Builds: []config.Build{
+				{ID: "go", Builder: "go"},
+				{ID: "bin", Builder: builder, Dir: dir},
+			},
+			SBOMs: []config.SBOM{{Backend: "lockfile"}},
+		}, testctx.WithVersion("1.0.0"))
+
+		for _, id := range []string{"go", "bin"} {
+			for i, typ := range types {
+				path := filepath.Join(dist, id+string(rune('a'+i)))
+				require.NoError(tb, os.WriteFile(path, []byte(id), 0o755))
+				ctx.Artifacts.Add(&artifact.Artifact{
+					Type: typ,
+					Name: filepath.Base(path),
+					Path: path,
+					Extra: map[string]any{
+						artifact.ExtraID:      id,
+						artifact.ExtraBuilder: map[string]string{"go": "go", "bin": builder}[id],
+					},
+				})
+			}
+		}
+
+		require.NoError(tb, Pipe{}.Default(ctx))
+		require.NoError(tb, Pipe{}.Run(ctx))
+
+		sboms := ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+		require.Len(tb, sboms, 1)
+		require.Equal(tb, "proj_1.0.0_bin.sbom.json", sboms[0].Name)
+		require.Equal(tb, "default", sboms[0].ID())
+
+		var doc cdxTestDocument
+		bts, err := os.ReadFile(sboms[0].Path)
+		require.NoError(tb, err)
+		require.NoError(tb, json.Unmarshal(bts, &doc))
+		require.Equal(tb, "1.0.0", doc.Metadata.Component.Version)
+		return ctx.Artifacts, doc
+	}
+
+	t.Run("cargo", func(t *testing.T) {
+		artifacts, doc := setup(t, "rust", "testdata/lockfile/rust/crates/foo", artifact.Binary, artifact.Binary)
+		require.Equal(t, "some-name", doc.Metadata.Component.Name)
+		require.Len(t, doc.Components, 3)
+		require.Equal(t, "file", doc.Components[0].Type)
+		require.Equal(t, "bina", doc.Components[0].Name)
+		require.Equal(t, "file:bina", doc.Components[0].BOMRef)
+		require.Equal(t, "binb", doc.Components[1].Name)
+		require.Equal(t, "pkg:cargo/libc@0.2.172", doc.Components[2].PURL)
+		require.Equal(t, "d750af042f7ef4f724306de029d18836c26c1765a54a6a3f094cbd23a7267ffa", doc.Components[2].Hashes[0].Content)
+
+		sbom := artifacts.Filter(artifact.ByType(artifact.SBOM)).List()[0]
+		require.Len(t, artifact.MustExtra[[]string](*sbom, artifact.ExtraSBOMOf), 2)
+	})
+
+	t.Run("npm", func(t *testing.T) {
+		_, doc := setup(t, "node", "testdata/lockfile/node", artifact.Binary)
+		require.Equal(t, "example", doc.Metadata.Component.Name)
+		var purls []string
+		for _, c := range doc.Components[1:] {
+			purls = append(purls, c.PURL)
+		}
+		require.Equal(t, []string{
+			"pkg:npm/%40scope/foo@0.9.0",
+			"pkg:npm/%40scope/foo@1.0.1",
+			"pkg:npm/bar@2.0.0",
+		}, purls)
+		require.Empty(t, doc.Components[1].Hashes)
+		require.Equal(t, "SHA-512", doc.Components[2].Hashes[0].Alg)
+		require.Len(t, doc.Components[2].Hashes[0].Content, 128)
+	})
+
+	t.Run("uv", func(t *testing.T) {
+		_, doc := setup(t, "uv", "testdata/lockfile/uv", artifact.PyWheel, artifact.PySdist)
+		require.Equal(t, "python-test", doc.Metadata.Component.Name)
+		require.Len(t, doc.Components, 3)
+		require.Equal(t, "pkg:pypi/requests@2.32.3", doc.Components[2].PURL)
+		require.Equal(t, "55365417734eb18255590a9ff9eb97e9e1da868d4ccd6402399eaf68af20a760", doc.Components[2].Hashes[0].Content)
+	})
+}
+
+func TestLockfileCatalogErrors(t *testing.T) {
+	t.Run("no lockfile", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist:   t.TempDir(),
+			Builds: []config.Build{{ID: "bin", Builder: "poetry", Dir: t.TempDir()}},
+			SBOMs:  []config.SBOM{{Backend: "lockfile"}},
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.PyWheel,
+			Extra: map[string]any{
+				artifact.ExtraID:      "bin",
+				artifact.ExtraBuilder: "poetry",
+			},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.EqualError(t, Pipe{}.Run(ctx), `cataloging artifacts: build "bin": could not find any of poetry.lock`)
+	})
+
+	t.Run("no build", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			SBOMs: []config.SBOM{{Backend: "lockfile"}},
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.Binary,
+			Extra: map[string]any{
+				artifact.ExtraID:      "bin",
+				artifact.ExtraBuilder: "rust",
+			},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.EqualError(t, Pipe{}.Run(ctx), `cataloging artifacts: build "bin" not found`)
+	})
+
+	t.Run("invalid lockfile", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Cargo.lock"), []byte("nope"), 0o644))
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist:   t.TempDir(),
+			Builds: []config.Build{{ID: "bin", Builder: "rust", Dir: dir}},
+			SBOMs:  []config.SBOM{{Backend: "lockfile"}},
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.Binary,
+			Extra: map[string]any{
+				artifact.ExtraID:      "bin",
+				artifact.ExtraBuilder: "rust",
+			},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.ErrorContains(t, Pipe{}.Run(ctx), "Cargo.lock")
+	})
+}
+
+func TestFindLockfile(t *testing.T) {
+	require.Equal(t, "testdata/lockfile/rust/Cargo.lock", findLockfile("testdata/lockfile/rust/crates/foo", "Cargo.lock"))
+	require.Equal(t, "testdata/lockfile/node/package-lock.json", findLockfile("testdata/lockfile/node", "bun.lock", "package-lock.json"))
+	require.Empty(t, findLockfile("testdata/lockfile/node", "nope.lock"))
+}
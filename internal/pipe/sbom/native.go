@@ -0,0 +1,174 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"debug/buildinfo"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/sbom"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// Available backends.
+const (
+	backendCmd      = "cmd"
+	backendGo       = "go"
+	backendLockfile = "lockfile"
+)
+
+// catalogNative generates the SBOM of the given artifact in-process, from the
+// build info embedded in Go binaries.
+func catalogNative(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact, path string) ([]*artifact.Artifact, error) {
+	var doc sbom.Document
+	var err error
+	switch a.Type {
+	case artifact.UploadableArchive:
+		doc, err = archiveDocument(ctx, a)
+	default:
+		doc, err = binaryDocument(a)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cataloging artifacts: %w", err)
+	}
+	if doc.Subject.Ref == "" {
+		log.WithField("artifact", a.Path).Warn("no go build info found, skipping")
+		return nil, nil
+	}
+	doc.Date = ctx.Date
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ctx.Config.Dist, path)
+	}
+	log.WithField("backend", cfg.Backend).
+		WithField("sbom", filepath.Base(path)).
+		Info("cataloging")
+	if err := writeDocument(doc, cfg.Format, path); err != nil {
+		return nil, fmt.Errorf("cataloging artifacts: %w", err)
+	}
+
+	return []*artifact.Artifact{{
+		Type: artifact.SBOM,
+		Name: filepath.Base(path),
+		Path: path,
+		Extra: map[string]any{
+			artifact.ExtraID:     cfg.ID,
+			artifact.ExtraSBOMOf: []string{a.Path},
+		},
+	}}, nil
+}
+
+// binaryDocument creates a SBOM document for a single Go binary.
+//
+// If the binary has no Go build info, an empty document is returned.
+func binaryDocument(a *artifact.Artifact) (sbom.Document, error) {
+	app, modules, err := binaryComponents(a)
+	if err != nil || app.Ref == "" {
+		return sbom.Document{}, err
+	}
+	return sbom.Document{
+		Subject:    app,
+		Components: modules,
+	}, nil
+}
+
+// archiveDocument creates a SBOM document for an archive, aggregating all
+// the Go binaries inside it.
+func archiveDocument(ctx *context.Context, a *artifact.Artifact) (sbom.Document, error) {
+	sum, err := sha256sum(a.Path)
+	if err != nil {
+		return sbom.Document{}, err
+	}
+	doc := sbom.Document{
+		Subject: sbom.Component{
+			Ref:    "file:" + a.Name,
+			Type:   sbom.TypeFile,
+			Name:   a.Name,
+			SHA256: sum,
+		},
+	}
+	for _, bin := range archiveBinaries(ctx, a) {
+		app, modules, err := binaryComponents(bin)
+		if err != nil {
+			return sbom.Document{}, err
+		}
+		if app.Ref == "" {
+			log.WithField("binary", bin.Path).Warn("no go build info found, skipping")
+			continue
+		}
+		doc.Subject.Contains = append(doc.Subject.Contains, app.Ref)
+		doc.Add(app)
+		doc.Add(modules...)
+	}
+	return doc, nil
+}
+
+// archiveBinaries returns the binaries that were added to the given archive.
+func archiveBinaries(ctx *context.Context, a *artifact.Artifact) []*artifact.Artifact {
+	names := artifact.ExtraOr(*a, artifact.ExtraBinaries, []string{})
+	var result []*artifact.Artifact
+	for _, bin := range ctx.Artifacts.Filter(artifact.And(
+		artifact.ByTypes(artifact.Binary, artifact.UniversalBinary),
+		func(bin *artifact.Artifact) bool {
+			return bin.Target == a.Target && slices.Contains(names, bin.Name)
+		},
+	)).List() {
+		if slices.ContainsFunc(result, func(b *artifact.Artifact) bool {
+			return b.Name == bin.Name
+		}) {
+			continue
+		}
+		result = append(result, bin)
+	}
+	return result
+}
+
+// binaryComponents returns the application and module components of the
+// given binary.
+//
+// If the binary has no Go build info, empty values are returned.
+func binaryComponents(a *artifact.Artifact) (sbom.Component, []sbom.Component, error) {
+	info, err := buildinfo.ReadFile(a.Path)
+	if err != nil {
+		log.WithError(err).WithField("binary", a.Path).Debug("could not read build info")
+		return sbom.Component{}, nil, nil
+	}
+	sum, err := sha256sum(a.Path)
+	if err != nil {
+		return sbom.Component{}, nil, err
+	}
+	app, modules := sbom.FromBuildInfo(a.Name, sum, info)
+	return app, modules, nil
+}
+
+func writeDocument(doc sbom.Document, format, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := doc.Write(f, format); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func sha256sum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,190 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNativeDefault(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			SBOMs: []config.SBOM{{Backend: "go"}},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		cfg := ctx.Config.SBOMs[0]
+		require.Empty(t, cfg.Cmd)
+		require.Empty(t, cfg.Args)
+		require.Equal(t, "spdx", cfg.Format)
+		require.Equal(t, "archive", cfg.Artifacts)
+		require.Equal(t, []string{"{{ .ArtifactName }}.sbom.json"}, cfg.Documents)
+		require.Empty(t, Pipe{}.Dependencies(ctx))
+	})
+
+	t.Run("invalid artifacts", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			SBOMs: []config.SBOM{{Backend: "go", Artifacts: "source"}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), `backend "go" only supports artifacts=archive or artifacts=binary, got "source"`)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			SBOMs: []config.SBOM{{Backend: "go", Format: "xml"}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "invalid sbom format: xml")
+	})
+
+	t.Run("invalid backend", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			SBOMs: []config.SBOM{{Backend: "nope"}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "invalid sbom backend: nope")
+	})
+}
+
+func TestNativeCatalog(t *testing.T) {
+	// the test binary itself has go build info embedded.
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	setup := func(tb testing.TB, sboms ...config.SBOM) (string, *artifact.Artifacts) {
+		tb.Helper()
+		dist := tb.TempDir()
+		ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+			Dist:  dist,
+			SBOMs: sboms,
+		}, testctx.WithVersion("1.0.0"))
+
+		bin := filepath.Join(dist, "foo")
+		bts, err := os.ReadFile(exe)
+		require.NoError(tb, err)
+		require.NoError(tb, os.WriteFile(bin, bts, 0o755))
+		notgo := filepath.Join(dist, "bar")
+		require.NoError(tb, os.WriteFile(notgo, []byte("#!/bin/sh"), 0o755))
+		archive := filepath.Join(dist, "foo_linux_amd64.tar.gz")
+		require.NoError(tb, os.WriteFile(archive, []byte("fake"), 0o644))
+
+		for _, name := range []string{"foo", "bar"} {
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Type:   artifact.Binary,
+				Name:   name,
+				Path:   filepath.Join(dist, name),
+				Goos:   "linux",
+				Goarch: "amd64",
+				Target: "linux_amd64_v1",
+				Extra: map[string]any{
+					artifact.ExtraID:     "foo",
+					artifact.ExtraBinary: name,
+				},
+			})
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.UploadableArchive,
+			Name:   "foo_linux_amd64.tar.gz",
+			Path:   archive,
+			Goos:   "linux",
+			Goarch: "amd64",
+			Target: "linux_amd64_v1",
+			Extra: map[string]any{
+				artifact.ExtraID:       "foo",
+				artifact.ExtraBinaries: []string{"foo", "bar"},
+			},
+		})
+
+		require.NoError(tb, Pipe{}.Default(ctx))
+		require.NoError(tb, Pipe{}.Run(ctx))
+		return dist, ctx.Artifacts
+	}
+
+	t.Run("binary cyclonedx", func(t *testing.T) {
+		dist, artifacts := setup(t, config.SBOM{
+			Backend:   "go",
+			Artifacts: "binary",
+			Format:    "cyclonedx",
+		})
+
+		sboms := artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+		require.Len(t, sboms, 1)
+		require.Equal(t, "foo_1.0.0_linux_amd64.sbom.json", sboms[0].Name)
+
+		var doc struct {
+			BOMFormat   string `json:"bomFormat"`
+			SpecVersion string `json:"specVersion"`
+			Metadata    struct {
+				Component struct {
+					Name       string `json:"name"`
+					Properties []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"properties"`
+				} `json:"component"`
+			} `json:"metadata"`
+			Components []struct {
+				PURL string `json:"purl"`
+			} `json:"components"`
+		}
+		bts, err := os.ReadFile(filepath.Join(dist, sboms[0].Name))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(bts, &doc))
+		require.Equal(t, "CycloneDX", doc.BOMFormat)
+		require.Equal(t, "1.5", doc.SpecVersion)
+		require.Equal(t, "foo", doc.Metadata.Component.Name)
+		require.NotEmpty(t, doc.Metadata.Component.Properties)
+		require.Equal(t, "go:version", doc.Metadata.Component.Properties[0].Name)
+		require.NotEmpty(t, doc.Components)
+		require.Contains(t, doc.Components[0].PURL, "pkg:golang/github.com/goreleaser/goreleaser/v2")
+	})
+
+	t.Run("archive spdx", func(t *testing.T) {
+		dist, artifacts := setup(t, config.SBOM{Backend: "go"})
+
+		sboms := artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+		require.Len(t, sboms, 1)
+		require.Equal(t, "foo_linux_amd64.tar.gz.sbom.json", sboms[0].Name)
+		require.Equal(t, []string{filepath.Join(dist, "foo_linux_amd64.tar.gz")}, artifact.MustExtra[[]string](*sboms[0], artifact.ExtraSBOMOf))
+
+		var doc struct {
+			SPDXVersion string `json:"spdxVersion"`
+			Packages    []struct {
+				Name    string `json:"name"`
+				Purpose string `json:"primaryPackagePurpose"`
+			} `json:"packages"`
+			Relationships []struct {
+				Element string `json:"spdxElementId"`
+				Type    string `json:"relationshipType"`
+				Related string `json:"relatedSpdxElement"`
+			} `json:"relationships"`
+		}
+		bts, err := os.ReadFile(filepath.Join(dist, sboms[0].Name))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(bts, &doc))
+		require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+		require.Greater(t, len(doc.Packages), 2)
+		require.Equal(t, "foo_linux_amd64.tar.gz", doc.Packages[0].Name)
+		require.Equal(t, "FILE", doc.Packages[0].Purpose)
+		require.Equal(t, "foo", doc.Packages[1].Name)
+		require.Equal(t, "APPLICATION", doc.Packages[1].Purpose)
+		require.Equal(t, "DESCRIBES", doc.Relationships[0].Type)
+		require.Equal(t, "CONTAINS", doc.Relationships[1].Type)
+		require.Equal(t, doc.Relationships[0].Related, doc.Relationships[1].Element)
+	})
+
+	t.Run("no build info", func(t *testing.T) {
+		_, artifacts := setup(t, config.SBOM{
+			Backend:   "go",
+			Artifacts: "binary",
+			IDs:       []string{"foo"},
+			Documents: []string{"{{ .Binary }}.sbom.json"},
+		})
+		sboms := artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+		require.Len(t, sboms, 1)
+		require.Equal(t, "foo.sbom.json", sboms[0].Name)
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/logext"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
 	"github.com/goreleaser/goreleaser/v2/internal/redact"
+	"github.com/goreleaser/goreleaser/v2/internal/sbom"
 	"github.com/goreleaser/goreleaser/v2/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/v2/internal/skips"
 	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
@@ -40,6 +41,9 @@ func (Pipe) Skip(ctx *context.Context) bool {
 func (Pipe) Dependencies(ctx *context.Context) []string {
 	var cmds []string
 	for _, s := range ctx.Config.SBOMs {
+		if s.Backend == backendGo || s.Backend == backendLockfile {
+			continue
+		}
 		cmds = append(cmds, s.Cmd)
 	}
 	return cmds
@@ -59,8 +63,42 @@ func (Pipe) Default(ctx *context.Context) error {
 }
 
 func setConfigDefaults(cfg *config.SBOM) error {
-	if cfg.Cmd == "" {
-		cfg.Cmd = "syft"
+	if cfg.Backend == "" {
+		cfg.Backend = backendCmd
+	}
+	switch cfg.Backend {
+	case backendCmd:
+		if cfg.Cmd == "" {
+			cfg.Cmd = "syft"
+		}
+	case backendGo:
+		if cfg.Artifacts == "" {
+			cfg.Artifacts = "archive"
+		}
+		if cfg.Artifacts != "archive" && cfg.Artifacts != "binary" {
+			return fmt.Errorf("backend %q only supports artifacts=archive or artifacts=binary, got %q", cfg.Backend, cfg.Artifacts)
+		}
+		if cfg.Format == "" {
+			cfg.Format = sbom.FormatSPDX
+		}
+	case backendLockfile:
+		if cfg.Artifacts == "" {
+			cfg.Artifacts = "binary"
+		}
+		if cfg.Artifacts != "binary" {
+			return fmt.Errorf("backend %q only supports artifacts=binary, got %q", cfg.Backend, cfg.Artifacts)
+		}
+		if len(cfg.Documents) == 0 {
+			cfg.Documents = []string{"{{ .ProjectName }}_{{ .Version }}_${artifactID}.sbom.json"}
+		}
+		if cfg.Format == "" {
+			cfg.Format = sbom.FormatCycloneDX
+		}
+	default:
+		return fmt.Errorf("invalid sbom backend: %s", cfg.Backend)
+	}
+	if cfg.Backend != backendCmd && cfg.Format != sbom.FormatSPDX && cfg.Format != sbom.FormatCycloneDX {
+		return fmt.Errorf("invalid sbom format: %s", cfg.Format)
 	}
 	if cfg.Artifacts == "" {
 		cfg.Artifacts = "archive"
@@ -111,6 +149,9 @@ func catalogTask(ctx *context.Context, cfg config.SBOM) error {
 	if disabled {
 		return pipe.Skip("configuration is disabled")
 	}
+	if cfg.Backend == backendLockfile {
+		return catalogLockfiles(ctx, cfg)
+	}
 	var filters []artifact.Filter
 	switch cfg.Artifacts {
 	case "source":
@@ -191,6 +232,10 @@ func catalogArtifact(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact
 		artifactDisplayName = a.Path
 	}
 
+	if cfg.Backend == backendGo {
+		return catalogNative(ctx, cfg, a, paths[0])
+	}
+
 	var names []string
 	for _, p := range paths {
 		names = append(names, filepath.Base(p))
@@ -247,14 +292,18 @@ func catalogArtifact(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact
 			return nil, fmt.Errorf("cataloging artifacts: failed to find SBOM artifact %q: %w", path, err)
 		}
 		for _, match := range matches {
-			artifacts = append(artifacts, &artifact.Artifact{
+			sbom := &artifact.Artifact{
 				Type: artifact.SBOM,
 				Name: filepath.Base(match),
 				Path: match,
 				Extra: map[string]any{
 					artifact.ExtraID: cfg.ID,
 				},
-			})
+			}
+			if a != nil {
+				sbom.Extra[artifact.ExtraSBOMOf] = []string{a.Path}
+			}
+			artifacts = append(artifacts, sbom)
 		}
 	}
 
@@ -678,9 +678,16 @@ func testSBOMCataloging(
 
 	require.NoError(tb, Pipe{}.Run(ctx))
 
-	// ensure all artifacts have an ID
+	// ensure all artifacts have an ID, and point to what they catalog
 	for _, arti := range ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List() {
 		require.NotEmptyf(tb, arti.ID(), ".Extra.ID on %s", arti.Path)
+		of := artifact.ExtraOr[[]string](*arti, artifact.ExtraSBOMOf, nil)
+		if len(ctx.Config.SBOMs) == 1 && ctx.Config.SBOMs[0].Artifacts != "any" {
+			require.Lenf(tb, of, 1, ".Extra.SBOMOf on %s", arti.Path)
+		}
+		for _, path := range of {
+			require.FileExists(tb, path)
+		}
 	}
 
 	// verify that only the artifacts and the sboms are in the dist dir
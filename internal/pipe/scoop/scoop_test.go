@@ -575,6 +575,47 @@ func Test_doRun(t *testing.T) {
 			shouldErr("release is prerelease"),
 			noAssertions,
 		},
+		{
+			"release channel not included",
+			args{
+				testctx.WrapWithCfg(t.Context(),
+					config.Project{
+						ProjectName: "run-pipe",
+						Scoops: []config.Scoop{
+							{
+								Channels: []string{"stable"},
+								Repository: config.RepoRef{
+									Owner: "test",
+									Name:  "test",
+								},
+								Description: "A run pipe test formula",
+								Homepage:    "https://github.com/goreleaser",
+							},
+						},
+					},
+					testctx.GitHubTokenType,
+					testctx.WithCurrentTag("v1.0.1-beta.1"),
+					testctx.WithVersion("1.0.1-beta.1"),
+					testctx.WithSemver(1, 0, 1, "beta.1")),
+
+				client.NewMock(),
+			},
+			[]artifact.Artifact{
+				{
+					Name:    "foo_1.0.1-beta.1_windows_amd64.tar.gz",
+					Goos:    "windows",
+					Goarch:  "amd64",
+					Goamd64: "v1",
+					Path:    file,
+					Extra: map[string]any{
+						artifact.ExtraBinaries: []string{"foo"},
+					},
+				},
+			},
+			shouldNotErr,
+			shouldErr(`release channel "beta" is not in [stable]`),
+			noAssertions,
+		},
 		{
 			"skip upload set to true",
 			args{
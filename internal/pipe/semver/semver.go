@@ -6,6 +6,7 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/calver"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
 	"github.com/goreleaser/goreleaser/v2/internal/skips"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
@@ -21,6 +22,17 @@ func (Pipe) String() string {
 
 // Run executes the hooks.
 func (Pipe) Run(ctx *context.Context) error {
+	switch ctx.Config.Versioning.Mode {
+	case "", "semver":
+		return runSemver(ctx)
+	case "calver":
+		return runCalVer(ctx)
+	default:
+		return fmt.Errorf("invalid versioning mode: %q", ctx.Config.Versioning.Mode)
+	}
+}
+
+func runSemver(ctx *context.Context) error {
 	sv, err := semver.NewVersion(ctx.Git.CurrentTag)
 	if err != nil {
 		if skips.Any(ctx, skips.Validate) {
@@ -39,3 +51,42 @@ func (Pipe) Run(ctx *context.Context) error {
 	}
 	return nil
 }
+
+func runCalVer(ctx *context.Context) error {
+	scheme, err := calver.ParseScheme(ctx.Config.Versioning.Scheme)
+	if err != nil {
+		return err
+	}
+	cv, err := scheme.Parse(ctx.Git.CurrentTag)
+	if err != nil {
+		if skips.Any(ctx, skips.Validate) {
+			log.WithError(err).
+				WithField("tag", ctx.Git.CurrentTag).
+				Warn("current tag is not calver")
+			return pipe.ErrSkipValidateEnabled
+		}
+		return fmt.Errorf("failed to parse tag '%s' as calver: %w", ctx.Git.CurrentTag, err)
+	}
+	ctx.CalVer = context.CalVer{
+		Year:     cv.Year,
+		Month:    cv.Month,
+		Week:     cv.Week,
+		Day:      cv.Day,
+		Major:    cv.Major,
+		Minor:    cv.Minor,
+		Micro:    cv.Micro,
+		Modifier: cv.Modifier,
+	}
+
+	// the first 3 segments are also set as the semver, so things like
+	// `.Major`, `.RawVersion`, and the prerelease detection keep working.
+	segments := make([]uint64, 3)
+	copy(segments, cv.Segments())
+	ctx.Semver = context.Semver{
+		Major:      segments[0],
+		Minor:      segments[1],
+		Patch:      segments[2],
+		Prerelease: cv.Modifier,
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/skips"
 	"github.com/goreleaser/goreleaser/v2/internal/testctx"
 	"github.com/goreleaser/goreleaser/v2/internal/testlib"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
 	"github.com/stretchr/testify/require"
 )
@@ -44,3 +45,61 @@ func TestInvalidSemverSkipValidate(t *testing.T) {
 		Prerelease: "",
 	}, ctx.Semver)
 }
+
+func TestValidCalVer(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{Mode: "calver"},
+	}, testctx.WithCurrentTag("v2024.01.3-rc1"))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, context.CalVer{
+		Year:     2024,
+		Month:    1,
+		Micro:    3,
+		Modifier: "rc1",
+	}, ctx.CalVer)
+	require.Equal(t, context.Semver{
+		Major:      2024,
+		Minor:      1,
+		Patch:      3,
+		Prerelease: "rc1",
+	}, ctx.Semver)
+}
+
+func TestValidCalVerShortScheme(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{Mode: "calver", Scheme: "YY.0W"},
+	}, testctx.WithCurrentTag("24.07"))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, context.CalVer{Year: 2024, Week: 7}, ctx.CalVer)
+	require.Equal(t, context.Semver{Major: 2024, Minor: 7}, ctx.Semver)
+}
+
+func TestInvalidCalVer(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{Mode: "calver"},
+	}, testctx.WithCurrentTag("v1.5.2"))
+	err := Pipe{}.Run(ctx)
+	require.ErrorContains(t, err, "failed to parse tag 'v1.5.2' as calver")
+}
+
+func TestInvalidCalVerSkipValidate(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{Mode: "calver"},
+	}, testctx.WithCurrentTag("v1.5.2"), testctx.Skip(skips.Validate))
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+	require.Equal(t, context.CalVer{}, ctx.CalVer)
+}
+
+func TestInvalidCalVerScheme(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{Mode: "calver", Scheme: "YYYY.FOO"},
+	}, testctx.WithCurrentTag("v2024.1"))
+	require.ErrorContains(t, Pipe{}.Run(ctx), `invalid calver scheme "YYYY.FOO"`)
+}
+
+func TestInvalidVersioningMode(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Versioning: config.Versioning{Mode: "nope"},
+	}, testctx.WithCurrentTag("v1.2.3"))
+	require.EqualError(t, Pipe{}.Run(ctx), `invalid versioning mode: "nope"`)
+}
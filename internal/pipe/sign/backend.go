@@ -0,0 +1,277 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/goreleaser/goreleaser/v2/internal/keyfile"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+// Available signing backends.
+// Everything but [backendCmd] signs in-process, without external binaries.
+const (
+	backendCmd      = "cmd"
+	backendOpenPGP  = "openpgp"
+	backendMinisign = "minisign"
+	backendSSH      = "ssh"
+)
+
+const defaultSSHNamespace = "file"
+
+// signer signs artifacts in-process.
+type signer interface {
+	// sign writes the signature of the given file into w.
+	sign(w io.Writer, path, name string) error
+}
+
+// isInProcess returns true if the given backend signs in-process.
+func isInProcess(backend string) bool {
+	return backend != "" && backend != backendCmd
+}
+
+// newSigner creates a signer for the given backend.
+//
+// The key can be either a path to a file or the key contents, which allows
+// to pass it directly from an environment variable.
+func newSigner(backend, key, password, namespace string, date time.Time) (signer, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%s: key is required", backend)
+	}
+	bts, err := keyfile.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not read key: %w", backend, err)
+	}
+
+	var s signer
+	switch backend {
+	case backendOpenPGP:
+		s, err = newOpenPGPSigner(bts, password)
+	case backendMinisign:
+		s, err = newMinisignSigner(bts, password, date)
+	case backendSSH:
+		s, err = newSSHSigner(bts, password, namespace)
+	default:
+		return nil, fmt.Errorf("invalid signing backend: %s", backend)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", backend, err)
+	}
+	return s, nil
+}
+
+type openpgpSigner struct {
+	entity *openpgp.Entity
+}
+
+func newOpenPGPSigner(key []byte, password string) (openpgpSigner, error) {
+	entity, err := keyfile.OpenPGP(key, password)
+	if err != nil {
+		return openpgpSigner{}, err
+	}
+	return openpgpSigner{entity: entity}, nil
+}
+
+func (s openpgpSigner) sign(w io.Writer, path, _ string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return openpgp.ArmoredDetachSign(w, s.entity, f, nil)
+}
+
+// minisignSigner creates minisign signatures.
+//
+// See https://jedisct1.github.io/minisign/ for the formats.
+type minisignSigner struct {
+	keyID [8]byte
+	key   ed25519.PrivateKey
+	date  time.Time
+}
+
+const minisignKeyLen = 2 + 2 + 2 + 32 + 8 + 8 + 104
+
+func newMinisignSigner(key []byte, password string, date time.Time) (minisignSigner, error) {
+	lines := strings.Split(strings.TrimSpace(string(key)), "\n")
+	if len(lines) < 2 {
+		return minisignSigner{}, errors.New("invalid secret key")
+	}
+	bin, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(bin) != minisignKeyLen {
+		return minisignSigner{}, errors.New("invalid secret key")
+	}
+
+	sigAlg, kdfAlg, cksumAlg := bin[0:2], bin[2:4], bin[4:6]
+	salt := bin[6:38]
+	opsLimit := binary.LittleEndian.Uint64(bin[38:46])
+	memLimit := binary.LittleEndian.Uint64(bin[46:54])
+	keynum := bytes.Clone(bin[54:])
+
+	if string(sigAlg) != "Ed" || string(cksumAlg) != "B2" {
+		return minisignSigner{}, errors.New("unsupported secret key algorithm")
+	}
+	switch string(kdfAlg) {
+	case "Sc":
+		logN, r, p := scryptParams(opsLimit, memLimit)
+		stream, err := scrypt.Key([]byte(password), salt, 1<<logN, r, p, len(keynum))
+		if err != nil {
+			return minisignSigner{}, fmt.Errorf("could not decrypt key: %w", err)
+		}
+		subtle.XORBytes(keynum, keynum, stream)
+	case "\x00\x00":
+	default:
+		return minisignSigner{}, errors.New("unsupported secret key algorithm")
+	}
+
+	s := minisignSigner{date: date}
+	copy(s.keyID[:], keynum[0:8])
+	s.key = ed25519.PrivateKey(keynum[8:72])
+	sum := blake2b.Sum256(bytes.Join([][]byte{sigAlg, keynum[0:8], keynum[8:72]}, nil))
+	if subtle.ConstantTimeCompare(sum[:], keynum[72:104]) != 1 {
+		return minisignSigner{}, errors.New("could not decrypt key: wrong password")
+	}
+	return s, nil
+}
+
+// scryptParams picks the scrypt parameters the same way libsodium does.
+func scryptParams(opsLimit, memLimit uint64) (logN uint, r, p int) {
+	opsLimit = max(opsLimit, 32768)
+	r = 8
+	var maxN uint64
+	if opsLimit < memLimit/32 {
+		p = 1
+		maxN = opsLimit / uint64(r*4)
+	} else {
+		maxN = memLimit / uint64(r*128)
+	}
+	for logN = 1; logN < 63; logN++ {
+		if uint64(1)<<logN > maxN/2 {
+			break
+		}
+	}
+	if opsLimit >= memLimit/32 {
+		maxrp := min((opsLimit/4)/(uint64(1)<<logN), 0x3fffffff)
+		p = int(maxrp) / r
+	}
+	return logN, r, p
+}
+
+func (s minisignSigner) sign(w io.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h, _ := blake2b.New512(nil)
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	sig := ed25519.Sign(s.key, h.Sum(nil))
+	trusted := fmt.Sprintf("timestamp:%d\tfile:%s\thashed", s.date.Unix(), name)
+	global := ed25519.Sign(s.key, append(bytes.Clone(sig), trusted...))
+
+	_, err = fmt.Fprintf(
+		w,
+		"untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(bytes.Join([][]byte{[]byte("ED"), s.keyID[:], sig}, nil)),
+		trusted,
+		base64.StdEncoding.EncodeToString(global),
+	)
+	return err
+}
+
+// sshSigner creates signatures compatible with 'ssh-keygen -Y sign'.
+//
+// See https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig.
+type sshSigner struct {
+	signer    ssh.Signer
+	namespace string
+}
+
+func newSSHSigner(key []byte, password, namespace string) (sshSigner, error) {
+	var signer ssh.Signer
+	var err error
+	if password != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(password))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return sshSigner{}, fmt.Errorf("could not read key: %w", err)
+	}
+	if namespace == "" {
+		namespace = defaultSSHNamespace
+	}
+	return sshSigner{signer: signer, namespace: namespace}, nil
+}
+
+const (
+	sshSigMagic   = "SSHSIG"
+	sshSigHashAlg = "sha512"
+)
+
+func (s sshSigner) sign(w io.Writer, path, _ string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha512.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	signed := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Hash      []byte
+	}{s.namespace, "", sshSigHashAlg, h.Sum(nil)})...)
+
+	var sig *ssh.Signature
+	if as, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return fmt.Errorf("could not sign: %w", err)
+	}
+
+	blob := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Signature []byte
+	}{1, s.signer.PublicKey().Marshal(), s.namespace, "", sshSigHashAlg, ssh.Marshal(sig)})...)
+
+	var b strings.Builder
+	b.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	enc := base64.StdEncoding.EncodeToString(blob)
+	for len(enc) > 70 {
+		b.WriteString(enc[:70] + "\n")
+		enc = enc[70:]
+	}
+	b.WriteString(enc + "\n")
+	b.WriteString("-----END SSH SIGNATURE-----\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
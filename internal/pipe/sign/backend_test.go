@@ -0,0 +1,388 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/jedisct1/go-minisign"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+const testPassword = "s3cr3t"
+
+func TestSignBackends(t *testing.T) {
+	for name, tt := range map[string]struct {
+		backend  string
+		key      func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte))
+		password string
+		sig      string
+	}{
+		"openpgp": {
+			backend: backendOpenPGP,
+			key:     openPGPKey(""),
+			sig:     "checksums.txt.asc",
+		},
+		"openpgp with password": {
+			backend:  backendOpenPGP,
+			key:      openPGPKey(testPassword),
+			password: testPassword,
+			sig:      "checksums.txt.asc",
+		},
+		"minisign": {
+			backend: backendMinisign,
+			key:     minisignKey(""),
+			sig:     "checksums.txt.minisig",
+		},
+		"minisign with password": {
+			backend:  backendMinisign,
+			key:      minisignKey(testPassword),
+			password: testPassword,
+			sig:      "checksums.txt.minisig",
+		},
+		"ssh": {
+			backend: backendSSH,
+			key:     sshKey(""),
+			sig:     "checksums.txt.sig",
+		},
+		"ssh with password": {
+			backend:  backendSSH,
+			key:      sshKey(testPassword),
+			password: testPassword,
+			sig:      "checksums.txt.sig",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dist := t.TempDir()
+			key, verify := tt.key(t, t.TempDir())
+			ctx := backendContext(t, dist, config.Sign{
+				Artifacts: "checksum",
+				Backend:   tt.backend,
+				Key:       key,
+				Password:  "{{ .Env.SIGN_PASSWORD }}",
+			})
+			ctx.Env["SIGN_PASSWORD"] = tt.password
+
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.Empty(t, ctx.Config.Signs[0].Cmd)
+			require.Empty(t, Pipe{}.Dependencies(ctx))
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			sigs := ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List()
+			require.Len(t, sigs, 1)
+			require.Equal(t, tt.sig, sigs[0].Name)
+			require.Equal(t, filepath.Join(dist, tt.sig), sigs[0].Path)
+
+			content, err := os.ReadFile(filepath.Join(dist, "checksums.txt"))
+			require.NoError(t, err)
+			sig, err := os.ReadFile(sigs[0].Path)
+			require.NoError(t, err)
+			verify(t, content, sig)
+		})
+	}
+}
+
+func TestSignBackendKeyFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	path, verify := sshKey("")(t, dir)
+	bts, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	dist := t.TempDir()
+	ctx := backendContext(t, dist, config.Sign{
+		Artifacts: "checksum",
+		Backend:   backendSSH,
+		Key:       "{{ .Env.SSH_SIGNING_KEY }}",
+	})
+	ctx.Env["SSH_SIGNING_KEY"] = string(bts)
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	content, err := os.ReadFile(filepath.Join(dist, "checksums.txt"))
+	require.NoError(t, err)
+	sig, err := os.ReadFile(filepath.Join(dist, "checksums.txt.sig"))
+	require.NoError(t, err)
+	verify(t, content, sig)
+}
+
+func TestSignBinaryBackend(t *testing.T) {
+	dist := t.TempDir()
+	key, verify := minisignKey("")(t, t.TempDir())
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "bin"), []byte("binary"), 0o755))
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		Dist: dist,
+		BinarySigns: []config.BinarySign{{
+			Backend: backendMinisign,
+			Key:     key,
+		}},
+	}, testctx.WithDate(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bin",
+		Path:   filepath.Join(dist, "bin"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+	})
+
+	require.NoError(t, BinaryPipe{}.Default(ctx))
+	require.Empty(t, BinaryPipe{}.Dependencies(ctx))
+	require.NoError(t, BinaryPipe{}.Run(ctx))
+
+	sig, err := os.ReadFile(filepath.Join(dist, "bin_linux_amd64"))
+	require.NoError(t, err)
+	verify(t, []byte("binary"), sig)
+}
+
+func TestSignBackendErrors(t *testing.T) {
+	t.Run("no key", func(t *testing.T) {
+		ctx := backendContext(t, t.TempDir(), config.Sign{
+			Artifacts: "checksum",
+			Backend:   backendSSH,
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.EqualError(t, Pipe{}.Run(ctx), "sign failed: ssh: key is required")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		ctx := backendContext(t, t.TempDir(), config.Sign{
+			Artifacts: "checksum",
+			Backend:   backendOpenPGP,
+			Key:       filepath.Join(t.TempDir(), "nope.asc"),
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.ErrorIs(t, Pipe{}.Run(ctx), os.ErrNotExist)
+	})
+
+	t.Run("invalid backend", func(t *testing.T) {
+		ctx := backendContext(t, t.TempDir(), config.Sign{
+			Artifacts: "checksum",
+			Backend:   "nope",
+			Key:       "-----BEGIN FOO-----",
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.EqualError(t, Pipe{}.Run(ctx), "sign failed: invalid signing backend: nope")
+	})
+
+	for name, tt := range map[string]struct {
+		backend string
+		key     func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte))
+		err     string
+	}{
+		"openpgp":  {backendOpenPGP, openPGPKey(testPassword), "could not decrypt key"},
+		"minisign": {backendMinisign, minisignKey(testPassword), "could not decrypt key: wrong password"},
+		"ssh":      {backendSSH, sshKey(testPassword), "could not read key"},
+	} {
+		t.Run(name+" wrong password", func(t *testing.T) {
+			key, _ := tt.key(t, t.TempDir())
+			ctx := backendContext(t, t.TempDir(), config.Sign{
+				Artifacts: "checksum",
+				Backend:   tt.backend,
+				Key:       key,
+				Password:  "wrong",
+			})
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.ErrorContains(t, Pipe{}.Run(ctx), tt.err)
+		})
+	}
+
+	t.Run("docker", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			DockerSigns: []config.Sign{{Backend: backendSSH}},
+		})
+		require.EqualError(t, DockerPipe{}.Default(ctx), `docker_signs: backend "ssh" is not supported`)
+	})
+}
+
+func TestScryptParams(t *testing.T) {
+	// minisign's defaults, the values were taken from libsodium.
+	logN, r, p := scryptParams(33554432, 1073741824)
+	require.Equal(t, uint(20), logN)
+	require.Equal(t, 8, r)
+	require.Equal(t, 1, p)
+
+	logN, r, p = scryptParams(1, 1)
+	require.Equal(t, uint(1), logN)
+	require.Equal(t, 8, r)
+	require.Equal(t, 512, p)
+}
+
+func backendContext(tb testing.TB, dist string, cfg config.Sign) *context.Context {
+	tb.Helper()
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "checksums.txt"), []byte("deadbeef  foo.tar.gz\n"), 0o644))
+	ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+		Dist:  dist,
+		Signs: []config.Sign{cfg},
+	}, testctx.WithDate(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "checksums.txt",
+		Path: filepath.Join(dist, "checksums.txt"),
+		Type: artifact.Checksum,
+	})
+	return ctx
+}
+
+func openPGPKey(password string) func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte)) {
+	return func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte)) {
+		tb.Helper()
+		entity, err := openpgp.NewEntity("goreleaser", "test", "test@goreleaser.com", nil)
+		require.NoError(tb, err)
+
+		var b bytes.Buffer
+		w, err := armor.Encode(&b, openpgp.PrivateKeyType, nil)
+		require.NoError(tb, err)
+		if password != "" {
+			require.NoError(tb, entity.EncryptPrivateKeys([]byte(password), nil))
+			require.NoError(tb, entity.SerializePrivateWithoutSigning(w, nil))
+		} else {
+			require.NoError(tb, entity.SerializePrivate(w, nil))
+		}
+		require.NoError(tb, w.Close())
+
+		path := filepath.Join(dir, "key.asc")
+		require.NoError(tb, os.WriteFile(path, b.Bytes(), 0o600))
+		return path, func(tb testing.TB, artifact, signature []byte) {
+			tb.Helper()
+			require.True(tb, bytes.HasPrefix(signature, []byte("-----BEGIN PGP SIGNATURE-----")))
+			signer, err := openpgp.CheckArmoredDetachedSignature(
+				openpgp.EntityList{entity},
+				bytes.NewReader(artifact),
+				bytes.NewReader(signature),
+				nil,
+			)
+			require.NoError(tb, err)
+			require.Equal(tb, entity.PrimaryKey.KeyId, signer.PrimaryKey.KeyId)
+		}
+	}
+}
+
+func minisignKey(password string) func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte)) {
+	return func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte)) {
+		tb.Helper()
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(tb, err)
+		keyID := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+		// the lowest limits, so tests are fast.
+		const opsLimit, memLimit = 32768, 16777216
+		salt := make([]byte, 32)
+		_, err = rand.Read(salt)
+		require.NoError(tb, err)
+
+		sum := blake2b.Sum256(bytes.Join([][]byte{[]byte("Ed"), keyID[:], priv}, nil))
+		keynum := bytes.Join([][]byte{keyID[:], priv, sum[:]}, nil)
+		kdf := []byte{0, 0}
+		if password != "" {
+			kdf = []byte("Sc")
+			logN, r, p := scryptParams(opsLimit, memLimit)
+			stream, err := scrypt.Key([]byte(password), salt, 1<<logN, r, p, len(keynum))
+			require.NoError(tb, err)
+			for i := range keynum {
+				keynum[i] ^= stream[i]
+			}
+		}
+		limits := binary.LittleEndian.AppendUint64(nil, opsLimit)
+		limits = binary.LittleEndian.AppendUint64(limits, memLimit)
+		bin := bytes.Join([][]byte{[]byte("Ed"), kdf, []byte("B2"), salt, limits, keynum}, nil)
+
+		path := filepath.Join(dir, "minisign.key")
+		require.NoError(tb, os.WriteFile(path, []byte(
+			"untrusted comment: minisign encrypted secret key\n"+
+				base64.StdEncoding.EncodeToString(bin)+"\n",
+		), 0o600))
+
+		return path, func(tb testing.TB, artifact, signature []byte) {
+			tb.Helper()
+			pk := minisign.PublicKey{
+				SignatureAlgorithm: [2]byte{'E', 'd'},
+				KeyId:              keyID,
+			}
+			copy(pk.PublicKey[:], pub)
+			sig, err := minisign.DecodeSignature(string(signature))
+			require.NoError(tb, err)
+			require.True(tb, strings.HasPrefix(sig.TrustedComment, "trusted comment: timestamp:1735787045\tfile:"))
+			require.True(tb, strings.HasSuffix(sig.TrustedComment, "\thashed"))
+			ok, err := pk.Verify(artifact, sig)
+			require.NoError(tb, err)
+			require.True(tb, ok)
+		}
+	}
+}
+
+func sshKey(password string) func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte)) {
+	return func(tb testing.TB, dir string) (string, func(tb testing.TB, artifact, signature []byte)) {
+		tb.Helper()
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(tb, err)
+
+		var block *pem.Block
+		if password != "" {
+			block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "test", []byte(password))
+		} else {
+			block, err = ssh.MarshalPrivateKey(priv, "test")
+		}
+		require.NoError(tb, err)
+
+		path := filepath.Join(dir, "id_ed25519")
+		require.NoError(tb, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+
+		signer, err := ssh.NewSignerFromKey(priv)
+		require.NoError(tb, err)
+		return path, func(tb testing.TB, artifact, signature []byte) {
+			tb.Helper()
+			verifySSHSignature(tb, signer.PublicKey(), artifact, signature)
+		}
+	}
+}
+
+func verifySSHSignature(tb testing.TB, pub ssh.PublicKey, artifact, signature []byte) {
+	tb.Helper()
+	block, _ := pem.Decode(signature)
+	require.NotNil(tb, block)
+	require.Equal(tb, "SSH SIGNATURE", block.Type)
+
+	blob, ok := bytes.CutPrefix(block.Bytes, []byte(sshSigMagic))
+	require.True(tb, ok)
+	var sig struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Signature []byte
+	}
+	require.NoError(tb, ssh.Unmarshal(blob, &sig))
+	require.Equal(tb, uint32(1), sig.Version)
+	require.Equal(tb, pub.Marshal(), sig.PublicKey)
+	require.Equal(tb, defaultSSHNamespace, sig.Namespace)
+	require.Equal(tb, sshSigHashAlg, sig.HashAlg)
+
+	var s ssh.Signature
+	require.NoError(tb, ssh.Unmarshal(sig.Signature, &s))
+
+	h := sha512.Sum512(artifact)
+	signed := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Hash      []byte
+	}{sig.Namespace, "", sig.HashAlg, h[:]})...)
+	require.NoError(tb, pub.Verify(signed, &s))
+}
@@ -40,6 +40,9 @@ func (Pipe) Skip(ctx *context.Context) bool {
 func (Pipe) Dependencies(ctx *context.Context) []string {
 	var cmds []string
 	for _, s := range ctx.Config.Signs {
+		if isInProcess(s.Backend) {
+			continue
+		}
 		cmds = append(cmds, s.Cmd)
 	}
 	return cmds
@@ -61,14 +64,24 @@ func (Pipe) Default(ctx *context.Context) error {
 	ids := ids.New("signs")
 	for i := range ctx.Config.Signs {
 		cfg := &ctx.Config.Signs[i]
-		if cfg.Cmd == "" {
+		if cfg.Backend == "" {
+			cfg.Backend = backendCmd
+		}
+		if cfg.Cmd == "" && cfg.Backend == backendCmd {
 			// gpgPath is either "gpg" (default) or the user's git config gpg.program value
 			cfg.Cmd = gpgPath()
 		}
 		if cfg.Signature == "" {
-			cfg.Signature = "${artifact}.sig"
+			switch cfg.Backend {
+			case backendOpenPGP:
+				cfg.Signature = "${artifact}.asc"
+			case backendMinisign:
+				cfg.Signature = "${artifact}.minisig"
+			default:
+				cfg.Signature = "${artifact}.sig"
+			}
 		}
-		if len(cfg.Args) == 0 {
+		if len(cfg.Args) == 0 && cfg.Backend == backendCmd {
 			cfg.Args = []string{"--output", "$signature", "--detach-sig", "$artifact"}
 		}
 		if cfg.Artifacts == "" {
@@ -144,11 +157,19 @@ func sign(ctx *context.Context, cfg config.Sign, artifacts []*artifact.Artifact)
 		log.Warn("no artifacts matching the given filters found")
 		return nil
 	}
+	var s signer
+	if isInProcess(cfg.Backend) {
+		var err error
+		s, err = configSigner(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("sign failed: %w", err)
+		}
+	}
 	for _, a := range artifacts {
 		if err := a.Refresh(); err != nil {
 			return err
 		}
-		artifacts, err := signone(ctx, cfg, a)
+		artifacts, err := signone(ctx, cfg, s, a)
 		if err != nil {
 			return err
 		}
@@ -159,6 +180,22 @@ func sign(ctx *context.Context, cfg config.Sign, artifacts []*artifact.Artifact)
 	return nil
 }
 
+// configSigner creates the in-process signer for the given config.
+func configSigner(ctx *context.Context, cfg config.Sign) (signer, error) {
+	tmplEnv, err := templateEnvS(ctx, cfg.Env)
+	if err != nil {
+		return nil, err
+	}
+	env := ctx.Env.Copy()
+	maps.Copy(env, context.ToEnv(tmplEnv))
+
+	key, password, namespace := cfg.Key, cfg.Password, cfg.Namespace
+	if err := tmpl.New(ctx).WithEnv(env).ApplyAll(&key, &password, &namespace); err != nil {
+		return nil, err
+	}
+	return newSigner(cfg.Backend, key, password, namespace, ctx.Date)
+}
+
 func relativeToDist(dist, f string) (string, error) {
 	af, err := filepath.Abs(f)
 	if err != nil {
@@ -182,7 +219,7 @@ func tmplPath(ctx *context.Context, env map[string]string, a *artifact.Artifact,
 	return relativeToDist(ctx.Config.Dist, result)
 }
 
-func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*artifact.Artifact, error) {
+func signone(ctx *context.Context, cfg config.Sign, s signer, art *artifact.Artifact) ([]*artifact.Artifact, error) {
 	env := ctx.Env.Copy()
 	env["artifactName"] = art.Name // shouldn't be used
 	env["artifact"] = art.Path
@@ -208,12 +245,82 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 	}
 	env["certificate"] = cert
 
+	log := log.WithField("artifact", art.Name)
+	if name != "" {
+		log = log.WithField("signature", name)
+	}
+	if cert != "" {
+		log = log.WithField("certificate", cert)
+	}
+
+	if s != nil {
+		log.WithField("backend", cfg.Backend).Info("signing")
+		if err := signInProcess(s, art, name); err != nil {
+			return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
+		}
+	} else if err := signWithCmd(ctx, cfg, env, art, log); err != nil {
+		return nil, err
+	}
+
+	var result []*artifact.Artifact
+
+	// re-execute template results, using artifact desc as artifact so they eval to the actual needed file desc.
+	env["artifact"] = art.Name
+	name, err = tmpl.New(ctx).WithArtifact(art).WithEnv(env).Apply(expand(cfg.Signature, env))
+	if err != nil {
+		return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
+	}
+	cert, err = tmpl.New(ctx).WithArtifact(art).WithEnv(env).Apply(expand(cfg.Certificate, env))
+	if err != nil {
+		return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
+	}
+
+	if cfg.Signature != "" {
+		result = append(result, &artifact.Artifact{
+			Type: artifact.Signature,
+			Name: name,
+			Path: env["signature"],
+			Extra: map[string]any{
+				artifact.ExtraID:          cfg.ID,
+				artifact.ExtraSignatureOf: art.Name,
+			},
+		})
+	}
+
+	if cert != "" {
+		result = append(result, &artifact.Artifact{
+			Type: artifact.Certificate,
+			Name: cert,
+			Path: env["certificate"],
+			Extra: map[string]any{
+				artifact.ExtraID:          cfg.ID,
+				artifact.ExtraSignatureOf: art.Name,
+			},
+		})
+	}
+
+	return result, nil
+}
+
+func signInProcess(s signer, art *artifact.Artifact, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := s.sign(f, art.Path, art.Name); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func signWithCmd(ctx *context.Context, cfg config.Sign, env context.Env, art *artifact.Artifact, log *log.Entry) error {
 	//nolint:prealloc
 	var args []string
 	for _, a := range cfg.Args {
 		arg, err := tmpl.New(ctx).WithEnv(env).Apply(expand(a, env))
 		if err != nil {
-			return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
+			return fmt.Errorf("sign failed: %s: %w", art.Name, err)
 		}
 		args = append(args, arg)
 	}
@@ -222,30 +329,24 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 	if cfg.Stdin != nil {
 		s, err := tmpl.New(ctx).WithEnv(env).Apply(expand(*cfg.Stdin, env))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		stdin = strings.NewReader(s)
 	} else if cfg.StdinFile != "" {
 		f, err := os.Open(cfg.StdinFile)
 		if err != nil {
-			return nil, fmt.Errorf("sign failed: cannot open file %s: %w", cfg.StdinFile, err)
+			return fmt.Errorf("sign failed: cannot open file %s: %w", cfg.StdinFile, err)
 		}
 		defer f.Close()
 
 		stdin = f
 	}
 
-	log := log.WithField("cmd", cfg.Cmd).WithField("artifact", art.Name)
-	if name != "" {
-		log = log.WithField("signature", name)
-	}
-	if cert != "" {
-		log = log.WithField("certificate", cert)
-	}
+	log = log.WithField("cmd", cfg.Cmd)
 
 	output, err := tmpl.New(ctx).WithEnv(env).Bool(cfg.Output)
 	if err != nil {
-		return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
+		return fmt.Errorf("sign failed: %s: %w", art.Name, err)
 	}
 
 	// The GoASTScanner flags this as a security risk.
@@ -264,7 +365,7 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 	}
 	log.Info("signing")
 	if err := cmd.Run(); err != nil {
-		return nil, gerrors.Wrap(
+		return gerrors.Wrap(
 			err,
 			gerrors.WithMessage("could not sign artifact"),
 			gerrors.WithDetails(
@@ -274,43 +375,7 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 			gerrors.WithOutput(b.String()),
 		)
 	}
-
-	var result []*artifact.Artifact
-
-	// re-execute template results, using artifact desc as artifact so they eval to the actual needed file desc.
-	env["artifact"] = art.Name
-	name, err = tmpl.New(ctx).WithArtifact(art).WithEnv(env).Apply(expand(cfg.Signature, env))
-	if err != nil {
-		return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
-	}
-	cert, err = tmpl.New(ctx).WithArtifact(art).WithEnv(env).Apply(expand(cfg.Certificate, env))
-	if err != nil {
-		return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
-	}
-
-	if cfg.Signature != "" {
-		result = append(result, &artifact.Artifact{
-			Type: artifact.Signature,
-			Name: name,
-			Path: env["signature"],
-			Extra: map[string]any{
-				artifact.ExtraID: cfg.ID,
-			},
-		})
-	}
-
-	if cert != "" {
-		result = append(result, &artifact.Artifact{
-			Type: artifact.Certificate,
-			Name: cert,
-			Path: env["certificate"],
-			Extra: map[string]any{
-				artifact.ExtraID: cfg.ID,
-			},
-		})
-	}
-
-	return result, nil
+	return nil
 }
 
 func expand(s string, env map[string]string) string {
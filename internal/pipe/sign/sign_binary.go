@@ -27,6 +27,9 @@ func (BinaryPipe) Skip(ctx *context.Context) bool {
 func (BinaryPipe) Dependencies(ctx *context.Context) []string {
 	var cmds []string
 	for _, s := range ctx.Config.BinarySigns {
+		if isInProcess(s.Backend) {
+			continue
+		}
 		cmds = append(cmds, s.Cmd)
 	}
 	return cmds
@@ -42,14 +45,17 @@ func (BinaryPipe) Default(ctx *context.Context) error {
 	ids := ids.New("binary_signs")
 	for i := range ctx.Config.BinarySigns {
 		cfg := &ctx.Config.BinarySigns[i]
-		if cfg.Cmd == "" {
+		if cfg.Backend == "" {
+			cfg.Backend = backendCmd
+		}
+		if cfg.Cmd == "" && cfg.Backend == backendCmd {
 			// gpgPath is either "gpg" (default) or the user's git config gpg.program value
 			cfg.Cmd = gpgPath
 		}
 		if cfg.Signature == "" {
 			cfg.Signature = defaultSignatureName
 		}
-		if len(cfg.Args) == 0 {
+		if len(cfg.Args) == 0 && cfg.Backend == backendCmd {
 			cfg.Args = []string{"--output", "$signature", "--detach-sig", "$artifact"}
 		}
 		if cfg.Artifacts == "" {
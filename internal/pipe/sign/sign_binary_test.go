@@ -173,8 +173,8 @@ func TestBinarySign(t *testing.T) {
 		require.Equal(
 			t,
 			[]*artifact.Artifact{
-				{Name: "prefix_amd64_suffix", Path: "prefix_amd64_suffix", Type: 13, Extra: artifact.Extras{"ID": "default"}},
-				{Name: "prefix_arm64_suffix", Path: "prefix_arm64_suffix", Type: 13, Extra: artifact.Extras{"ID": "default"}},
+				{Name: "prefix_amd64_suffix", Path: "prefix_amd64_suffix", Type: 13, Extra: artifact.Extras{"ID": "default", "SignatureOf": "bin1"}},
+				{Name: "prefix_arm64_suffix", Path: "prefix_arm64_suffix", Type: 13, Extra: artifact.Extras{"ID": "default", "SignatureOf": "bin2"}},
 			},
 			sigs,
 		)
@@ -33,6 +33,9 @@ func (DockerPipe) Default(ctx *context.Context) error {
 	ids := ids.New("docker_signs")
 	for i := range ctx.Config.DockerSigns {
 		cfg := &ctx.Config.DockerSigns[i]
+		if isInProcess(cfg.Backend) {
+			return fmt.Errorf("docker_signs: backend %q is not supported", cfg.Backend)
+		}
 		if cfg.Cmd == "" {
 			cfg.Cmd = "cosign"
 		}
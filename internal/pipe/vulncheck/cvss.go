@@ -0,0 +1,90 @@
+package vulncheck
+
+import (
+	"math"
+	"strings"
+)
+
+// cvss3 calculates the base score of the given CVSS v3 vector.
+//
+// See https://www.first.org/cvss/v3.1/specification-document#7-4-Metric-Values.
+func cvss3(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) < 9 || !strings.HasPrefix(parts[0], "CVSS:3") {
+		return 0, false
+	}
+	metrics := map[string]string{}
+	for _, part := range parts[1:] {
+		k, v, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, false
+		}
+		metrics[k] = v
+	}
+
+	changed := metrics["S"] == "C"
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	if changed {
+		weights["PR"]["L"] = 0.68
+		weights["PR"]["H"] = 0.5
+	}
+	w := map[string]float64{}
+	for k, values := range weights {
+		v, ok := values[metrics[k]]
+		if !ok {
+			return 0, false
+		}
+		w[k] = v
+	}
+	if s := metrics["S"]; s != "U" && s != "C" {
+		return 0, false
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundup(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundup(math.Min(impact+exploitability, 10)), true
+}
+
+// roundup rounds up to one decimal place, as defined by the CVSS v3.1
+// specification.
+func roundup(f float64) float64 {
+	i := int(math.Round(f * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return (math.Floor(float64(i)/10000) + 1) / 10
+}
+
+// severityOf returns the qualitative severity of the given CVSS score.
+func severityOf(score float64) string {
+	switch {
+	case score >= 9:
+		return severityCritical
+	case score >= 7:
+		return severityHigh
+	case score >= 4:
+		return severityMedium
+	case score > 0:
+		return severityLow
+	default:
+		return severityNone
+	}
+}
@@ -0,0 +1,305 @@
+package vulncheck
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// advisory is an OSV advisory.
+type advisory struct {
+	ID               string     `json:"id"`
+	Aliases          []string   `json:"aliases"`
+	Summary          string     `json:"summary"`
+	Details          string     `json:"details"`
+	Withdrawn        string     `json:"withdrawn"`
+	Severity         []score    `json:"severity"`
+	Affected         []affected `json:"affected"`
+	DatabaseSpecific specific   `json:"database_specific"`
+	References       []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"references"`
+}
+
+type score struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type specific struct {
+	Severity string `json:"severity"`
+}
+
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string              `json:"type"`
+		Events []map[string]string `json:"events"`
+	} `json:"ranges"`
+	Versions          []string `json:"versions"`
+	EcosystemSpecific specific `json:"ecosystem_specific"`
+	DatabaseSpecific  specific `json:"database_specific"`
+}
+
+// url returns the URL with more information about the advisory, if any.
+func (a advisory) url() string {
+	for _, ref := range a.References {
+		if ref.Type == "ADVISORY" || ref.Type == "WEB" {
+			return ref.URL
+		}
+	}
+	return ""
+}
+
+// severity returns the severity of the advisory for the given affected
+// package.
+func (a advisory) severity(aff affected) string {
+	for _, s := range []string{
+		aff.EcosystemSpecific.Severity,
+		aff.DatabaseSpecific.Severity,
+		a.DatabaseSpecific.Severity,
+	} {
+		switch s := strings.ToLower(s); s {
+		case "moderate":
+			return severityMedium
+		case severityLow, severityMedium, severityHigh, severityCritical:
+			return s
+		}
+	}
+	for _, s := range a.Severity {
+		if !strings.HasPrefix(s.Type, "CVSS_V3") {
+			continue
+		}
+		if score, ok := cvss3(s.Score); ok {
+			return severityOf(score)
+		}
+	}
+	return severityUnknown
+}
+
+// db is an offline OSV database.
+type db struct {
+	size       int
+	advisories map[string][]*advisory
+}
+
+// loadDB loads all the advisories from the given directory, recursively.
+func loadDB(dir string) (*db, error) {
+	result := &db{advisories: map[string][]*advisory{}}
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var adv advisory
+		if err := json.Unmarshal(bts, &adv); err != nil {
+			return fmt.Errorf("invalid advisory: %s: %w", path, err)
+		}
+		if adv.Withdrawn != "" {
+			return nil
+		}
+		result.size++
+		var keys []string
+		for _, aff := range adv.Affected {
+			key := pkgKey(aff.Package.Ecosystem, aff.Package.Name)
+			if slices.Contains(keys, key) {
+				continue
+			}
+			keys = append(keys, key)
+			result.advisories[key] = append(result.advisories[key], &adv)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("could not load advisories: %w", err)
+	}
+	return result, nil
+}
+
+// check returns the vulnerabilities affecting the given packages.
+func (d *db) check(pkgs []pkg) []context.Vulnerability {
+	var result []context.Vulnerability
+	for _, p := range pkgs {
+		for _, adv := range d.advisories[pkgKey(p.ecosystem, p.name)] {
+			for _, aff := range adv.Affected {
+				if pkgKey(aff.Package.Ecosystem, aff.Package.Name) != pkgKey(p.ecosystem, p.name) {
+					continue
+				}
+				fixed, ok := aff.affects(p.version)
+				if !ok {
+					continue
+				}
+				result = append(result, context.Vulnerability{
+					ID:        adv.ID,
+					Aliases:   adv.Aliases,
+					Summary:   adv.Summary,
+					Severity:  adv.severity(aff),
+					Ecosystem: p.ecosystem,
+					Package:   p.name,
+					Version:   p.version,
+					Fixed:     fixed,
+					Artifacts: p.artifacts,
+				})
+				break
+			}
+		}
+	}
+	return result
+}
+
+func (d *db) advisory(id string) *advisory {
+	for _, advs := range d.advisories {
+		for _, adv := range advs {
+			if adv.ID == id {
+				return adv
+			}
+		}
+	}
+	return nil
+}
+
+// affects returns whether the given version is affected, and the version
+// that fixes it, if any.
+func (a affected) affects(version string) (string, bool) {
+	if slices.ContainsFunc(a.Versions, func(v string) bool {
+		return compareVersions(v, version) == 0
+	}) {
+		return "", true
+	}
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		events := slices.Clone(r.Events)
+		slices.SortStableFunc(events, func(a, b map[string]string) int {
+			return compareVersions(eventVersion(a), eventVersion(b))
+		})
+
+		var isAffected bool
+		var fixed string
+		for _, e := range events {
+			if v, ok := e["introduced"]; ok && (v == "0" || compareVersions(version, v) >= 0) {
+				isAffected = true
+			}
+			if v, ok := e["fixed"]; ok {
+				if compareVersions(version, v) >= 0 {
+					isAffected = false
+				} else if isAffected && fixed == "" {
+					fixed = v
+				}
+			}
+			if v, ok := e["last_affected"]; ok && compareVersions(version, v) > 0 {
+				isAffected = false
+			}
+		}
+		if isAffected {
+			return fixed, true
+		}
+	}
+	return "", false
+}
+
+func eventVersion(e map[string]string) string {
+	for _, k := range []string{"introduced", "fixed", "last_affected", "limit"} {
+		if v, ok := e[k]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// pkgKey returns the key of a package in the database.
+func pkgKey(ecosystem, name string) string {
+	if ecosystem == "PyPI" {
+		name = strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+	}
+	return ecosystem + "/" + name
+}
+
+// compareVersions compares two versions, using semver if possible, and
+// falling back to comparing each numeric and alphabetic part otherwise.
+func compareVersions(a, b string) int {
+	if a == "0" {
+		a = "0.0.0"
+	}
+	if b == "0" {
+		b = "0.0.0"
+	}
+	va, erra := semver.NewVersion(a)
+	vb, errb := semver.NewVersion(b)
+	if erra == nil && errb == nil {
+		return va.Compare(vb)
+	}
+
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range max(len(pa), len(pb)) {
+		if i >= len(pa) {
+			return -1
+		}
+		if i >= len(pb) {
+			return 1
+		}
+		na, erra := strconv.Atoi(pa[i])
+		nb, errb := strconv.Atoi(pb[i])
+		switch {
+		case erra == nil && errb == nil:
+			if na != nb {
+				return cmp.Compare(na, nb)
+			}
+		case erra == nil:
+			return 1
+		case errb == nil:
+			return -1
+		default:
+			if c := strings.Compare(pa[i], pb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// versionParts splits a version in its numeric and alphabetic parts.
+func versionParts(v string) []string {
+	var parts []string
+	var current strings.Builder
+	var digit bool
+	for _, r := range strings.TrimPrefix(v, "v") {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		if current.Len() > 0 && unicode.IsDigit(r) != digit {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		digit = unicode.IsDigit(r)
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
@@ -0,0 +1,198 @@
+package vulncheck
+
+import (
+	"cmp"
+	"debug/buildinfo"
+	"encoding/json"
+	"go/version"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/sbom"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// pkg is a package used by the release artifacts.
+type pkg struct {
+	ecosystem string
+	name      string
+	version   string
+	artifacts []string
+}
+
+// ecosystems maps package URL types to OSV ecosystems.
+var ecosystems = map[string]string{
+	sbom.PURLGolang: "Go",
+	sbom.PURLCargo:  "crates.io",
+	sbom.PURLNPM:    "npm",
+	sbom.PURLPyPI:   "PyPI",
+	"gem":           "RubyGems",
+	"nuget":         "NuGet",
+	"maven":         "Maven",
+	"composer":      "Packagist",
+}
+
+// collectPackages collects the packages from the SBOMs and from the build
+// info of the Go binaries.
+func collectPackages(ctx *context.Context) ([]pkg, error) {
+	var result []pkg
+	add := func(purl, artifactName string) {
+		ecosystem, name, version, ok := parsePURL(purl)
+		if !ok {
+			return
+		}
+		idx := slices.IndexFunc(result, func(p pkg) bool {
+			return p.ecosystem == ecosystem && p.name == name && p.version == version
+		})
+		if idx < 0 {
+			result = append(result, pkg{ecosystem: ecosystem, name: name, version: version})
+			idx = len(result) - 1
+		}
+		if !slices.Contains(result[idx].artifacts, artifactName) {
+			result[idx].artifacts = append(result[idx].artifacts, artifactName)
+		}
+	}
+
+	for _, a := range ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List() {
+		purls, err := sbomPURLs(a.Path)
+		if err != nil {
+			log.WithError(err).WithField("sbom", a.Name).Warn("could not read sbom, ignoring")
+			continue
+		}
+		for _, purl := range purls {
+			add(purl, a.Name)
+		}
+	}
+
+	for _, a := range ctx.Artifacts.Filter(artifact.And(
+		artifact.ByTypes(artifact.Binary, artifact.UniversalBinary),
+		func(a *artifact.Artifact) bool {
+			return artifact.ExtraOr(*a, artifact.ExtraBuilder, "") == "go"
+		},
+	)).List() {
+		info, err := buildinfo.ReadFile(a.Path)
+		if err != nil {
+			log.WithError(err).WithField("binary", a.Path).Debug("could not read build info")
+			continue
+		}
+		_, modules := sbom.FromBuildInfo(a.Name, "", info)
+		for _, m := range modules {
+			add(m.PURL, a.Name)
+		}
+		if v, ok := stdlibVersion(info.GoVersion); ok {
+			add(sbom.PURL(sbom.PURLGolang, "stdlib", v), a.Name)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b pkg) int {
+		return cmp.Or(
+			cmp.Compare(a.ecosystem, b.ecosystem),
+			cmp.Compare(a.name, b.name),
+			cmp.Compare(a.version, b.version),
+		)
+	})
+	return result, nil
+}
+
+// stdlibVersion converts a Go version (e.g. go1.22.1) into the version used
+// by the Go vulnerability database for the standard library.
+func stdlibVersion(goVersion string) (string, bool) {
+	goVersion, _, _ = strings.Cut(goVersion, " ")
+	if !version.IsValid(goVersion) {
+		return "", false
+	}
+	v := strings.TrimPrefix(goVersion, "go")
+	if version.Lang(goVersion) == goVersion {
+		// language versions (e.g. go1.22) are the first release.
+		v += ".0"
+	}
+	return v, true
+}
+
+type cdxComponent struct {
+	PURL       string         `json:"purl"`
+	Components []cdxComponent `json:"components"`
+}
+
+// sbomPURLs returns all the package URLs in the given CycloneDX or SPDX JSON
+// document.
+func sbomPURLs(path string) ([]string, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Components []cdxComponent `json:"components"`
+		Packages   []struct {
+			ExternalRefs []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(bts, &doc); err != nil {
+		return nil, err
+	}
+
+	var purls []string
+	var walk func(components []cdxComponent)
+	walk = func(components []cdxComponent) {
+		for _, c := range components {
+			if c.PURL != "" {
+				purls = append(purls, c.PURL)
+			}
+			walk(c.Components)
+		}
+	}
+	walk(doc.Components)
+	for _, p := range doc.Packages {
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				purls = append(purls, ref.ReferenceLocator)
+			}
+		}
+	}
+	return purls, nil
+}
+
+// parsePURL parses the given package URL, returning its OSV ecosystem, name,
+// and version.
+//
+// See https://github.com/package-url/purl-spec.
+func parsePURL(purl string) (string, string, string, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return "", "", "", false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	typ, rest, ok := strings.Cut(rest, "/")
+	if !ok {
+		return "", "", "", false
+	}
+	ecosystem, ok := ecosystems[strings.ToLower(typ)]
+	if !ok {
+		return "", "", "", false
+	}
+	idx := strings.LastIndex(rest, "@")
+	if idx < 0 {
+		return "", "", "", false
+	}
+	name, version := rest[:idx], rest[idx+1:]
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		return "", "", "", false
+	}
+	version, err = url.PathUnescape(version)
+	if err != nil || version == "" {
+		return "", "", "", false
+	}
+	if ecosystem == "Maven" {
+		name = strings.Replace(name, "/", ":", 1)
+	}
+	return ecosystem, name, version, true
+}
@@ -0,0 +1,175 @@
+package vulncheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+const (
+	reportJSON  = "vulncheck.json"
+	reportSARIF = "vulncheck.sarif"
+)
+
+type jsonReport struct {
+	Vulnerabilities []context.Vulnerability `json:"vulnerabilities"`
+}
+
+// SARIF 2.1.0 report.
+//
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver sarifDriver `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+	Properties       struct {
+		Tags []string `json:"tags,omitempty"`
+	} `json:"properties"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// writeReports writes the JSON and SARIF reports to the dist folder.
+func writeReports(ctx *context.Context, db *db, vulns []context.Vulnerability) error {
+	if vulns == nil {
+		vulns = []context.Vulnerability{}
+	}
+	if err := writeJSON(ctx, reportJSON, jsonReport{Vulnerabilities: vulns}); err != nil {
+		return err
+	}
+	return writeJSON(ctx, reportSARIF, sarif(db, vulns))
+}
+
+func sarif(db *db, vulns []context.Vulnerability) sarifReport {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver = sarifDriver{
+		Name:           "goreleaser",
+		InformationURI: "https://goreleaser.com",
+		Rules:          []sarifRule{},
+	}
+
+	seen := map[string]bool{}
+	for _, v := range vulns {
+		if !seen[v.ID] {
+			seen[v.ID] = true
+			rule := sarifRule{
+				ID:               v.ID,
+				ShortDescription: sarifMessage{Text: v.Summary},
+				FullDescription:  sarifMessage{Text: v.Summary},
+			}
+			if adv := db.advisory(v.ID); adv != nil {
+				if adv.Details != "" {
+					rule.FullDescription.Text = adv.Details
+				}
+				rule.HelpURI = adv.url()
+			}
+			if rule.ShortDescription.Text == "" {
+				rule.ShortDescription.Text = v.ID
+			}
+			if rule.FullDescription.Text == "" {
+				rule.FullDescription.Text = v.ID
+			}
+			rule.Properties.Tags = []string{"security", v.Severity}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		msg := fmt.Sprintf("%s@%s is affected by %s", v.Package, v.Version, v.ID)
+		if v.Fixed != "" {
+			msg += fmt.Sprintf(", fixed in %s", v.Fixed)
+		}
+		result := sarifResult{
+			RuleID:  v.ID,
+			Level:   sarifLevel(v.Severity),
+			Message: sarifMessage{Text: msg},
+		}
+		for _, name := range v.Artifacts {
+			var loc sarifLocation
+			loc.PhysicalLocation.ArtifactLocation.URI = name
+			result.Locations = append(result.Locations, loc)
+		}
+		if v.Allowed {
+			result.Suppressions = []sarifSuppression{{
+				Kind:          "external",
+				Justification: v.Reason,
+			}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return sarifReport{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case severityLow:
+		return "note"
+	case severityMedium:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+func writeJSON(ctx *context.Context, name string, v any) error {
+	bts, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.WithField("path", path).Debug("writing report")
+	if err := os.WriteFile(path, bts, 0o644); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package vulncheck checks the components of the release against an offline
+// OSV advisory database.
+//
+// See https://ossf.github.io/osv-schema/.
+package vulncheck
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/caarlos0/log"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/tmpl"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+)
+
+// Severities, from lowest to highest.
+const (
+	severityNone     = "none"
+	severityLow      = "low"
+	severityMedium   = "medium"
+	severityHigh     = "high"
+	severityCritical = "critical"
+	severityUnknown  = "unknown"
+)
+
+const expiresLayout = "2006-01-02"
+
+var errNoDB = errors.New("vulncheck: db is required")
+
+// Pipe that checks the release components for known vulnerabilities.
+type Pipe struct{}
+
+func (Pipe) String() string { return "checking for known vulnerabilities" }
+
+func (Pipe) Skip(ctx *context.Context) (bool, error) {
+	if skips.Any(ctx, skips.VulnCheck) {
+		return true, nil
+	}
+	enabled, err := tmpl.New(ctx).Bool(ctx.Config.VulnCheck.Enabled)
+	return !enabled, err
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	cfg := &ctx.Config.VulnCheck
+	if cfg.FailOn == "" {
+		cfg.FailOn = severityHigh
+	}
+	if cfg.FailOn == severityUnknown || (cfg.FailOn != severityNone && rank(cfg.FailOn) == 0) {
+		return fmt.Errorf("vulncheck: invalid fail_on: %s", cfg.FailOn)
+	}
+	for _, allow := range cfg.Allow {
+		if allow.ID == "" {
+			return errors.New("vulncheck: allow: id is required")
+		}
+		if allow.Expires == "" {
+			continue
+		}
+		if _, err := time.Parse(expiresLayout, allow.Expires); err != nil {
+			return fmt.Errorf("vulncheck: allow: %s: invalid expires date: %w", allow.ID, err)
+		}
+	}
+	return nil
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	cfg := ctx.Config.VulnCheck
+	dir, err := tmpl.New(ctx).Apply(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("vulncheck: db: %w", err)
+	}
+	if dir == "" {
+		return errNoDB
+	}
+
+	db, err := loadDB(dir)
+	if err != nil {
+		return fmt.Errorf("vulncheck: %w", err)
+	}
+	pkgs, err := collectPackages(ctx)
+	if err != nil {
+		return fmt.Errorf("vulncheck: %w", err)
+	}
+	log.WithField("advisories", db.size).
+		WithField("packages", len(pkgs)).
+		Info("checking")
+
+	vulns := db.check(pkgs)
+	for i := range vulns {
+		allowVuln(ctx, cfg, &vulns[i])
+	}
+	slices.SortFunc(vulns, func(a, b context.Vulnerability) int {
+		return cmp.Or(
+			cmp.Compare(rank(b.Severity), rank(a.Severity)),
+			cmp.Compare(a.ID, b.ID),
+			cmp.Compare(a.Package, b.Package),
+			cmp.Compare(a.Version, b.Version),
+		)
+	})
+	ctx.Vulnerabilities = vulns
+
+	if err := writeReports(ctx, db, vulns); err != nil {
+		return fmt.Errorf("vulncheck: %w", err)
+	}
+
+	var failed int
+	for _, v := range vulns {
+		log := log.WithField("id", v.ID).
+			WithField("package", v.Package+"@"+v.Version).
+			WithField("severity", v.Severity)
+		if v.Allowed {
+			log.WithField("reason", v.Reason).Info("allowed vulnerability")
+			continue
+		}
+		log.Warn("vulnerability found")
+		if cfg.FailOn != severityNone && rank(v.Severity) >= rank(cfg.FailOn) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("vulncheck: found %d vulnerabilities with %s or higher severity", failed, cfg.FailOn)
+	}
+	return nil
+}
+
+// allowVuln marks the given vulnerability as allowed if it is in the allow
+// list, and the entry is not expired.
+func allowVuln(ctx *context.Context, cfg config.VulnCheck, v *context.Vulnerability) {
+	for _, allow := range cfg.Allow {
+		if allow.ID != v.ID && !slices.Contains(v.Aliases, allow.ID) {
+			continue
+		}
+		if allow.Expires != "" {
+			expires, _ := time.Parse(expiresLayout, allow.Expires)
+			if !ctx.Date.Before(expires) {
+				log.WithField("id", allow.ID).
+					WithField("expires", allow.Expires).
+					Warn("allow list entry is expired")
+				continue
+			}
+		}
+		v.Allowed = true
+		v.Reason = allow.Reason
+		return
+	}
+}
+
+// rank returns the rank of the given severity.
+//
+// Advisories without a severity are ranked as high, as we can't know better.
+func rank(severity string) int {
+	switch severity {
+	case severityLow:
+		return 1
+	case severityMedium:
+		return 2
+	case severityHigh, severityUnknown:
+		return 3
+	case severityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,415 @@
+package vulncheck
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/skips"
+	"github.com/goreleaser/goreleaser/v2/internal/testctx"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/goreleaser/goreleaser/v2/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		ctx := testctx.Wrap(t.Context())
+		skip, err := Pipe{}.Skip(ctx)
+		require.NoError(t, err)
+		require.True(t, skip)
+	})
+
+	t.Run("skip flag", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			VulnCheck: config.VulnCheck{Enabled: "true"},
+		}, testctx.Skip(skips.VulnCheck))
+		skip, err := Pipe{}.Skip(ctx)
+		require.NoError(t, err)
+		require.True(t, skip)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			VulnCheck: config.VulnCheck{Enabled: "{{ .Env.VULNCHECK }}"},
+		}, testctx.WithEnv(map[string]string{"VULNCHECK": "true"}))
+		skip, err := Pipe{}.Skip(ctx)
+		require.NoError(t, err)
+		require.False(t, skip)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			VulnCheck: config.VulnCheck{Enabled: "{{ .Nope }}"},
+		})
+		_, err := Pipe{}.Skip(ctx)
+		require.Error(t, err)
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+		VulnCheck: config.VulnCheck{Allow: []config.VulnCheckAllow{{ID: "GO-2024-0001", Expires: "2024-12-31"}}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "high", ctx.Config.VulnCheck.FailOn)
+
+	for name, tt := range map[string]struct {
+		cfg config.VulnCheck
+		err string
+	}{
+		"invalid fail_on": {
+			cfg: config.VulnCheck{FailOn: "unknown"},
+			err: "vulncheck: invalid fail_on: unknown",
+		},
+		"allow without id": {
+			cfg: config.VulnCheck{Allow: []config.VulnCheckAllow{{Reason: "foo"}}},
+			err: "vulncheck: allow: id is required",
+		},
+		"allow with invalid date": {
+			cfg: config.VulnCheck{Allow: []config.VulnCheckAllow{{ID: "GO-2024-0001", Expires: "tomorrow"}}},
+			err: "vulncheck: allow: GO-2024-0001: invalid expires date",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := testctx.WrapWithCfg(t.Context(), config.Project{VulnCheck: tt.cfg})
+			require.ErrorContains(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	setup := func(tb testing.TB, cfg config.VulnCheck) *context.Context {
+		tb.Helper()
+		dist := tb.TempDir()
+		cfg.Enabled = "true"
+		if cfg.DB == "" {
+			cfg.DB = "testdata/osv"
+		}
+		ctx := testctx.WrapWithCfg(tb.Context(), config.Project{
+			Dist:      dist,
+			VulnCheck: cfg,
+		}, testctx.WithDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+
+		path := filepath.Join(dist, "proj.sbom.json")
+		require.NoError(tb, os.WriteFile(path, []byte(`{
+			"bomFormat": "CycloneDX",
+			"components": [
+				{"purl": "pkg:golang/example.com/foo@v1.1.0"},
+				{"purl": "pkg:npm/%40scope/bar@2.0.1", "components": [
+					{"purl": "pkg:npm/baz@1.0.0"}
+				]},
+				{"name": "no-purl"}
+			]
+		}`), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: "proj.sbom.json",
+			Path: path,
+			Type: artifact.SBOM,
+		})
+		require.NoError(tb, Pipe{}.Default(ctx))
+		return ctx
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{})
+		require.EqualError(t, Pipe{}.Run(ctx), "vulncheck: found 2 vulnerabilities with high or higher severity")
+		require.Len(t, ctx.Vulnerabilities, 3)
+
+		critical := ctx.Vulnerabilities[0]
+		require.Equal(t, "GHSA-aaaa-bbbb-cccc", critical.ID)
+		require.Equal(t, "critical", critical.Severity)
+		require.Equal(t, "npm", critical.Ecosystem)
+		require.Equal(t, "@scope/bar", critical.Package)
+		require.Equal(t, "2.0.5", critical.Fixed)
+		require.Equal(t, []string{"proj.sbom.json"}, critical.Artifacts)
+
+		high := ctx.Vulnerabilities[1]
+		require.Equal(t, "GO-2024-0001", high.ID)
+		require.Equal(t, "high", high.Severity)
+		require.Equal(t, "v1.1.0", high.Version)
+		require.Equal(t, "1.2.3", high.Fixed)
+
+		low := ctx.Vulnerabilities[2]
+		require.Equal(t, "GO-2024-0002", low.ID)
+		require.Equal(t, "low", low.Severity)
+		require.Empty(t, low.Fixed)
+	})
+
+	t.Run("fail on critical", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{FailOn: "critical"})
+		require.EqualError(t, Pipe{}.Run(ctx), "vulncheck: found 1 vulnerabilities with critical or higher severity")
+	})
+
+	t.Run("warn only", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{FailOn: "none"})
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Len(t, ctx.Vulnerabilities, 3)
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{
+			Allow: []config.VulnCheckAllow{
+				{ID: "CVE-2024-1234", Reason: "not reachable"},
+				{ID: "GO-2024-0001", Expires: "2024-06-30", Reason: "fix pending"},
+				{ID: "GO-2024-0002", Expires: "2024-06-01"},
+			},
+		})
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.True(t, ctx.Vulnerabilities[0].Allowed)
+		require.Equal(t, "not reachable", ctx.Vulnerabilities[0].Reason)
+		require.True(t, ctx.Vulnerabilities[1].Allowed)
+		require.False(t, ctx.Vulnerabilities[2].Allowed)
+	})
+
+	t.Run("expired allow", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{
+			Allow: []config.VulnCheckAllow{{ID: "GO-2024-0001", Expires: "2024-05-31"}},
+		})
+		require.EqualError(t, Pipe{}.Run(ctx), "vulncheck: found 2 vulnerabilities with high or higher severity")
+	})
+
+	t.Run("reports", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{
+			FailOn: "none",
+			Allow:  []config.VulnCheckAllow{{ID: "GO-2024-0002", Reason: "test only"}},
+		})
+		require.NoError(t, Pipe{}.Run(ctx))
+
+		bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "vulncheck.json"))
+		require.NoError(t, err)
+		var report jsonReport
+		require.NoError(t, json.Unmarshal(bts, &report))
+		require.Equal(t, ctx.Vulnerabilities, report.Vulnerabilities)
+
+		bts, err = os.ReadFile(filepath.Join(ctx.Config.Dist, "vulncheck.sarif"))
+		require.NoError(t, err)
+		var sarif sarifReport
+		require.NoError(t, json.Unmarshal(bts, &sarif))
+		require.Equal(t, "2.1.0", sarif.Version)
+		require.Len(t, sarif.Runs, 1)
+		run := sarif.Runs[0]
+		require.Len(t, run.Tool.Driver.Rules, 3)
+		require.Equal(t, "https://github.com/advisories/GHSA-aaaa-bbbb-cccc", run.Tool.Driver.Rules[0].HelpURI)
+		require.Equal(t, "Parsing a crafted input causes unbounded memory growth.", run.Tool.Driver.Rules[1].FullDescription.Text)
+		require.Len(t, run.Results, 3)
+		require.Equal(t, "error", run.Results[0].Level)
+		require.Equal(t, "example.com/foo@v1.1.0 is affected by GO-2024-0001, fixed in 1.2.3", run.Results[1].Message.Text)
+		require.Equal(t, "note", run.Results[2].Level)
+		require.Equal(t, "proj.sbom.json", run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		require.Equal(t, []sarifSuppression{{Kind: "external", Justification: "test only"}}, run.Results[2].Suppressions)
+	})
+
+	t.Run("no vulnerabilities", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{DB: t.TempDir()})
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Empty(t, ctx.Vulnerabilities)
+		bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "vulncheck.json"))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"vulnerabilities":[]}`, string(bts))
+	})
+
+	t.Run("go binary", func(t *testing.T) {
+		exe, err := os.Executable()
+		require.NoError(t, err)
+		ctx := testctx.WrapWithCfg(t.Context(), config.Project{
+			Dist:      t.TempDir(),
+			VulnCheck: config.VulnCheck{Enabled: "true", DB: t.TempDir()},
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:  "bin",
+			Path:  exe,
+			Type:  artifact.Binary,
+			Extra: map[string]any{artifact.ExtraBuilder: "go"},
+		})
+		pkgs, err := collectPackages(ctx)
+		require.NoError(t, err)
+		var stdlib bool
+		for _, p := range pkgs {
+			require.Equal(t, "Go", p.ecosystem)
+			require.Equal(t, []string{"bin"}, p.artifacts)
+			if p.name == "stdlib" {
+				stdlib = true
+			}
+		}
+		require.True(t, stdlib)
+	})
+
+	t.Run("no db", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{})
+		ctx.Config.VulnCheck.DB = ""
+		require.ErrorIs(t, Pipe{}.Run(ctx), errNoDB)
+	})
+
+	t.Run("missing db", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{DB: "testdata/nope"})
+		require.ErrorContains(t, Pipe{}.Run(ctx), "could not load advisories")
+	})
+
+	t.Run("invalid db", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o644))
+		ctx := setup(t, config.VulnCheck{DB: dir})
+		require.ErrorContains(t, Pipe{}.Run(ctx), "invalid advisory")
+	})
+
+	t.Run("invalid db template", func(t *testing.T) {
+		ctx := setup(t, config.VulnCheck{DB: "{{ .Nope }}"})
+		require.Error(t, Pipe{}.Run(ctx))
+	})
+}
+
+func TestCVSS3(t *testing.T) {
+	for vector, expected := range map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H": 9.9,
+		"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:N/A:N": 3.7,
+		"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 5.5,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	} {
+		t.Run(vector, func(t *testing.T) {
+			score, ok := cvss3(vector)
+			require.True(t, ok)
+			require.InDelta(t, expected, score, 0.001)
+		})
+	}
+
+	for _, vector := range []string{
+		"CVSS:2.0/AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H",
+		"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:X/C:H/I:H/A:H",
+	} {
+		t.Run(vector, func(t *testing.T) {
+			_, ok := cvss3(vector)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tt := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2.0", "1.10.0", -1},
+		{"0", "0.0.1", -1},
+		{"2.0.0-rc1", "2.0.0", -1},
+		{"1.2.3.4", "1.2.3", 1},
+		{"1.0a", "1.0b", -1},
+		{"1.0.post1", "1.0.1", -1},
+	} {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			require.Equal(t, tt.expected, compareVersions(tt.a, tt.b))
+			require.Equal(t, -tt.expected, compareVersions(tt.b, tt.a))
+		})
+	}
+}
+
+func TestAffects(t *testing.T) {
+	var aff affected
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"ranges": [
+			{"type": "GIT", "events": [{"introduced": "0"}]},
+			{"type": "ECOSYSTEM", "events": [
+				{"fixed": "1.5.0"},
+				{"introduced": "1.0.0"},
+				{"introduced": "2.0.0"},
+				{"fixed": "2.1.0"}
+			]}
+		],
+		"versions": ["0.9.0-special"]
+	}`), &aff))
+
+	for version, expected := range map[string]string{
+		"1.0.0":         "1.5.0",
+		"1.4.9":         "1.5.0",
+		"2.0.3":         "2.1.0",
+		"0.9.0-special": "",
+	} {
+		fixed, ok := aff.affects(version)
+		require.True(t, ok, version)
+		require.Equal(t, expected, fixed, version)
+	}
+	for _, version := range []string{"0.9.0", "1.5.0", "1.9.9", "2.1.0", "3.0.0"} {
+		_, ok := aff.affects(version)
+		require.False(t, ok, version)
+	}
+}
+
+func TestParsePURL(t *testing.T) {
+	for purl, expected := range map[string][3]string{
+		"pkg:golang/github.com/foo/bar@v1.2.3":         {"Go", "github.com/foo/bar", "v1.2.3"},
+		"pkg:npm/%40scope/foo@1.0.0?arch=x64#sub/path": {"npm", "@scope/foo", "1.0.0"},
+		"pkg:pypi/Requests@2.32.3":                     {"PyPI", "Requests", "2.32.3"},
+		"pkg:cargo/libc@0.2.172":                       {"crates.io", "libc", "0.2.172"},
+		"pkg:maven/org.apache/commons@1.0":             {"Maven", "org.apache:commons", "1.0"},
+	} {
+		t.Run(purl, func(t *testing.T) {
+			ecosystem, name, version, ok := parsePURL(purl)
+			require.True(t, ok)
+			require.Equal(t, expected, [3]string{ecosystem, name, version})
+		})
+	}
+
+	for _, purl := range []string{
+		"golang/foo@v1.0.0",
+		"pkg:golang",
+		"pkg:unknown/foo@1.0.0",
+		"pkg:golang/foo",
+		"pkg:golang/foo@",
+	} {
+		t.Run(purl, func(t *testing.T) {
+			_, _, _, ok := parsePURL(purl)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestStdlibVersion(t *testing.T) {
+	for goVersion, expected := range map[string]string{
+		"go1.22":                  "1.22.0",
+		"go1.22.1":                "1.22.1",
+		"go1.23rc1":               "1.23rc1",
+		"go1.21beta1":             "1.21beta1",
+		"go1.21.0":                "1.21.0",
+		"go1.19":                  "1.19.0",
+		"go1.21.4 X:boringcrypto": "1.21.4",
+	} {
+		v, ok := stdlibVersion(goVersion)
+		require.True(t, ok)
+		require.Equal(t, expected, v)
+	}
+	for _, goVersion := range []string{"devel", "devel go1.23-abcdef", "go", "1.22.1"} {
+		_, ok := stdlibVersion(goVersion)
+		require.False(t, ok, goVersion)
+	}
+}
+
+func TestSBOMPURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.spdx.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"spdxVersion": "SPDX-2.3",
+		"packages": [
+			{"externalRefs": [
+				{"referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:foo:foo:1.0.0"},
+				{"referenceType": "purl", "referenceLocator": "pkg:golang/foo@v1.0.0"}
+			]},
+			{}
+		]
+	}`), 0o644))
+	purls, err := sbomPURLs(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"pkg:golang/foo@v1.0.0"}, purls)
+
+	_, err = sbomPURLs(filepath.Join(t.TempDir(), "nope.json"))
+	require.Error(t, err)
+}
@@ -8,11 +8,13 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/caarlos0/log"
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/channel"
 	"github.com/goreleaser/goreleaser/v2/internal/client"
 	"github.com/goreleaser/goreleaser/v2/internal/commitauthor"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe"
@@ -207,6 +209,7 @@ func (p Pipe) doRun(ctx *context.Context, winget config.Winget, cl client.Releas
 				artifact.ByType(artifact.UploadableArchive),
 			),
 			artifact.ByType(artifact.UploadableBinary),
+			artifact.ByType(artifact.MSI),
 		),
 		artifact.Or(
 			artifact.ByGoarch("386"),
@@ -221,6 +224,12 @@ func (p Pipe) doRun(ctx *context.Context, winget config.Winget, cl client.Releas
 		filters = append(filters, artifact.ByIDs(winget.IDs...))
 	}
 	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	// prefer msi installers over archives and binaries, if any.
+	if msis := slices.DeleteFunc(slices.Clone(archives), func(a *artifact.Artifact) bool {
+		return a.Type != artifact.MSI
+	}); len(msis) > 0 {
+		archives = msis
+	}
 	if len(archives) == 0 {
 		return errNoArchivesFound{
 			goamd64: winget.Goamd64,
@@ -415,6 +424,10 @@ func doPublish(ctx *context.Context, cl client.Client, wingets []*artifact.Artif
 		return errSkipUploadAuto
 	}
 
+	if !channel.Includes(ctx, winget.Channels) {
+		return pipe.Skipf("release channel %q is not in %v", channel.Of(ctx), winget.Channels)
+	}
+
 	msg, err := tmpl.New(ctx).WithExtraFields(tmpl.Fields{
 		"PackageIdentifier": winget.PackageIdentifier,
 	}).Apply(winget.CommitMessageTemplate)
@@ -609,6 +622,10 @@ func makeInstaller(ctx *context.Context, winget config.Winget, archives []*artif
 			installer.InstallerType = "portable"
 			cmd := artifact.MustExtra[string](*archive, artifact.ExtraBinary)
 			installer.Commands = []string{cmd}
+		case artifact.MSI:
+			installer.InstallerType = "wix"
+			// msi installers upgrade previous versions themselves.
+			item.UpgradeBehavior = "install"
 		}
 		installer.Installers = append(installer.Installers, item)
 		// a manifest may only have one installer per architecture.
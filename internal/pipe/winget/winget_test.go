@@ -1199,6 +1199,59 @@ func TestRunPipeSkippedWingetDoesNotStopOthers(t *testing.T) {
 	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.WingetVersion)).List(), 1)
 }
 
+func TestPublishChannels(t *testing.T) {
+	folder := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(),
+		config.Project{
+			Dist:        folder,
+			ProjectName: "foo",
+			Winget: []config.Winget{
+				{
+					Name:             "foo",
+					Publisher:        "Foo",
+					License:          "MIT",
+					ShortDescription: "foo bar zaz",
+					Channels:         []string{"stable"},
+					Repository: config.RepoRef{
+						Owner: "foo",
+						Name:  "bar",
+					},
+				},
+			},
+		},
+		testctx.WithVersion("1.2.1-rc1"),
+		testctx.WithCurrentTag("v1.2.1-rc1"),
+		testctx.WithSemver(1, 2, 1, "rc1"))
+
+	path := filepath.Join(folder, "dist/foo_windows_amd64v1.zip")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("fake"), 0o644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:    "foo_windows_amd64v1.zip",
+		Path:    path,
+		Goos:    "windows",
+		Goarch:  "amd64",
+		Goamd64: "v1",
+		Type:    artifact.UploadableArchive,
+		Extra: map[string]any{
+			artifact.ExtraID:        "foo",
+			artifact.ExtraFormat:    "zip",
+			artifact.ExtraBinaries:  []string{"foo.exe"},
+			artifact.ExtraWrappedIn: "",
+		},
+	})
+
+	p := Pipe{}
+	cli := client.NewMock()
+	require.NoError(t, p.Default(ctx))
+	require.NoError(t, p.runAll(ctx, cli))
+
+	err := p.publishAll(ctx, cli)
+	require.True(t, pipe.IsSkip(err), "expected a skip error, got %v", err)
+	require.ErrorContains(t, err, `release channel "rc" is not in [stable]`)
+	require.False(t, cli.CreatedFile)
+}
+
 func TestErrNoArchivesFound(t *testing.T) {
 	require.EqualError(t, errNoArchivesFound{
 		goamd64: "v1",
@@ -1325,3 +1378,78 @@ func TestFormatBinary(t *testing.T) {
 	require.NoError(t, pipe.publishAll(ctx, client))
 	require.True(t, client.CreatedFile)
 }
+
+func TestFormatMSI(t *testing.T) {
+	folder := t.TempDir()
+	ctx := testctx.WrapWithCfg(t.Context(),
+		config.Project{
+			Dist:        folder,
+			ProjectName: "foo",
+			Winget: []config.Winget{{
+				Name:             "foo",
+				Publisher:        "goreleaser",
+				License:          "MIT",
+				ShortDescription: "foo bar zaz",
+				Repository: config.RepoRef{
+					Owner: "foo",
+					Name:  "bar",
+				},
+			}},
+		},
+		testctx.WithVersion("1.2.1"),
+		testctx.WithCurrentTag("v1.2.1"),
+		testctx.WithSemver(1, 2, 1, "rc1"),
+		testctx.WithDate(time.Date(2023, 6, 12, 20, 32, 10, 12, time.Local)))
+
+	ctx.ReleaseNotes = "the changelog for this release..."
+	createFakeArtifact := func(typ artifact.Type, goarch, goamd64, ext string) {
+		path := filepath.Join(folder, "dist/foo_"+goarch+goamd64+ext)
+		art := artifact.Artifact{
+			Name:    "foo_" + goarch + goamd64 + ext,
+			Path:    path,
+			Goos:    "windows",
+			Goarch:  goarch,
+			Goamd64: goamd64,
+			Type:    typ,
+			Extra: map[string]any{
+				artifact.ExtraID:     "foo",
+				artifact.ExtraFormat: strings.TrimPrefix(ext, "."),
+			},
+		}
+		ctx.Artifacts.Add(&art)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	for _, goarch := range []string{"amd64", "arm64"} {
+		goamd64 := ""
+		if goarch == "amd64" {
+			goamd64 = "v1"
+		}
+		createFakeArtifact(artifact.UploadableArchive, goarch, goamd64, ".zip")
+		createFakeArtifact(artifact.MSI, goarch, goamd64, ".msi")
+	}
+
+	client := client.NewMock()
+	pipe := Pipe{}
+
+	require.NoError(t, pipe.Default(ctx))
+	require.NoError(t, pipe.runAll(ctx, client))
+	for _, winget := range ctx.Artifacts.Filter(artifact.ByTypes(
+		artifact.WingetInstaller,
+		artifact.WingetVersion,
+		artifact.WingetDefaultLocale,
+		artifact.WingetLocale,
+	)).List() {
+		bts, err := os.ReadFile(winget.Path)
+		require.NoError(t, err)
+		locale := artifact.ExtraOr(*winget, wingetLocaleExtra, "")
+		if locale == "" {
+			cfg := artifact.MustExtra[config.Winget](*winget, wingetConfigExtra)
+			locale = cfg.DefaultLocale
+		}
+		golden.RequireEqualExtSubfolder(t, bts, extFor(winget.Type, locale))
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/chocolatey"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/defaults"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/dist"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/dmg"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/docker"
 	dockerv2 "github.com/goreleaser/goreleaser/v2/internal/pipe/docker/v2"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/effectiveconfig"
@@ -24,15 +25,20 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/flatpak"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/git"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/gomod"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/helm"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/ko"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/krew"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/macospkg"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/makeself"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/nfpm"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/nix"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/notary"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/ociimage"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/partial"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/prebuild"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/provenance"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/publish"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/reportsizes"
@@ -46,6 +52,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/srpm"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/universalbinary"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/upx"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/vulncheck"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/winget"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
 )
@@ -102,6 +109,9 @@ var BuildPipeline = []Piper{
 	universalbinary.Pipe{},
 	// upx
 	upx.Pipe{},
+	// sign windows binaries, which changes them, so it must run before the
+	// binary signatures are created
+	notary.Windows{},
 	// sign binaries
 	sign.BinaryPipe{},
 	// notarize macos apps
@@ -138,10 +148,24 @@ var Pipeline = append(
 	snapcraft.Pipe{},
 	// create flatpak bundles
 	flatpak.Pipe{},
+	// create macOS installer packages
+	macospkg.Pipe{},
+	// create macOS disk images
+	dmg.Pipe{},
+	// create windows installers
+	msi.Pipe{},
+	// sign windows installers
+	notary.WindowsPackages{},
+	// sign & notarize macOS installer packages and disk images
+	notary.MacOSPackages{},
 	// create SBOMs of artifacts
 	sbom.Pipe{},
+	// check for known vulnerabilities
+	vulncheck.Pipe{},
 	// checksums of the files
 	checksums.Pipe{},
+	// slsa provenance of the artifacts
+	provenance.Pipe{},
 	// sign artifacts
 	sign.Pipe{},
 	// create arch linux aur pkgbuild
@@ -169,6 +193,10 @@ var Pipeline = append(
 	dockerv2.Snapshot{},
 	// create and push docker images using ko
 	ko.Pipe{},
+	// create OCI images from the built binaries
+	ociimage.Pipe{},
+	// package helm charts
+	helm.Pipe{},
 	// publishes artifacts
 	publish.New(),
 	// creates a artifacts.json files in the dist directory
@@ -3,6 +3,7 @@ package pyproject
 
 import (
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -40,3 +41,122 @@ func Open(name string) (PyProject, error) {
 	err = toml.Unmarshal(data, &proj)
 	return proj, err
 }
+
+// Lock represents a poetry.lock or uv.lock file.
+type Lock struct {
+	Packages []LockPackage `toml:"package"`
+}
+
+// LockPackage is a package locked in a lock file.
+type LockPackage struct {
+	Name    string
+	Version string
+	// Category and Groups are used by poetry to mark development
+	// dependencies.
+	Category string
+	Groups   []string
+	// Source and Sdist are only set by uv.
+	Source struct {
+		Registry string
+		Editable string
+		Virtual  string
+	}
+	Sdist struct {
+		Hash string
+	}
+	// Dependencies, OptionalDependencies and DevDependencies are only set
+	// by uv.
+	Dependencies         []LockDependency
+	OptionalDependencies map[string][]LockDependency `toml:"optional-dependencies"`
+	DevDependencies      map[string][]LockDependency `toml:"dev-dependencies"`
+}
+
+// LockDependency is a dependency of a package locked in a uv.lock file.
+type LockDependency struct {
+	Name  string
+	Extra []string
+}
+
+// IsDev returns true if the package is only a development dependency.
+func (p LockPackage) IsDev() bool {
+	return p.Category == "dev" ||
+		(len(p.Groups) > 0 && !slices.Contains(p.Groups, "main"))
+}
+
+// IsLocal returns true if the package is the project itself, or any other
+// local package.
+func (p LockPackage) IsLocal() bool {
+	return p.Source.Editable != "" || p.Source.Virtual != ""
+}
+
+// RuntimePackages returns the packages that are not only needed for
+// development.
+//
+// uv.lock files do not mark development dependencies, so they are found by
+// walking the dependencies of the local packages, including their optional
+// dependencies, but not their development dependency groups.
+func (l Lock) RuntimePackages() []LockPackage {
+	var roots []string
+	byName := map[string][]LockPackage{}
+	for _, p := range l.Packages {
+		byName[p.Name] = append(byName[p.Name], p)
+		if p.IsLocal() {
+			roots = append(roots, p.Name)
+		}
+	}
+	if len(roots) == 0 {
+		return slices.DeleteFunc(slices.Clone(l.Packages), LockPackage.IsDev)
+	}
+
+	type key struct{ name, extra string }
+	seen := map[key]bool{}
+	runtime := map[string]bool{}
+	var walk func(name, extra string)
+	walk = func(name, extra string) {
+		if seen[key{name, extra}] {
+			return
+		}
+		seen[key{name, extra}] = true
+		runtime[name] = true
+		for _, p := range byName[name] {
+			deps := p.Dependencies
+			switch {
+			case p.IsLocal():
+				deps = slices.Clone(deps)
+				for _, optional := range p.OptionalDependencies {
+					deps = append(deps, optional...)
+				}
+			case extra != "":
+				deps = p.OptionalDependencies[extra]
+			}
+			for _, dep := range deps {
+				walk(dep.Name, "")
+				for _, extra := range dep.Extra {
+					walk(dep.Name, extra)
+				}
+			}
+		}
+	}
+	for _, name := range roots {
+		walk(name, "")
+	}
+
+	var result []LockPackage
+	for _, p := range l.Packages {
+		if runtime[p.Name] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// OpenLock opens and parses a poetry.lock or uv.lock file.
+func OpenLock(name string) (Lock, error) {
+	var lock Lock
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return lock, err
+	}
+	err = toml.Unmarshal(data, &lock)
+	return lock, err
+}
@@ -30,3 +30,57 @@ func TestIsPoetry(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, proj.IsPoetry())
 }
+
+func TestOpenPoetryLock(t *testing.T) {
+	lock, err := OpenLock("./testdata/poetry.lock")
+	require.NoError(t, err)
+	require.Len(t, lock.Packages, 3)
+	require.Equal(t, "requests", lock.Packages[0].Name)
+	require.Equal(t, "2.32.3", lock.Packages[0].Version)
+	require.False(t, lock.Packages[0].IsDev())
+	require.False(t, lock.Packages[0].IsLocal())
+	require.True(t, lock.Packages[1].IsDev())
+	require.True(t, lock.Packages[2].IsDev())
+}
+
+func TestOpenUVLock(t *testing.T) {
+	lock, err := OpenLock("./testdata/uv.lock")
+	require.NoError(t, err)
+	require.Len(t, lock.Packages, 6)
+	require.True(t, lock.Packages[0].IsLocal())
+	require.False(t, lock.Packages[1].IsLocal())
+	require.False(t, lock.Packages[1].IsDev())
+	require.Equal(t, "sha256:55365417734eb18255590a9ff9eb97e9e1da868d4ccd6402399eaf68af20a760", lock.Packages[1].Sdist.Hash)
+}
+
+func TestRuntimePackages(t *testing.T) {
+	names := func(pkgs []LockPackage) []string {
+		var result []string
+		for _, p := range pkgs {
+			result = append(result, p.Name)
+		}
+		return result
+	}
+
+	t.Run("poetry", func(t *testing.T) {
+		lock, err := OpenLock("./testdata/poetry.lock")
+		require.NoError(t, err)
+		require.Equal(t, []string{"requests"}, names(lock.RuntimePackages()))
+	})
+
+	t.Run("uv", func(t *testing.T) {
+		lock, err := OpenLock("./testdata/uv.lock")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"python-test",
+			"requests",
+			"certifi",
+			"rich",
+		}, names(lock.RuntimePackages()))
+	})
+}
+
+func TestOpenLockError(t *testing.T) {
+	_, err := OpenLock("./testdata/nope.lock")
+	require.Error(t, err)
+}
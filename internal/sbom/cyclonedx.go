@@ -0,0 +1,106 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// See https://cyclonedx.org/docs/1.5/json/.
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func writeCycloneDX(w io.Writer, d Document) error {
+	doc := cdxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: d.Date.UTC().Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{{Type: TypeApplication, Name: "goreleaser"}},
+			},
+			Component: toCycloneDX(d.Subject),
+		},
+		Components: []cdxComponent{},
+	}
+	for _, c := range append([]Component{d.Subject}, d.Components...) {
+		if c.Ref != d.Subject.Ref {
+			doc.Components = append(doc.Components, toCycloneDX(c))
+		}
+		// CycloneDX has no "contains" relationship in the dependency graph,
+		// so both are represented as dependencies.
+		deps := append(append([]string{}, c.Contains...), c.DependsOn...)
+		if len(deps) > 0 {
+			doc.Dependencies = append(doc.Dependencies, cdxDependency{
+				Ref:       c.Ref,
+				DependsOn: deps,
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func toCycloneDX(c Component) cdxComponent {
+	result := cdxComponent{
+		BOMRef:  c.Ref,
+		Type:    c.Type,
+		Name:    c.Name,
+		Version: c.Version,
+		PURL:    c.PURL,
+	}
+	if c.SHA256 != "" {
+		result.Hashes = append(result.Hashes, cdxHash{Alg: "SHA-256", Content: c.SHA256})
+	}
+	if c.SHA512 != "" {
+		result.Hashes = append(result.Hashes, cdxHash{Alg: "SHA-512", Content: c.SHA512})
+	}
+	for _, p := range c.Properties {
+		result.Properties = append(result.Properties, cdxProperty(p))
+	}
+	return result
+}
@@ -0,0 +1,191 @@
+// Package sbom provides a minimal Software Bill of Materials model, and
+// encoders for the CycloneDX and SPDX JSON formats.
+package sbom
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Available formats.
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// Component types.
+const (
+	TypeApplication = "application"
+	TypeLibrary     = "library"
+	TypeFile        = "file"
+)
+
+// Document is a software bill of materials for a single subject.
+type Document struct {
+	// Subject is what the document describes, e.g. a binary or an archive.
+	Subject Component
+	// Components are all the components the subject contains or depends on.
+	Components []Component
+	// Date is the creation date of the document.
+	Date time.Time
+}
+
+// Component is a single component of a [Document].
+type Component struct {
+	// Ref uniquely identifies the component within the document.
+	Ref     string
+	Type    string
+	Name    string
+	Version string
+	PURL    string
+	// SHA256 and SHA512 are the hex encoded digests of the component.
+	SHA256     string
+	SHA512     string
+	Properties []Property
+	// Contains are the refs of the components inside this one.
+	Contains []string
+	// DependsOn are the refs of the components this one depends on.
+	DependsOn []string
+}
+
+// Property is a name/value pair attached to a [Component].
+type Property struct {
+	Name  string
+	Value string
+}
+
+// Add adds the given components to the document, ignoring the ones already
+// in it.
+func (d *Document) Add(components ...Component) {
+	for _, c := range components {
+		if slices.ContainsFunc(d.Components, func(e Component) bool {
+			return e.Ref == c.Ref
+		}) {
+			continue
+		}
+		d.Components = append(d.Components, c)
+	}
+}
+
+// Write writes the document in the given format.
+func (d Document) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatCycloneDX:
+		return writeCycloneDX(w, d)
+	case FormatSPDX:
+		return writeSPDX(w, d)
+	default:
+		return fmt.Errorf("invalid sbom format: %s", format)
+	}
+}
+
+// FromBuildInfo creates an application component from the given Go build
+// info, alongside its module dependencies.
+//
+// The returned application depends on the main module and all the
+// dependencies, and has the build settings (GOOS, GOARCH, CGO_ENABLED, etc)
+// as properties.
+func FromBuildInfo(name, digest string, info *debug.BuildInfo) (Component, []Component) {
+	var modules []Component
+	if info.Main.Path != "" {
+		modules = append(modules, fromModule(&info.Main))
+	}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		modules = append(modules, fromModule(dep))
+	}
+
+	app := Component{
+		Ref:     "file:" + name,
+		Type:    TypeApplication,
+		Name:    name,
+		Version: moduleVersion(info.Main.Version),
+		SHA256:  digest,
+		Properties: []Property{
+			{Name: "go:version", Value: info.GoVersion},
+		},
+	}
+	for _, setting := range info.Settings {
+		app.Properties = append(app.Properties, Property{
+			Name:  "go:build:" + setting.Key,
+			Value: setting.Value,
+		})
+	}
+	for _, m := range modules {
+		app.DependsOn = append(app.DependsOn, m.Ref)
+	}
+	return app, modules
+}
+
+func fromModule(m *debug.Module) Component {
+	c := Library(PURLGolang, m.Path, moduleVersion(m.Version))
+	c.SHA256 = h1ToHex(m.Sum)
+	return c
+}
+
+// Package URL types.
+//
+// See https://github.com/package-url/purl-spec/blob/main/PURL-TYPES.rst.
+const (
+	PURLGolang = "golang"
+	PURLCargo  = "cargo"
+	PURLNPM    = "npm"
+	PURLPyPI   = "pypi"
+)
+
+// Library creates a library component for the given package, identified by
+// its package URL.
+func Library(purlType, name, version string) Component {
+	purl := PURL(purlType, name, version)
+	return Component{
+		Ref:     purl,
+		Type:    TypeLibrary,
+		Name:    name,
+		Version: version,
+		PURL:    purl,
+	}
+}
+
+// PURL returns the package URL of the given package.
+func PURL(purlType, name, version string) string {
+	switch purlType {
+	case PURLNPM:
+		name = strings.Replace(name, "@", "%40", 1)
+	case PURLPyPI:
+		name = strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+	}
+	purl := "pkg:" + purlType + "/" + name
+	if version != "" {
+		purl += "@" + version
+	}
+	return purl
+}
+
+// moduleVersion cleans up the version of local, unversioned, modules.
+func moduleVersion(v string) string {
+	if v == "(devel)" {
+		return ""
+	}
+	return v
+}
+
+// h1ToHex converts a go.sum "h1:" hash into its hex encoded SHA-256 form.
+func h1ToHex(sum string) string {
+	b64, ok := strings.CutPrefix(sum, "h1:")
+	if !ok {
+		return ""
+	}
+	bts, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil || len(bts) != 32 {
+		return ""
+	}
+	return hex.EncodeToString(bts)
+}
@@ -0,0 +1,130 @@
+package sbom
+
+import (
+	"bytes"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/v2/internal/golden"
+	"github.com/stretchr/testify/require"
+)
+
+var testInfo = &debug.BuildInfo{
+	GoVersion: "go1.25.0",
+	Path:      "example.com/foo/cmd/foo",
+	Main: debug.Module{
+		Path:    "example.com/foo",
+		Version: "v1.2.3",
+	},
+	Deps: []*debug.Module{
+		{
+			Path:    "github.com/bar/bar",
+			Version: "v0.1.0",
+			Sum:     "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+		},
+		{
+			Path:    "github.com/old/old",
+			Version: "v1.0.0",
+			Replace: &debug.Module{
+				Path:    "github.com/new/new",
+				Version: "v1.1.0",
+			},
+		},
+	},
+	Settings: []debug.BuildSetting{
+		{Key: "CGO_ENABLED", Value: "0"},
+		{Key: "GOARCH", Value: "amd64"},
+		{Key: "GOOS", Value: "linux"},
+	},
+}
+
+func testDocument() Document {
+	app, modules := FromBuildInfo("foo", "abcd", testInfo)
+	doc := Document{
+		Subject: Component{
+			Ref:      "file:foo.tar.gz",
+			Type:     TypeFile,
+			Name:     "foo.tar.gz",
+			SHA256:   "1234",
+			Contains: []string{app.Ref},
+		},
+		Date: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	doc.Add(app)
+	doc.Add(modules...)
+	doc.Add(modules...)
+	return doc
+}
+
+func TestFromBuildInfo(t *testing.T) {
+	app, modules := FromBuildInfo("foo", "abcd", testInfo)
+	require.Equal(t, "file:foo", app.Ref)
+	require.Equal(t, TypeApplication, app.Type)
+	require.Equal(t, "v1.2.3", app.Version)
+	require.Equal(t, "abcd", app.SHA256)
+	require.Equal(t, []Property{
+		{Name: "go:version", Value: "go1.25.0"},
+		{Name: "go:build:CGO_ENABLED", Value: "0"},
+		{Name: "go:build:GOARCH", Value: "amd64"},
+		{Name: "go:build:GOOS", Value: "linux"},
+	}, app.Properties)
+	require.Equal(t, []string{
+		"pkg:golang/example.com/foo@v1.2.3",
+		"pkg:golang/github.com/bar/bar@v0.1.0",
+		"pkg:golang/github.com/new/new@v1.1.0",
+	}, app.DependsOn)
+
+	require.Len(t, modules, 3)
+	require.Equal(t, "github.com/bar/bar", modules[1].Name)
+	require.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", modules[1].SHA256)
+	require.Empty(t, modules[2].SHA256)
+}
+
+func TestFromBuildInfoDevel(t *testing.T) {
+	app, modules := FromBuildInfo("foo", "", &debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/foo", Version: "(devel)"},
+	})
+	require.Empty(t, app.Version)
+	require.Len(t, modules, 1)
+	require.Equal(t, "pkg:golang/example.com/foo", modules[0].PURL)
+}
+
+func TestH1ToHex(t *testing.T) {
+	require.Empty(t, h1ToHex(""))
+	require.Empty(t, h1ToHex("h2:foo"))
+	require.Empty(t, h1ToHex("h1:not base64"))
+	require.Empty(t, h1ToHex("h1:Zm9v"))
+}
+
+func TestCycloneDX(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, testDocument().Write(&b, FormatCycloneDX))
+	golden.RequireEqualJSON(t, b.Bytes())
+}
+
+func TestSPDX(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, testDocument().Write(&b, FormatSPDX))
+	golden.RequireEqualJSON(t, b.Bytes())
+}
+
+func TestInvalidFormat(t *testing.T) {
+	require.EqualError(t, testDocument().Write(&bytes.Buffer{}, "nope"), "invalid sbom format: nope")
+}
+
+func TestPURL(t *testing.T) {
+	for expected, args := range map[string][3]string{
+		"pkg:golang/github.com/foo/bar@v1.0.0": {PURLGolang, "github.com/foo/bar", "v1.0.0"},
+		"pkg:golang/example.com/foo":           {PURLGolang, "example.com/foo", ""},
+		"pkg:cargo/libc@0.2.172":               {PURLCargo, "libc", "0.2.172"},
+		"pkg:npm/foo@1.0.0":                    {PURLNPM, "foo", "1.0.0"},
+		"pkg:npm/%40scope/foo@1.0.0":           {PURLNPM, "@scope/foo", "1.0.0"},
+		"pkg:pypi/typing-extensions@4.12.2":    {PURLPyPI, "Typing_Extensions", "4.12.2"},
+		"pkg:pypi/zope-interface@7.0":          {PURLPyPI, "zope.interface", "7.0"},
+	} {
+		t.Run(expected, func(t *testing.T) {
+			require.Equal(t, expected, PURL(args[0], args[1], args[2]))
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// See https://spdx.github.io/spdx-spec/v2.3/.
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string           `json:"SPDXID"`
+	Name                  string           `json:"name"`
+	VersionInfo           string           `json:"versionInfo,omitempty"`
+	DownloadLocation      string           `json:"downloadLocation"`
+	FilesAnalyzed         bool             `json:"filesAnalyzed"`
+	LicenseConcluded      string           `json:"licenseConcluded"`
+	LicenseDeclared       string           `json:"licenseDeclared"`
+	CopyrightText         string           `json:"copyrightText"`
+	PrimaryPackagePurpose string           `json:"primaryPackagePurpose"`
+	Checksums             []spdxChecksum   `json:"checksums,omitempty"`
+	ExternalRefs          []spdxRef        `json:"externalRefs,omitempty"`
+	Annotations           []spdxAnnotation `json:"annotations,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct {
+	Annotator      string `json:"annotator"`
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Comment        string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+const (
+	spdxDocumentID = "SPDXRef-DOCUMENT"
+	spdxNoAssert   = "NOASSERTION"
+	spdxCreator    = "Tool: goreleaser"
+)
+
+func writeSPDX(w io.Writer, d Document) error {
+	created := d.Date.UTC().Format(time.RFC3339)
+	all := append([]Component{d.Subject}, d.Components...)
+	ids := make(map[string]string, len(all))
+	for i, c := range all {
+		ids[c.Ref] = fmt.Sprintf("SPDXRef-Package-%d", i)
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              d.Subject.Name,
+		DocumentNamespace: spdxNamespace(d),
+		CreationInfo: spdxCreationInfo{
+			Created:  created,
+			Creators: []string{spdxCreator},
+		},
+		Relationships: []spdxRelationship{{
+			Element: spdxDocumentID,
+			Type:    "DESCRIBES",
+			Related: ids[d.Subject.Ref],
+		}},
+	}
+
+	for _, c := range all {
+		pkg := spdxPackage{
+			SPDXID:                ids[c.Ref],
+			Name:                  c.Name,
+			VersionInfo:           c.Version,
+			DownloadLocation:      spdxNoAssert,
+			LicenseConcluded:      spdxNoAssert,
+			LicenseDeclared:       spdxNoAssert,
+			CopyrightText:         spdxNoAssert,
+			PrimaryPackagePurpose: strings.ToUpper(c.Type),
+		}
+		if c.SHA256 != "" {
+			pkg.Checksums = append(pkg.Checksums, spdxChecksum{Algorithm: "SHA256", ChecksumValue: c.SHA256})
+		}
+		if c.SHA512 != "" {
+			pkg.Checksums = append(pkg.Checksums, spdxChecksum{Algorithm: "SHA512", ChecksumValue: c.SHA512})
+		}
+		if c.PURL != "" {
+			pkg.ExternalRefs = []spdxRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  c.PURL,
+			}}
+		}
+		// SPDX packages have no arbitrary properties, so they are added as
+		// annotations instead.
+		for _, p := range c.Properties {
+			pkg.Annotations = append(pkg.Annotations, spdxAnnotation{
+				Annotator:      spdxCreator,
+				AnnotationDate: created,
+				AnnotationType: "OTHER",
+				Comment:        p.Name + "=" + p.Value,
+			})
+		}
+		doc.Packages = append(doc.Packages, pkg)
+
+		for _, ref := range c.Contains {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				Element: ids[c.Ref],
+				Type:    "CONTAINS",
+				Related: ids[ref],
+			})
+		}
+		for _, ref := range c.DependsOn {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				Element: ids[c.Ref],
+				Type:    "DEPENDS_ON",
+				Related: ids[ref],
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// spdxNamespace returns an unique, but reproducible, namespace for the
+// given document.
+func spdxNamespace(d Document) string {
+	h := sha256.New()
+	for _, c := range append([]Component{d.Subject}, d.Components...) {
+		fmt.Fprintf(h, "%s\x00%s\n", c.Ref, c.SHA256)
+	}
+	return fmt.Sprintf(
+		"https://goreleaser.com/spdxdocs/%s-%s",
+		d.Subject.Name,
+		hex.EncodeToString(h.Sum(nil))[:16],
+	)
+}
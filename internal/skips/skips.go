@@ -20,6 +20,8 @@ const (
 	Sign           Key = "sign"
 	Validate       Key = "validate"
 	SBOM           Key = "sbom"
+	Provenance     Key = "provenance"
+	VulnCheck      Key = "vulncheck"
 	Ko             Key = "ko"
 	Docker         Key = "docker"
 	Before         Key = "before"
@@ -39,6 +41,12 @@ const (
 	MCP            Key = "mcp"
 	Iru            Key = "iru"
 	SRPM           Key = "srpm"
+	MacOSPkg       Key = "macos-pkg"
+	MacOSDMG       Key = "macos-dmg"
+	WindowsMSI     Key = "windows-msi"
+	OCIImage       Key = "oci-image"
+	Helm           Key = "helm"
+	Deploy         Key = "deploy"
 )
 
 func String(ctx *context.Context) string {
@@ -117,6 +125,8 @@ var Release = Keys{
 	Sign,
 	Validate,
 	SBOM,
+	Provenance,
+	VulnCheck,
 	Ko,
 	Docker,
 	Winget,
@@ -131,6 +141,12 @@ var Release = Keys{
 	Makeself,
 	Flatpak,
 	SRPM,
+	MacOSPkg,
+	MacOSDMG,
+	WindowsMSI,
+	OCIImage,
+	Helm,
+	Deploy,
 	Before,
 	Notarize,
 	Archive,
@@ -2,6 +2,8 @@ package testlib
 
 import (
 	"maps"
+	"os"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"strings"
@@ -55,6 +57,47 @@ func RequireEqualArtifacts(tb testing.TB, expected, got []*artifact.Artifact) {
 	}
 }
 
+// AddBinaries writes a binary for each of the given goos_goarch targets in
+// dist, and adds it, and an archive of it, to the given artifacts.
+//
+// The "all" goarch adds an universal binary instead.
+func AddBinaries(tb testing.TB, artifacts *artifact.Artifacts, dist, name string, targets ...string) {
+	tb.Helper()
+	for _, target := range targets {
+		goos, goarch, _ := strings.Cut(target, "_")
+		typ := artifact.Binary
+		if goarch == "all" {
+			typ = artifact.UniversalBinary
+		}
+		bin := name
+		if goos == "windows" {
+			bin += ".exe"
+		}
+		path := filepath.Join(dist, target, bin)
+		require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(tb, os.WriteFile(path, []byte(goos+"/"+goarch), 0o755))
+		artifacts.Add(&artifact.Artifact{
+			Type:    typ,
+			Name:    bin,
+			Path:    path,
+			Goos:    goos,
+			Goarch:  goarch,
+			Goamd64: "v1",
+			Target:  target,
+			Extra:   map[string]any{artifact.ExtraID: "foo"},
+		})
+		artifacts.Add(&artifact.Artifact{
+			Type:    artifact.UploadableArchive,
+			Name:    "foo_" + target + ".tar.gz",
+			Path:    path + ".tar.gz",
+			Goos:    goos,
+			Goarch:  goarch,
+			Goamd64: "v1",
+			Extra:   map[string]any{artifact.ExtraID: "foo"},
+		})
+	}
+}
+
 func artifactSort(a, b *artifact.Artifact) int {
 	return strings.Compare(a.Path, b.Path)
 }
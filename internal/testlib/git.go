@@ -46,6 +46,15 @@ func GitCommit(tb testing.TB, msg string) {
 	require.Contains(tb, out, "main", msg)
 }
 
+// GitCommitAs creates a git commit authored by the given author, in the
+// `Name <email>` format.
+func GitCommitAs(tb testing.TB, author, msg string) {
+	tb.Helper()
+	out, err := fakeGit("commit", "--allow-empty", "--author", author, "-m", msg)
+	require.NoError(tb, err)
+	require.Contains(tb, out, "main", msg)
+}
+
 // GitTag creates a git tag.
 func GitTag(tb testing.TB, tag string) {
 	tb.Helper()
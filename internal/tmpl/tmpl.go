@@ -17,6 +17,8 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/changelog"
+	"github.com/goreleaser/goreleaser/v2/internal/channel"
 	"github.com/goreleaser/goreleaser/v2/pkg/build"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
 	"golang.org/x/text/cases"
@@ -70,6 +72,8 @@ const (
 	minor           = "Minor"
 	patch           = "Patch"
 	prerelease      = "Prerelease"
+	calVer          = "CalVer"
+	channelK        = "Channel"
 	isSnapshot      = "IsSnapshot"
 	isSingleTarget  = "IsSingleTarget"
 	isNightly       = "IsNightly"
@@ -80,6 +84,9 @@ const (
 	timestamp       = "Timestamp"
 	modulePath      = "ModulePath"
 	releaseNotes    = "ReleaseNotes"
+	contributors    = "Contributors"
+	newContributors = "NewContributors"
+	vulnerabilities = "Vulnerabilities"
 	runtimeK        = "Runtime"
 )
 
@@ -135,11 +142,16 @@ func New(ctx *context.Context) *Template {
 		minor:           ctx.Semver.Minor,
 		patch:           ctx.Semver.Patch,
 		prerelease:      ctx.Semver.Prerelease,
+		calVer:          ctx.CalVer,
+		channelK:        channel.Of(ctx),
 		isSnapshot:      ctx.Snapshot,
 		isSingleTarget:  ctx.SingleTarget,
 		isNightly:       false,
 		isDraft:         ctx.Config.Release.Draft,
 		releaseNotes:    ctx.ReleaseNotes,
+		contributors:    ctx.Contributors,
+		newContributors: newContributorsOf(ctx.Contributors),
+		vulnerabilities: ctx.Vulnerabilities,
 		releaseURL:      ctx.ReleaseURL,
 		tagSubject:      ctx.Git.TagSubject,
 		tagContents:     ctx.Git.TagContents,
@@ -152,6 +164,16 @@ func New(ctx *context.Context) *Template {
 	}
 }
 
+func newContributorsOf(all []changelog.Contributor) []changelog.Contributor {
+	var result []changelog.Contributor
+	for _, c := range all {
+		if c.New {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
 // SetEnv adds a single environment variable into the template env.
 func (t *Template) SetEnv(single string) *Template {
 	k, v, ok := strings.Cut(single, "=")
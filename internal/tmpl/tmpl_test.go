@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/changelog"
 	"github.com/goreleaser/goreleaser/v2/internal/testctx"
 	"github.com/goreleaser/goreleaser/v2/pkg/build"
 	"github.com/goreleaser/goreleaser/v2/pkg/config"
@@ -48,6 +49,14 @@ func TestWithArtifact(t *testing.T) {
 		func(ctx *context.Context) {
 			ctx.ModulePath = "github.com/goreleaser/goreleaser/v2"
 			ctx.ReleaseNotes = "test release notes"
+			ctx.Contributors = []changelog.Contributor{
+				{Author: changelog.Author{Username: "foo"}, Commits: 3},
+				{Author: changelog.Author{Username: "bar"}, Commits: 1, New: true},
+			}
+			ctx.Vulnerabilities = []context.Vulnerability{
+				{ID: "GO-2024-0001", Severity: "high", Package: "foo", Version: "v1.0.0"},
+			}
+			ctx.CalVer = context.CalVer{Year: 2024, Month: 1, Micro: 3}
 			ctx.Date = time.Unix(1678327562, 0)
 			ctx.SingleTarget = true
 		})
@@ -81,7 +90,12 @@ func TestWithArtifact(t *testing.T) {
 		"v1.2.4":                              "{{.Tag | incpatch }}",
 		"1.2.4":                               "{{.Version | incpatch }}",
 		"test release notes":                  "{{ .ReleaseNotes }}",
+		"foo:3 bar:1":                         "{{ range $i, $c := .Contributors }}{{ if $i }} {{ end }}{{ $c.Username }}:{{ $c.Commits }}{{ end }}",
+		"new: bar":                            "new:{{ range .NewContributors }} {{ .Username }}{{ end }}",
+		"vulns: GO-2024-0001 (high)":          "vulns:{{ range .Vulnerabilities }} {{ .ID }} ({{ .Severity }}){{ end }}",
 		"v1.2.2":                              "{{ .PreviousTag }}",
+		"calver: 2024.01.3":                   `calver: {{ .CalVer.Year }}.{{ printf "%02d" .CalVer.Month }}.{{ .CalVer.Micro }}`,
+		"channel: stable":                     "channel: {{ .Channel }}",
 		"awesome release":                     "{{ .TagSubject }}",
 		"awesome release\n\nanother line":     "{{ .TagContents }}",
 		"another line":                        "{{ .TagBody }}",
@@ -0,0 +1,233 @@
+// Package udif writes Apple Universal Disk Image Format (UDIF) images, the
+// format of DMG files.
+//
+// Images are compressed with zlib (UDZO) and contain a single partition
+// without a partition map, as the ones created by `hdiutil -layout NONE`.
+//
+// See: https://newosxbook.com/DMG.html
+package udif
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+	"text/template"
+)
+
+const (
+	sectorSize = 512
+	// chunkSectors is the amount of sectors in each compressed chunk.
+	chunkSectors = 2048
+
+	chunkZero       = 0x00000002
+	chunkZlib       = 0x80000005
+	chunkTerminator = 0xffffffff
+
+	checksumCRC32 = 2
+)
+
+// Write writes a compressed UDIF image to w, with the filesystem image read
+// from fs as its only partition.
+//
+// The filesystem is compressed as it is read, so it is never fully kept in
+// memory.
+// Its size must be a multiple of 512 bytes, and name is the partition
+// description, e.g. "ISO".
+func Write(w io.Writer, fs io.Reader, name string) error {
+	type chunk struct {
+		kind                   uint32
+		sector, sectors        uint64
+		offset, compressedSize uint64
+	}
+
+	// the compressed data is written as it goes, keeping track of its size
+	// and checksum.
+	data := &countWriter{w: w, crc: crc32.NewIEEE()}
+	fsCRC := crc32.NewIEEE()
+	fsSum := sha256.New()
+	raw := make([]byte, chunkSectors*sectorSize)
+	var chunks []chunk
+	var totalSectors uint64
+	for {
+		n, err := io.ReadFull(fs, raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("udif: %w", err)
+		}
+		if n%sectorSize != 0 {
+			return fmt.Errorf("udif: image size must be a multiple of %d", sectorSize)
+		}
+		raw := raw[:n]
+		_, _ = fsCRC.Write(raw)
+		_, _ = fsSum.Write(raw)
+
+		c := chunk{kind: chunkZero, sector: totalSectors, sectors: uint64(n / sectorSize), offset: data.n}
+		if !isZero(raw) {
+			zw := zlib.NewWriter(data)
+			if _, err := zw.Write(raw); err != nil {
+				return fmt.Errorf("udif: %w", err)
+			}
+			if err := zw.Close(); err != nil {
+				return fmt.Errorf("udif: %w", err)
+			}
+			c.kind = chunkZlib
+			c.compressedSize = data.n - c.offset
+		}
+		chunks = append(chunks, c)
+		totalSectors += c.sectors
+	}
+	chunks = append(chunks, chunk{kind: chunkTerminator, sector: totalSectors, offset: data.n})
+
+	fsChecksum := fsCRC.Sum32()
+	var blkx bytes.Buffer
+	write(&blkx,
+		[]byte("mish"),
+		uint32(1),              // version
+		uint64(0),              // first sector
+		totalSectors,           // sector count
+		uint64(0),              // data offset
+		uint32(chunkSectors+8), // buffers needed
+		uint32(0),              // block descriptors
+		make([]byte, 24),       // reserved
+	)
+	writeChecksum(&blkx, fsChecksum)
+	write(&blkx, uint32(len(chunks)))
+	for _, c := range chunks {
+		write(&blkx, c.kind, uint32(0), c.sector, c.sectors, c.offset, c.compressedSize)
+	}
+
+	var plist bytes.Buffer
+	if err := plistTmpl.Execute(&plist, map[string]string{
+		"Name": fmt.Sprintf("whole disk (%s : 0)", name),
+		"Data": base64.StdEncoding.EncodeToString(blkx.Bytes()),
+	}); err != nil {
+		return fmt.Errorf("udif: %w", err)
+	}
+
+	// the master checksum is the checksum of all partitions checksums.
+	var master [4]byte
+	binary.BigEndian.PutUint32(master[:], fsChecksum)
+
+	// derive the segment id from the contents, so images are reproducible.
+	var segmentID [16]byte
+	copy(segmentID[:], fsSum.Sum(nil))
+
+	dataLen := data.n
+	var koly bytes.Buffer
+	write(&koly,
+		[]byte("koly"),
+		uint32(4),   // version
+		uint32(512), // header size
+		uint32(1),   // flags: flattened
+		uint64(0),   // running data fork offset
+		uint64(0),   // data fork offset
+		dataLen,     // data fork length
+		uint64(0),   // resource fork offset
+		uint64(0),   // resource fork length
+		uint32(1),   // segment number
+		uint32(1),   // segment count
+		segmentID,
+	)
+	writeChecksum(&koly, data.crc.Sum32())
+	write(&koly,
+		dataLen,             // plist offset
+		uint64(plist.Len()), // plist length
+		make([]byte, 120),   // reserved
+	)
+	writeChecksum(&koly, crc32.ChecksumIEEE(master[:]))
+	write(&koly,
+		uint32(1),    // image variant
+		totalSectors, // sector count
+		make([]byte, 12),
+	)
+
+	for _, b := range [][]byte{plist.Bytes(), koly.Bytes()} {
+		if _, err := w.Write(b); err != nil {
+			return fmt.Errorf("udif: %w", err)
+		}
+	}
+	return nil
+}
+
+// countWriter keeps track of the size and checksum of what is written to w.
+type countWriter struct {
+	w   io.Writer
+	n   uint64
+	crc hash.Hash32
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	_, _ = c.crc.Write(p[:n])
+	return n, err
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func writeChecksum(b *bytes.Buffer, sum uint32) {
+	data := make([]uint32, 32)
+	data[0] = sum
+	write(b, uint32(checksumCRC32), uint32(32), data)
+}
+
+func write(b *bytes.Buffer, values ...any) {
+	for _, v := range values {
+		// writes to a bytes.Buffer never fail.
+		_ = binary.Write(b, binary.BigEndian, v)
+	}
+}
+
+var plistTmpl = template.Must(template.New("plist").Funcs(template.FuncMap{
+	"wrap": func(s string) string {
+		var lines []string
+		for len(s) > 52 {
+			lines = append(lines, s[:52])
+			s = s[52:]
+		}
+		return strings.Join(append(lines, s), "\n\t\t\t\t")
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>resource-fork</key>
+	<dict>
+		<key>blkx</key>
+		<array>
+			<dict>
+				<key>Attributes</key>
+				<string>0x0050</string>
+				<key>CFName</key>
+				<string>{{ html .Name }}</string>
+				<key>Data</key>
+				<data>
+				{{ wrap .Data }}
+				</data>
+				<key>ID</key>
+				<string>0</string>
+				<key>Name</key>
+				<string>{{ html .Name }}</string>
+			</dict>
+		</array>
+	</dict>
+</dict>
+</plist>
+`))
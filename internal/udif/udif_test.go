@@ -0,0 +1,88 @@
+package udif
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	// 2 full chunks (one of them all zeroes), plus a partial one.
+	fs := make([]byte, (chunkSectors*2+3)*sectorSize)
+	copy(fs, "hello")
+	copy(fs[len(fs)-5:], "world")
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, bytes.NewReader(fs), "ISO"))
+
+	kinds, got := readImage(t, buf.Bytes())
+	require.Equal(t, []uint32{chunkZlib, chunkZero, chunkZlib, chunkTerminator}, kinds)
+	require.Equal(t, fs, got)
+
+	t.Run("reproducible", func(t *testing.T) {
+		var again bytes.Buffer
+		require.NoError(t, Write(&again, bytes.NewReader(fs), "ISO"))
+		require.Equal(t, buf.Bytes(), again.Bytes())
+	})
+}
+
+func TestWriteInvalidSize(t *testing.T) {
+	require.EqualError(t, Write(io.Discard, bytes.NewReader(make([]byte, 100)), "ISO"), "udif: image size must be a multiple of 512")
+}
+
+// readImage decompresses the image, returning the chunk types and the
+// filesystem.
+func readImage(tb testing.TB, img []byte) ([]uint32, []byte) {
+	tb.Helper()
+	be := binary.BigEndian
+
+	koly := img[len(img)-512:]
+	require.Equal(tb, "koly", string(koly[:4]))
+	require.Equal(tb, uint32(4), be.Uint32(koly[4:]))
+	dataLen := be.Uint64(koly[32:])
+	data := img[:dataLen]
+	require.Equal(tb, crc32.ChecksumIEEE(data), be.Uint32(koly[88:]))
+	plistOffset, plistLen := be.Uint64(koly[216:]), be.Uint64(koly[224:])
+	require.Equal(tb, uint64(len(img)-512), plistOffset+plistLen)
+
+	var plist struct {
+		Data []string `xml:"dict>dict>array>dict>data"`
+	}
+	require.NoError(tb, xml.Unmarshal(img[plistOffset:plistOffset+plistLen], &plist))
+	require.Len(tb, plist.Data, 1)
+	blkx, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(plist.Data[0]), ""))
+	require.NoError(tb, err)
+	require.Equal(tb, "mish", string(blkx[:4]))
+	sectors := be.Uint64(blkx[16:])
+	require.Equal(tb, sectors, be.Uint64(koly[492:]))
+
+	fs := make([]byte, sectors*sectorSize)
+	var kinds []uint32
+	count := be.Uint32(blkx[200:])
+	for i := range count {
+		c := blkx[204+i*40:]
+		kind := be.Uint32(c)
+		kinds = append(kinds, kind)
+		sector, n := be.Uint64(c[8:]), be.Uint64(c[16:])
+		offset, size := be.Uint64(c[24:]), be.Uint64(c[32:])
+		if kind != chunkZlib {
+			continue
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data[offset : offset+size]))
+		require.NoError(tb, err)
+		raw, err := io.ReadAll(zr)
+		require.NoError(tb, err)
+		require.Len(tb, raw, int(n*sectorSize))
+		copy(fs[sector*sectorSize:], raw)
+	}
+	require.Equal(tb, crc32.ChecksumIEEE(fs), be.Uint32(blkx[72:]))
+	return kinds, fs
+}
@@ -0,0 +1,146 @@
+package verify
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/goreleaser/goreleaser/v2/internal/keyfile"
+	"golang.org/x/crypto/ssh"
+)
+
+// keyring holds the public keys signatures are verified against.
+type keyring struct {
+	openpgp  openpgp.EntityList
+	minisign []minisignKey
+	ssh      []ssh.PublicKey
+	pkix     []crypto.PublicKey
+
+	// namespace is the expected namespace of ssh signatures.
+	namespace string
+}
+
+type minisignKey struct {
+	id  [8]byte
+	key []byte
+}
+
+// loadKeys loads the given public keys.
+//
+// Each key can be either a path to a file or the key contents.
+// The key type is detected from its contents.
+func loadKeys(keys []string) (*keyring, error) {
+	kr := &keyring{}
+	for _, key := range keys {
+		bts, err := keyfile.Read(key)
+		if err != nil {
+			return nil, fmt.Errorf("could not read key: %w", err)
+		}
+		if err := kr.add(bts); err != nil {
+			return nil, fmt.Errorf("%s: %w", keyName(key), err)
+		}
+	}
+	return kr, nil
+}
+
+func (kr *keyring) add(bts []byte) error {
+	trimmed := bytes.TrimSpace(bts)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")):
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(trimmed))
+		if err != nil {
+			return fmt.Errorf("invalid openpgp key: %w", err)
+		}
+		kr.openpgp = append(kr.openpgp, entities...)
+		return nil
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN PUBLIC KEY-----")):
+		block, _ := pem.Decode(trimmed)
+		if block == nil {
+			return errors.New("invalid public key")
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("invalid public key: %w", err)
+		}
+		kr.pkix = append(kr.pkix, pub)
+		return nil
+	}
+
+	if key, ok := parseMinisignKey(trimmed); ok {
+		kr.minisign = append(kr.minisign, key)
+		return nil
+	}
+
+	var keys []ssh.PublicKey
+	for rest := trimmed; len(rest) > 0; {
+		pub, _, _, next, err := ssh.ParseAuthorizedKey(rest)
+		if err != nil {
+			break
+		}
+		keys = append(keys, pub)
+		rest = next
+	}
+	if len(keys) > 0 {
+		kr.ssh = append(kr.ssh, keys...)
+		return nil
+	}
+
+	if entities, err := openpgp.ReadKeyRing(bytes.NewReader(bts)); err == nil {
+		kr.openpgp = append(kr.openpgp, entities...)
+		return nil
+	}
+	return errors.New("unsupported public key")
+}
+
+// parseMinisignKey parses a minisign public key, with or without the
+// untrusted comment line.
+func parseMinisignKey(bts []byte) (minisignKey, bool) {
+	lines := strings.Split(string(bts), "\n")
+	if strings.HasPrefix(lines[0], "untrusted comment:") {
+		lines = lines[1:]
+	}
+	if len(lines) != 1 {
+		return minisignKey{}, false
+	}
+	bin, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+	if err != nil || len(bin) != 42 || string(bin[0:2]) != "Ed" {
+		return minisignKey{}, false
+	}
+	var key minisignKey
+	copy(key.id[:], bin[2:10])
+	key.key = bin[10:]
+	return key, true
+}
+
+func keyName(key string) string {
+	if strings.Contains(key, "\n") {
+		return "key"
+	}
+	return key
+}
+
+// loadRoots loads the given PEM-encoded root certificates.
+// If none are given, it returns nil, and no certificates can be verified.
+func loadRoots(paths []string) (*x509.CertPool, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	pool := x509.NewCertPool()
+	for _, path := range paths {
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read root certificates: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(bts) {
+			return nil, fmt.Errorf("%s: no certificates found", path)
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,415 @@
+package verify
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ssh"
+)
+
+// Signature formats.
+const (
+	backendOpenPGP  = "openpgp"
+	backendMinisign = "minisign"
+	backendSSH      = "ssh"
+	backendX509     = "x509"
+	backendPKIX     = "pkix"
+)
+
+var (
+	errNoKeys     = errors.New("no public keys given")
+	errNoKey      = errors.New("no matching public key found")
+	errNoRoots    = errors.New("no root certificates given")
+	errNoIdentity = errors.New("no certificate identity or issuer given")
+)
+
+// Fulcio extensions with the OIDC issuer of a certificate.
+//
+// See https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md.
+var (
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// certPolicy is what certificates are validated against.
+type certPolicy struct {
+	roots      *x509.CertPool
+	identities []string
+	issuers    []string
+}
+
+// verifySignature verifies the signature of the given file.
+//
+// The signature format is detected from its contents.
+// Signatures which are not openpgp, minisign, or ssh signatures are verified
+// using the given certificate, if any, or the PKIX public keys in the keyring.
+func verifySignature(kr *keyring, policy certPolicy, path, sigPath, certPath string) (string, error) {
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return "", err
+	}
+	trimmed := bytes.TrimSpace(sig)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN PGP SIGNATURE-----")):
+		return backendOpenPGP, verifyOpenPGP(kr, path, sig, true)
+	case bytes.HasPrefix(trimmed, []byte("untrusted comment:")):
+		return backendMinisign, verifyMinisign(kr, path, trimmed)
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN SSH SIGNATURE-----")):
+		return backendSSH, verifySSH(kr, path, trimmed)
+	case certPath != "":
+		return backendX509, verifyWithCertificate(policy, path, trimmed, certPath)
+	case len(kr.pkix) > 0:
+		return backendPKIX, verifyWithPublicKeys(kr.pkix, path, trimmed)
+	case len(kr.openpgp) > 0:
+		return backendOpenPGP, verifyOpenPGP(kr, path, sig, false)
+	default:
+		return "", errors.New("unsupported signature format")
+	}
+}
+
+func verifyOpenPGP(kr *keyring, path string, sig []byte, armored bool) error {
+	if len(kr.openpgp) == 0 {
+		return errNoKeys
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if armored {
+		_, err = openpgp.CheckArmoredDetachedSignature(kr.openpgp, f, bytes.NewReader(sig), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(kr.openpgp, f, bytes.NewReader(sig), nil)
+	}
+	return err
+}
+
+// verifyClearSign verifies an OpenPGP cleartext signature.
+func verifyClearSign(kr *keyring, block *clearsign.Block) error {
+	if len(kr.openpgp) == 0 {
+		return errNoKeys
+	}
+	_, err := block.VerifySignature(kr.openpgp, nil)
+	return err
+}
+
+// verifyMinisign verifies a minisign signature.
+//
+// See https://jedisct1.github.io/minisign/ for the format.
+func verifyMinisign(kr *keyring, path string, sig []byte) error {
+	if len(kr.minisign) == 0 {
+		return errNoKeys
+	}
+	lines := strings.Split(string(sig), "\n")
+	if len(lines) < 4 {
+		return errors.New("invalid minisign signature")
+	}
+	bin, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(bin) != 74 {
+		return errors.New("invalid minisign signature")
+	}
+	trusted, ok := strings.CutPrefix(strings.TrimSpace(lines[2]), "trusted comment: ")
+	if !ok {
+		return errors.New("invalid minisign signature: missing trusted comment")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return errors.New("invalid minisign signature")
+	}
+
+	alg, id, signature := string(bin[0:2]), bin[2:10], bin[10:]
+	var key *minisignKey
+	for _, k := range kr.minisign {
+		if bytes.Equal(k.id[:], id) {
+			key = &k
+			break
+		}
+	}
+	if key == nil {
+		return errNoKey
+	}
+
+	var msg []byte
+	switch alg {
+	case "ED":
+		h, _ := blake2b.New512(nil)
+		if msg, err = hashFile(h, path); err != nil {
+			return err
+		}
+	case "Ed":
+		if msg, err = os.ReadFile(path); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm: %q", alg)
+	}
+
+	pub := ed25519.PublicKey(key.key)
+	if !ed25519.Verify(pub, msg, signature) {
+		return errors.New("invalid signature")
+	}
+	if !ed25519.Verify(pub, append(bytes.Clone(signature), trusted...), global) {
+		return errors.New("invalid trusted comment signature")
+	}
+	return nil
+}
+
+// verifySSH verifies a signature created by 'ssh-keygen -Y sign'.
+//
+// See https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig.
+func verifySSH(kr *keyring, path string, sig []byte) error {
+	if len(kr.ssh) == 0 {
+		return errNoKeys
+	}
+	block, _ := pem.Decode(sig)
+	if block == nil {
+		return errors.New("invalid ssh signature")
+	}
+	blob, ok := bytes.CutPrefix(block.Bytes, []byte("SSHSIG"))
+	if !ok {
+		return errors.New("invalid ssh signature")
+	}
+	var envelope struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Signature []byte
+	}
+	if err := ssh.Unmarshal(blob, &envelope); err != nil {
+		return fmt.Errorf("invalid ssh signature: %w", err)
+	}
+	if envelope.Version != 1 {
+		return fmt.Errorf("unsupported ssh signature version: %d", envelope.Version)
+	}
+	if envelope.Namespace != kr.namespace {
+		return fmt.Errorf("ssh signature namespace mismatch: expected %q, got %q", kr.namespace, envelope.Namespace)
+	}
+	pub, err := ssh.ParsePublicKey(envelope.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid ssh signature: %w", err)
+	}
+	var allowed bool
+	for _, k := range kr.ssh {
+		if bytes.Equal(k.Marshal(), pub.Marshal()) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return errNoKey
+	}
+
+	var h hash.Hash
+	switch envelope.HashAlg {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported ssh signature hash algorithm: %s", envelope.HashAlg)
+	}
+	sum, err := hashFile(h, path)
+	if err != nil {
+		return err
+	}
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Hash      []byte
+	}{envelope.Namespace, envelope.Reserved, envelope.HashAlg, sum})...)
+
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(envelope.Signature, &signature); err != nil {
+		return fmt.Errorf("invalid ssh signature: %w", err)
+	}
+	return pub.Verify(signed, &signature)
+}
+
+// verifyWithCertificate verifies the given signature using the public key
+// of the given certificate, after validating its chain.
+func verifyWithCertificate(policy certPolicy, path string, sig []byte, certPath string) error {
+	leaf, err := verifyCertificate(policy, certPath)
+	if err != nil {
+		return err
+	}
+	return verifyWithPublicKeys([]crypto.PublicKey{leaf.PublicKey}, path, sig)
+}
+
+// verifyWithPublicKeys verifies a base64 or raw signature of the SHA256
+// digest of the file, as created by cosign and openssl, for example.
+func verifyWithPublicKeys(keys []crypto.PublicKey, path string, sig []byte) error {
+	if decoded, err := base64.StdEncoding.DecodeString(string(sig)); err == nil {
+		sig = decoded
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(data)
+	for _, key := range keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil ||
+				rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil) == nil {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, data, sig) {
+				return nil
+			}
+		}
+	}
+	return errors.New("invalid signature")
+}
+
+// verifyCertificate validates the chain and the identity of the given
+// certificate file.
+//
+// The file should have the leaf certificate first, followed by any
+// intermediates, and can be base64 encoded, as created by cosign.
+// As signing certificates are usually short-lived, the chain is validated
+// at the time the leaf certificate was issued.
+func verifyCertificate(policy certPolicy, path string) (*x509.Certificate, error) {
+	if policy.roots == nil {
+		return nil, errNoRoots
+	}
+	if len(policy.identities) == 0 && len(policy.issuers) == 0 {
+		return nil, errNoIdentity
+	}
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	bts = bytes.TrimSpace(bts)
+	if !bytes.HasPrefix(bts, []byte("-----BEGIN ")) {
+		if decoded, err := base64.StdEncoding.DecodeString(string(bts)); err == nil {
+			bts = decoded
+		}
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, bts = pem.Decode(bts)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         policy.roots,
+		Intermediates: intermediates,
+		CurrentTime:   certs[0].NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate chain: %w", err)
+	}
+	if err := verifyIdentity(policy, certs[0], chains[0]); err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// verifyIdentity checks that the given certificate has one of the expected
+// identities, matched against its subject alternative names, and one of the
+// expected issuers, matched against its OIDC issuer (as set by Fulcio) or the
+// common name of its issuer.
+func verifyIdentity(policy certPolicy, cert *x509.Certificate, chain []*x509.Certificate) error {
+	if len(policy.identities) > 0 {
+		var sans []string
+		sans = append(sans, cert.EmailAddresses...)
+		sans = append(sans, cert.DNSNames...)
+		for _, uri := range cert.URIs {
+			sans = append(sans, uri.String())
+		}
+		if !slices.ContainsFunc(sans, func(san string) bool {
+			return slices.Contains(policy.identities, san)
+		}) {
+			return fmt.Errorf("certificate identity mismatch: expected one of %v, got %v", policy.identities, sans)
+		}
+	}
+	if len(policy.issuers) > 0 {
+		var issuers []string
+		if issuer := oidcIssuer(cert); issuer != "" {
+			issuers = append(issuers, issuer)
+		}
+		if len(chain) > 1 {
+			issuers = append(issuers, chain[1].Subject.CommonName)
+		}
+		if !slices.ContainsFunc(issuers, func(issuer string) bool {
+			return slices.Contains(policy.issuers, issuer)
+		}) {
+			return fmt.Errorf("certificate issuer mismatch: expected one of %v, got %v", policy.issuers, issuers)
+		}
+	}
+	return nil
+}
+
+func oidcIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuerV1):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+func hashFile(h hash.Hash, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
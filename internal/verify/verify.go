@@ -0,0 +1,380 @@
+// Package verify verifies the checksums, signatures, and certificates of a
+// release, either from a dist directory or from a downloaded release.
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+)
+
+// Options of the verification.
+type Options struct {
+	// Dir is the dist directory or the directory of a downloaded release.
+	// It must contain an artifacts.json file.
+	Dir string
+	// Keys are the public keys to verify signatures against, either paths
+	// or contents.
+	Keys []string
+	// Roots are the paths to PEM-encoded root certificates to validate
+	// certificate chains against.
+	// Certificates can't be verified without them.
+	Roots []string
+	// Identities are the expected identities of certificates, matched
+	// against their subject alternative names (e.g. emails or URIs).
+	Identities []string
+	// Issuers are the expected issuers of certificates, matched against their
+	// OIDC issuer (as set by Fulcio) or the common name of their issuer.
+	// Certificates can't be verified without either an identity or an
+	// issuer.
+	Issuers []string
+	// SSHNamespace is the expected namespace of SSH signatures.
+	// Defaults to "file".
+	SSHNamespace string
+}
+
+// Check is the result of a single verification.
+type Check struct {
+	// Name is a short description of what was verified.
+	Name string
+	// Err is nil if the verification succeeded.
+	Err error
+}
+
+// Run verifies all the checksums, signatures, and certificates in the
+// release.
+//
+// The returned error is only set if the release could not be loaded at
+// all, the result of each check is in the returned [Check]s.
+func Run(opts Options) ([]Check, error) {
+	artifacts, err := load(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	kr, err := loadKeys(opts.Keys)
+	if err != nil {
+		return nil, err
+	}
+	kr.namespace = cmp.Or(opts.SSHNamespace, "file")
+	r := release{dir: opts.Dir, artifacts: artifacts}
+
+	var checks []Check
+	for _, a := range r.byType(artifact.Checksum) {
+		checks = append(checks, r.checksums(kr, a)...)
+	}
+
+	signatures := r.byType(artifact.Signature)
+	certificates := r.byType(artifact.Certificate)
+	if len(signatures) == 0 && len(certificates) == 0 {
+		return checks, nil
+	}
+
+	roots, err := loadRoots(opts.Roots)
+	if err != nil {
+		return nil, err
+	}
+	policy := certPolicy{
+		roots:      roots,
+		identities: opts.Identities,
+		issuers:    opts.Issuers,
+	}
+
+	used := map[string]bool{}
+	for _, sig := range signatures {
+		cert := certificateFor(certificates, sig)
+		check, usedCert := r.signature(kr, policy, sig, cert)
+		if usedCert {
+			used[cert.Name] = true
+		}
+		checks = append(checks, check)
+	}
+	for _, cert := range certificates {
+		if used[cert.Name] {
+			continue
+		}
+		check := Check{Name: "certificate " + cert.Name}
+		if path := r.path(*cert); path == "" {
+			check.Err = errNotFound
+		} else {
+			_, check.Err = verifyCertificate(policy, path)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+var errNotFound = errors.New("file not found")
+
+type release struct {
+	dir       string
+	artifacts []*artifact.Artifact
+}
+
+func load(dir string) ([]*artifact.Artifact, error) {
+	bts, err := os.ReadFile(filepath.Join(dir, "artifacts.json"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read artifacts: %w", err)
+	}
+	var artifacts []*artifact.Artifact
+	if err := json.Unmarshal(bts, &artifacts); err != nil {
+		return nil, fmt.Errorf("could not parse artifacts: %w", err)
+	}
+	return artifacts, nil
+}
+
+func (r release) byType(t artifact.Type) []*artifact.Artifact {
+	var result []*artifact.Artifact
+	for _, a := range r.artifacts {
+		if a.Type == t {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// path returns the path of the given artifact, looking for it by name in the
+// release directory first, and then by its original path.
+func (r release) path(a artifact.Artifact) string {
+	for _, path := range []string{
+		filepath.Join(r.dir, a.Name),
+		a.Path,
+	} {
+		if path == "" {
+			continue
+		}
+		if st, err := os.Stat(path); err == nil && !st.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// byName returns the artifact with the given name, if any.
+// Binaries are only used if nothing else has the same name.
+func (r release) byName(name string) *artifact.Artifact {
+	var result *artifact.Artifact
+	for _, a := range r.artifacts {
+		if a.Name != name {
+			continue
+		}
+		if result == nil || result.Type == artifact.Binary {
+			result = a
+		}
+	}
+	return result
+}
+
+// checksums verifies all the entries of the given checksums file.
+//
+// Both the GNU ("{checksum}  {filename}") and the BSD
+// ("{ALGORITHM} ({filename}) = {checksum}") formats are supported, as well as
+// split checksums files, which have only the checksum.
+// If the file is cleartext signed, its signature is verified as well.
+func (r release) checksums(kr *keyring, a *artifact.Artifact) []Check {
+	path := r.path(*a)
+	if path == "" {
+		return []Check{{Name: "checksums " + a.Name, Err: errNotFound}}
+	}
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return []Check{{Name: "checksums " + a.Name, Err: err}}
+	}
+
+	var checks []Check
+	if block, _ := clearsign.Decode(bts); block != nil {
+		checks = append(checks, Check{
+			Name: "signature " + a.Name + " [openpgp cleartext]",
+			Err:  verifyClearSign(kr, block),
+		})
+		bts = block.Bytes
+	}
+
+	var found bool
+	scanner := bufio.NewScanner(bytes.NewReader(bts))
+	for scanner.Scan() {
+		algorithm, name, sum, ok := parseChecksumLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		if name == "" {
+			signed := r.byPath(artifact.ExtraOr(*a, artifact.ExtraChecksumOf, ""))
+			if signed == nil {
+				continue
+			}
+			name = signed.Name
+		}
+		found = true
+		check := Check{Name: "checksum " + name}
+		if filepath.IsLocal(name) {
+			check.Err = r.checksum(filepath.Dir(path), name, algorithm, sum)
+		} else {
+			// don't read files outside the release directory.
+			check.Err = fmt.Errorf("invalid file name %q", name)
+		}
+		checks = append(checks, check)
+	}
+	if !found {
+		checks = append(checks, Check{Name: "checksums " + a.Name, Err: errors.New("no checksums found")})
+	}
+	return checks
+}
+
+var bsdChecksumLine = regexp.MustCompile(`^([A-Za-z0-9-]+) \((.+)\) = ([0-9a-fA-F]+)$`)
+
+// parseChecksumLine parses a line of a checksums file, returning the
+// algorithm (if known), file name (if any) and checksum.
+func parseChecksumLine(line string) (string, string, string, bool) {
+	line = strings.TrimSpace(line)
+	if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+		return strings.ToLower(m[1]), m[2], m[3], true
+	}
+	if sum, name, ok := strings.Cut(line, "  "); ok {
+		return "", name, sum, true
+	}
+	if sum, name, ok := strings.Cut(line, " *"); ok {
+		return "", name, sum, true
+	}
+	if _, err := hex.DecodeString(line); err == nil && line != "" {
+		return "", "", line, true
+	}
+	return "", "", "", false
+}
+
+func (r release) checksum(dir, name, algorithm, expected string) error {
+	target := artifact.Artifact{Name: name, Path: filepath.Join(dir, name)}
+	if a := r.byName(name); a != nil {
+		if alg, sum, ok := strings.Cut(artifact.ExtraOr(*a, artifact.ExtraChecksum, ""), ":"); ok && sum == expected && algorithm == "" {
+			algorithm = alg
+		}
+		if _, err := os.Stat(target.Path); err != nil {
+			target.Path = r.path(*a)
+		}
+	}
+	if _, err := os.Stat(target.Path); err != nil {
+		return errNotFound
+	}
+	if algorithm == "" {
+		algorithm = checksumAlgorithm(expected)
+	}
+	if algorithm == "" {
+		return fmt.Errorf("could not detect checksum algorithm of %q", expected)
+	}
+	sum, err := target.Checksum(algorithm)
+	if err != nil {
+		return err
+	}
+	if sum != expected {
+		return fmt.Errorf("%s checksum mismatch: expected %s, got %s", algorithm, expected, sum)
+	}
+	return nil
+}
+
+// byPath returns the artifact with the given path, if any.
+func (r release) byPath(path string) *artifact.Artifact {
+	if path == "" {
+		return nil
+	}
+	for _, a := range r.artifacts {
+		if a.Path == path && a.Type != artifact.Checksum {
+			return a
+		}
+	}
+	return nil
+}
+
+// checksumAlgorithm guesses the checksum algorithm by its length.
+// When multiple algorithms have the same length, the SHA-2 one is assumed.
+func checksumAlgorithm(sum string) string {
+	switch len(sum) {
+	case 8:
+		return "crc32"
+	case 32:
+		return "md5"
+	case 40:
+		return "sha1"
+	case 56:
+		return "sha224"
+	case 64:
+		return "sha256"
+	case 96:
+		return "sha384"
+	case 128:
+		return "sha512"
+	default:
+		return ""
+	}
+}
+
+// signedName returns the name of the artifact the given signature or
+// certificate is for.
+//
+// Older releases don't have it in the artifact metadata, in which case the
+// extension is removed from the name (e.g. foo.tar.gz.sig -> foo.tar.gz).
+func signedName(a *artifact.Artifact) string {
+	if name := artifact.ExtraOr(*a, artifact.ExtraSignatureOf, ""); name != "" {
+		return name
+	}
+	return strings.TrimSuffix(a.Name, filepath.Ext(a.Name))
+}
+
+// certificateFor returns the certificate for the same artifact as the given
+// signature, preferring the ones created by the same signing config.
+func certificateFor(certificates []*artifact.Artifact, sig *artifact.Artifact) *artifact.Artifact {
+	var result *artifact.Artifact
+	for _, cert := range certificates {
+		if signedName(cert) != signedName(sig) {
+			continue
+		}
+		if result == nil || (cert.ID() == sig.ID() && result.ID() != sig.ID()) {
+			result = cert
+		}
+	}
+	return result
+}
+
+// signature verifies the given signature.
+// The certificate is only used if the signature format doesn't have the
+// signer information, in which case the returned bool is true.
+func (r release) signature(kr *keyring, policy certPolicy, sig, cert *artifact.Artifact) (Check, bool) {
+	name := signedName(sig)
+	check := Check{Name: "signature " + sig.Name}
+	sigPath := r.path(*sig)
+	if sigPath == "" {
+		check.Err = errNotFound
+		return check, false
+	}
+	signed := r.byName(name)
+	if signed == nil {
+		check.Err = fmt.Errorf("signed artifact %q not found", name)
+		return check, false
+	}
+	path := r.path(*signed)
+	if path == "" {
+		check.Err = fmt.Errorf("%s: %w", name, errNotFound)
+		return check, false
+	}
+	var certPath string
+	if cert != nil {
+		certPath = r.path(*cert)
+	}
+	backend, err := verifySignature(kr, policy, path, sigPath, certPath)
+	if backend == backendX509 {
+		check.Name += " (certificate " + cert.Name + ")"
+	}
+	if backend != "" {
+		check.Name += " [" + backend + "]"
+	}
+	check.Err = err
+	return check, backend == backendX509
+}
@@ -0,0 +1,601 @@
+package verify
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ssh"
+)
+
+type keys struct {
+	openpgp     *openpgp.Entity
+	openpgpPub  string
+	minisign    ed25519.PrivateKey
+	minisignID  []byte
+	minisignPub string
+	ssh         ssh.Signer
+	sshPub      string
+	ecdsa       *ecdsa.PrivateKey
+	ecdsaPub    string
+	ca          *x509.Certificate
+	caKey       *ecdsa.PrivateKey
+	caPath      string
+}
+
+func newKeys(tb testing.TB) keys {
+	tb.Helper()
+	var k keys
+	dir := tb.TempDir()
+
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	require.NoError(tb, err)
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(tb, err)
+	require.NoError(tb, entity.Serialize(w))
+	require.NoError(tb, w.Close())
+	k.openpgp = entity
+	k.openpgpPub = buf.String()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(tb, err)
+	k.minisign = priv
+	k.minisignID = make([]byte, 8)
+	_, _ = rand.Read(k.minisignID)
+	k.minisignPub = "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(bytes.Join([][]byte{[]byte("Ed"), k.minisignID, pub}, nil)) + "\n"
+
+	_, sshPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(tb, err)
+	k.ssh, err = ssh.NewSignerFromKey(sshPriv)
+	require.NoError(tb, err)
+	k.sshPub = filepath.Join(dir, "id_ed25519.pub")
+	require.NoError(tb, os.WriteFile(k.sshPub, ssh.MarshalAuthorizedKey(k.ssh.PublicKey()), 0o644))
+
+	k.ecdsa, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	der, err := x509.MarshalPKIXPublicKey(&k.ecdsa.PublicKey)
+	require.NoError(tb, err)
+	k.ecdsaPub = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	k.caKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err = x509.CreateCertificate(rand.Reader, tmpl, tmpl, &k.caKey.PublicKey, k.caKey)
+	require.NoError(tb, err)
+	k.ca, err = x509.ParseCertificate(der)
+	require.NoError(tb, err)
+	k.caPath = filepath.Join(dir, "ca.pem")
+	require.NoError(tb, os.WriteFile(k.caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	return k
+}
+
+func (k keys) all() []string {
+	return []string{k.openpgpPub, k.minisignPub, k.sshPub, k.ecdsaPub}
+}
+
+func (k keys) signOpenPGP(tb testing.TB, data []byte) []byte {
+	tb.Helper()
+	var buf bytes.Buffer
+	require.NoError(tb, openpgp.ArmoredDetachSign(&buf, k.openpgp, bytes.NewReader(data), nil))
+	return buf.Bytes()
+}
+
+func (k keys) signMinisign(tb testing.TB, data []byte) []byte {
+	tb.Helper()
+	sum := blake2b.Sum512(data)
+	sig := ed25519.Sign(k.minisign, sum[:])
+	trusted := "timestamp:1\tfile:foo\thashed"
+	global := ed25519.Sign(k.minisign, append(bytes.Clone(sig), trusted...))
+	return fmt.Appendf(
+		nil,
+		"untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(bytes.Join([][]byte{[]byte("ED"), k.minisignID, sig}, nil)),
+		trusted,
+		base64.StdEncoding.EncodeToString(global),
+	)
+}
+
+func (k keys) signSSH(tb testing.TB, data []byte) []byte {
+	tb.Helper()
+	sum := sha512.Sum512(data)
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Hash      []byte
+	}{"file", "", "sha512", sum[:]})...)
+	sig, err := k.ssh.Sign(rand.Reader, signed)
+	require.NoError(tb, err)
+	blob := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Signature []byte
+	}{1, k.ssh.PublicKey().Marshal(), "file", "", "sha512", ssh.Marshal(sig)})...)
+	return pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob})
+}
+
+func signECDSA(tb testing.TB, key *ecdsa.PrivateKey, data []byte) []byte {
+	tb.Helper()
+	sum := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	require.NoError(tb, err)
+	return []byte(base64.StdEncoding.EncodeToString(sig))
+}
+
+const (
+	testIdentity = "https://github.com/foo/bar/.github/workflows/release.yml@refs/tags/v1.0.0"
+	testIssuer   = "https://token.actions.githubusercontent.com"
+)
+
+// certificate creates a short-lived leaf certificate, the way cosign
+// keyless signing does, and returns it base64 encoded.
+func (k keys) certificate(tb testing.TB, key *ecdsa.PrivateKey) []byte {
+	tb.Helper()
+	identity, err := url.Parse(testIdentity)
+	require.NoError(tb, err)
+	issuer, err := asn1.Marshal(testIssuer)
+	require.NoError(tb, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "signer"},
+		NotBefore:    time.Now().Add(-30 * time.Minute),
+		NotAfter:     time.Now().Add(-20 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:         []*url.URL{identity},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidIssuerV2, Value: issuer},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, k.ca, &key.PublicKey, k.caKey)
+	require.NoError(tb, err)
+	return []byte(base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+}
+
+type releaseDir struct {
+	dir       string
+	artifacts []*artifact.Artifact
+}
+
+func (r *releaseDir) add(tb testing.TB, typ artifact.Type, name string, content []byte, extra map[string]any) {
+	tb.Helper()
+	require.NoError(tb, os.WriteFile(filepath.Join(r.dir, name), content, 0o644))
+	r.artifacts = append(r.artifacts, &artifact.Artifact{
+		Type:  typ,
+		Name:  name,
+		Path:  filepath.Join("dist", name),
+		Extra: extra,
+	})
+}
+
+func (r *releaseDir) write(tb testing.TB) {
+	tb.Helper()
+	bts, err := json.Marshal(r.artifacts)
+	require.NoError(tb, err)
+	require.NoError(tb, os.WriteFile(filepath.Join(r.dir, "artifacts.json"), bts, 0o644))
+}
+
+func sha256sum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%x", sum)
+}
+
+func newRelease(tb testing.TB, k keys) *releaseDir {
+	tb.Helper()
+	r := &releaseDir{dir: tb.TempDir()}
+	archive := []byte("archive")
+	pkg := []byte("package")
+	bin := []byte("binary")
+	checksums := []byte(sha256sum(archive) + "  foo.tar.gz\n" + sha256sum(pkg) + "  foo.deb\n")
+
+	r.add(tb, artifact.UploadableArchive, "foo.tar.gz", archive, map[string]any{
+		artifact.ExtraChecksum: "sha256:" + sha256sum(archive),
+	})
+	r.add(tb, artifact.LinuxPackage, "foo.deb", pkg, nil)
+	r.add(tb, artifact.UploadableBinary, "foo", bin, nil)
+	r.add(tb, artifact.Checksum, "checksums.txt", checksums, nil)
+	r.add(tb, artifact.Checksum, "foo.sha512", fmt.Appendf(nil, "%x  foo\n", sha512.Sum512(bin)), map[string]any{
+		artifact.ExtraChecksumOf: "dist/foo",
+	})
+
+	r.add(tb, artifact.Signature, "checksums.txt.asc", k.signOpenPGP(tb, checksums), map[string]any{
+		artifact.ExtraSignatureOf: "checksums.txt",
+	})
+	r.add(tb, artifact.Signature, "foo.tar.gz.minisig", k.signMinisign(tb, archive), map[string]any{
+		artifact.ExtraSignatureOf: "foo.tar.gz",
+	})
+	// no SignatureOf, like older releases.
+	r.add(tb, artifact.Signature, "foo.deb.sig", k.signSSH(tb, pkg), nil)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	r.add(tb, artifact.Signature, "checksums.txt.sig", signECDSA(tb, leafKey, checksums), map[string]any{
+		artifact.ExtraSignatureOf: "checksums.txt",
+	})
+	r.add(tb, artifact.Certificate, "checksums.txt.pem", k.certificate(tb, leafKey), map[string]any{
+		artifact.ExtraSignatureOf: "checksums.txt",
+	})
+	r.add(tb, artifact.Signature, "foo.cosign.sig", signECDSA(tb, k.ecdsa, bin), map[string]any{
+		artifact.ExtraSignatureOf: "foo",
+	})
+	r.write(tb)
+	return r
+}
+
+func requireChecks(tb testing.TB, checks []Check, failed map[string]string) {
+	tb.Helper()
+	names := map[string]bool{}
+	for _, c := range checks {
+		names[c.Name] = true
+		if expected, ok := failed[c.Name]; ok {
+			require.ErrorContains(tb, c.Err, expected, c.Name)
+			continue
+		}
+		require.NoError(tb, c.Err, c.Name)
+	}
+	for name := range failed {
+		require.True(tb, names[name], "check %q not found", name)
+	}
+}
+
+func TestRun(t *testing.T) {
+	k := newKeys(t)
+
+	t.Run("valid", func(t *testing.T) {
+		r := newRelease(t, k)
+		checks, err := Run(Options{Dir: r.dir, Keys: k.all(), Roots: []string{k.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		var names []string
+		for _, c := range checks {
+			require.NoError(t, c.Err, c.Name)
+			names = append(names, c.Name)
+		}
+		require.Equal(t, []string{
+			"checksum foo.tar.gz",
+			"checksum foo.deb",
+			"checksum foo",
+			"signature checksums.txt.asc [openpgp]",
+			"signature foo.tar.gz.minisig [minisign]",
+			"signature foo.deb.sig [ssh]",
+			"signature checksums.txt.sig (certificate checksums.txt.pem) [x509]",
+			"signature foo.cosign.sig [pkix]",
+		}, names)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		r := newRelease(t, k)
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, "foo.tar.gz"), []byte("evil"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, "foo"), []byte("evil"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, "foo.deb"), []byte("evil"), 0o644))
+		checks, err := Run(Options{Dir: r.dir, Keys: k.all(), Roots: []string{k.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"checksum foo.tar.gz":                     "sha256 checksum mismatch",
+			"checksum foo.deb":                        "sha256 checksum mismatch",
+			"checksum foo":                            "sha512 checksum mismatch",
+			"signature foo.tar.gz.minisig [minisign]": "invalid signature",
+			"signature foo.deb.sig [ssh]":             "ssh: signature did not verify",
+			"signature foo.cosign.sig [pkix]":         "invalid signature",
+		})
+	})
+
+	t.Run("tampered checksums", func(t *testing.T) {
+		r := newRelease(t, k)
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, "checksums.txt"), []byte(sha256sum([]byte("evil"))+"  foo.tar.gz\n"), 0o644))
+		checks, err := Run(Options{Dir: r.dir, Keys: k.all(), Roots: []string{k.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"checksum foo.tar.gz":                                                "checksum mismatch",
+			"signature checksums.txt.asc [openpgp]":                              "signature",
+			"signature checksums.txt.sig (certificate checksums.txt.pem) [x509]": "invalid signature",
+		})
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		r := newRelease(t, k)
+		require.NoError(t, os.Remove(filepath.Join(r.dir, "foo.deb")))
+		require.NoError(t, os.Remove(filepath.Join(r.dir, "foo.cosign.sig")))
+		checks, err := Run(Options{Dir: r.dir, Keys: k.all(), Roots: []string{k.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"checksum foo.deb":         "file not found",
+			"signature foo.deb.sig":    "foo.deb: file not found",
+			"signature foo.cosign.sig": "file not found",
+		})
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		r := newRelease(t, k)
+		checks, err := Run(Options{Dir: r.dir, Roots: []string{k.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"signature checksums.txt.asc [openpgp]":   "no public keys given",
+			"signature foo.tar.gz.minisig [minisign]": "no public keys given",
+			"signature foo.deb.sig [ssh]":             "no public keys given",
+			"signature foo.cosign.sig":                "unsupported signature format",
+		})
+	})
+
+	t.Run("wrong keys", func(t *testing.T) {
+		other := newKeys(t)
+		r := newRelease(t, k)
+		checks, err := Run(Options{Dir: r.dir, Keys: other.all(), Roots: []string{other.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"signature checksums.txt.asc [openpgp]":                              "signature made by unknown entity",
+			"signature foo.tar.gz.minisig [minisign]":                            "no matching public key found",
+			"signature foo.deb.sig [ssh]":                                        "no matching public key found",
+			"signature foo.cosign.sig [pkix]":                                    "invalid signature",
+			"signature checksums.txt.sig (certificate checksums.txt.pem) [x509]": "invalid certificate chain",
+		})
+	})
+
+	t.Run("certificate only", func(t *testing.T) {
+		r := &releaseDir{dir: t.TempDir()}
+		leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		r.add(t, artifact.Certificate, "foo.pem", k.certificate(t, leafKey), nil)
+		r.write(t)
+
+		checks, err := Run(Options{Dir: r.dir, Roots: []string{k.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		requireChecks(t, checks, nil)
+		require.Len(t, checks, 1)
+
+		checks, err = Run(Options{Dir: r.dir, Roots: []string{newKeys(t).caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"certificate foo.pem": "invalid certificate chain",
+		})
+	})
+
+	t.Run("files by path", func(t *testing.T) {
+		r := newRelease(t, k)
+		dir := t.TempDir()
+		for _, a := range r.artifacts {
+			a.Path = filepath.Join(r.dir, a.Name)
+		}
+		r.dir = dir
+		r.write(t)
+		checks, err := Run(Options{Dir: dir, Keys: k.all(), Roots: []string{k.caPath}, Identities: []string{testIdentity}})
+		require.NoError(t, err)
+		require.Len(t, checks, 8)
+		requireChecks(t, checks, nil)
+	})
+
+	t.Run("no artifacts", func(t *testing.T) {
+		_, err := Run(Options{Dir: t.TempDir()})
+		require.ErrorContains(t, err, "could not read artifacts")
+	})
+
+	t.Run("invalid artifacts", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "artifacts.json"), []byte("{"), 0o644))
+		_, err := Run(Options{Dir: dir})
+		require.ErrorContains(t, err, "could not parse artifacts")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		r := newRelease(t, k)
+		_, err := Run(Options{Dir: r.dir, Keys: []string{"-----BEGIN PUBLIC KEY-----\nfoo\n-----END PUBLIC KEY-----"}})
+		require.ErrorContains(t, err, "invalid public key")
+		_, err = Run(Options{Dir: r.dir, Keys: []string{filepath.Join(r.dir, "foo")}})
+		require.ErrorContains(t, err, "unsupported public key")
+		_, err = Run(Options{Dir: r.dir, Keys: []string{filepath.Join(r.dir, "nope")}})
+		require.ErrorContains(t, err, "could not read key")
+	})
+
+	t.Run("invalid roots", func(t *testing.T) {
+		r := newRelease(t, k)
+		_, err := Run(Options{Dir: r.dir, Roots: []string{filepath.Join(r.dir, "foo")}})
+		require.ErrorContains(t, err, "no certificates found")
+	})
+
+	t.Run("certificate policy", func(t *testing.T) {
+		r := newRelease(t, k)
+		const name = "signature checksums.txt.sig (certificate checksums.txt.pem) [x509]"
+		for desc, tt := range map[string]struct {
+			opts     Options
+			expected string
+		}{
+			"issuer only":    {opts: Options{Roots: []string{k.caPath}, Issuers: []string{testIssuer}}},
+			"both":           {opts: Options{Roots: []string{k.caPath}, Identities: []string{testIdentity}, Issuers: []string{testIssuer, "test ca"}}},
+			"issuer name":    {opts: Options{Roots: []string{k.caPath}, Issuers: []string{"test ca"}}},
+			"no roots":       {opts: Options{Identities: []string{testIdentity}}, expected: "no root certificates given"},
+			"no identity":    {opts: Options{Roots: []string{k.caPath}}, expected: "no certificate identity or issuer given"},
+			"wrong identity": {opts: Options{Roots: []string{k.caPath}, Identities: []string{"foo@example.com"}}, expected: "certificate identity mismatch"},
+			"wrong issuer":   {opts: Options{Roots: []string{k.caPath}, Identities: []string{testIdentity}, Issuers: []string{"https://accounts.google.com"}}, expected: "certificate issuer mismatch"},
+		} {
+			t.Run(desc, func(t *testing.T) {
+				tt.opts.Dir = r.dir
+				tt.opts.Keys = k.all()
+				checks, err := Run(tt.opts)
+				require.NoError(t, err)
+				if tt.expected == "" {
+					requireChecks(t, checks, nil)
+					return
+				}
+				requireChecks(t, checks, map[string]string{name: tt.expected})
+			})
+		}
+	})
+
+	t.Run("ssh namespace", func(t *testing.T) {
+		r := newRelease(t, k)
+		checks, err := Run(Options{Dir: r.dir, Keys: k.all(), Roots: []string{k.caPath}, Identities: []string{testIdentity}, SSHNamespace: "git"})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"signature foo.deb.sig [ssh]": `ssh signature namespace mismatch: expected "git", got "file"`,
+		})
+	})
+
+	t.Run("checksums outside release", func(t *testing.T) {
+		r := &releaseDir{dir: filepath.Join(t.TempDir(), "release")}
+		require.NoError(t, os.Mkdir(r.dir, 0o755))
+		outside := []byte("outside")
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(r.dir), "outside"), outside, 0o644))
+		r.add(t, artifact.Checksum, "checksums.txt", []byte(
+			sha256sum(outside)+"  ../outside\n"+
+				sha256sum(outside)+"  "+filepath.Join(filepath.Dir(r.dir), "outside")+"\n",
+		), nil)
+		r.write(t)
+		checks, err := Run(Options{Dir: r.dir})
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+		for _, c := range checks {
+			require.ErrorContains(t, c.Err, "invalid file name", c.Name)
+		}
+	})
+}
+
+func TestRunChecksumFormats(t *testing.T) {
+	k := newKeys(t)
+	archive := []byte("archive")
+	sha512sum := fmt.Sprintf("%x", sha512.Sum512(archive))
+
+	newFormatRelease := func(tb testing.TB, name string, checksums []byte, extra map[string]any) *releaseDir {
+		tb.Helper()
+		r := &releaseDir{dir: tb.TempDir()}
+		r.add(tb, artifact.UploadableArchive, "foo.tar.gz", archive, nil)
+		r.add(tb, artifact.Checksum, name, checksums, extra)
+		r.write(tb)
+		return r
+	}
+
+	t.Run("bsd", func(t *testing.T) {
+		r := newFormatRelease(t, "checksums.txt", []byte(
+			"SHA256 (foo.tar.gz) = "+sha256sum(archive)+"\n"+
+				"SHA512 (foo.tar.gz) = "+sha512sum+"\n",
+		), nil)
+		checks, err := Run(Options{Dir: r.dir})
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+		requireChecks(t, checks, nil)
+
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, "foo.tar.gz"), []byte("evil"), 0o644))
+		checks, err = Run(Options{Dir: r.dir})
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+		require.ErrorContains(t, checks[0].Err, "sha256 checksum mismatch")
+		require.ErrorContains(t, checks[1].Err, "sha512 checksum mismatch")
+	})
+
+	t.Run("split", func(t *testing.T) {
+		r := newFormatRelease(t, "foo.tar.gz.sha512", []byte(sha512sum), map[string]any{
+			artifact.ExtraChecksumOf: "dist/foo.tar.gz",
+		})
+		checks, err := Run(Options{Dir: r.dir})
+		require.NoError(t, err)
+		require.Equal(t, []Check{{Name: "checksum foo.tar.gz"}}, checks)
+	})
+
+	t.Run("clearsigned", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := clearsign.Encode(&buf, k.openpgp.PrivateKey, nil)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(sha256sum(archive) + "  foo.tar.gz\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		r := newFormatRelease(t, "checksums.txt", buf.Bytes(), nil)
+
+		checks, err := Run(Options{Dir: r.dir, Keys: []string{k.openpgpPub}})
+		require.NoError(t, err)
+		require.Equal(t, []Check{
+			{Name: "signature checksums.txt [openpgp cleartext]"},
+			{Name: "checksum foo.tar.gz"},
+		}, checks)
+
+		checks, err = Run(Options{Dir: r.dir})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"signature checksums.txt [openpgp cleartext]": "no public keys given",
+		})
+
+		tampered := bytes.Replace(buf.Bytes(), []byte("foo.tar.gz"), []byte("bar.tar.gz"), 1)
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, "checksums.txt"), tampered, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, "bar.tar.gz"), archive, 0o644))
+		checks, err = Run(Options{Dir: r.dir, Keys: []string{k.openpgpPub}})
+		require.NoError(t, err)
+		requireChecks(t, checks, map[string]string{
+			"signature checksums.txt [openpgp cleartext]": "signature",
+		})
+	})
+}
+
+func TestParseChecksumLine(t *testing.T) {
+	for line, expected := range map[string][4]string{
+		"abcd  foo.tar.gz":          {"", "foo.tar.gz", "abcd"},
+		"abcd *foo.tar.gz":          {"", "foo.tar.gz", "abcd"},
+		"SHA3-256 (foo bar) = abcd": {"sha3-256", "foo bar", "abcd"},
+		"BLAKE2b (foo) = abcd":      {"blake2b", "foo", "abcd"},
+		"abcd\n":                    {"", "", "abcd"},
+	} {
+		alg, name, sum, ok := parseChecksumLine(line)
+		require.True(t, ok, line)
+		require.Equal(t, expected[:3], []string{alg, name, sum}, line)
+	}
+	for _, line := range []string{"", "not a checksum", "-----BEGIN PGP SIGNATURE-----"} {
+		_, _, _, ok := parseChecksumLine(line)
+		require.False(t, ok, line)
+	}
+}
+
+func TestChecksumAlgorithm(t *testing.T) {
+	for alg, size := range map[string]int{
+		"crc32":  4,
+		"md5":    16,
+		"sha1":   20,
+		"sha224": 28,
+		"sha256": 32,
+		"sha384": 48,
+		"sha512": 64,
+	} {
+		require.Equal(t, alg, checksumAlgorithm(strings.Repeat("ab", size)))
+	}
+	require.Empty(t, checksumAlgorithm("abc"))
+}
+
+func TestSignedName(t *testing.T) {
+	require.Equal(t, "foo.tar.gz", signedName(&artifact.Artifact{Name: "foo.tar.gz.sig"}))
+	require.Equal(t, "bar", signedName(&artifact.Artifact{
+		Name:  "foo.tar.gz.sig",
+		Extra: map[string]any{artifact.ExtraSignatureOf: "bar"},
+	}))
+}
@@ -0,0 +1,350 @@
+// Package xar reads and writes xar archives, the container format used by
+// macOS flat installer packages.
+//
+// Only the subset needed to create installer packages is implemented:
+// uncompressed file data and SHA-1 checksums.
+//
+// See: https://github.com/apple-oss-distributions/xar
+package xar
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	magic      = 0x78617221 // "xar!"
+	headerSize = 28
+	version    = 1
+	cksumSHA1  = 1
+
+	timeFormat = "2006-01-02T15:04:05Z"
+)
+
+// File is a file or directory inside a xar archive.
+type File struct {
+	// Name is the slash separated path of the file inside the archive.
+	Name    string
+	Mode    fs.FileMode
+	ModTime time.Time
+	// Data is the file contents, ignored for directories.
+	Data []byte
+}
+
+// Archive is a xar archive read with [Read].
+type Archive struct {
+	Files        []File
+	CreationTime time.Time
+}
+
+// Write writes a xar archive with the given files to w.
+//
+// Parent directories are created as needed.
+func Write(w io.Writer, files []File, created time.Time) error {
+	root, err := tree(files)
+	if err != nil {
+		return err
+	}
+
+	heap := bytes.Buffer{}
+	heap.Write(make([]byte, sha1.Size))
+
+	toc := xmlTOC{
+		CreationTime: created.UTC().Format("2006-01-02T15:04:05"),
+		Checksum: xmlChecksum{
+			Style:  "sha1",
+			Offset: 0,
+			Size:   sha1.Size,
+		},
+	}
+
+	id := 0
+	toc.Files = root.toXML(&id, &heap)
+
+	bts, err := xml.MarshalIndent(xmlXar{TOC: toc}, "", " ")
+	if err != nil {
+		return fmt.Errorf("xar: %w", err)
+	}
+	bts = append([]byte(xml.Header), bts...)
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(bts); err != nil {
+		return fmt.Errorf("xar: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("xar: %w", err)
+	}
+
+	digest := sha1.Sum(compressed.Bytes())
+	heapBts := heap.Bytes()
+	copy(heapBts, digest[:])
+
+	header := make([]byte, headerSize)
+	binary.BigEndian.PutUint32(header[0:], magic)
+	binary.BigEndian.PutUint16(header[4:], headerSize)
+	binary.BigEndian.PutUint16(header[6:], version)
+	binary.BigEndian.PutUint64(header[8:], uint64(compressed.Len()))
+	binary.BigEndian.PutUint64(header[16:], uint64(len(bts)))
+	binary.BigEndian.PutUint32(header[24:], cksumSHA1)
+
+	for _, b := range [][]byte{header, compressed.Bytes(), heapBts} {
+		if _, err := w.Write(b); err != nil {
+			return fmt.Errorf("xar: %w", err)
+		}
+	}
+	return nil
+}
+
+// Read reads a xar archive.
+//
+// Checksums are verified, but signatures are not.
+func Read(bts []byte) (*Archive, error) {
+	if len(bts) < headerSize || binary.BigEndian.Uint32(bts) != magic {
+		return nil, errors.New("xar: not a xar archive")
+	}
+	hsize := int(binary.BigEndian.Uint16(bts[4:]))
+	compressedLen := binary.BigEndian.Uint64(bts[8:])
+	if binary.BigEndian.Uint32(bts[24:]) != cksumSHA1 {
+		return nil, errors.New("xar: unsupported checksum algorithm")
+	}
+	if uint64(len(bts)) < uint64(hsize)+compressedLen {
+		return nil, errors.New("xar: truncated archive")
+	}
+	compressed := bts[hsize : uint64(hsize)+compressedLen]
+	heap := bts[uint64(hsize)+compressedLen:]
+
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("xar: %w", err)
+	}
+	defer zr.Close()
+	var x xmlXar
+	if err := xml.NewDecoder(zr).Decode(&x); err != nil {
+		return nil, fmt.Errorf("xar: %w", err)
+	}
+
+	digest := sha1.Sum(compressed)
+	sum, err := slice(heap, x.TOC.Checksum.Offset, x.TOC.Checksum.Size)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sum, digest[:]) {
+		return nil, errors.New("xar: table of contents checksum mismatch")
+	}
+
+	created, _ := time.Parse("2006-01-02T15:04:05", x.TOC.CreationTime)
+	archive := &Archive{CreationTime: created}
+	if err := readFiles(archive, heap, "", x.TOC.Files); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+func readFiles(archive *Archive, heap []byte, parent string, files []*xmlFile) error {
+	for _, f := range files {
+		name := path.Join(parent, f.Name)
+		var mode uint32
+		if _, err := fmt.Sscanf(f.Mode, "%o", &mode); err != nil {
+			return fmt.Errorf("xar: %s: invalid mode %q", name, f.Mode)
+		}
+		mtime, _ := time.Parse(timeFormat, f.MTime)
+		file := File{
+			Name:    name,
+			Mode:    fs.FileMode(mode) & fs.ModePerm,
+			ModTime: mtime,
+		}
+		switch f.Type {
+		case "directory":
+			file.Mode |= fs.ModeDir
+			archive.Files = append(archive.Files, file)
+			if err := readFiles(archive, heap, name, f.Files); err != nil {
+				return err
+			}
+			continue
+		case "file":
+		default:
+			return fmt.Errorf("xar: %s: unsupported file type %q", name, f.Type)
+		}
+		if f.Data != nil {
+			if f.Data.Encoding.Style != "application/octet-stream" {
+				return fmt.Errorf("xar: %s: unsupported encoding %q", name, f.Data.Encoding.Style)
+			}
+			data, err := slice(heap, f.Data.Offset, f.Data.Length)
+			if err != nil {
+				return err
+			}
+			sum := sha1.Sum(data)
+			if hex.EncodeToString(sum[:]) != f.Data.ExtractedChecksum.Value {
+				return fmt.Errorf("xar: %s: checksum mismatch", name)
+			}
+			file.Data = data
+		}
+		archive.Files = append(archive.Files, file)
+	}
+	return nil
+}
+
+func slice(heap []byte, offset, size int64) ([]byte, error) {
+	if offset < 0 || size < 0 || offset+size > int64(len(heap)) {
+		return nil, errors.New("xar: data out of bounds")
+	}
+	return heap[offset : offset+size], nil
+}
+
+// node is a file in the archive tree.
+type node struct {
+	file     File
+	children []*node
+}
+
+func tree(files []File) (*node, error) {
+	root := &node{}
+	dirs := map[string]*node{"": root}
+	var mkdir func(name string, mtime time.Time) *node
+	mkdir = func(name string, mtime time.Time) *node {
+		if n, ok := dirs[name]; ok {
+			return n
+		}
+		parent := mkdir(parentOf(name), mtime)
+		n := &node{file: File{
+			Name:    name,
+			Mode:    fs.ModeDir | 0o755,
+			ModTime: mtime,
+		}}
+		parent.children = append(parent.children, n)
+		dirs[name] = n
+		return n
+	}
+
+	for _, f := range files {
+		name := strings.Trim(path.Clean(f.Name), "/")
+		if name == "" || name == "." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("xar: invalid file name %q", f.Name)
+		}
+		f.Name = name
+		if f.Mode.IsDir() {
+			n := mkdir(name, f.ModTime)
+			n.file = f
+			continue
+		}
+		if _, ok := dirs[name]; ok {
+			return nil, fmt.Errorf("xar: %s: is a directory", name)
+		}
+		parent := mkdir(parentOf(name), f.ModTime)
+		if slices.ContainsFunc(parent.children, func(n *node) bool { return n.file.Name == name }) {
+			return nil, fmt.Errorf("xar: %s: duplicated file", name)
+		}
+		parent.children = append(parent.children, &node{file: f})
+	}
+	return root, nil
+}
+
+func parentOf(name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+func (n *node) toXML(id *int, heap *bytes.Buffer) []*xmlFile {
+	result := make([]*xmlFile, 0, len(n.children))
+	for _, child := range n.children {
+		*id++
+		f := child.file
+		mtime := f.ModTime.UTC().Format(timeFormat)
+		x := &xmlFile{
+			ID:    *id,
+			CTime: mtime,
+			MTime: mtime,
+			ATime: mtime,
+			Group: "wheel",
+			User:  "root",
+			Mode:  fmt.Sprintf("%04o", f.Mode.Perm()),
+			Type:  "file",
+			Name:  path.Base(f.Name),
+		}
+		if f.Mode.IsDir() {
+			x.Type = "directory"
+			x.Files = child.toXML(id, heap)
+		} else {
+			sum := sha1.Sum(f.Data)
+			hash := hex.EncodeToString(sum[:])
+			x.Data = &xmlData{
+				Length:            int64(len(f.Data)),
+				Offset:            int64(heap.Len()),
+				Size:              int64(len(f.Data)),
+				Encoding:          xmlEncoding{Style: "application/octet-stream"},
+				ExtractedChecksum: xmlHash{Style: "sha1", Value: hash},
+				ArchivedChecksum:  xmlHash{Style: "sha1", Value: hash},
+			}
+			heap.Write(f.Data)
+		}
+		result = append(result, x)
+	}
+	return result
+}
+
+type xmlXar struct {
+	XMLName xml.Name `xml:"xar"`
+	TOC     xmlTOC   `xml:"toc"`
+}
+
+type xmlTOC struct {
+	CreationTime string      `xml:"creation-time"`
+	Checksum     xmlChecksum `xml:"checksum"`
+	Files        []*xmlFile  `xml:"file"`
+}
+
+type xmlChecksum struct {
+	Style  string `xml:"style,attr"`
+	Offset int64  `xml:"offset"`
+	Size   int64  `xml:"size"`
+}
+
+type xmlFile struct {
+	ID    int        `xml:"id,attr"`
+	Data  *xmlData   `xml:"data,omitempty"`
+	CTime string     `xml:"ctime"`
+	MTime string     `xml:"mtime"`
+	ATime string     `xml:"atime"`
+	Group string     `xml:"group"`
+	GID   int        `xml:"gid"`
+	User  string     `xml:"user"`
+	UID   int        `xml:"uid"`
+	Mode  string     `xml:"mode"`
+	Type  string     `xml:"type"`
+	Name  string     `xml:"name"`
+	Files []*xmlFile `xml:"file"`
+}
+
+type xmlData struct {
+	Length            int64       `xml:"length"`
+	Offset            int64       `xml:"offset"`
+	Size              int64       `xml:"size"`
+	Encoding          xmlEncoding `xml:"encoding"`
+	ExtractedChecksum xmlHash     `xml:"extracted-checksum"`
+	ArchivedChecksum  xmlHash     `xml:"archived-checksum"`
+}
+
+type xmlEncoding struct {
+	Style string `xml:"style,attr"`
+}
+
+type xmlHash struct {
+	Style string `xml:"style,attr"`
+	Value string `xml:",chardata"`
+}
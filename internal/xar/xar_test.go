@@ -0,0 +1,95 @@
+package xar
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testFiles() []File {
+	return []File{
+		{Name: "Distribution", Mode: 0o644, ModTime: now, Data: []byte("<xml/>")},
+		{Name: "foo.pkg/Payload", Mode: 0o644, ModTime: now, Data: []byte("payload")},
+		{Name: "foo.pkg/Scripts/postinstall", Mode: 0o755, ModTime: now, Data: []byte("#!/bin/sh")},
+		{Name: "foo.pkg/empty", Mode: 0o600, ModTime: now},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testFiles(), now))
+
+	archive, err := Read(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, now, archive.CreationTime)
+	require.Equal(t, []File{
+		{Name: "Distribution", Mode: 0o644, ModTime: now, Data: []byte("<xml/>")},
+		{Name: "foo.pkg", Mode: fs.ModeDir | 0o755, ModTime: now},
+		{Name: "foo.pkg/Payload", Mode: 0o644, ModTime: now, Data: []byte("payload")},
+		{Name: "foo.pkg/Scripts", Mode: fs.ModeDir | 0o755, ModTime: now},
+		{Name: "foo.pkg/Scripts/postinstall", Mode: 0o755, ModTime: now, Data: []byte("#!/bin/sh")},
+		{Name: "foo.pkg/empty", Mode: 0o600, ModTime: now, Data: []byte{}},
+	}, archive.Files)
+
+	t.Run("rewrite", func(t *testing.T) {
+		var again bytes.Buffer
+		require.NoError(t, Write(&again, archive.Files, archive.CreationTime))
+		require.Equal(t, buf.Bytes(), again.Bytes())
+	})
+}
+
+func TestWriteErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		files []File
+		err   string
+	}{
+		"empty name": {
+			files: []File{{Name: "/"}},
+			err:   `xar: invalid file name "/"`,
+		},
+		"outside": {
+			files: []File{{Name: "../foo"}},
+			err:   `xar: invalid file name "../foo"`,
+		},
+		"duplicated": {
+			files: []File{{Name: "foo"}, {Name: "foo"}},
+			err:   "xar: foo: duplicated file",
+		},
+		"file over directory": {
+			files: []File{{Name: "foo/bar"}, {Name: "foo"}},
+			err:   "xar: foo: is a directory",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.EqualError(t, Write(&bytes.Buffer{}, tc.files, now), tc.err)
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testFiles(), now))
+	valid := buf.Bytes()
+
+	t.Run("not xar", func(t *testing.T) {
+		_, err := Read([]byte("not a xar archive at all, really"))
+		require.EqualError(t, err, "xar: not a xar archive")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := Read(valid[:40])
+		require.EqualError(t, err, "xar: truncated archive")
+	})
+
+	t.Run("bad checksum", func(t *testing.T) {
+		bts := bytes.Clone(valid)
+		bts[len(bts)-1] ^= 0xff
+		_, err := Read(bts)
+		require.EqualError(t, err, "xar: foo.pkg/Scripts/postinstall: checksum mismatch")
+	})
+}
@@ -16,11 +16,17 @@ type Versioned struct {
 
 // Git configs.
 type Git struct {
-	TagSort          string   `yaml:"tag_sort,omitempty" json:"tag_sort,omitempty" jsonschema:"enum=-version:refname,enum=-version:creatordate,default=-version:refname"`
+	TagSort          string   `yaml:"tag_sort,omitempty" json:"tag_sort,omitempty" jsonschema:"enum=-version:refname,enum=-version:creatordate,enum=calver,default=-version:refname"`
 	PrereleaseSuffix string   `yaml:"prerelease_suffix,omitempty" json:"prerelease_suffix,omitempty"`
 	IgnoreTags       []string `yaml:"ignore_tags,omitempty" json:"ignore_tags,omitempty"`
 }
 
+// Versioning configures how the current tag is parsed.
+type Versioning struct {
+	Mode   string `yaml:"mode,omitempty" json:"mode,omitempty" jsonschema:"enum=semver,enum=calver,default=semver"`
+	Scheme string `yaml:"scheme,omitempty" json:"scheme,omitempty" jsonschema:"default=YYYY.0M.MICRO"`
+}
+
 // GitHubURLs holds the URLs to be used when using github enterprise.
 type GitHubURLs struct {
 	API           string `yaml:"api,omitempty" json:"api,omitempty"`
@@ -212,6 +218,9 @@ type Homebrew struct {
 	Goarm                 string               `yaml:"goarm,omitempty" json:"goarm,omitempty" jsonschema:"oneof_type=string;integer"`
 	Goamd64               string               `yaml:"goamd64,omitempty" json:"goamd64,omitempty"`
 	Service               string               `yaml:"service,omitempty" json:"service,omitempty"`
+
+	// v2.18+
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
 }
 
 // HomebrewCask contains the homebrew_casks section.
@@ -249,6 +258,9 @@ type HomebrewCask struct {
 
 	// Deprecated: use [HomebrewCask.Binaries] instead.
 	Binary string `yaml:"binary,omitempty" json:"binary,omitempty" jsonschema:"deprecated=true"`
+
+	// v2.18+
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
 }
 
 type HomebrewCaskURL struct {
@@ -372,6 +384,9 @@ type Winget struct {
 	Tags                  []string           `yaml:"tags,omitempty" json:"tags,omitempty"`
 	Dependencies          []WingetDependency `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
 	AdditionalLocales     []WingetLocale     `yaml:"additional_locales,omitempty" json:"additional_locales,omitempty"`
+
+	// v2.18+
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
 }
 
 type WingetLocale struct {
@@ -468,6 +483,9 @@ type Scoop struct {
 	Depends               []string     `yaml:"depends,omitempty" json:"depends,omitempty"`
 	Shortcuts             [][]string   `yaml:"shortcuts,omitempty" json:"shortcuts,omitempty"`
 	Goamd64               string       `yaml:"goamd64,omitempty" json:"goamd64,omitempty"`
+
+	// v2.18+
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
 }
 
 // CommitAuthor is the author of a Git commit.
@@ -532,6 +550,9 @@ type Build struct {
 	UnproxiedMain   string          `yaml:"-" json:"-"` // used by gomod.proxy
 	UnproxiedDir    string          `yaml:"-" json:"-"` // used by gomod.proxy
 
+	// v2.18+
+	VersionFiles []VersionFile `yaml:"version_files,omitempty" json:"version_files,omitempty"`
+
 	BuildDetails          `yaml:",inline" json:",inline"`
 	BuildDetailsOverrides []BuildDetailsOverride `yaml:"overrides,omitempty" json:"overrides,omitempty"`
 
@@ -542,6 +563,13 @@ type Build struct {
 	GoBinary string `yaml:"gobinary,omitempty" json:"gobinary,omitempty" jsonschema:"deprecated=true"`
 }
 
+// VersionFile is a manifest file that gets its version rewritten before the
+// build.
+type VersionFile struct {
+	Path    string `yaml:"path" json:"path"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
 type BuildInternalDefaults struct {
 	// whether the pipe set the current binary.
 	// this is true when the user didn't set a binary name.
@@ -1016,6 +1044,34 @@ type SBOM struct {
 
 	// v2.10+
 	Disable string `yaml:"disable,omitempty" json:"disable,omitempty" jsonschema:"oneof_type=string;boolean"`
+
+	// v2.18+
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty" jsonschema:"enum=cmd,enum=go,enum=lockfile,default=cmd"`
+	Format  string `yaml:"format,omitempty" json:"format,omitempty" jsonschema:"enum=spdx,enum=cyclonedx,default=spdx"`
+}
+
+// Provenance config.
+type Provenance struct {
+	Enabled      string   `yaml:"enabled,omitempty" json:"enabled,omitempty" jsonschema:"oneof_type=string;boolean"`
+	NameTemplate string   `yaml:"name_template,omitempty" json:"name_template,omitempty"`
+	Key          string   `yaml:"key,omitempty" json:"key,omitempty"`
+	BuilderID    string   `yaml:"builder_id,omitempty" json:"builder_id,omitempty"`
+	Env          []string `yaml:"env,omitempty" json:"env,omitempty"`
+}
+
+// VulnCheck config.
+type VulnCheck struct {
+	Enabled string           `yaml:"enabled,omitempty" json:"enabled,omitempty" jsonschema:"oneof_type=string;boolean"`
+	DB      string           `yaml:"db,omitempty" json:"db,omitempty"`
+	FailOn  string           `yaml:"fail_on,omitempty" json:"fail_on,omitempty" jsonschema:"enum=none,enum=low,enum=medium,enum=high,enum=critical,default=high"`
+	Allow   []VulnCheckAllow `yaml:"allow,omitempty" json:"allow,omitempty"`
+}
+
+// VulnCheckAllow is an allowed vulnerability.
+type VulnCheckAllow struct {
+	ID      string `yaml:"id" json:"id"`
+	Expires string `yaml:"expires,omitempty" json:"expires,omitempty"`
+	Reason  string `yaml:"reason,omitempty" json:"reason,omitempty"`
 }
 
 // Sign config.
@@ -1031,6 +1087,12 @@ type Sign struct {
 	Env         []string `yaml:"env,omitempty" json:"env,omitempty"`
 	Certificate string   `yaml:"certificate,omitempty" json:"certificate,omitempty"`
 	Output      string   `yaml:"output,omitempty" json:"output,omitempty" jsonschema:"oneof_type=string;boolean"`
+
+	// v2.18+
+	Backend   string `yaml:"backend,omitempty" json:"backend,omitempty" jsonschema:"enum=cmd,enum=openpgp,enum=minisign,enum=ssh,default=cmd"`
+	Key       string `yaml:"key,omitempty" json:"key,omitempty"`
+	Password  string `yaml:"password,omitempty" json:"password,omitempty"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
 }
 
 // BinarySign config.
@@ -1046,10 +1108,32 @@ type BinarySign struct {
 	Env         []string `yaml:"env,omitempty" json:"env,omitempty"`
 	Certificate string   `yaml:"certificate,omitempty" json:"certificate,omitempty"`
 	Output      string   `yaml:"output,omitempty" json:"output,omitempty" jsonschema:"oneof_type=string;boolean"`
+
+	// v2.18+
+	Backend   string `yaml:"backend,omitempty" json:"backend,omitempty" jsonschema:"enum=cmd,enum=openpgp,enum=minisign,enum=ssh,default=cmd"`
+	Key       string `yaml:"key,omitempty" json:"key,omitempty"`
+	Password  string `yaml:"password,omitempty" json:"password,omitempty"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
 }
 
 type Notarize struct {
 	MacOS []MacOSSignNotarize `yaml:"macos" json:"macos"`
+
+	// v2.18+
+	Windows []WindowsSign `yaml:"windows,omitempty" json:"windows,omitempty"`
+}
+
+// WindowsSign configures the Authenticode signing of Windows binaries and
+// installers.
+type WindowsSign struct {
+	IDs          []string `yaml:"ids,omitempty" json:"ids,omitempty"`
+	Enabled      string   `yaml:"enabled,omitempty" json:"enabled,omitempty" jsonschema:"oneof_type=string;boolean"`
+	Certificate  string   `yaml:"certificate" json:"certificate"`
+	Key          string   `yaml:"key,omitempty" json:"key,omitempty"`
+	Password     string   `yaml:"password,omitempty" json:"password,omitempty"`
+	Description  string   `yaml:"description,omitempty" json:"description,omitempty"`
+	URL          string   `yaml:"url,omitempty" json:"url,omitempty"`
+	TimestampURL string   `yaml:"timestamp_url,omitempty" json:"timestamp_url,omitempty"`
 }
 
 type MacOSSignNotarize struct {
@@ -1073,6 +1157,11 @@ type MacOSSign struct {
 
 	// v2.6+
 	Entitlements string `yaml:"entitlements,omitempty" json:"entitlements,omitempty"`
+
+	// v2.18+
+	InstallerCertificate string `yaml:"installer_certificate,omitempty" json:"installer_certificate,omitempty"`
+	InstallerPassword    string `yaml:"installer_password,omitempty" json:"installer_password,omitempty"`
+	TimestampURL         string `yaml:"timestamp_url,omitempty" json:"timestamp_url,omitempty"`
 }
 
 // SnapcraftAppMetadata for the binaries that will be in the snap package.
@@ -1196,6 +1285,19 @@ type Checksum struct {
 	IDs          []string    `yaml:"ids,omitempty" json:"ids,omitempty"`
 	Disable      bool        `yaml:"disable,omitempty" json:"disable,omitempty"`
 	ExtraFiles   []ExtraFile `yaml:"extra_files,omitempty" json:"extra_files,omitempty"`
+
+	// v2.18+
+	Algorithms []string          `yaml:"algorithms,omitempty" json:"algorithms,omitempty"`
+	Format     string            `yaml:"format,omitempty" json:"format,omitempty" jsonschema:"enum=gnu,enum=bsd,default=gnu"`
+	ClearSign  ChecksumClearSign `yaml:"clearsign,omitempty" json:"clearsign,omitempty"`
+}
+
+// ChecksumClearSign configures the OpenPGP cleartext signing of the
+// checksums files.
+type ChecksumClearSign struct {
+	Enabled  string `yaml:"enabled,omitempty" json:"enabled,omitempty" jsonschema:"oneof_type=string;boolean"`
+	Key      string `yaml:"key,omitempty" json:"key,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
 }
 
 // Retry config for operations that support retries.
@@ -1259,9 +1361,34 @@ type DockerV2 struct {
 	SBOM        string            `yaml:"sbom,omitempty" json:"sbom,omitempty" jsonschema:"oneof_type=string;boolean"`
 	Hooks       BuildHookConfig   `yaml:"hooks,omitempty" json:"hooks,omitempty"`
 
+	// v2.18+
+	Channels  []string        `yaml:"channels,omitempty" json:"channels,omitempty"`
+	Audit     DockerV2Audit   `yaml:"audit,omitempty" json:"audit,omitempty"`
+	CacheFrom []DockerV2Cache `yaml:"cache_from,omitempty" json:"cache_from,omitempty"`
+	CacheTo   []DockerV2Cache `yaml:"cache_to,omitempty" json:"cache_to,omitempty"`
+
 	Retry Retry `yaml:"retry,omitempty" json:"retry,omitempty"` // Deprecated: use [Project.Retry] instead.
 }
 
+// DockerV2Cache is a build cache to import from or export to.
+type DockerV2Cache struct {
+	Dir  string `yaml:"dir,omitempty" json:"dir,omitempty"`
+	Ref  string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty" jsonschema:"enum=min,enum=max,default=max"`
+}
+
+// DockerV2Audit configures the checks done on the images before they are
+// pushed.
+type DockerV2Audit struct {
+	Enabled         string   `yaml:"enabled,omitempty" json:"enabled,omitempty" jsonschema:"oneof_type=string;boolean"`
+	NonRoot         string   `yaml:"non_root,omitempty" json:"non_root,omitempty" jsonschema:"oneof_type=string;boolean"`
+	Secrets         string   `yaml:"secrets,omitempty" json:"secrets,omitempty" jsonschema:"oneof_type=string;boolean"`
+	SecretPatterns  []string `yaml:"secret_patterns,omitempty" json:"secret_patterns,omitempty"`
+	RequiredLabels  []string `yaml:"required_labels,omitempty" json:"required_labels,omitempty"`
+	PinnedBaseImage string   `yaml:"pinned_base_image,omitempty" json:"pinned_base_image,omitempty" jsonschema:"oneof_type=string;boolean"`
+	MaxSize         string   `yaml:"max_size,omitempty" json:"max_size,omitempty"`
+}
+
 // DockerDigest config.
 type DockerDigest struct {
 	Disable      string `yaml:"disable,omitempty" json:"disable,omitempty" jsonschema:"oneof_type=string;boolean"`
@@ -1283,6 +1410,27 @@ type Changelog struct {
 	Format  string           `yaml:"format,omitempty" json:"format,omitempty"`
 	Groups  []ChangelogGroup `yaml:"groups,omitempty" json:"groups,omitempty"`
 	Abbrev  int              `yaml:"abbrev,omitempty" json:"abbrev,omitempty"`
+
+	// v2.18+
+	Contributors   ChangelogContributors `yaml:"contributors,omitempty" json:"contributors,omitempty"`
+	Formats        []string              `yaml:"formats,omitempty" json:"formats,omitempty" jsonschema:"enum=markdown,enum=json"`
+	KeepAChangelog KeepAChangelog        `yaml:"keep_a_changelog,omitempty" json:"keep_a_changelog,omitempty"`
+}
+
+// KeepAChangelog configures the update of a repository-tracked changelog file
+// in the Keep a Changelog format.
+type KeepAChangelog struct {
+	Enabled               string       `yaml:"enabled,omitempty" json:"enabled,omitempty" jsonschema:"oneof_type=string;boolean"`
+	Path                  string       `yaml:"path,omitempty" json:"path,omitempty"`
+	Repository            RepoRef      `yaml:"repository,omitempty" json:"repository,omitempty"`
+	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty" json:"commit_author,omitempty"`
+	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty" json:"commit_msg_template,omitempty"`
+}
+
+// ChangelogContributors configures the contributors section of the changelog.
+type ChangelogContributors struct {
+	Enabled  string `yaml:"enabled,omitempty" json:"enabled,omitempty" jsonschema:"oneof_type=string;boolean"`
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
 }
 
 // ChangelogGroup holds the grouping criteria for the changelog.
@@ -1323,6 +1471,23 @@ type Blob struct {
 	ContentDisposition string      `yaml:"content_disposition,omitempty" json:"content_disposition,omitempty"`
 	IncludeMeta        bool        `yaml:"include_meta,omitempty" json:"include_meta,omitempty"`
 	ExtraFilesOnly     bool        `yaml:"extra_files_only,omitempty" json:"extra_files_only,omitempty"`
+
+	// v2.18+
+	KMSEnvelope bool          `yaml:"kms_envelope,omitempty" json:"kms_envelope,omitempty"`
+	Channels    []string      `yaml:"channels,omitempty" json:"channels,omitempty"`
+	PartSize    int           `yaml:"part_size,omitempty" json:"part_size,omitempty"`
+	Overwrite   *bool         `yaml:"overwrite,omitempty" json:"overwrite,omitempty"`
+	Index       bool          `yaml:"index,omitempty" json:"index,omitempty"`
+	Retention   BlobRetention `yaml:"retention,omitempty" json:"retention,omitempty"`
+}
+
+// BlobRetention configures which old versions should be deleted from a blob
+// bucket.
+type BlobRetention struct {
+	KeepLast             int  `yaml:"keep_last,omitempty" json:"keep_last,omitempty"`
+	KeepStable           bool `yaml:"keep_stable,omitempty" json:"keep_stable,omitempty"`
+	PrereleaseMaxAgeDays int  `yaml:"prerelease_max_age_days,omitempty" json:"prerelease_max_age_days,omitempty"`
+	DryRun               bool `yaml:"dry_run,omitempty" json:"dry_run,omitempty"`
 }
 
 // Upload configuration.
@@ -1349,6 +1514,11 @@ type Upload struct {
 
 	// Since v2.12
 	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+
+	// v2.18+
+	Channels       []string `yaml:"channels,omitempty" json:"channels,omitempty"`
+	Parallelism    int      `yaml:"parallelism,omitempty" json:"parallelism,omitempty"`
+	ChecksumDeploy bool     `yaml:"checksum_deploy,omitempty" json:"checksum_deploy,omitempty"`
 }
 
 // Publisher configuration.
@@ -1427,6 +1597,18 @@ type Project struct {
 	Iru               Iru               `yaml:"iru,omitempty" json:"iru,omitempty"`
 	Retry             Retry             `yaml:"retry,omitempty" json:"retry,omitempty"`
 
+	// v2.18+
+	Provenance      Provenance       `yaml:"provenance,omitempty" json:"provenance,omitempty"`
+	VulnCheck       VulnCheck        `yaml:"vulncheck,omitempty" json:"vulncheck,omitempty"`
+	Versioning      Versioning       `yaml:"versioning,omitempty" json:"versioning,omitempty"`
+	MacOSPkgs       []MacOSPkg       `yaml:"macos_pkgs,omitempty" json:"macos_pkgs,omitempty"`
+	MacOSDMGs       []MacOSDMG       `yaml:"macos_dmgs,omitempty" json:"macos_dmgs,omitempty"`
+	WindowsMSIs     []WindowsMSI     `yaml:"windows_msis,omitempty" json:"windows_msis,omitempty"`
+	OCIImages       []OCIImage       `yaml:"oci_images,omitempty" json:"oci_images,omitempty"`
+	OCIArtifacts    []OCIArtifact    `yaml:"oci_artifacts,omitempty" json:"oci_artifacts,omitempty"`
+	HelmCharts      []HelmChart      `yaml:"helm_charts,omitempty" json:"helm_charts,omitempty"`
+	DeployManifests []DeployManifest `yaml:"deploy_manifests,omitempty" json:"deploy_manifests,omitempty"`
+
 	// force the SCM token to use when multiple are set
 	ForceToken string `yaml:"force_token,omitempty" json:"force_token,omitempty" jsonschema:"enum=github,enum=gitlab,enum=gitea,enum=,default="`
 
@@ -1477,6 +1659,9 @@ type Announce struct {
 	OpenCollective OpenCollective `yaml:"opencollective,omitempty" json:"opencollective,omitempty"`
 	Bluesky        Bluesky        `yaml:"bluesky,omitempty" json:"bluesky,omitempty"`
 	Discourse      Discourse      `yaml:"discourse,omitempty" json:"discourse,omitempty"`
+
+	// v2.18+
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty"`
 }
 
 type Webhook struct {
@@ -1669,6 +1854,142 @@ type MakeselfFile struct {
 	StripParent bool   `yaml:"strip_parent,omitempty" json:"strip_parent,omitempty"`
 }
 
+// MacOSPkg configures a macOS flat installer package.
+type MacOSPkg struct {
+	ID              string   `yaml:"id,omitempty" json:"id,omitempty"`
+	Name            string   `yaml:"name,omitempty" json:"name,omitempty"`
+	IDs             []string `yaml:"ids,omitempty" json:"ids,omitempty"`
+	Goamd64         string   `yaml:"goamd64,omitempty" json:"goamd64,omitempty"`
+	Identifier      string   `yaml:"identifier" json:"identifier"`
+	InstallLocation string   `yaml:"install_location,omitempty" json:"install_location,omitempty"`
+	Scripts         string   `yaml:"scripts,omitempty" json:"scripts,omitempty"`
+	Replace         bool     `yaml:"replace,omitempty" json:"replace,omitempty"`
+	ModTimestamp    string   `yaml:"mod_timestamp,omitempty" json:"mod_timestamp,omitempty"`
+}
+
+// MacOSDMG configures a macOS disk image.
+type MacOSDMG struct {
+	ID           string      `yaml:"id,omitempty" json:"id,omitempty"`
+	Name         string      `yaml:"name,omitempty" json:"name,omitempty"`
+	IDs          []string    `yaml:"ids,omitempty" json:"ids,omitempty"`
+	Goamd64      string      `yaml:"goamd64,omitempty" json:"goamd64,omitempty"`
+	ExtraFiles   []ExtraFile `yaml:"extra_files,omitempty" json:"extra_files,omitempty"`
+	Replace      bool        `yaml:"replace,omitempty" json:"replace,omitempty"`
+	ModTimestamp string      `yaml:"mod_timestamp,omitempty" json:"mod_timestamp,omitempty"`
+}
+
+// WindowsMSI configures a Windows installer package.
+type WindowsMSI struct {
+	ID           string   `yaml:"id,omitempty" json:"id,omitempty"`
+	Name         string   `yaml:"name,omitempty" json:"name,omitempty"`
+	WXS          string   `yaml:"wxs,omitempty" json:"wxs,omitempty"`
+	IDs          []string `yaml:"ids,omitempty" json:"ids,omitempty"`
+	Goamd64      string   `yaml:"goamd64,omitempty" json:"goamd64,omitempty"`
+	ExtraFiles   []string `yaml:"extra_files,omitempty" json:"extra_files,omitempty"`
+	Extensions   []string `yaml:"extensions,omitempty" json:"extensions,omitempty"`
+	Version      string   `yaml:"version,omitempty" json:"version,omitempty" jsonschema:"enum=v3,enum=v4"`
+	Manufacturer string   `yaml:"manufacturer,omitempty" json:"manufacturer,omitempty"`
+	UpgradeCode  string   `yaml:"upgrade_code,omitempty" json:"upgrade_code,omitempty"`
+	Scope        string   `yaml:"scope,omitempty" json:"scope,omitempty" jsonschema:"enum=perMachine,enum=perUser,default=perMachine"`
+	AddToPath    bool     `yaml:"add_to_path,omitempty" json:"add_to_path,omitempty"`
+	Disable      string   `yaml:"disable,omitempty" json:"disable,omitempty" jsonschema:"oneof_type=string;boolean"`
+	Replace      bool     `yaml:"replace,omitempty" json:"replace,omitempty"`
+	ModTimestamp string   `yaml:"mod_timestamp,omitempty" json:"mod_timestamp,omitempty"`
+}
+
+// OCIImage configures an OCI image assembled from prebuilt binaries, without
+// a Docker daemon.
+type OCIImage struct {
+	ID           string            `yaml:"id,omitempty" json:"id,omitempty"`
+	IDs          []string          `yaml:"ids,omitempty" json:"ids,omitempty"`
+	BaseImage    string            `yaml:"base_image,omitempty" json:"base_image,omitempty"`
+	Images       []string          `yaml:"images,omitempty" json:"images,omitempty"`
+	Tags         []string          `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Platforms    []string          `yaml:"platforms,omitempty" json:"platforms,omitempty"`
+	BinaryDir    string            `yaml:"binary_dir,omitempty" json:"binary_dir,omitempty"`
+	ExtraFiles   []File            `yaml:"extra_files,omitempty" json:"extra_files,omitempty"`
+	Labels       map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations  map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	User         string            `yaml:"user,omitempty" json:"user,omitempty"`
+	Entrypoint   []string          `yaml:"entrypoint,omitempty" json:"entrypoint,omitempty"`
+	Cmd          []string          `yaml:"cmd,omitempty" json:"cmd,omitempty"`
+	Env          []string          `yaml:"env,omitempty" json:"env,omitempty"`
+	ModTimestamp string            `yaml:"mod_timestamp,omitempty" json:"mod_timestamp,omitempty"`
+	Disable      string            `yaml:"disable,omitempty" json:"disable,omitempty" jsonschema:"oneof_type=string;boolean"`
+}
+
+// OCIArtifact configures the publishing of release artifacts to an OCI
+// registry as an OCI artifact.
+type OCIArtifact struct {
+	ID           string            `yaml:"id,omitempty" json:"id,omitempty"`
+	IDs          []string          `yaml:"ids,omitempty" json:"ids,omitempty"`
+	Artifacts    string            `yaml:"artifacts,omitempty" json:"artifacts,omitempty" jsonschema:"enum=all,enum=archive,enum=binary,enum=package,enum=checksum,enum=source,default=all"`
+	Repository   string            `yaml:"repository,omitempty" json:"repository,omitempty"`
+	Tags         []string          `yaml:"tags,omitempty" json:"tags,omitempty"`
+	ArtifactType string            `yaml:"artifact_type,omitempty" json:"artifact_type,omitempty"`
+	Annotations  map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Disable      string            `yaml:"disable,omitempty" json:"disable,omitempty" jsonschema:"oneof_type=string;boolean"`
+}
+
+// HelmChart configures the packaging and publishing of a Helm chart.
+type HelmChart struct {
+	ID                    string            `yaml:"id,omitempty" json:"id,omitempty"`
+	Path                  string            `yaml:"path,omitempty" json:"path,omitempty"`
+	Version               string            `yaml:"version,omitempty" json:"version,omitempty"`
+	AppVersion            string            `yaml:"app_version,omitempty" json:"app_version,omitempty"`
+	ModTimestamp          string            `yaml:"mod_timestamp,omitempty" json:"mod_timestamp,omitempty"`
+	Values                map[string]string `yaml:"values,omitempty" json:"values,omitempty"`
+	Sign                  HelmChartSign     `yaml:"sign,omitempty" json:"sign,omitempty"`
+	Registry              string            `yaml:"registry,omitempty" json:"registry,omitempty"`
+	Blob                  HelmChartBlob     `yaml:"blob,omitempty" json:"blob,omitempty"`
+	Repository            RepoRef           `yaml:"repository,omitempty" json:"repository,omitempty"`
+	Directory             string            `yaml:"directory,omitempty" json:"directory,omitempty"`
+	CommitAuthor          CommitAuthor      `yaml:"commit_author,omitempty" json:"commit_author,omitempty"`
+	CommitMessageTemplate string            `yaml:"commit_msg_template,omitempty" json:"commit_msg_template,omitempty"`
+	Disable               string            `yaml:"disable,omitempty" json:"disable,omitempty" jsonschema:"oneof_type=string;boolean"`
+}
+
+// DeployManifest configures the Kubernetes manifests updated in a GitOps
+// repository once the images are pushed.
+type DeployManifest struct {
+	ID                    string                   `yaml:"id,omitempty" json:"id,omitempty"`
+	IDs                   []string                 `yaml:"ids,omitempty" json:"ids,omitempty"`
+	Templates             []DeployManifestTemplate `yaml:"templates,omitempty" json:"templates,omitempty"`
+	Kustomize             DeployManifestKustomize  `yaml:"kustomize,omitempty" json:"kustomize,omitempty"`
+	Repository            RepoRef                  `yaml:"repository,omitempty" json:"repository,omitempty"`
+	CommitAuthor          CommitAuthor             `yaml:"commit_author,omitempty" json:"commit_author,omitempty"`
+	CommitMessageTemplate string                   `yaml:"commit_msg_template,omitempty" json:"commit_msg_template,omitempty"`
+	Disable               string                   `yaml:"disable,omitempty" json:"disable,omitempty" jsonschema:"oneof_type=string;boolean"`
+}
+
+// DeployManifestTemplate is a Kubernetes manifest template, rendered to the
+// given path in the repository.
+type DeployManifestTemplate struct {
+	Src string `yaml:"src,omitempty" json:"src,omitempty"`
+	Dst string `yaml:"dst,omitempty" json:"dst,omitempty"`
+}
+
+// DeployManifestKustomize configures the Kustomization file generated with
+// the images pinned by digest.
+type DeployManifestKustomize struct {
+	Path      string   `yaml:"path,omitempty" json:"path,omitempty"`
+	Resources []string `yaml:"resources,omitempty" json:"resources,omitempty"`
+}
+
+// HelmChartSign configures the provenance file of a Helm chart.
+type HelmChartSign struct {
+	Key      string `yaml:"key,omitempty" json:"key,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+}
+
+// HelmChartBlob configures the publishing of a Helm chart to a chart
+// repository hosted in a blob storage bucket.
+type HelmChartBlob struct {
+	Bucket    string `yaml:"bucket,omitempty" json:"bucket,omitempty"`
+	Directory string `yaml:"directory,omitempty" json:"directory,omitempty"`
+	URL       string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
 // MCP server configuration.
 type MCP struct {
 	// Deprecated: Use top-level MCP fields instead of nesting under GitHub.
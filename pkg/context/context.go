@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/goreleaser/goreleaser/v2/internal/artifact"
+	"github.com/goreleaser/goreleaser/v2/internal/changelog"
 	"github.com/goreleaser/goreleaser/v2/pkg/config"
 )
 
@@ -95,6 +96,8 @@ type Context struct {
 	ReleaseHeaderTmpl string
 	ReleaseFooterFile string
 	ReleaseFooterTmpl string
+	Contributors      []changelog.Contributor
+	Vulnerabilities   []Vulnerability
 	Version           string
 	ModulePath        string
 	PartialTarget     string
@@ -108,12 +111,29 @@ type Context struct {
 	Deprecated        bool
 	Parallelism       int
 	Semver            Semver
+	CalVer            CalVer
 	Runtime           Runtime
 	Skips             map[string]bool
 
 	NotifiedDeprecations map[string]struct{}
 }
 
+// Vulnerability is a known vulnerability affecting a component of the
+// release.
+type Vulnerability struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+	Severity  string   `json:"severity"`
+	Ecosystem string   `json:"ecosystem"`
+	Package   string   `json:"package"`
+	Version   string   `json:"version"`
+	Fixed     string   `json:"fixed,omitempty"`
+	Artifacts []string `json:"artifacts"`
+	Allowed   bool     `json:"allowed"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
 type Runtime struct {
 	Goos   string
 	Goarch string
@@ -127,6 +147,18 @@ type Semver struct {
 	Prerelease string
 }
 
+// CalVer represents a calendar version.
+type CalVer struct {
+	Year     uint64
+	Month    uint64
+	Week     uint64
+	Day      uint64
+	Major    uint64
+	Minor    uint64
+	Micro    uint64
+	Modifier string
+}
+
 // WrapWithTimeout new context with the given timeout.
 func WrapWithTimeout(parent stdctx.Context, config config.Project, timeout time.Duration) (*Context, stdctx.CancelFunc) {
 	ctx, cancel := stdctx.WithTimeout(parent, timeout) // nosem
@@ -17,27 +17,35 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/changelog"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/checksums"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/chocolatey"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/deploy"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/discord"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/discourse"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/dist"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/dmg"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/docker"
 	dockerv2 "github.com/goreleaser/goreleaser/v2/internal/pipe/docker/v2"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/dockerdigest"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/flatpak"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/gomod"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/helm"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/ko"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/krew"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/linkedin"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/macospkg"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/makeself"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/mastodon"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/mattermost"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/mcp"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/nfpm"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/nix"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/notary"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/ociartifact"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/ociimage"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/opencollective"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/project"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/provenance"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/reddit"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/sbom"
@@ -55,6 +63,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/universalbinary"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/upload"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/upx"
+	"github.com/goreleaser/goreleaser/v2/internal/pipe/vulncheck"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/webhook"
 	"github.com/goreleaser/goreleaser/v2/internal/pipe/winget"
 	"github.com/goreleaser/goreleaser/v2/pkg/context"
@@ -91,10 +100,15 @@ var Defaulters = []Defaulter{
 	srpm.Pipe{},
 	snapcraft.Pipe{},
 	flatpak.Pipe{},
+	macospkg.Pipe{},
+	dmg.Pipe{},
+	msi.Pipe{},
 	checksums.Pipe{},
 	sign.Pipe{},
 	sign.DockerPipe{},
 	sbom.Pipe{},
+	vulncheck.Pipe{},
+	provenance.Pipe{},
 	docker.Pipe{},
 	dockerv2.Base{},
 	docker.ManifestPipe{},
@@ -110,6 +124,10 @@ var Defaulters = []Defaulter{
 	cask.Pipe{},
 	krew.Pipe{},
 	ko.Pipe{},
+	ociimage.Pipe{},
+	ociartifact.Pipe{},
+	helm.Pipe{},
+	deploy.Pipe{},
 	scoop.Pipe{},
 	mcp.Pipe{},
 	discord.Pipe{},